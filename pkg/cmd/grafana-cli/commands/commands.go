@@ -166,6 +166,51 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "import-prometheus-rules",
+		Usage:  "import-prometheus-rules --folder <folder title> --datasource-uid <data source UID> <rule file>...",
+		Action: runPluginCommand(importPrometheusRulesCommand),
+		CustomHelpTemplate: `
+This command converts the rule groups of Prometheus or Loki rule files to Grafana-managed alert and recording rules
+that query the given data source. The result is a file provisioning file that can be placed in the
+provisioning/alerting directory of Grafana.
+
+# prints the rules that would be created
+grafana-cli admin import-prometheus-rules --folder Mimir --datasource-uid mimir --dry-run rules.yaml
+
+# writes the provisioning file
+grafana-cli admin import-prometheus-rules --folder Mimir --datasource-uid mimir --output provisioning/alerting/mimir.yaml rules.yaml
+`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "folder",
+				Usage: "The title of the folder the rules are provisioned to",
+			},
+			&cli.StringFlag{
+				Name:  "datasource-uid",
+				Usage: "The UID of the data source the rules query",
+			},
+			&cli.StringFlag{
+				Name:  "datasource-type",
+				Usage: "The type of the data source, prometheus or loki",
+				Value: "prometheus",
+			},
+			&cli.StringFlag{
+				Name:  "interval",
+				Usage: "The evaluation interval of rule groups that do not set one",
+				Value: "1m",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "The path of the provisioning file. The file is written to stdout if it is not set",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the rules that would be created without writing the provisioning file",
+				Value: false,
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// importPrometheusRulesCommand converts the rule groups of Prometheus or Loki rule files to a Grafana file
+// provisioning file of Grafana-managed rules.
+func importPrometheusRulesCommand(c utils.CommandLine) error {
+	files := c.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("at least one rule file must be specified")
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		return errors.New("the folder of the rules must be specified with --folder")
+	}
+	interval, err := time.ParseDuration(c.String("interval"))
+	if err != nil {
+		return fmt.Errorf("invalid interval: %w", err)
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		OrgID:           1,
+		DatasourceUID:   c.String("datasource-uid"),
+		DatasourceType:  c.String("datasource-type"),
+		DefaultInterval: interval,
+	})
+	if err != nil {
+		return err
+	}
+
+	var ruleFile prom.RuleFile
+	for _, f := range files {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path is a command line argument.
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to read rule file: %w", err)
+		}
+		parsed, err := prom.ParseRuleFile(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		ruleFile.Groups = append(ruleFile.Groups, parsed.Groups...)
+	}
+
+	groups, err := converter.ConvertRuleFile(ruleFile)
+	if err != nil {
+		return err
+	}
+	cfg := setting.UnifiedAlertingSettings{BaseInterval: setting.SchedulerBaseInterval}
+	withFolder := make([]models.AlertRuleGroupWithFolderTitle, 0, len(groups))
+	for i := range groups {
+		group := &groups[i]
+		for j := range group.Rules {
+			rule := &group.Rules[j]
+			if err := rule.ValidateAlertRule(cfg); err != nil {
+				return fmt.Errorf("rule group %q: invalid rule %q: %w", group.Title, rule.Title, err)
+			}
+			rule.UID = importedRuleUID(folder, group.Title, rule.Title)
+		}
+		withFolder = append(withFolder, models.AlertRuleGroupWithFolderTitle{AlertRuleGroup: group, OrgID: 1, FolderTitle: folder})
+	}
+
+	if c.Bool("dry-run") {
+		printImportReport(folder, groups)
+		return nil
+	}
+
+	export, err := api.AlertingFileExportFromAlertRuleGroupWithFolderTitle(withFolder)
+	if err != nil {
+		return fmt.Errorf("failed to create provisioning file: %w", err)
+	}
+	out, err := yaml.Marshal(export)
+	if err != nil {
+		return fmt.Errorf("failed to create provisioning file: %w", err)
+	}
+
+	output := c.String("output")
+	if output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := os.WriteFile(output, out, 0640); err != nil {
+		return fmt.Errorf("failed to write provisioning file: %w", err)
+	}
+	logger.Infof("Converted %d rule groups to %s %s\n", len(groups), output, color.GreenString("✔"))
+	return nil
+}
+
+// importedRuleUID returns the UID of a converted rule. It is derived from the folder, group and title of the rule so
+// that converting the same rules again results in the same UIDs and provisioning updates the existing rules.
+func importedRuleUID(folder, group, title string) string {
+	h := fnv.New64a()
+	for _, s := range []string{folder, group, title} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	return fmt.Sprintf("prom-%x", h.Sum64())
+}
+
+func printImportReport(folder string, groups []models.AlertRuleGroup) {
+	logger.Infof("The following rule groups would be created in folder %q:\n", folder)
+	for _, group := range groups {
+		logger.Infof("\n%s (every %s)\n", color.CyanString(group.Title), time.Duration(group.Interval)*time.Second)
+		for _, rule := range group.Rules {
+			if rule.Type() == models.RuleTypeRecording {
+				logger.Infof("  %s recording rule %q, records %s\n", color.GreenString("+"), rule.Title, rule.Record.Metric)
+				continue
+			}
+			logger.Infof("  %s alerting rule %q, pending period %s\n", color.GreenString("+"), rule.Title, rule.For)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const testRuleFile = `
+groups:
+  - name: example
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: page
+`
+
+func newImportRulesContext(t *testing.T, flags map[string]string, args ...string) utils.CommandLine {
+	t.Helper()
+	flagSet := flag.NewFlagSet("Test", 0)
+	for name, value := range map[string]string{"datasource-type": "prometheus", "interval": "1m"} {
+		flagSet.String(name, value, "")
+	}
+	for name, value := range flags {
+		if flagSet.Lookup(name) == nil {
+			flagSet.String(name, "", "")
+		}
+		require.NoError(t, flagSet.Set(name, value))
+	}
+	require.NoError(t, flagSet.Parse(args))
+	return &utils.ContextCommandLine{Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil)}
+}
+
+func TestImportPrometheusRulesCommand(t *testing.T) {
+	dir := t.TempDir()
+	ruleFile := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(ruleFile, []byte(testRuleFile), 0600))
+
+	t.Run("writes provisioning file", func(t *testing.T) {
+		output := filepath.Join(dir, "alerting.yaml")
+		c := newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir", "output": output}, ruleFile)
+
+		require.NoError(t, importPrometheusRulesCommand(c))
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+		var export definitions.AlertingFileExport
+		require.NoError(t, yaml.Unmarshal(content, &export))
+		require.Len(t, export.Groups, 1)
+		group := export.Groups[0]
+		require.Equal(t, "example", group.Name)
+		require.Equal(t, "Mimir", group.Folder)
+		require.Len(t, group.Rules, 1)
+		rule := group.Rules[0]
+		require.Equal(t, "InstanceDown", rule.Title)
+		require.Equal(t, importedRuleUID("Mimir", "example", "InstanceDown"), rule.UID)
+		require.Equal(t, "C", rule.Condition)
+		require.Equal(t, "mimir", rule.Data[0].DatasourceUID)
+	})
+
+	t.Run("does not write file on dry run", func(t *testing.T) {
+		output := filepath.Join(dir, "dry-run.yaml")
+		c := newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir", "output": output, "dry-run": "true"}, ruleFile)
+
+		require.NoError(t, importPrometheusRulesCommand(c))
+
+		require.NoFileExists(t, output)
+	})
+
+	t.Run("fails on invalid input", func(t *testing.T) {
+		tests := map[string]utils.CommandLine{
+			"no rule files":       newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir"}),
+			"no folder":           newImportRulesContext(t, map[string]string{"datasource-uid": "mimir"}, ruleFile),
+			"no data source":      newImportRulesContext(t, map[string]string{"folder": "Mimir"}, ruleFile),
+			"unsupported type":    newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir", "datasource-type": "graphite"}, ruleFile),
+			"missing rule file":   newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir"}, filepath.Join(dir, "missing.yaml")),
+			"duplicate group":     newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir"}, ruleFile, ruleFile),
+			"interval is invalid": newImportRulesContext(t, map[string]string{"folder": "Mimir", "datasource-uid": "mimir", "interval": "15s"}, ruleFile),
+		}
+		for name, c := range tests {
+			t.Run(name, func(t *testing.T) {
+				require.Error(t, importPrometheusRulesCommand(c))
+			})
+		}
+	})
+}
@@ -24,6 +24,8 @@ const (
 	TypeDatasourceNode
 	// TypeMLNode is a NodeType for Machine Learning queries.
 	TypeMLNode
+	// TypeInputNode is a NodeType for queries whose data is provided by the caller.
+	TypeInputNode
 )
 
 func (nt NodeType) String() string {
@@ -34,6 +36,8 @@ func (nt NodeType) String() string {
 		return "Datasource"
 	case TypeMLNode:
 		return "Machine Learning"
+	case TypeInputNode:
+		return "Input"
 	default:
 		return "Unknown"
 	}
@@ -60,6 +64,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	stats := executionStatsFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -111,7 +116,9 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
+		start := time.Now()
 		res, err := execNode.Execute(c, now, vars, s)
+		stats.record(node, time.Since(start))
 		if err != nil {
 			res.Error = err
 		}
@@ -233,6 +240,8 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 					err = fmt.Errorf("fail to parse expression with refID %v: %w", rn.RefID, err)
 				}
 			}
+		case TypeInputNode:
+			node = buildInputNode(rn, query)
 		}
 
 		if node == nil && err == nil {
@@ -285,10 +294,34 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 				}
 			}
 
+			if cmdNode.CMDType == TypeSQL && neededNode.NodeType() == TypeDatasourceNode {
+				neededNode.(*DSNode).isInputToSQLExpr = true
+			}
+
 			edge := dp.NewEdge(neededNode, cmdNode)
 
 			dp.SetEdge(edge)
 		}
 	}
+	return validateSQLInputs(dp)
+}
+
+// validateSQLInputs checks that queries whose results are passed to SQL expressions as tables
+// are not also the input of other expressions, which require the results to be numbers or series.
+func validateSQLInputs(dp *simple.DirectedGraph) error {
+	nodeIt := dp.Nodes()
+	for nodeIt.Next() {
+		dsNode, ok := nodeIt.Node().(*DSNode)
+		if !ok || !dsNode.isInputToSQLExpr {
+			continue
+		}
+		toIt := dp.From(dsNode.ID())
+		for toIt.Next() {
+			cmdNode := toIt.Node().(*CMDNode)
+			if cmdNode.CMDType != TypeSQL {
+				return fmt.Errorf("query %v is the input for a sql expression and cannot also be the input for %v", dsNode.RefID(), cmdNode.RefID())
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,61 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/dataplane/sdata/reader"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// inputDatasourceID is similar to a fake ID for CMDNode. There is no specific reason for the selection of this value.
+	inputDatasourceID = -300
+
+	// InputDatasourceUID is the string constant used as the datasource name in requests to identify a query
+	// whose data is provided by the caller in Query.Frames instead of being queried from a data source.
+	InputDatasourceUID = "__input__"
+)
+
+// InputNode is a node of expression tree whose result is provided by the caller of the expression service, for
+// example, the latest results of other alert rules.
+type InputNode struct {
+	baseNode
+	frames data.Frames
+}
+
+// NodeType returns the data pipeline node type.
+func (in *InputNode) NodeType() NodeType {
+	return TypeInputNode
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies to execute the node.
+func (in *InputNode) NeedsVars() []string {
+	return []string{}
+}
+
+// Execute converts the frames of the node to mathexp.Results. The frames must follow one of the dataplane
+// contracts, such as numeric multi or time series multi. No frames is treated as no data.
+func (in *InputNode) Execute(ctx context.Context, _ time.Time, _ mathexp.Vars, s *Service) (mathexp.Results, error) {
+	if len(in.frames) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+	dt, err := reader.CanReadBasedOnMeta(in.frames)
+	if err != nil {
+		return mathexp.Results{}, MakeQueryError(in.refID, InputDatasourceUID, fmt.Errorf("input data is not in a supported format: %w", err))
+	}
+	return handleDataplaneFrames(ctx, s.tracer, dt, in.frames)
+}
+
+func buildInputNode(rn *rawNode, query Query) *InputNode {
+	return &InputNode{
+		baseNode: baseNode{
+			id:    rn.idx,
+			refID: rn.RefID,
+		},
+		frames: query.Frames,
+	}
+}
@@ -0,0 +1,88 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestInputNode(t *testing.T) {
+	s := Service{
+		cfg:      setting.NewCfg(),
+		features: &featuremgmt.FeatureManager{},
+		tracer:   tracing.InitializeTracerForTest(),
+		metrics:  newMetrics(nil),
+	}
+
+	numberFrame := func(labels data.Labels, v float64) *data.Frame {
+		f := data.NewFrame("", data.NewField("value", labels, []float64{v}))
+		f.SetMeta(&data.FrameMeta{Type: data.FrameTypeNumericMulti, TypeVersion: data.FrameTypeVersion{0, 1}})
+		return f
+	}
+
+	execute := func(t *testing.T, frames data.Frames) *backend.QueryDataResponse {
+		t.Helper()
+		ds, err := DataSourceModelFromNodeType(TypeInputNode)
+		require.NoError(t, err)
+		req := &Request{
+			User: &user.SignedInUser{},
+			Queries: []Query{
+				{
+					RefID:      "A",
+					DataSource: ds,
+					JSON:       json.RawMessage(`{}`),
+					Frames:     frames,
+				},
+				{
+					RefID:      "B",
+					DataSource: dataSourceModel(),
+					JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+				},
+			},
+		}
+		pl, err := s.BuildPipeline(req)
+		require.NoError(t, err)
+		res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("converts numeric frames", func(t *testing.T) {
+		res := execute(t, data.Frames{
+			numberFrame(data.Labels{"service": "a"}, 1),
+			numberFrame(data.Labels{"service": "b"}, 0),
+		}).Responses["B"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+		values := map[string]float64{}
+		for _, f := range res.Frames {
+			v, err := f.Fields[0].FloatAt(0)
+			require.NoError(t, err)
+			values[f.Fields[0].Labels["service"]] = v
+		}
+		require.Equal(t, map[string]float64{"a": 2, "b": 0}, values)
+	})
+
+	t.Run("no frames is no data", func(t *testing.T) {
+		res := execute(t, nil).Responses["B"]
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		require.Empty(t, res.Frames[0].Fields)
+	})
+
+	t.Run("fails if frames are not in a dataplane format", func(t *testing.T) {
+		res := execute(t, data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))})
+		require.ErrorContains(t, res.Responses["A"].Error, "input data is not in a supported format")
+		require.Error(t, res.Responses["B"].Error)
+	})
+}
@@ -0,0 +1,224 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// walkAggregate executes an aggregation such as sum by (service) ($A) or topk(3, $A).
+func (e *State) walkAggregate(node *parse.AggregateNode) (Results, error) {
+	res := Results{Values: Values{}}
+	arg, err := e.walk(node.Arg)
+	if err != nil {
+		return res, err
+	}
+
+	if len(arg.Values) == 1 && arg.Values[0].Type() == parse.TypeNoData {
+		res.Values = append(res.Values, NoData{}.New())
+		return res, nil
+	}
+
+	if node.Op == "topk" || node.Op == "bottomk" {
+		k, err := e.aggregateParam(node)
+		if err != nil {
+			return res, err
+		}
+		return e.selectK(node, arg, k)
+	}
+
+	groups, order, err := groupByLabels(node, arg)
+	if err != nil {
+		return res, err
+	}
+	for _, key := range order {
+		g := groups[key]
+		switch g.valueType {
+		case parse.TypeNumberSet:
+			res.Values = append(res.Values, e.aggregateNumbers(node.Op, g))
+		case parse.TypeSeriesSet:
+			res.Values = append(res.Values, e.aggregateSeries(node.Op, g))
+		}
+	}
+	return res, nil
+}
+
+// aggregationGroup holds the values that belong to the same group of an aggregation.
+type aggregationGroup struct {
+	labels    data.Labels
+	valueType parse.ReturnType
+	values    []Value
+}
+
+// groupByLabels splits the values into groups by the labels of the by (...) or without (...) clause.
+// The returned slice contains the keys of the groups in order of appearance.
+func groupByLabels(node *parse.AggregateNode, arg Results) (map[string]*aggregationGroup, []string, error) {
+	groups := map[string]*aggregationGroup{}
+	order := []string{}
+	for _, v := range arg.Values {
+		switch v.Type() {
+		case parse.TypeNumberSet, parse.TypeSeriesSet:
+		case parse.TypeNoData:
+			continue
+		default:
+			return nil, nil, fmt.Errorf("%s: can only aggregate numbers or series, got type %v", node.Op, v.Type())
+		}
+		labels := groupingLabels(v.GetLabels(), node)
+		key := labels.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &aggregationGroup{labels: labels, valueType: v.Type()}
+			groups[key] = g
+			order = append(order, key)
+		}
+		if g.valueType != v.Type() {
+			return nil, nil, fmt.Errorf("%s: cannot aggregate numbers and series together", node.Op)
+		}
+		g.values = append(g.values, v)
+	}
+	return groups, order, nil
+}
+
+// groupingLabels returns the labels that identify the group of a value.
+func groupingLabels(labels data.Labels, node *parse.AggregateNode) data.Labels {
+	result := data.Labels{}
+	if node.Without {
+		for k, v := range labels {
+			if !slices.Contains(node.Grouping, k) {
+				result[k] = v
+			}
+		}
+		return result
+	}
+	for _, name := range node.Grouping {
+		if v, ok := labels[name]; ok {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// aggregateFloats applies the aggregation operator to the values. Null values are skipped.
+// It returns nil if there are no values to aggregate.
+func aggregateFloats(op string, values []*float64) *float64 {
+	var result float64
+	count := 0
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		switch {
+		case op == "sum" || op == "avg":
+			result += *v
+		case op == "min" && (count == 0 || *v < result || math.IsNaN(*v)):
+			result = *v
+		case op == "max" && (count == 0 || *v > result || math.IsNaN(*v)):
+			result = *v
+		}
+		count++
+	}
+	switch {
+	case op == "count":
+		result = float64(count)
+	case count == 0:
+		return nil
+	case op == "avg":
+		result /= float64(count)
+	}
+	return &result
+}
+
+func (e *State) aggregateNumbers(op string, g *aggregationGroup) Number {
+	values := make([]*float64, 0, len(g.values))
+	for _, v := range g.values {
+		values = append(values, v.(Number).GetFloat64Value())
+	}
+	n := NewNumber(e.RefID, g.labels)
+	n.SetValue(aggregateFloats(op, values))
+	return n
+}
+
+// aggregateSeries aggregates the points of all series in the group that have the same timestamp.
+func (e *State) aggregateSeries(op string, g *aggregationGroup) Series {
+	points := map[time.Time][]*float64{}
+	times := []time.Time{}
+	for _, v := range g.values {
+		s := v.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if _, ok := points[t]; !ok {
+				times = append(times, t)
+			}
+			points[t] = append(points[t], f)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	newSeries := NewSeries(e.RefID, g.labels, len(times))
+	for i, t := range times {
+		newSeries.SetPoint(i, t, aggregateFloats(op, points[t]))
+	}
+	return newSeries
+}
+
+// aggregateParam returns the k parameter of topk and bottomk.
+func (e *State) aggregateParam(node *parse.AggregateNode) (int, error) {
+	param, err := e.walk(node.Param)
+	if err != nil {
+		return 0, err
+	}
+	k, err := scalarArg(param)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", node.Op, err)
+	}
+	if k < 1 || k != math.Trunc(k) {
+		return 0, fmt.Errorf("%s: parameter must be a positive integer, got %v", node.Op, k)
+	}
+	return int(k), nil
+}
+
+// selectK returns the k largest (topk) or smallest (bottomk) numbers of each group. The numbers keep their labels.
+// Numbers with null or NaN values are never selected.
+func (e *State) selectK(node *parse.AggregateNode, arg Results, k int) (Results, error) {
+	res := Results{Values: Values{}}
+	groups, order, err := groupByLabels(node, arg)
+	if err != nil {
+		return res, err
+	}
+	for _, key := range order {
+		g := groups[key]
+		if g.valueType != parse.TypeNumberSet {
+			return res, fmt.Errorf("%s: can only be applied to numbers, got type %v. Use Reduce to turn series into numbers", node.Op, g.valueType)
+		}
+		numbers := make([]Number, 0, len(g.values))
+		for _, v := range g.values {
+			n := v.(Number)
+			if f := n.GetFloat64Value(); f != nil && !math.IsNaN(*f) {
+				numbers = append(numbers, n)
+			}
+		}
+		sort.SliceStable(numbers, func(i, j int) bool {
+			a, b := *numbers[i].GetFloat64Value(), *numbers[j].GetFloat64Value()
+			if node.Op == "topk" {
+				return a > b
+			}
+			return a < b
+		})
+		if len(numbers) > k {
+			numbers = numbers[:k]
+		}
+		for _, n := range numbers {
+			selected := NewNumber(e.RefID, n.GetLabels())
+			selected.SetValue(n.GetFloat64Value())
+			res.Values = append(res.Values, selected)
+		}
+	}
+	return res, nil
+}
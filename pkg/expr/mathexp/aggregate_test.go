@@ -0,0 +1,231 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// numbersByLabels returns the values of the numbers in the results keyed by the string representation of their labels.
+func numbersByLabels(t *testing.T, res Results) map[string]*float64 {
+	t.Helper()
+	m := make(map[string]*float64, len(res.Values))
+	for _, v := range res.Values {
+		n, ok := v.(Number)
+		require.Truef(t, ok, "expected number, got %T", v)
+		m[n.GetLabels().String()] = n.GetFloat64Value()
+	}
+	return m
+}
+
+var requestsByInstance = Vars{
+	"A": resultValuesNoErr(
+		makeNumber("", data.Labels{"service": "api", "cluster": "eu", "instance": "1"}, float64Pointer(1)),
+		makeNumber("", data.Labels{"service": "api", "cluster": "eu", "instance": "2"}, float64Pointer(4)),
+		makeNumber("", data.Labels{"service": "api", "cluster": "us", "instance": "3"}, float64Pointer(2)),
+		makeNumber("", data.Labels{"service": "web", "cluster": "us", "instance": "4"}, float64Pointer(8)),
+		makeNumber("", data.Labels{"service": "web", "cluster": "us", "instance": "5"}, nil),
+	),
+	"B": resultValuesNoErr(
+		makeNumber("", data.Labels{"service": "api"}, float64Pointer(10)),
+		makeNumber("", data.Labels{"service": "web"}, float64Pointer(100)),
+	),
+	"C": resultValuesNoErr(
+		makeNumber("", data.Labels{"service": "api", "cluster": "eu", "instance": "1", "env": "prod"}, float64Pointer(2)),
+		makeNumber("", data.Labels{"service": "api", "cluster": "eu", "instance": "2", "env": "prod"}, float64Pointer(3)),
+	),
+}
+
+func TestAggregations(t *testing.T) {
+	var tests = []struct {
+		name     string
+		expr     string
+		expected map[string]*float64
+	}{
+		{
+			name: "sum by",
+			expr: "sum by (service) ($A)",
+			expected: map[string]*float64{
+				"service=api": float64Pointer(7),
+				"service=web": float64Pointer(8),
+			},
+		},
+		{
+			name: "by clause after the expression",
+			expr: "max($A) by (cluster)",
+			expected: map[string]*float64{
+				"cluster=eu": float64Pointer(4),
+				"cluster=us": float64Pointer(8),
+			},
+		},
+		{
+			name: "without",
+			expr: "min without (instance) ($A)",
+			expected: map[string]*float64{
+				"cluster=eu, service=api": float64Pointer(1),
+				"cluster=us, service=api": float64Pointer(2),
+				"cluster=us, service=web": float64Pointer(8),
+			},
+		},
+		{
+			name: "avg of everything",
+			expr: "avg($A)",
+			expected: map[string]*float64{
+				"": float64Pointer(3.75),
+			},
+		},
+		{
+			name: "count counts only non-null values",
+			expr: "count by (service) ($A)",
+			expected: map[string]*float64{
+				"service=api": float64Pointer(3),
+				"service=web": float64Pointer(1),
+			},
+		},
+		{
+			name: "topk",
+			expr: "topk(2, $A)",
+			expected: map[string]*float64{
+				"cluster=us, instance=4, service=web": float64Pointer(8),
+				"cluster=eu, instance=2, service=api": float64Pointer(4),
+			},
+		},
+		{
+			name: "bottomk by",
+			expr: "bottomk by (service) (1, $A)",
+			expected: map[string]*float64{
+				"cluster=eu, instance=1, service=api": float64Pointer(1),
+				"cluster=us, instance=4, service=web": float64Pointer(8),
+			},
+		},
+		{
+			name: "aggregation in binary operation and function",
+			expr: "abs(sum by (service) ($A) - 10)",
+			expected: map[string]*float64{
+				"service=api": float64Pointer(3),
+				"service=web": float64Pointer(2),
+			},
+		},
+		{
+			name: "on matching",
+			expr: "$A / on(service) $B",
+			expected: map[string]*float64{
+				"cluster=eu, instance=1, service=api": float64Pointer(0.1),
+				"cluster=eu, instance=2, service=api": float64Pointer(0.4),
+				"cluster=us, instance=3, service=api": float64Pointer(0.2),
+				"cluster=us, instance=4, service=web": float64Pointer(0.08),
+				"cluster=us, instance=5, service=web": nil,
+			},
+		},
+		{
+			name: "ignoring matching one-to-one",
+			expr: "$C - ignoring(env) $A",
+			expected: map[string]*float64{
+				"cluster=eu, instance=1, service=api": float64Pointer(1),
+				"cluster=eu, instance=2, service=api": float64Pointer(-1),
+			},
+		},
+		{
+			name: "on matching one-to-one keeps only the matching labels",
+			expr: "sum by (service) ($A) + on(service) $B",
+			expected: map[string]*float64{
+				"service=api": float64Pointer(17),
+				"service=web": float64Pointer(108),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", requestsByInstance, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			actual := numbersByLabels(t, res)
+			require.Len(t, actual, len(tt.expected))
+			for labels, expected := range tt.expected {
+				require.Contains(t, actual, labels)
+				if expected == nil {
+					require.Nil(t, actual[labels])
+					continue
+				}
+				require.NotNil(t, actual[labels], labels)
+				require.InDelta(t, *expected, *actual[labels], 1e-9, labels)
+			}
+		})
+	}
+}
+
+func TestAggregationsOnSeries(t *testing.T) {
+	vars := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", data.Labels{"service": "api", "instance": "1"},
+				tp{time.Unix(5, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(2)}),
+			makeSeries("", data.Labels{"service": "api", "instance": "2"},
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(15, 0), nil}),
+		),
+	}
+	e, err := New("sum by (service) ($A)")
+	require.NoError(t, err)
+	res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.Equal(t, resultValuesNoErr(
+		makeSeries("", data.Labels{"service": "api"},
+			tp{time.Unix(5, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(5)},
+			tp{time.Unix(15, 0), nil}),
+	), res)
+
+	t.Run("topk should fail on series", func(t *testing.T) {
+		e, err := New("topk(1, $A)")
+		require.NoError(t, err)
+		_, err = e.Execute("", vars, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
+
+func TestAggregationFailures(t *testing.T) {
+	t.Run("parse errors", func(t *testing.T) {
+		for _, expr := range []string{
+			"sum by service ($A)",
+			"sum by (service ($A)",
+			"sum(1)",
+			"topk($A)",
+			`topk("a", $A)`,
+			"$A + on service $B",
+		} {
+			_, err := New(expr)
+			require.Errorf(t, err, expr)
+		}
+	})
+
+	t.Run("many-to-many matching", func(t *testing.T) {
+		e, err := New("$A + on(service) $A")
+		require.NoError(t, err)
+		_, err = e.Execute("", requestsByInstance, tracing.InitializeTracerForTest())
+		require.ErrorContains(t, err, "many-to-many")
+	})
+
+	t.Run("invalid topk parameter", func(t *testing.T) {
+		for _, expr := range []string{"topk(0, $A)", "topk(1.5, $A)"} {
+			e, err := New(expr)
+			require.NoError(t, err)
+			_, err = e.Execute("", requestsByInstance, tracing.InitializeTracerForTest())
+			require.Errorf(t, err, expr)
+		}
+	})
+
+	t.Run("NaN propagates in sum", func(t *testing.T) {
+		e, err := New("sum($A)")
+		require.NoError(t, err)
+		res, err := e.Execute("", Vars{"A": resultValuesNoErr(makeNumber("", nil, NaN), makeNumber("", nil, float64Pointer(1)))}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(*res.Values[0].(Number).GetFloat64Value()))
+	})
+}
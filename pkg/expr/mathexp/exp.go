@@ -5,7 +5,9 @@ import (
 	"math"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -30,6 +32,10 @@ type State struct {
 	Drops     map[string]map[string][]data.Labels // binary node text -> LH/RH -> Drop Labels
 	DropCount int64
 
+	// Now is the evaluation time used by functions such as timestamp() and hour().
+	// If not set, the current time is used.
+	Now time.Time
+
 	tracer tracing.Tracer
 }
 
@@ -51,16 +57,30 @@ func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
 
 // Execute applies a parse expression to the context and executes it
 func (e *Expr) Execute(refID string, vars Vars, tracer tracing.Tracer) (r Results, err error) {
+	return e.ExecuteAt(refID, time.Time{}, vars, tracer)
+}
+
+// ExecuteAt is like Execute but uses now as the evaluation time for functions that depend on it.
+func (e *Expr) ExecuteAt(refID string, now time.Time, vars Vars, tracer tracing.Tracer) (r Results, err error) {
 	s := &State{
 		Expr:  e,
 		Vars:  vars,
 		RefID: refID,
+		Now:   now,
 
 		tracer: tracer,
 	}
 	return e.executeState(s)
 }
 
+// now returns the evaluation time of the expression.
+func (e *State) now() time.Time {
+	if e.Now.IsZero() {
+		return time.Now()
+	}
+	return e.Now
+}
+
 func (e *Expr) executeState(s *State) (r Results, err error) {
 	defer errRecover(&err, s)
 	r, err = s.walk(e.Tree.Root)
@@ -98,6 +118,8 @@ func (e *State) walk(node parse.Node) (res Results, err error) {
 		res, err = e.walkUnary(node)
 	case *parse.FuncNode:
 		res, err = e.walkFunc(node)
+	case *parse.AggregateNode:
+		res, err = e.walkAggregate(node)
 	default:
 		return res, fmt.Errorf("expr: can not walk node type: %s", node.Type())
 	}
@@ -208,34 +230,10 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 		unions = append(unions, u)
 	}
 
-	aVar := biNode.Args[0].String()
-	bVar := biNode.Args[1].String()
-
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
-		check := func(v string, matchArray []bool, r *Results) {
-			for i, b := range matchArray {
-				if b {
-					continue
-				}
-				if e.Drops == nil {
-					e.Drops = make(map[string]map[string][]data.Labels)
-				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
-				}
-
-				if r.Values[i].Type() == parse.TypeNoData {
-					continue
-				}
-
-				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
-			}
-		}
-		check(aVar, aMatched, &aResults)
-		check(bVar, bMatched, &bResults)
+		e.collectDrops(biNode, aResults, bResults, aMatched, bMatched)
 	}
 
 	aValueLen := len(aResults.Values)
@@ -306,6 +304,109 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	return unions
 }
 
+// collectDrops records the labels of the values that were not matched by a binary operation.
+func (e *State) collectDrops(biNode *parse.BinaryNode, aResults, bResults Results, aMatched, bMatched []bool) {
+	check := func(v string, matchArray []bool, r *Results) {
+		for i, b := range matchArray {
+			if b {
+				continue
+			}
+			if e.Drops == nil {
+				e.Drops = make(map[string]map[string][]data.Labels)
+			}
+			if e.Drops[biNode.String()] == nil {
+				e.Drops[biNode.String()] = make(map[string][]data.Labels)
+			}
+
+			if r.Values[i].Type() == parse.TypeNoData {
+				continue
+			}
+
+			e.DropCount++
+			e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
+		}
+	}
+	check(biNode.Args[0].String(), aMatched, &aResults)
+	check(biNode.Args[1].String(), bMatched, &bResults)
+}
+
+// unionOnLabels creates Union objects for a binary operation with an on(...) or ignoring(...) modifier.
+// Values are matched when the labels selected by the modifier are equal. If a value on one side matches
+// several values on the other side, the labels of the many side are kept. Otherwise only the labels
+// selected by the modifier are kept. It is an error if values on both sides match several values.
+func (e *State) unionOnLabels(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	for _, r := range []Results{aResults, bResults} {
+		if len(r.Values) == 1 && (r.Values[0].Type() == parse.TypeNoData || r.Values[0].Type() == parse.TypeScalar) {
+			// label matching does not apply to scalars and no data.
+			return e.union(aResults, bResults, biNode), nil
+		}
+	}
+
+	matching := biNode.Matching
+	aKeys := make([]string, len(aResults.Values))
+	aCount := map[string]int{}
+	for i, a := range aResults.Values {
+		aKeys[i] = matchingLabels(a.GetLabels(), matching).String()
+		aCount[aKeys[i]]++
+	}
+	bKeys := make([]string, len(bResults.Values))
+	bCount := map[string]int{}
+	for i, b := range bResults.Values {
+		bKeys[i] = matchingLabels(b.GetLabels(), matching).String()
+		bCount[bKeys[i]]++
+	}
+
+	unions := []*Union{}
+	aMatched := make([]bool, len(aResults.Values))
+	bMatched := make([]bool, len(bResults.Values))
+	for iA, a := range aResults.Values {
+		for iB, b := range bResults.Values {
+			if aKeys[iA] != bKeys[iB] {
+				continue
+			}
+			var labels data.Labels
+			switch {
+			case aCount[aKeys[iA]] > 1 && bCount[bKeys[iB]] > 1:
+				return nil, fmt.Errorf("many-to-many matching not allowed in %s: multiple values on both sides match labels {%s}", biNode, aKeys[iA])
+			case aCount[aKeys[iA]] > 1:
+				labels = a.GetLabels()
+			case bCount[bKeys[iB]] > 1:
+				labels = b.GetLabels()
+			default:
+				labels = matchingLabels(a.GetLabels(), matching)
+			}
+			unions = append(unions, &Union{
+				Labels: labels,
+				A:      a,
+				B:      b,
+			})
+			aMatched[iA] = true
+			bMatched[iB] = true
+		}
+	}
+	e.collectDrops(biNode, aResults, bResults, aMatched, bMatched)
+	return unions, nil
+}
+
+// matchingLabels returns the subset of labels that is used for matching by the on(...) or ignoring(...) modifier.
+func matchingLabels(labels data.Labels, matching *parse.VectorMatching) data.Labels {
+	result := data.Labels{}
+	if matching.On {
+		for _, name := range matching.Labels {
+			if v, ok := labels[name]; ok {
+				result[name] = v
+			}
+		}
+		return result
+	}
+	for k, v := range labels {
+		if !slices.Contains(matching.Labels, k) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values: Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -316,7 +417,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.unionOnLabels(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = e.union(ar, br, node)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -574,6 +683,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 			v, err = e.walkUnary(t)
 		case *parse.BinaryNode:
 			v, err = e.walkBinary(t)
+		case *parse.AggregateNode:
+			v, err = e.walkAggregate(t)
 		default:
 			return res, fmt.Errorf("expr: unknown func arg type: %T", t)
 		}
@@ -1,7 +1,13 @@
 package mathexp
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +74,59 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"timestamp": {
+		Return: parse.TypeScalar,
+		F:      timestamp,
+	},
+	"hour": {
+		Args:         []parse.ReturnType{parse.TypeString},
+		OptionalArgs: 1,
+		Return:       parse.TypeScalar,
+		F:            hour,
+		Check:        checkTimezone,
+	},
+	"day_of_week": {
+		Args:         []parse.ReturnType{parse.TypeString},
+		OptionalArgs: 1,
+		Return:       parse.TypeScalar,
+		F:            dayOfWeek,
+		Check:        checkTimezone,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +366,261 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clampMin returns the value for each result in NumberSet, SeriesSet, or Scalar, or min if the value is lower than min.
+func clampMin(e *State, varSet Results, minRes Results) (Results, error) {
+	minV, err := scalarArg(minRes)
+	if err != nil {
+		return Results{}, fmt.Errorf("clamp_min: %w", err)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(f, minV)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// clampMax returns the value for each result in NumberSet, SeriesSet, or Scalar, or max if the value is greater than max.
+func clampMax(e *State, varSet Results, maxRes Results) (Results, error) {
+	maxV, err := scalarArg(maxRes)
+	if err != nil {
+		return Results{}, fmt.Errorf("clamp_max: %w", err)
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Min(f, maxV)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// shift moves the timestamps of each series in SeriesSet forward by the given duration, for example "1d".
+// A negative duration moves the timestamps backward. This allows comparing a series with itself in the past,
+// e.g. $A - shift($A, "1d").
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := parseSignedDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: %w", err)
+	}
+	return perSeries(e, varSet, "shift", func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+// delta returns the difference between each point and the previous point of each series in SeriesSet.
+// The resulting series does not have a point for the first timestamp.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "delta", func(s Series) Series {
+		return perPointPair(e, s, func(prevT time.Time, prev float64, t time.Time, cur float64) float64 {
+			return cur - prev
+		})
+	})
+}
+
+// rate returns the per-second rate of increase between each point and the previous point of each series in SeriesSet.
+// The series are treated as counters, i.e. a value that is lower than the previous one is considered a counter reset.
+// The resulting series does not have a point for the first timestamp.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "rate", func(s Series) Series {
+		return perPointPair(e, s, func(prevT time.Time, prev float64, t time.Time, cur float64) float64 {
+			seconds := t.Sub(prevT).Seconds()
+			if seconds <= 0 {
+				return math.NaN()
+			}
+			increase := cur - prev
+			if cur < prev {
+				increase = cur
+			}
+			return increase / seconds
+		})
+	})
+}
+
+// movingAvg returns the average of each point and up to n-1 previous points of each series in SeriesSet.
+// Null and NaN values are skipped. If there are no values in the window the point is null.
+func movingAvg(e *State, varSet Results, nRes Results) (Results, error) {
+	nF, err := scalarArg(nRes)
+	if err != nil {
+		return Results{}, fmt.Errorf("moving_avg: %w", err)
+	}
+	if nF < 1 || nF != math.Trunc(nF) {
+		return Results{}, fmt.Errorf("moving_avg: window size must be a positive integer, got %v", nF)
+	}
+	n := int(nF)
+	return perSeries(e, varSet, "moving_avg", func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			var sum float64
+			var count int
+			for j := i; j >= 0 && j > i-n; j-- {
+				if f := s.GetValue(j); f != nil && !math.IsNaN(*f) {
+					sum += *f
+					count++
+				}
+			}
+			var value *float64
+			if count > 0 {
+				avg := sum / float64(count)
+				value = &avg
+			}
+			newSeries.SetPoint(i, s.GetTime(i), value)
+		}
+		return newSeries
+	})
+}
+
+// cumsum returns the running total of each series in SeriesSet. Null and NaN values are skipped
+// and the corresponding point is null.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, "cumsum", func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil || math.IsNaN(*f) {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			value := sum
+			newSeries.SetPoint(i, t, &value)
+		}
+		return newSeries
+	})
+}
+
+// timestamp returns a scalar with the evaluation time as number of seconds since the Unix epoch.
+func timestamp(e *State) Results {
+	f := float64(e.now().UnixNano()) / float64(time.Second)
+	return NewScalarResults(e.RefID, &f)
+}
+
+// hour returns a scalar with the hour of the day (0-23) of the evaluation time in the optional IANA timezone,
+// for example "Europe/Paris", or in UTC if no timezone is given.
+func hour(e *State, timezone ...string) (Results, error) {
+	now, err := nowIn(e, timezone)
+	if err != nil {
+		return Results{}, err
+	}
+	f := float64(now.Hour())
+	return NewScalarResults(e.RefID, &f), nil
+}
+
+// dayOfWeek returns a scalar with the day of the week (0-6, where 0 is Sunday) of the evaluation time in the
+// optional IANA timezone, or in UTC if no timezone is given.
+func dayOfWeek(e *State, timezone ...string) (Results, error) {
+	now, err := nowIn(e, timezone)
+	if err != nil {
+		return Results{}, err
+	}
+	f := float64(now.Weekday())
+	return NewScalarResults(e.RefID, &f), nil
+}
+
+func nowIn(e *State, timezone []string) (time.Time, error) {
+	if len(timezone) == 0 {
+		return e.now().UTC(), nil
+	}
+	loc, err := time.LoadLocation(timezone[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q: %w", timezone[0], err)
+	}
+	return e.now().In(loc), nil
+}
+
+// checkTimezone checks at parse time that the timezone argument of a function, if any, is a known IANA timezone.
+func checkTimezone(_ *parse.Tree, f *parse.FuncNode) error {
+	if len(f.Args) == 0 {
+		return nil
+	}
+	if s, ok := f.Args[0].(*parse.StringNode); ok {
+		if _, err := time.LoadLocation(s.Text); err != nil {
+			return fmt.Errorf("parse: invalid timezone %q for %s", s.Text, f.Name)
+		}
+	}
+	return nil
+}
+
+// perSeries applies seriesF to each series in the results. NoData is passed through,
+// any other type of value results in an error.
+func perSeries(e *State, varSet Results, name string, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("%s: can only be applied to type series, got type %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// perPointPair creates a series where each point is the result of pairF applied to the point and the previous point.
+// The first point is dropped. If any of the two values is null the resulting point is null.
+func perPointPair(e *State, s Series, pairF func(prevT time.Time, prev float64, t time.Time, cur float64) float64) Series {
+	size := s.Len() - 1
+	if size < 0 {
+		size = 0
+	}
+	newSeries := NewSeries(e.RefID, s.GetLabels(), size)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		var value *float64
+		if prev != nil && cur != nil {
+			f := pairF(prevT, *prev, t, *cur)
+			value = &f
+		}
+		newSeries.SetPoint(i-1, t, value)
+	}
+	return newSeries
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(res Results) (float64, error) {
+	if len(res.Values) != 1 {
+		return 0, errors.New("expected a single scalar argument")
+	}
+	s, ok := res.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("expected a scalar argument, got %v", res.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, errors.New("argument cannot be null")
+	}
+	return *f, nil
+}
+
+// parseSignedDuration parses a duration such as "1d" or "-1h".
+func parseSignedDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	negative := strings.HasPrefix(raw, "-")
+	d, err := gtime.ParseDuration(strings.TrimPrefix(raw, "-"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration %q: %w", raw, err)
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
@@ -5,8 +5,11 @@ import (
 	"testing"
 	"time"
 
-	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestAbsFunc(t *testing.T) {
@@ -140,3 +143,177 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesFuncs(t *testing.T) {
+	series := resultValuesNoErr(
+		makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(5)},
+			tp{time.Unix(20, 0), nil},
+			tp{time.Unix(30, 0), float64Pointer(2)},
+			tp{time.Unix(40, 0), float64Pointer(8)}),
+	)
+
+	var tests = []struct {
+		name    string
+		expr    string
+		results Results
+	}{
+		{
+			name: "shift moves timestamps forward",
+			expr: `shift($A, "1d")`,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(86400, 0), float64Pointer(1)},
+					tp{time.Unix(86410, 0), float64Pointer(5)},
+					tp{time.Unix(86420, 0), nil},
+					tp{time.Unix(86430, 0), float64Pointer(2)},
+					tp{time.Unix(86440, 0), float64Pointer(8)}),
+			),
+		},
+		{
+			name: "shift with negative duration moves timestamps backward",
+			expr: `shift($A, "-10s")`,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(-10, 0), float64Pointer(1)},
+					tp{time.Unix(0, 0), float64Pointer(5)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(2)},
+					tp{time.Unix(30, 0), float64Pointer(8)}),
+			),
+		},
+		{
+			name: "delta",
+			expr: "delta($A)",
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(4)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(6)}),
+			),
+		},
+		{
+			name: "rate handles counter resets",
+			expr: "rate($A)",
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(0.4)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(0.6)}),
+			),
+		},
+		{
+			name: "moving_avg skips null values",
+			expr: "moving_avg($A, 2)",
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+					tp{time.Unix(40, 0), float64Pointer(5)}),
+			),
+		},
+		{
+			name: "cumsum",
+			expr: "cumsum($A)",
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(6)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(8)},
+					tp{time.Unix(40, 0), float64Pointer(16)}),
+			),
+		},
+		{
+			name: "clamp_min and clamp_max",
+			expr: "clamp_max(clamp_min($A, 2), 6)",
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(20, 0), NaN},
+					tp{time.Unix(30, 0), float64Pointer(2)},
+					tp{time.Unix(40, 0), float64Pointer(6)}),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", Vars{"A": series}, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("series functions should fail on numbers", func(t *testing.T) {
+		e, err := New("delta($A)")
+		require.NoError(t, err)
+		_, err = e.Execute("", Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1)))}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+
+	t.Run("moving_avg should fail if window is not a positive integer", func(t *testing.T) {
+		for _, expr := range []string{"moving_avg($A, 0)", "moving_avg($A, 1.5)"} {
+			e, err := New(expr)
+			require.NoError(t, err)
+			_, err = e.Execute("", Vars{"A": series}, tracing.InitializeTracerForTest())
+			require.Errorf(t, err, expr)
+		}
+	})
+
+	t.Run("shift should fail if duration is invalid", func(t *testing.T) {
+		e, err := New(`shift($A, "abc")`)
+		require.NoError(t, err)
+		_, err = e.Execute("", Vars{"A": series}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
+
+func TestTimeFuncs(t *testing.T) {
+	// Friday, 2023-11-03 14:30:00 UTC
+	now := time.Date(2023, time.November, 3, 14, 30, 0, 0, time.UTC)
+
+	var tests = []struct {
+		expr     string
+		expected float64
+	}{
+		{expr: "timestamp()", expected: float64(now.Unix())},
+		{expr: "hour()", expected: 14},
+		{expr: "day_of_week()", expected: 5},
+		{expr: "hour() >= 9 && hour() < 17 && day_of_week() > 0 && day_of_week() < 6", expected: 1},
+		{expr: `hour("UTC")`, expected: 14},
+		{expr: `hour("America/New_York")`, expected: 10},
+		{expr: `hour("Asia/Tokyo")`, expected: 23},
+		{expr: `day_of_week("Asia/Tokyo")`, expected: 5},
+		{expr: `day_of_week("Pacific/Kiritimati")`, expected: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.ExecuteAt("", now, Vars{}, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, resultValuesNoErr(NewScalar("", float64Pointer(tt.expected))), res)
+		})
+	}
+}
+
+func TestTimeFuncsTimezoneErrors(t *testing.T) {
+	for _, expr := range []string{`hour("Mars/Olympus")`, `day_of_week("Nowhere")`, `hour("UTC", "UTC")`, "hour(1)"} {
+		_, err := New(expr)
+		require.Error(t, err, expr)
+	}
+}
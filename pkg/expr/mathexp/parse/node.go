@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +63,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeAggregate is an aggregation over labels: sum by (service) ($A)
+	NodeAggregate
 )
 
 // String returns the string representation of the NodeType
@@ -79,6 +82,8 @@ func (t NodeType) String() string {
 		return "NodeNumber"
 	case NodeVar:
 		return "NodeVar"
+	case NodeAggregate:
+		return "NodeAggregate"
 	default:
 		return "NodeUnknown"
 	}
@@ -163,7 +168,7 @@ func (f *FuncNode) StringAST() string {
 
 // Check performs parse time checking on the FuncNode so it fulfills the Node interface.
 func (f *FuncNode) Check(t *Tree) error {
-	if len(f.Args) < len(f.F.Args) {
+	if len(f.Args) < len(f.F.Args)-f.F.OptionalArgs {
 		return fmt.Errorf("parse: not enough arguments for %s", f.Name)
 	} else if len(f.Args) > len(f.F.Args) {
 		return fmt.Errorf("parse: too many arguments for %s", f.Name)
@@ -300,6 +305,23 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is the optional on(...) or ignoring(...) modifier. If nil the default label matching is used.
+	Matching *VectorMatching
+}
+
+// VectorMatching describes which labels are used to match the values of the two sides of a binary operation.
+type VectorMatching struct {
+	// On is true if only Labels are used for matching, otherwise all labels except Labels are used.
+	On     bool
+	Labels []string
+}
+
+// String returns the string representation of the VectorMatching, e.g. on(service).
+func (m *VectorMatching) String() string {
+	if m.On {
+		return fmt.Sprintf("on(%s)", strings.Join(m.Labels, ", "))
+	}
+	return fmt.Sprintf("ignoring(%s)", strings.Join(m.Labels, ", "))
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,11 +330,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -369,6 +397,83 @@ func (u *UnaryNode) Return() ReturnType {
 	return u.Arg.Return()
 }
 
+// aggregations is the set of supported aggregation operators.
+var aggregations = map[string]struct{}{
+	"sum":     {},
+	"avg":     {},
+	"min":     {},
+	"max":     {},
+	"count":   {},
+	"topk":    {},
+	"bottomk": {},
+}
+
+// IsAggregation returns true if name is an aggregation operator.
+func IsAggregation(name string) bool {
+	_, ok := aggregations[name]
+	return ok
+}
+
+// AggregateNode holds an aggregation over the labels of a set of numbers or series.
+type AggregateNode struct {
+	NodeType
+	Pos
+	Op       string
+	Param    Node // k for topk and bottomk, nil otherwise.
+	Arg      Node
+	Grouping []string
+	// Without is true if Grouping lists the labels to remove instead of the labels to keep.
+	Without bool
+}
+
+func newAggregate(pos Pos, op string) *AggregateNode {
+	return &AggregateNode{NodeType: NodeAggregate, Pos: pos, Op: op}
+}
+
+// String returns the string representation of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) String() string {
+	s := a.Op
+	if a.Grouping != nil {
+		clause := "by"
+		if a.Without {
+			clause = "without"
+		}
+		s += fmt.Sprintf(" %s (%s) ", clause, strings.Join(a.Grouping, ", "))
+	}
+	if a.Param != nil {
+		return fmt.Sprintf("%s(%s, %s)", s, a.Param, a.Arg)
+	}
+	return fmt.Sprintf("%s(%s)", s, a.Arg)
+}
+
+// StringAST returns the string representation of abstract syntax tree of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) StringAST() string {
+	return a.String()
+}
+
+// Check performs parse time checking on the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Check(t *Tree) error {
+	if a.Param != nil {
+		if rt := a.Param.Return(); rt != TypeScalar {
+			return fmt.Errorf(`parse: type error in %s, expected "scalar" parameter, got %s`, a, rt)
+		}
+		if err := a.Param.Check(t); err != nil {
+			return err
+		}
+	}
+	switch rt := a.Arg.Return(); rt {
+	case TypeNumberSet, TypeSeriesSet:
+		return a.Arg.Check(t)
+	default:
+		return fmt.Errorf(`parse: type error in %s, expected "number" or "series", got %s`, a, rt)
+	}
+}
+
+// Return returns the result type of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Return() ReturnType {
+	return a.Arg.Return()
+}
+
 // Walk invokes f on n and sub-nodes of n.
 func Walk(n Node, f func(Node)) {
 	f(n)
@@ -384,6 +489,11 @@ func Walk(n Node, f func(Node)) {
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
+	case *AggregateNode:
+		if n.Param != nil {
+			Walk(n.Param, f)
+		}
+		Walk(n.Arg, f)
 	default:
 		panic(fmt.Errorf("other type: %T", n))
 	}
@@ -405,6 +515,8 @@ const (
 	TypeVariantSet
 	// TypeNoData is a no data response without a known data type.
 	TypeNoData
+	// TypeTableData is a tabular data frame that is not a number or a series.
+	TypeTableData
 )
 
 // String returns a string representation of the ReturnType.
@@ -422,6 +534,8 @@ func (f ReturnType) String() string {
 		return "variant"
 	case TypeNoData:
 		return "noData"
+	case TypeTableData:
+		return "tableData"
 	default:
 		return "unknown"
 	}
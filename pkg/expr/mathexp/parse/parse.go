@@ -30,7 +30,9 @@ type Tree struct {
 
 // Func holds the structure of a parsed function call.
 type Func struct {
-	Args          []ReturnType
+	Args []ReturnType
+	// OptionalArgs is the number of trailing Args that can be omitted. F must be variadic if it is not zero.
+	OptionalArgs  int
 	Return        ReturnType
 	F             interface{}
 	VariantReturn bool
@@ -165,18 +167,92 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
-v -> number | func(..) | queryVar
+v -> number | func(..) | aggregate(..) | queryVar
 Func -> name "(" param {"," param} ")"
-param -> number | "string" | queryVar
+param -> O | "string"
+matching -> ( "on" | "ignoring" ) labels
+aggregate -> aggop [grouping] "(" [O ","] O ")" [grouping]
+grouping -> ( "by" | "without" ) labels
+labels -> "(" [label {"," label}] ")"
 */
 
+// binary creates a BinaryNode for the operator, parsing the optional label matching
+// modifier that follows the operator before the right hand side.
+func (t *Tree) binary(operator item, left Node, right func() Node) *BinaryNode {
+	var matching *VectorMatching
+	if token := t.peek(); token.typ == itemFunc && (token.val == "on" || token.val == "ignoring") {
+		t.next()
+		matching = &VectorMatching{
+			On:     token.val == "on",
+			Labels: t.labels("label matching"),
+		}
+	}
+	n := newBinary(operator, left, right())
+	n.Matching = matching
+	return n
+}
+
+// labels parses a parenthesized comma separated list of label names.
+func (t *Tree) labels(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		token := t.next()
+		switch token.typ {
+		case itemRightParen:
+			return labels
+		case itemFunc:
+			labels = append(labels, token.val)
+		default:
+			t.unexpected(token, context)
+		}
+		switch token = t.next(); token.typ {
+		case itemRightParen:
+			return labels
+		case itemComma:
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
+// Aggregate parses an AggregateNode, e.g. sum by (service) ($A) or topk(3, $A).
+func (t *Tree) Aggregate() *AggregateNode {
+	token := t.next()
+	n := newAggregate(token.pos, token.val)
+	grouped := t.grouping(n)
+	t.expect(itemLeftParen, "aggregation")
+	if n.Op == "topk" || n.Op == "bottomk" {
+		n.Param = t.O()
+		t.expect(itemComma, "aggregation")
+	}
+	n.Arg = t.O()
+	t.expect(itemRightParen, "aggregation")
+	if !grouped {
+		t.grouping(n)
+	}
+	return n
+}
+
+// grouping parses the optional by or without clause of an aggregation. It returns true if the clause was present.
+func (t *Tree) grouping(n *AggregateNode) bool {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "by" && token.val != "without") {
+		return false
+	}
+	t.next()
+	n.Without = token.val == "without"
+	n.Grouping = t.labels("aggregation grouping")
+	return true
+}
+
 // expr:
 
 // O is A {"||" A} in the grammar.
@@ -185,7 +261,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +274,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +287,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +300,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +313,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,7 +326,7 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
@@ -286,6 +362,9 @@ func (t *Tree) v() Node {
 		return n
 	case itemFunc:
 		t.backup()
+		if _, ok := t.GetFunction(token.val); !ok && IsAggregation(token.val) {
+			return t.Aggregate()
+		}
 		return t.Func()
 	case itemVar:
 		t.backup()
@@ -329,6 +408,10 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		case itemRightParen:
 			return
 		}
@@ -1,8 +1,11 @@
 package mathexp
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -81,8 +84,135 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		f++
+	}
+	return &f
+}
+
+// Range returns the difference between the maximum and the minimum value.
+func Range(fv *Float64Field) *float64 {
+	minV, maxV := Min(fv), Max(fv)
+	f := *maxV - *minV
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reducer that calculates the p-th percentile (0 <= p <= 100) of the values
+// using linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := sortedValues(fv)
+		if !ok || len(values) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		rank := p / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	avg := Avg(fv)
+	if math.IsNaN(*avg) {
+		return avg
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *avg
+		sum += d * d
+	}
+	f := sum / float64(fv.Len())
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+// Increase treats the values as a monotonic counter and returns how much it increased.
+// A value lower than the previous one is considered a counter reset.
+func Increase(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var f float64
+	var prev float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		if i > 0 {
+			if *v >= prev {
+				f += *v - prev
+			} else {
+				f += *v
+			}
+		}
+		prev = *v
+	}
+	return &f
+}
+
+// sortedValues returns the values of the field sorted in ascending order.
+// It returns false if any of the values is null or NaN.
+func sortedValues(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	sort.Float64s(values)
+	return values, true
+}
+
+// GetReduceFunc returns the reducer function by its name.
+// Reducers that require a parameter are specified as a function call, for example "percentile(95)".
+// The function returned for "rate" calculates the increase of the counter, the caller must divide it by the duration.
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
-	switch strings.ToLower(rFunc) {
+	name, arg, hasArg, err := parseReducer(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	if hasArg && name != "percentile" {
+		return nil, fmt.Errorf("reduction %v does not accept arguments", name)
+	}
+	switch name {
 	case "sum":
 		return Sum, nil
 	case "mean":
@@ -95,14 +225,60 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "count_non_null":
+		return CountNonNull, nil
+	case "range":
+		return Range, nil
+	case "median":
+		return Median, nil
+	case "percentile":
+		if !hasArg {
+			return nil, errors.New("reduction percentile requires an argument, for example percentile(95)")
+		}
+		if arg < 0 || arg > 100 {
+			return nil, fmt.Errorf("percentile must be between 0 and 100, got %v", arg)
+		}
+		return Percentile(arg), nil
+	case "stddev":
+		return StdDev, nil
+	case "variance":
+		return Variance, nil
+	case "increase", "rate":
+		return Increase, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// GetSupportedReduceFuncs returns collection of supported function names. It does not include percentile, which
+// requires an argument, for example percentile(95).
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "count_non_null", "range", "median", "stddev", "variance", "increase", "rate"}
+}
+
+// isRateReducer returns true if the reduction function is "rate" and therefore the result of the
+// reducer function must be divided by the duration in seconds.
+func isRateReducer(rFunc string) bool {
+	return strings.ToLower(strings.TrimSpace(rFunc)) == "rate"
+}
+
+// parseReducer splits a reducer definition like "percentile(95)" into the name and the argument.
+func parseReducer(rFunc string) (name string, arg float64, hasArg bool, err error) {
+	name = strings.ToLower(strings.TrimSpace(rFunc))
+	open := strings.Index(name, "(")
+	if open < 0 {
+		return name, 0, false, nil
+	}
+	if !strings.HasSuffix(name, ")") {
+		return "", 0, false, fmt.Errorf("invalid reduction %v: missing closing parenthesis", rFunc)
+	}
+	arg, err = strconv.ParseFloat(strings.TrimSpace(name[open+1:len(name)-1]), 64)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid reduction %v: argument must be a number", rFunc)
+	}
+	return strings.TrimSpace(name[:open]), arg, true, nil
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
@@ -126,6 +302,9 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
 	f = reduceFunc(&floatField)
+	if f != nil && isRateReducer(rFunc) {
+		f = perSecond(f, series)
+	}
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -133,6 +312,17 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 	return number, nil
 }
 
+// perSecond divides the value by the time span of the series in seconds.
+func perSecond(v *float64, s Series) *float64 {
+	f := math.NaN()
+	if s.Len() > 1 {
+		if d := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds(); d > 0 {
+			f = *v / d
+		}
+	}
+	return &f
+}
+
 type ReduceMapper interface {
 	MapInput(s *float64) *float64
 	MapOutput(v *float64) *float64
@@ -386,3 +386,62 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesReduceAdditionalReducers(t *testing.T) {
+	series := makeSeries("temp", nil,
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(4)},
+		tp{time.Unix(20, 0), float64Pointer(2)},
+		tp{time.Unix(30, 0), float64Pointer(7)},
+		tp{time.Unix(40, 0), float64Pointer(6)},
+	)
+
+	var tests = []struct {
+		red      string
+		expected float64
+	}{
+		{red: "first", expected: 1},
+		{red: "count_non_null", expected: 5},
+		{red: "range", expected: 6},
+		{red: "median", expected: 4},
+		{red: "percentile(0)", expected: 1},
+		{red: "percentile(75)", expected: 6},
+		{red: "percentile(90)", expected: 6.6},
+		{red: "percentile(100)", expected: 7},
+		{red: "variance", expected: 5.2},
+		{red: "stddev", expected: math.Sqrt(5.2)},
+		// 1 -> 4 (+3), reset to 2 (+2), 2 -> 7 (+5), reset to 6 (+6)
+		{red: "increase", expected: 16},
+		{red: "rate", expected: 16.0 / 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.red, func(t *testing.T) {
+			num, err := series.Reduce("", tt.red, nil)
+			require.NoError(t, err)
+			require.NotNil(t, num.GetFloat64Value())
+			require.InDelta(t, tt.expected, *num.GetFloat64Value(), 1e-9)
+		})
+	}
+
+	t.Run("should return NaN if series has null values", func(t *testing.T) {
+		for _, red := range []string{"median", "percentile(95)", "range", "variance", "stddev", "increase", "rate"} {
+			num, err := seriesWithNil["A"].Values[0].Value().(*Series).Reduce("", red, nil)
+			require.NoError(t, err)
+			require.Truef(t, math.IsNaN(*num.GetFloat64Value()), "reducer %s", red)
+		}
+	})
+
+	t.Run("count_non_null should skip null values", func(t *testing.T) {
+		num, err := seriesWithNil["A"].Values[0].Value().(*Series).Reduce("", "count_non_null", nil)
+		require.NoError(t, err)
+		require.Equal(t, float64(1), *num.GetFloat64Value())
+	})
+
+	t.Run("should fail if percentile argument is invalid", func(t *testing.T) {
+		for _, red := range []string{"percentile", "percentile(101)", "percentile(abc)", "percentile(95", "median(50)"} {
+			_, err := series.Reduce("", red, nil)
+			require.Errorf(t, err, "reducer %s", red)
+		}
+	})
+}
@@ -13,6 +13,11 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
 	}
+	reduceFunc, err := GetReduceFunc(downsampler)
+	if err != nil {
+		return s, fmt.Errorf("downsampling %v: %w", downsampler, err)
+	}
+	keepSingleValue := downsamplerKeepsSingleValue(downsampler)
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
@@ -54,27 +59,16 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
-		} else if len(vals) == 1 {
+		} else if len(vals) == 1 && keepSingleValue {
 			value = vals[0]
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case "sum":
-				tmp = Sum(&ff)
-			case "mean":
-				tmp = Avg(&ff)
-			case "min":
-				tmp = Min(&ff)
-			case "max":
-				tmp = Max(&ff)
-			case "last":
-				tmp = Last(&ff)
-			default:
-				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
+			value = reduceFunc(&ff)
+			if value != nil && isRateReducer(downsampler) {
+				rate := *value / interval.Seconds()
+				value = &rate
 			}
-			value = tmp
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
@@ -82,3 +76,14 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	}
 	return resampled, nil
 }
+
+// downsamplerKeepsSingleValue returns true if applying the downsampler to a single value results in the same value.
+func downsamplerKeepsSingleValue(downsampler string) bool {
+	name, _, _, _ := parseReducer(downsampler)
+	switch name {
+	case "count", "count_non_null", "range", "stddev", "variance", "increase", "rate":
+		return false
+	default:
+		return true
+	}
+}
@@ -277,6 +277,71 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: downsampling (percentile(50) / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "percentile(50)",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(5),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(2),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(2),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (rate / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "rate",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(20),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(20),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(25),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}),
+		},
+		{
+			name:        "resample series: unknown downsampler",
+			interval:    time.Second * 5,
+			downsampler: "foo",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(10),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -290,3 +355,13 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleSeriesReportsDownsamplerError(t *testing.T) {
+	s := makeSeries("", nil, tp{time.Unix(1, 0), float64Pointer(10)})
+
+	_, err := s.Resample("", time.Second*5, "percentile(101)", "fillna", time.Unix(0, 0), time.Unix(10, 0))
+	require.ErrorContains(t, err, "downsampling percentile(101): percentile must be between 0 and 100")
+
+	_, err = s.Resample("", time.Second*5, "percentile", "fillna", time.Unix(0, 0), time.Unix(10, 0))
+	require.ErrorContains(t, err, "reduction percentile requires an argument")
+}
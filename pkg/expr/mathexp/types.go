@@ -246,3 +246,45 @@ func (s NoData) New() NoData {
 func NewNoData() NoData {
 	return NoData{data.NewFrame("no data")}
 }
+
+// TableData is a data frame that is passed as is, without converting it to numbers or series.
+// It is used for the inputs and outputs of SQL expressions.
+type TableData struct{ Frame *data.Frame }
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (s TableData) Type() parse.ReturnType { return parse.TypeTableData }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (s TableData) Value() any { return s }
+
+func (s TableData) GetLabels() data.Labels { return nil }
+
+func (s TableData) SetLabels(ls data.Labels) {}
+
+func (s TableData) GetMeta() any {
+	if s.Frame.Meta == nil {
+		return nil
+	}
+	return s.Frame.Meta.Custom
+}
+
+func (s TableData) SetMeta(v any) {
+	m := s.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		s.Frame.SetMeta(m)
+	}
+	m.Custom = v
+}
+
+func (s TableData) AddNotice(notice data.Notice) {
+	m := s.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		s.Frame.SetMeta(m)
+	}
+	m.Notices = append(m.Notices, notice)
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (s TableData) AsDataFrame() *data.Frame { return s.Frame }
@@ -323,3 +323,11 @@ func TestSeriesName(t *testing.T) {
 		})
 	}
 }
+
+func TestTableDataMeta(t *testing.T) {
+	table := TableData{Frame: data.NewFrame("", data.NewField("value", nil, []string{"a"}))}
+	require.Nil(t, table.GetMeta())
+
+	table.SetMeta("custom")
+	require.Equal(t, "custom", table.GetMeta())
+}
@@ -121,6 +121,11 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
 	case TypeThreshold:
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
+	case TypeSQL:
+		if !toggles.IsEnabledGlobally(featuremgmt.FlagSqlExpressions) {
+			return nil, fmt.Errorf("sql expressions are disabled. Enable the feature toggle %s to use expression '%v'", featuremgmt.FlagSqlExpressions, rn.RefID)
+		}
+		node.Command, err = UnmarshalSQLCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -148,6 +153,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// isInputToSQLExpr is true if the results of the query are only used by SQL expressions.
+	// The frames are then passed to the expressions as they are, without conversion to numbers or series.
+	isInputToSQLExpr bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -217,10 +226,19 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 		byDS[k] = append(byDS[k], node)
 	}
 
+	stats := executionStatsFromContext(ctx)
 	for _, nodeGroup := range byDS {
 		func() {
 			ctx, span := s.tracer.Start(ctx, "SSE.ExecuteDatasourceQuery")
 			defer span.End()
+			start := time.Now()
+			defer func() {
+				// All queries of the group are executed by a single request.
+				duration := time.Since(start)
+				for _, dn := range nodeGroup {
+					stats.record(dn, duration)
+				}
+			}()
 			firstNode := nodeGroup[0]
 			pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, firstNode.datasource.Type, firstNode.request.User, firstNode.datasource)
 			if err != nil {
@@ -289,7 +307,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				}
 
 				var result mathexp.Results
-				responseType, result, err := convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+				responseType, result, err := dn.convertFrames(ctx, dataFrames, s, logger)
 				if err != nil {
 					result.Error = makeConversionError(dn.RefID(), err)
 				}
@@ -357,13 +375,29 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}
 
 	var result mathexp.Results
-	responseType, result, err = convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+	responseType, result, err = dn.convertFrames(ctx, dataFrames, s, logger)
 	if err != nil {
 		err = makeConversionError(dn.refID, err)
 	}
 	return result, err
 }
 
+// convertFrames converts the frames of the response to results. If the node is an input to SQL expressions
+// every frame is returned as table data, otherwise the frames are converted to numbers or series.
+func (dn *DSNode) convertFrames(ctx context.Context, frames data.Frames, s *Service, logger log.Logger) (string, mathexp.Results, error) {
+	if !dn.isInputToSQLExpr {
+		return convertDataFramesToResults(ctx, frames, dn.datasource.Type, s, logger)
+	}
+	if len(frames) == 0 {
+		return "no-data", mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+	vals := make([]mathexp.Value, 0, len(frames))
+	for _, frame := range frames {
+		vals = append(vals, mathexp.TableData{Frame: frame})
+	}
+	return "table", mathexp.Results{Values: vals}, nil
+}
+
 func getResponseFrame(resp *backend.QueryDataResponse, refID string) (data.Frames, error) {
 	response, ok := resp.Responses[refID]
 	if !ok {
@@ -43,7 +43,8 @@ func IsDataSource(uid string) bool {
 }
 
 // NodeTypeFromDatasourceUID returns NodeType depending on the UID of the data source: TypeCMDNode if UID is DatasourceUID
-// or OldDatasourceUID, and TypeDatasourceNode otherwise.
+// or OldDatasourceUID, TypeMLNode if UID is MLDatasourceUID, TypeInputNode if UID is InputDatasourceUID, and
+// TypeDatasourceNode otherwise.
 func NodeTypeFromDatasourceUID(uid string) NodeType {
 	if IsDataSource(uid) {
 		return TypeCMDNode
@@ -51,6 +52,9 @@ func NodeTypeFromDatasourceUID(uid string) NodeType {
 	if uid == MLDatasourceUID {
 		return TypeMLNode
 	}
+	if uid == InputDatasourceUID {
+		return TypeInputNode
+	}
 	return TypeDatasourceNode
 }
 
@@ -136,6 +140,15 @@ func DataSourceModelFromNodeType(kind NodeType) (*datasources.DataSource, error)
 			JsonData:       simplejson.New(),
 			SecureJsonData: make(map[string][]byte),
 		}, nil
+	case TypeInputNode:
+		return &datasources.DataSource{
+			ID:             inputDatasourceID,
+			UID:            InputDatasourceUID,
+			Name:           InputDatasourceUID,
+			Type:           DatasourceType,
+			JsonData:       simplejson.New(),
+			SecureJsonData: make(map[string][]byte),
+		}, nil
 	case TypeDatasourceNode:
 		return nil, errors.New("cannot create expression data source for data source kind")
 	default:
@@ -114,6 +114,18 @@ func TestService(t *testing.T) {
 	if diff := cmp.Diff(expect, res, options...); diff != "" {
 		t.Errorf("Result mismatch (-want +got):\n%s", diff)
 	}
+
+	t.Run("records the execution statistics of each node", func(t *testing.T) {
+		stats := &ExecutionStats{}
+		_, err := s.ExecutePipeline(WithExecutionStats(context.Background(), stats), time.Now(), pl)
+		require.NoError(t, err)
+		nodes := stats.Nodes()
+		require.Len(t, nodes, 2)
+		require.Equal(t, "A", nodes[0].RefID)
+		require.Equal(t, TypeDatasourceNode, nodes[0].NodeType)
+		require.Equal(t, "B", nodes[1].RefID)
+		require.Equal(t, TypeCMDNode, nodes[1].NodeType)
+	})
 }
 
 func TestDSQueryError(t *testing.T) {
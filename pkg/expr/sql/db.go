@@ -0,0 +1,252 @@
+// Package sql runs SQL queries against data frames using an in-memory SQLite database.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/mattn/go-sqlite3"
+)
+
+const driverName = "sqlite3_sse"
+
+var registerDriver sync.Once
+
+// DB runs SQL queries against data frames. Every query is executed in a new in-memory database
+// that contains one table for each of the input frames, so queries cannot affect each other.
+type DB struct{}
+
+// NewInMemoryDB creates a DB.
+func NewInMemoryDB() *DB {
+	registerDriver.Do(func() {
+		sql.Register(driverName, &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				// Do not allow queries to access files or change the database settings.
+				conn.RegisterAuthorizer(func(op int, _, _, _ string) int {
+					switch op {
+					case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH, sqlite3.SQLITE_PRAGMA:
+						return sqlite3.SQLITE_DENY
+					default:
+						return sqlite3.SQLITE_OK
+					}
+				})
+				return nil
+			},
+		})
+	})
+	return &DB{}
+}
+
+// QueryFrames creates a table for each entry of tables, named after the key, runs the query and returns the result as a frame.
+// Frames of the same table are combined, columns that do not exist in a frame are NULL.
+func (db *DB) QueryFrames(ctx context.Context, name, query string, tables map[string][]*data.Frame) (*data.Frame, error) {
+	conn, err := sql.Open(driverName, ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	// every connection to :memory: creates a new database, therefore only a single connection must be used.
+	conn.SetMaxOpenConns(1)
+
+	names := make([]string, 0, len(tables))
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	for _, table := range names {
+		if err := createTable(ctx, conn, table, tables[table]); err != nil {
+			return nil, fmt.Errorf("failed to create table %s: %w", table, err)
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	return rowsToFrame(name, rows)
+}
+
+type column struct {
+	name    string
+	sqlType string
+}
+
+// createTable creates the table and inserts the rows of all frames.
+func createTable(ctx context.Context, conn *sql.DB, table string, frames []*data.Frame) error {
+	columns := []column{}
+	seen := map[string]struct{}{}
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if _, ok := seen[field.Name]; ok {
+				continue
+			}
+			seen[field.Name] = struct{}{}
+			columns = append(columns, column{name: field.Name, sqlType: sqlType(field.Type())})
+		}
+	}
+	if len(columns) == 0 {
+		// SQLite does not support tables without columns.
+		columns = append(columns, column{name: "value", sqlType: "REAL"})
+	}
+
+	defs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		defs = append(defs, quoteIdentifier(c.name)+" "+c.sqlType)
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(table), strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			names = append(names, quoteIdentifier(field.Name))
+		}
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			quoteIdentifier(table), strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")))
+		if err != nil {
+			return err
+		}
+		rowLen, err := frame.RowLen()
+		if err != nil {
+			_ = stmt.Close()
+			return err
+		}
+		args := make([]any, len(frame.Fields))
+		for i := 0; i < rowLen; i++ {
+			for j, field := range frame.Fields {
+				v, ok := field.ConcreteAt(i)
+				if !ok {
+					v = nil
+				}
+				args[j] = v
+			}
+			if _, err := stmt.ExecContext(ctx, args...); err != nil {
+				_ = stmt.Close()
+				return err
+			}
+		}
+		_ = stmt.Close()
+	}
+	return tx.Commit()
+}
+
+// sqlType returns the SQLite column type for the field type.
+func sqlType(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "TIMESTAMP"
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "BOOLEAN"
+	case t.Numeric():
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// quoteIdentifier quotes a table or column name.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// rowsToFrame reads the rows into a frame. Numeric columns are returned as nullable float64 fields,
+// time columns as nullable time fields and all other columns as nullable string fields.
+func rowsToFrame(name string, rows *sql.Rows) (*data.Frame, error) {
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var values [][]any
+	for rows.Next() {
+		row := make([]any, len(names))
+		ptrs := make([]any, len(names))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		values = append(values, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame(name)
+	for i, n := range names {
+		frame.Fields = append(frame.Fields, columnToField(n, i, values))
+	}
+	return frame, nil
+}
+
+// columnToField creates a field for the column. The type of the field is decided by the first non-null value.
+func columnToField(name string, idx int, rows [][]any) *data.Field {
+	var kind any
+	for _, row := range rows {
+		if row[idx] != nil {
+			kind = row[idx]
+			break
+		}
+	}
+	switch kind.(type) {
+	case time.Time:
+		v := make([]*time.Time, len(rows))
+		for i, row := range rows {
+			if t, ok := row[idx].(time.Time); ok {
+				v[i] = &t
+			}
+		}
+		return data.NewField(name, nil, v)
+	case string, []byte:
+		v := make([]*string, len(rows))
+		for i, row := range rows {
+			switch s := row[idx].(type) {
+			case string:
+				v[i] = &s
+			case []byte:
+				str := string(s)
+				v[i] = &str
+			case nil:
+			default:
+				str := fmt.Sprintf("%v", s)
+				v[i] = &str
+			}
+		}
+		return data.NewField(name, nil, v)
+	default:
+		v := make([]*float64, len(rows))
+		for i, row := range rows {
+			switch n := row[idx].(type) {
+			case float64:
+				v[i] = &n
+			case int64:
+				f := float64(n)
+				v[i] = &f
+			case bool:
+				f := 0.0
+				if n {
+					f = 1
+				}
+				v[i] = &f
+			}
+		}
+		return data.NewField(name, nil, v)
+	}
+}
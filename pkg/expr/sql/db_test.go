@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryFrames(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+	one, two := 1.0, 2.0
+
+	t.Run("should combine frames of the same table", func(t *testing.T) {
+		tables := map[string][]*data.Frame{
+			"A": {
+				data.NewFrame("",
+					data.NewField("time", nil, []time.Time{now, now.Add(time.Minute)}),
+					data.NewField("value", nil, []*float64{&one, nil}),
+				),
+				data.NewFrame("",
+					data.NewField("value", nil, []*float64{&two}),
+					data.NewField("host", nil, []string{"b"}),
+				),
+			},
+		}
+
+		frame, err := NewInMemoryDB().QueryFrames(context.Background(), "B", "SELECT time, value, host FROM A", tables)
+		require.NoError(t, err)
+		require.Equal(t, "B", frame.Name)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[2].Type())
+
+		ts, ok := frame.Fields[0].ConcreteAt(1)
+		require.True(t, ok)
+		require.Equal(t, now.Add(time.Minute), ts.(time.Time).UTC())
+		_, ok = frame.Fields[1].ConcreteAt(1)
+		require.False(t, ok)
+		_, ok = frame.Fields[2].ConcreteAt(0)
+		require.False(t, ok)
+		host, _ := frame.Fields[2].ConcreteAt(2)
+		require.Equal(t, "b", host)
+	})
+
+	t.Run("should create empty tables for tables without frames", func(t *testing.T) {
+		frame, err := NewInMemoryDB().QueryFrames(context.Background(), "B", "SELECT count(*) AS count FROM A", map[string][]*data.Frame{"A": nil})
+		require.NoError(t, err)
+		count, _ := frame.Fields[0].ConcreteAt(0)
+		require.Equal(t, 0.0, count)
+	})
+
+	t.Run("should deny pragma statements", func(t *testing.T) {
+		_, err := NewInMemoryDB().QueryFrames(context.Background(), "B", "PRAGMA database_list", map[string][]*data.Frame{})
+		require.Error(t, err)
+	})
+}
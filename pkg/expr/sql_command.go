@@ -0,0 +1,320 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// SQLCommand is an expression command that runs a SQL query against the results of other queries or expressions.
+// Every input referenced in the query by its refID, e.g. SELECT * FROM A JOIN B ON A.host = B.host, is available as a table.
+type SQLCommand struct {
+	Query       string
+	varsToQuery []string
+	refID       string
+	db          *sql.DB
+}
+
+// NewSQLCommand creates a new SQLCommand.
+func NewSQLCommand(refID, query string) (*SQLCommand, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query cannot be empty")
+	}
+	tables, err := tablesFromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("query does not reference any query or expression")
+	}
+	return &SQLCommand{
+		Query:       query,
+		varsToQuery: tables,
+		refID:       refID,
+		db:          sql.NewInMemoryDB(),
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("sql command is missing a query")
+	}
+	query, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("sql query is expected to be a string, got %T", rawExpr)
+	}
+	return NewSQLCommand(rn.RefID, query)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gr *SQLCommand) NeedsVars() []string {
+	return gr.varsToQuery
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gr *SQLCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+	span.SetAttributes(attribute.String("query", gr.Query))
+	defer span.End()
+
+	tables := make(map[string][]*data.Frame, len(gr.varsToQuery))
+	for _, refID := range gr.varsToQuery {
+		frames := []*data.Frame{}
+		for _, v := range vars[refID].Values {
+			frame, err := valueToTable(v)
+			if err != nil {
+				return mathexp.Results{}, fmt.Errorf("failed to convert %s to a table: %w", refID, err)
+			}
+			frames = append(frames, frame)
+		}
+		tables[refID] = frames
+	}
+
+	frame, err := gr.db.QueryFrames(ctx, gr.refID, gr.Query, tables)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute sql query: %w", err)
+	}
+	return tableToResults(frame)
+}
+
+// valueToTable converts a value to a frame in the long format. Numbers are converted to a row with a column per label
+// and a "value" column. Series are converted to a row per point with "time" and "value" columns and a column per label.
+func valueToTable(v mathexp.Value) (*data.Frame, error) {
+	switch t := v.(type) {
+	case mathexp.TableData:
+		if t.Frame.TimeSeriesSchema().Type == data.TimeSeriesTypeWide {
+			return data.WideToLong(t.Frame)
+		}
+		return t.Frame, nil
+	case mathexp.Number:
+		frame := data.NewFrame("", labelFields(t.GetLabels(), 1)...)
+		frame.Fields = append(frame.Fields, data.NewField("value", nil, []*float64{t.GetFloat64Value()}))
+		return frame, nil
+	case mathexp.Series:
+		times := make([]time.Time, t.Len())
+		values := make([]*float64, t.Len())
+		for i := 0; i < t.Len(); i++ {
+			times[i], values[i] = t.GetPoint(i)
+		}
+		frame := data.NewFrame("", data.NewField("time", nil, times), data.NewField("value", nil, values))
+		frame.Fields = append(frame.Fields, labelFields(t.GetLabels(), t.Len())...)
+		return frame, nil
+	case mathexp.NoData:
+		return data.NewFrame(""), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %v", v.Type())
+	}
+}
+
+// labelFields returns a string field for each label, sorted by name, that contains the label value rows times.
+func labelFields(labels data.Labels, rows int) []*data.Field {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]*data.Field, 0, len(names))
+	for _, name := range names {
+		values := make([]string, rows)
+		for i := range values {
+			values[i] = labels[name]
+		}
+		fields = append(fields, data.NewField(name, nil, values))
+	}
+	return fields
+}
+
+// tableToResults converts the result of the query to numbers if it has a single numeric column,
+// to series if it is a long time series and to table data otherwise.
+func tableToResults(frame *data.Frame) (mathexp.Results, error) {
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	if rowLen == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NoData{Frame: frame}}}, nil
+	}
+
+	if isNumberTable(frame) {
+		numbers, err := extractNumberSet(frame)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make([]mathexp.Value, 0, len(numbers))
+		for _, n := range numbers {
+			vals = append(vals, n)
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+		wide, err := data.LongToWide(frame, nil)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		series, err := WideToMany(wide, nil)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals := make([]mathexp.Value, 0, len(series))
+		for _, s := range series {
+			vals = append(vals, s)
+		}
+		return mathexp.Results{Values: vals}, nil
+	}
+
+	return mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}}, nil
+}
+
+// sqlKeywords are keywords that can follow a table name in a FROM or JOIN clause.
+var sqlKeywords = map[string]struct{}{
+	"where": {}, "group": {}, "order": {}, "limit": {}, "offset": {}, "having": {}, "window": {},
+	"join": {}, "inner": {}, "left": {}, "right": {}, "full": {}, "cross": {}, "outer": {}, "natural": {},
+	"on": {}, "using": {}, "union": {}, "except": {}, "intersect": {}, "select": {}, "from": {}, "as": {},
+}
+
+// tablesFromQuery returns the names of the tables that are referenced in FROM and JOIN clauses of the query
+// in order of appearance. Names of common table expressions are excluded.
+func tablesFromQuery(query string) ([]string, error) {
+	tokens, err := tokenizeSQL(query)
+	if err != nil {
+		return nil, err
+	}
+
+	cte := map[string]struct{}{}
+	for i := 0; i+2 < len(tokens); i++ {
+		if tokens[i].ident && strings.EqualFold(tokens[i+1].text, "as") && tokens[i+2].text == "(" {
+			cte[tokens[i].text] = struct{}{}
+		}
+	}
+
+	tables := []string{}
+	seen := map[string]struct{}{}
+	addTable := func(name string) {
+		if _, ok := cte[name]; ok {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		tables = append(tables, name)
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		keyword := strings.ToLower(tokens[i].text)
+		if tokens[i].quoted || (keyword != "from" && keyword != "join") {
+			continue
+		}
+		for i+1 < len(tokens) && tokens[i+1].ident {
+			i++
+			addTable(tokens[i].text)
+			// skip the optional alias
+			if i+1 < len(tokens) && strings.EqualFold(tokens[i+1].text, "as") {
+				i++
+			}
+			if i+1 < len(tokens) && tokens[i+1].ident && !isSQLKeyword(tokens[i+1]) {
+				i++
+			}
+			// a comma separated list of tables is only allowed after FROM
+			if keyword != "from" || i+1 >= len(tokens) || tokens[i+1].text != "," {
+				break
+			}
+			i++
+		}
+	}
+	return tables, nil
+}
+
+func isSQLKeyword(t sqlToken) bool {
+	if t.quoted {
+		return false
+	}
+	_, ok := sqlKeywords[strings.ToLower(t.text)]
+	return ok
+}
+
+type sqlToken struct {
+	text string
+	// ident is true if the token is an identifier or a keyword.
+	ident bool
+	// quoted is true if the identifier was quoted, which means it cannot be a keyword.
+	quoted bool
+}
+
+// tokenizeSQL splits the query into identifiers and punctuation. String literals, numbers and comments are dropped.
+func tokenizeSQL(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/'); i++ {
+			}
+			if i+1 >= len(runes) {
+				return nil, errors.New("unterminated comment in sql query")
+			}
+			i++
+		case r == '\'':
+			i++
+			for ; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated string in sql query")
+			}
+		case r == '"' || r == '`' || r == '[':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			start := i + 1
+			for i++; i < len(runes) && runes[i] != closing; i++ {
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated identifier in sql query")
+			}
+			tokens = append(tokens, sqlToken{text: string(runes[start:i]), ident: true, quoted: true})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < len(runes) && (unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1]) || runes[i+1] == '_' || runes[i+1] == '$') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{text: string(runes[start : i+1]), ident: true})
+		case unicode.IsDigit(r):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+		default:
+			tokens = append(tokens, sqlToken{text: string(r)})
+		}
+	}
+	return tokens, nil
+}
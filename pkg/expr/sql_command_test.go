@@ -0,0 +1,262 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestTablesFromQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:     "single table",
+			query:    "SELECT * FROM A",
+			expected: []string{"A"},
+		},
+		{
+			name:     "join with aliases",
+			query:    "SELECT a.host, a.value / b.value FROM A AS a INNER JOIN B b ON a.host = b.host",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "comma separated tables",
+			query:    "select * from A a, B where a.host = B.host",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "quoted names",
+			query:    "SELECT * FROM \"A\" JOIN `B` USING (host) JOIN [C] USING (host)",
+			expected: []string{"A", "B", "C"},
+		},
+		{
+			name:     "subquery",
+			query:    "SELECT host FROM (SELECT host, max(value) AS value FROM A GROUP BY host) WHERE value > 10",
+			expected: []string{"A"},
+		},
+		{
+			name:     "common table expressions are not tables",
+			query:    "WITH top AS (SELECT * FROM A ORDER BY value DESC LIMIT 3) SELECT * FROM top JOIN B ON top.host = B.host",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "strings and comments are ignored",
+			query:    "SELECT 'from X' AS s -- FROM Y\n/* JOIN Z */ FROM A",
+			expected: []string{"A"},
+		},
+		{
+			name:     "tables are only returned once",
+			query:    "SELECT * FROM A UNION SELECT * FROM A",
+			expected: []string{"A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := tablesFromQuery(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tables)
+		})
+	}
+
+	t.Run("unterminated string should error", func(t *testing.T) {
+		_, err := tablesFromQuery("SELECT * FROM A WHERE host = 'a")
+		require.Error(t, err)
+	})
+}
+
+func TestUnmarshalSQLCommand(t *testing.T) {
+	t.Run("should parse the query", func(t *testing.T) {
+		cmd, err := UnmarshalSQLCommand(&rawNode{
+			RefID: "C",
+			Query: map[string]any{"expression": "SELECT * FROM A JOIN B ON A.host = B.host", "type": "sql"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+	})
+	t.Run("should error if the query is missing", func(t *testing.T) {
+		_, err := UnmarshalSQLCommand(&rawNode{RefID: "C", Query: map[string]any{"type": "sql"}})
+		require.Error(t, err)
+	})
+	t.Run("should error if the query does not reference any table", func(t *testing.T) {
+		_, err := UnmarshalSQLCommand(&rawNode{RefID: "C", Query: map[string]any{"expression": "SELECT 1", "type": "sql"}})
+		require.ErrorContains(t, err, "does not reference")
+	})
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	now := time.Unix(1700000000, 0).UTC()
+
+	t.Run("should join numbers and return numbers", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT A.host, A.value / B.value AS value FROM A JOIN B ON A.host = B.host ORDER BY A.host")
+		require.NoError(t, err)
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{
+				sqlTestNumber("A", data.Labels{"host": "a"}, 10),
+				sqlTestNumber("A", data.Labels{"host": "b"}, 30),
+			}},
+			"B": mathexp.Results{Values: mathexp.Values{
+				sqlTestNumber("B", data.Labels{"host": "a"}, 2),
+				sqlTestNumber("B", data.Labels{"host": "b"}, 3),
+			}},
+		}
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for i, expected := range []struct {
+			host  string
+			value float64
+		}{{"a", 5}, {"b", 10}} {
+			n, ok := res.Values[i].(mathexp.Number)
+			require.True(t, ok)
+			require.Equal(t, data.Labels{"host": expected.host}, n.GetLabels())
+			require.Equal(t, expected.value, *n.GetFloat64Value())
+		}
+	})
+
+	t.Run("should return series for long time series results", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT time, host, value * 2 AS value FROM A ORDER BY time")
+		require.NoError(t, err)
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{
+				sqlTestSeries("A", data.Labels{"host": "a"}, now, 1, 2),
+			}},
+		}
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		s, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, s.GetLabels())
+		require.Equal(t, 2, s.Len())
+		ts, v := s.GetPoint(1)
+		require.Equal(t, now.Add(time.Minute), ts.UTC())
+		require.Equal(t, 4.0, *v)
+	})
+
+	t.Run("should query table data and return a table", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT host, status FROM A WHERE status <> 'ok'")
+		require.NoError(t, err)
+		frame := data.NewFrame("",
+			data.NewField("host", nil, []string{"a", "b"}),
+			data.NewField("status", nil, []string{"ok", "down"}),
+		)
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: frame}}},
+		}
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		table, ok := res.Values[0].(mathexp.TableData)
+		require.True(t, ok)
+		require.Equal(t, 1, table.Frame.Rows())
+		host, _ := table.Frame.Fields[0].ConcreteAt(0)
+		require.Equal(t, "b", host)
+	})
+
+	t.Run("should return no data if the query returns no rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A")
+		require.NoError(t, err)
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}
+
+		res, err := cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, mathexp.NewNoData().Type(), res.Values[0].Type())
+	})
+
+	t.Run("should not allow attaching databases", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "ATTACH DATABASE '/tmp/test.db' AS x; SELECT * FROM A")
+		require.NoError(t, err)
+		vars := mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}
+
+		_, err = cmd.Execute(context.Background(), now, vars, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
+
+func sqlTestNumber(refID string, labels data.Labels, value float64) mathexp.Number {
+	n := mathexp.NewNumber(refID, labels)
+	n.SetValue(util.Pointer(value))
+	return n
+}
+
+// sqlTestSeries creates a series with a point per value, one minute apart.
+func sqlTestSeries(refID string, labels data.Labels, start time.Time, values ...float64) mathexp.Series {
+	s := mathexp.NewSeries(refID, labels, len(values))
+	for i, v := range values {
+		s.SetPoint(i, start.Add(time.Duration(i)*time.Minute), util.Pointer(v))
+	}
+	return s
+}
+
+func TestSQLExpressionPipeline(t *testing.T) {
+	sqlQuery := func(expression string) Query {
+		return Query{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{"expression": "` + expression + `", "type": "sql"}`),
+		}
+	}
+	dsQuery := func(refID string) Query {
+		return Query{
+			RefID:      refID,
+			DataSource: &datasources.DataSource{UID: "Fake"},
+			TimeRange:  AbsoluteTimeRange{},
+		}
+	}
+
+	t.Run("should error if the feature toggle is disabled", func(t *testing.T) {
+		s := Service{features: featuremgmt.WithFeatures()}
+		_, err := s.buildPipeline(&Request{Queries: []Query{dsQuery("A"), sqlQuery("SELECT * FROM A")}})
+		require.ErrorContains(t, err, featuremgmt.FlagSqlExpressions)
+	})
+
+	s := Service{features: featuremgmt.WithFeatures(featuremgmt.FlagSqlExpressions)}
+
+	t.Run("should mark queries as inputs to sql expressions", func(t *testing.T) {
+		nodes, err := s.buildPipeline(&Request{Queries: []Query{dsQuery("A"), dsQuery("B"), sqlQuery("SELECT * FROM A JOIN B ON A.host = B.host")}})
+		require.NoError(t, err)
+		require.Equal(t, "C", nodes[len(nodes)-1].RefID())
+		for _, n := range nodes[:len(nodes)-1] {
+			require.True(t, n.(*DSNode).isInputToSQLExpr)
+		}
+	})
+
+	t.Run("should error if a query is also the input of another expression", func(t *testing.T) {
+		_, err := s.buildPipeline(&Request{Queries: []Query{
+			dsQuery("A"),
+			sqlQuery("SELECT * FROM A"),
+			{
+				RefID:      "D",
+				DataSource: dataSourceModel(),
+				JSON:       json.RawMessage(`{"expression": "$A * 2", "type": "math"}`),
+			},
+		}})
+		require.ErrorContains(t, err, "is the input for a sql expression")
+	})
+
+	t.Run("should error if a table is not a query or expression", func(t *testing.T) {
+		_, err := s.buildPipeline(&Request{Queries: []Query{dsQuery("A"), sqlQuery("SELECT * FROM X")}})
+		require.ErrorContains(t, err, "find dependent")
+	})
+}
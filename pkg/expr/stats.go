@@ -0,0 +1,57 @@
+package expr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NodeExecutionStats contains the statistics of the execution of a node of a pipeline.
+type NodeExecutionStats struct {
+	RefID    string
+	NodeType NodeType
+	// Duration is the time spent executing the node. Datasource nodes that are executed in a single request to the
+	// data source have the duration of the whole request.
+	Duration time.Duration
+}
+
+// ExecutionStats collects the statistics of the execution of a pipeline. It is passed to ExecutePipeline
+// in the context by WithExecutionStats.
+type ExecutionStats struct {
+	mtx   sync.Mutex
+	nodes []NodeExecutionStats
+}
+
+type executionStatsKey struct{}
+
+// WithExecutionStats returns a context that makes ExecutePipeline record the statistics of the execution in stats.
+func WithExecutionStats(ctx context.Context, stats *ExecutionStats) context.Context {
+	return context.WithValue(ctx, executionStatsKey{}, stats)
+}
+
+func executionStatsFromContext(ctx context.Context) *ExecutionStats {
+	stats, _ := ctx.Value(executionStatsKey{}).(*ExecutionStats)
+	return stats
+}
+
+// Nodes returns the statistics of the executed nodes in the order of execution.
+func (s *ExecutionStats) Nodes() []NodeExecutionStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	result := make([]NodeExecutionStats, len(s.nodes))
+	copy(result, s.nodes)
+	return result
+}
+
+func (s *ExecutionStats) record(node Node, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.nodes = append(s.nodes, NodeExecutionStats{
+		RefID:    node.RefID(),
+		NodeType: node.NodeType(),
+		Duration: duration,
+	})
+}
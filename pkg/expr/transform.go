@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -31,6 +32,8 @@ type Query struct {
 	Interval      time.Duration
 	QueryType     string
 	MaxDataPoints int64
+	// Frames is the data of a query of the InputDatasourceUID data source.
+	Frames data.Frames
 }
 
 // TimeRange is a time.Time based TimeRange.
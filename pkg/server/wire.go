@@ -91,6 +91,8 @@ import (
 	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmigration "github.com/grafana/grafana/pkg/services/ngalert/migration"
 	migrationStore "github.com/grafana/grafana/pkg/services/ngalert/migration/store"
+	ngnotifier "github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	nghistorian "github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
@@ -242,6 +244,8 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(jwt.JWTService), new(*jwt.AuthService)),
 	ngstore.ProvideDBStore,
 	ngimage.ProvideDeleteExpiredService,
+	nghistorian.ProvideDeleteExpiredService,
+	ngnotifier.ProvideDeleteExpiredDeliveriesService,
 	ngmigration.ProvideService,
 	migrationStore.ProvideMigrationStore,
 	ngalert.ProvideService,
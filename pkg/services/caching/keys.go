@@ -0,0 +1,199 @@
+package caching
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+)
+
+const (
+	keyPrefixQuery    = "qc-"
+	keyPrefixResource = "rc-"
+)
+
+// ignoredQueryFields are set by the frontend on every request and do not affect the result of a query.
+var ignoredQueryFields = []string{"requestId", "key"}
+
+// datasourceCachingSettings are read from the JSON data of a data source.
+type datasourceCachingSettings struct {
+	// QueryCachingTTL overrides the default TTL of query responses, in milliseconds.
+	QueryCachingTTL int64 `json:"queryCachingTTL"`
+	// ResourceCachingTTL overrides the default TTL of resource responses, in milliseconds.
+	ResourceCachingTTL int64 `json:"resourceCachingTTL"`
+	// DisableQueryCaching opts a data source out of caching.
+	DisableQueryCaching bool `json:"disableQueryCaching"`
+}
+
+func readDatasourceCachingSettings(ds *backend.DataSourceInstanceSettings) datasourceCachingSettings {
+	s := datasourceCachingSettings{}
+	if ds == nil || len(ds.JSONData) == 0 {
+		return s
+	}
+	// Invalid JSON data leaves the defaults in place; the data source itself reports such errors.
+	_ = json.Unmarshal(ds.JSONData, &s)
+	return s
+}
+
+// queryTTL returns the TTL for query responses of the data source and false if they should not be cached.
+func (s *OSSCachingService) queryTTL(ds *backend.DataSourceInstanceSettings) (time.Duration, bool) {
+	if ds == nil {
+		return 0, false
+	}
+	dsSettings := readDatasourceCachingSettings(ds)
+	return s.ttl(dsSettings, dsSettings.QueryCachingTTL, s.settings.TTL)
+}
+
+// resourceTTL returns the TTL for resource responses of the data source and false if they should not be cached.
+func (s *OSSCachingService) resourceTTL(ds *backend.DataSourceInstanceSettings) (time.Duration, bool) {
+	if ds == nil {
+		return 0, false
+	}
+	dsSettings := readDatasourceCachingSettings(ds)
+	return s.ttl(dsSettings, dsSettings.ResourceCachingTTL, s.settings.ResourcesTTL)
+}
+
+func (s *OSSCachingService) ttl(dsSettings datasourceCachingSettings, overrideMs int64, defaultTTL time.Duration) (time.Duration, bool) {
+	if dsSettings.DisableQueryCaching {
+		return 0, false
+	}
+	ttl := defaultTTL
+	if overrideMs > 0 {
+		ttl = time.Duration(overrideMs) * time.Millisecond
+	}
+	if s.settings.MaxTTL > 0 && ttl > s.settings.MaxTTL {
+		ttl = s.settings.MaxTTL
+	}
+	return ttl, ttl > 0
+}
+
+// shouldBypassQuery reports whether a query must be sent to the data source, because the user asked
+// for fresh data or because the response depends on the identity of the user.
+func shouldBypassQuery(ctx context.Context, req *backend.QueryDataRequest) bool {
+	if req.GetHTTPHeader(backend.OAuthIdentityTokenHeaderName) != "" || req.GetHTTPHeader(backend.OAuthIdentityIDTokenHeaderName) != "" {
+		return true
+	}
+	return requestsNoCache(ctx)
+}
+
+// shouldBypassResource reports whether a resource request must be sent to the plugin. Only GET requests
+// without forwarded credentials are cached.
+func shouldBypassResource(ctx context.Context, req *backend.CallResourceRequest) bool {
+	if req.Method != http.MethodGet {
+		return true
+	}
+	if req.GetHTTPHeader(backend.OAuthIdentityTokenHeaderName) != "" || req.GetHTTPHeader(backend.OAuthIdentityIDTokenHeaderName) != "" {
+		return true
+	}
+	return requestsNoCache(ctx)
+}
+
+func requestsNoCache(ctx context.Context) bool {
+	reqCtx := contexthandler.FromContext(ctx)
+	if reqCtx == nil || reqCtx.Req == nil {
+		return false
+	}
+	cc := strings.ToLower(reqCtx.Req.Header.Get("Cache-Control"))
+	return strings.Contains(cc, "no-cache") || strings.Contains(cc, "no-store")
+}
+
+type queryKey struct {
+	OrgID         int64
+	DatasourceUID string
+	// DatasourceUpdated invalidates cached entries when the data source is edited.
+	DatasourceUpdated time.Time
+	Queries           []normalizedQuery
+}
+
+type normalizedQuery struct {
+	RefID         string
+	QueryType     string
+	MaxDataPoints int64
+	Interval      time.Duration
+	From          time.Time
+	To            time.Time
+	Model         any
+}
+
+// queryCacheKey builds a cache key from the parts of the request that affect its result. Queries are
+// sorted by refID and the model is re-encoded so that field order does not matter. The time range is
+// aligned to the TTL, so that refreshes of a relative time range within one TTL window share the entry.
+func queryCacheKey(req *backend.QueryDataRequest, ttl time.Duration) (string, error) {
+	ds := req.PluginContext.DataSourceInstanceSettings
+	k := queryKey{
+		OrgID:             req.PluginContext.OrgID,
+		DatasourceUID:     ds.UID,
+		DatasourceUpdated: ds.Updated.UTC(),
+		Queries:           make([]normalizedQuery, 0, len(req.Queries)),
+	}
+
+	for _, q := range req.Queries {
+		var model map[string]any
+		if len(q.JSON) > 0 {
+			if err := json.Unmarshal(q.JSON, &model); err != nil {
+				return "", err
+			}
+			for _, f := range ignoredQueryFields {
+				delete(model, f)
+			}
+		}
+		k.Queries = append(k.Queries, normalizedQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			Interval:      q.Interval,
+			From:          q.TimeRange.From.UTC().Truncate(ttl),
+			To:            q.TimeRange.To.UTC().Truncate(ttl),
+			Model:         model,
+		})
+	}
+	sort.Slice(k.Queries, func(i, j int) bool {
+		return k.Queries[i].RefID < k.Queries[j].RefID
+	})
+
+	return hashKey(keyPrefixQuery, k)
+}
+
+type resourceKey struct {
+	OrgID         int64
+	PluginID      string
+	DatasourceUID string
+	// DatasourceUpdated invalidates cached entries when the data source is edited.
+	DatasourceUpdated time.Time
+	Method            string
+	URL               string
+	Body              []byte
+}
+
+func resourceCacheKey(req *backend.CallResourceRequest) (string, error) {
+	k := resourceKey{
+		OrgID:    req.PluginContext.OrgID,
+		PluginID: req.PluginContext.PluginID,
+		Method:   req.Method,
+		URL:      req.URL,
+		Body:     req.Body,
+	}
+	if ds := req.PluginContext.DataSourceInstanceSettings; ds != nil {
+		k.DatasourceUID = ds.UID
+		k.DatasourceUpdated = ds.Updated.UTC()
+	}
+	return hashKey(keyPrefixResource, k)
+}
+
+func hashKey(prefix string, v any) (string, error) {
+	// encoding/json sorts map keys, which makes the encoding of the query models deterministic.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return prefix + hex.EncodeToString(sum[:]), nil
+}
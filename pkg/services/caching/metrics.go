@@ -0,0 +1,44 @@
+package caching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+const (
+	cacheTypeQuery    = "query"
+	cacheTypeResource = "resource"
+)
+
+type cacheMetrics struct {
+	// requests counts cache lookups by type and X-Cache status, the hit rate is
+	// hits / (hits + misses).
+	requests        *prometheus.CounterVec
+	writeErrors     *prometheus.CounterVec
+	skippedTooLarge *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *cacheMetrics {
+	return &cacheMetrics{
+		requests: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "caching",
+			Name:      "requests_total",
+			Help:      "The number of cache lookups by request type and cache status.",
+		}, []string{"type", "status"}),
+		writeErrors: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "caching",
+			Name:      "write_errors_total",
+			Help:      "The number of responses that could not be written to the cache.",
+		}, []string{"type"}),
+		skippedTooLarge: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "caching",
+			Name:      "skipped_too_large_total",
+			Help:      "The number of responses not cached because they exceed max_value_mb.",
+		}, []string{"type"}),
+	}
+}
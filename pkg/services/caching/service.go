@@ -2,8 +2,19 @@ package caching
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -35,10 +46,6 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
-}
-
 type CachingService interface {
 	// HandleQueryRequest uses a QueryDataRequest to check the cache for any existing results for that query.
 	// If none are found, it should return false and a CachedQueryDataResponse with an UpdateCacheFn which can be used to update the results cache after the fact.
@@ -49,16 +56,176 @@ type CachingService interface {
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
 }
 
-// Implementation of interface - does nothing
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage, reg prometheus.Registerer) *OSSCachingService {
+	return &OSSCachingService{
+		settings: cfg.Caching,
+		cache:    cache,
+		metrics:  newMetrics(reg),
+		log:      log.New("caching"),
+	}
+}
+
+// OSSCachingService caches query and resource responses in the configured remote cache.
+// A zero value OSSCachingService is valid and never caches anything.
 type OSSCachingService struct {
+	settings setting.CachingSettings
+	cache    remotecache.CacheStorage
+	metrics  *cacheMetrics
+	log      log.Logger
 }
 
+func (s *OSSCachingService) isEnabled() bool {
+	return s.settings.Enabled && s.cache != nil
+}
+
+// HandleQueryRequest looks up the response for a query request in the cache. On a miss it returns an UpdateCacheFn
+// that stores successful responses for the TTL of the data source.
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	if !s.isEnabled() || req == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	ttl, ok := s.queryTTL(req.PluginContext.DataSourceInstanceSettings)
+	if !ok || shouldBypassQuery(ctx, req) {
+		s.setStatus(ctx, cacheTypeQuery, StatusBypass)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := queryCacheKey(req, ttl)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to compute query cache key", "error", err)
+		s.setStatus(ctx, cacheTypeQuery, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	cached, err := s.cache.Get(ctx, key)
+	if err == nil {
+		resp := &backend.QueryDataResponse{}
+		if err := json.Unmarshal(cached, resp); err == nil {
+			s.setStatus(ctx, cacheTypeQuery, StatusHit)
+			return true, CachedQueryDataResponse{Response: resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode cached query response, ignoring it", "key", key, "error", err)
+	} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.log.FromContext(ctx).Warn("Failed to read query response from cache", "error", err)
+		s.setStatus(ctx, cacheTypeQuery, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	s.setStatus(ctx, cacheTypeQuery, StatusMiss)
+	return false, CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.QueryDataResponse) {
+			if resp == nil || hasQueryErrors(resp) {
+				return
+			}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				s.log.FromContext(ctx).Warn("Failed to encode query response for cache", "error", err)
+				return
+			}
+			s.store(ctx, cacheTypeQuery, key, b, ttl)
+		},
+	}
 }
 
+// HandleResourceRequest looks up the response for a GET resource request in the cache. On a miss it returns an
+// UpdateCacheFn that stores the response if the plugin replies with a single successful response.
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
-	return false, CachedResourceDataResponse{}
+	if !s.isEnabled() || req == nil {
+		return false, CachedResourceDataResponse{}
+	}
+
+	ttl, ok := s.resourceTTL(req.PluginContext.DataSourceInstanceSettings)
+	if !ok || shouldBypassResource(ctx, req) {
+		s.setStatus(ctx, cacheTypeResource, StatusBypass)
+		return false, CachedResourceDataResponse{}
+	}
+
+	key, err := resourceCacheKey(req)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to compute resource cache key", "error", err)
+		s.setStatus(ctx, cacheTypeResource, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	cached, err := s.cache.Get(ctx, key)
+	if err == nil {
+		resp := &backend.CallResourceResponse{}
+		if err := json.Unmarshal(cached, resp); err == nil {
+			s.setStatus(ctx, cacheTypeResource, StatusHit)
+			return true, CachedResourceDataResponse{Response: resp}
+		}
+		s.log.FromContext(ctx).Warn("Failed to decode cached resource response, ignoring it", "key", key, "error", err)
+	} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.log.FromContext(ctx).Warn("Failed to read resource response from cache", "error", err)
+		s.setStatus(ctx, cacheTypeResource, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	s.setStatus(ctx, cacheTypeResource, StatusMiss)
+
+	// Plugins may stream several responses for a single request. Only single responses are cached, so the entry
+	// written for the first response is removed again as soon as a second one is sent.
+	var mu sync.Mutex
+	responses := 0
+	return false, CachedResourceDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.CallResourceResponse) {
+			mu.Lock()
+			defer mu.Unlock()
+			responses++
+			if responses > 1 {
+				if responses == 2 {
+					if err := s.cache.Delete(ctx, key); err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+						s.log.FromContext(ctx).Warn("Failed to remove streamed resource response from cache", "error", err)
+					}
+				}
+				return
+			}
+			if resp == nil || resp.Status < http.StatusOK || resp.Status >= http.StatusMultipleChoices {
+				return
+			}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				s.log.FromContext(ctx).Warn("Failed to encode resource response for cache", "error", err)
+				return
+			}
+			s.store(ctx, cacheTypeResource, key, b, ttl)
+		},
+	}
+}
+
+func (s *OSSCachingService) store(ctx context.Context, cacheType string, key string, value []byte, ttl time.Duration) {
+	if s.settings.MaxValueSize > 0 && len(value) > s.settings.MaxValueSize {
+		if s.metrics != nil {
+			s.metrics.skippedTooLarge.WithLabelValues(cacheType).Inc()
+		}
+		return
+	}
+	if err := s.cache.Set(ctx, key, value, ttl); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to write response to cache", "type", cacheType, "error", err)
+		if s.metrics != nil {
+			s.metrics.writeErrors.WithLabelValues(cacheType).Inc()
+		}
+	}
+}
+
+// setStatus writes the X-Cache response header, if the request came through the HTTP API, and records the lookup.
+func (s *OSSCachingService) setStatus(ctx context.Context, cacheType string, status string) {
+	if s.metrics != nil {
+		s.metrics.requests.WithLabelValues(cacheType, status).Inc()
+	}
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+	}
+}
+
+func hasQueryErrors(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return true
+		}
+	}
+	return false
 }
 
 var _ CachingService = &OSSCachingService{}
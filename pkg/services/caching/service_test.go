@@ -0,0 +1,211 @@
+package caching
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestOSSCachingService_HandleQueryRequest(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 30, 0, time.UTC)
+
+	newQueryRequest := func(jsonData string, from time.Time) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID: 1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					UID:      "ds",
+					Type:     "prometheus",
+					JSONData: []byte(jsonData),
+				},
+			},
+			Queries: []backend.DataQuery{
+				{
+					RefID:     "A",
+					JSON:      []byte(`{"expr":"up","requestId":"1"}`),
+					TimeRange: backend.TimeRange{From: from.Add(-time.Hour), To: from},
+				},
+			},
+		}
+	}
+
+	response := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("A", data.NewField("value", nil, []float64{1, 2}))}},
+	}}
+
+	t.Run("zero value service never caches", func(t *testing.T) {
+		s := &OSSCachingService{}
+		hit, cr := s.HandleQueryRequest(context.Background(), newQueryRequest(`{}`, now))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+	})
+
+	t.Run("miss stores the response and the next request hits", func(t *testing.T) {
+		s, reg := newTestService(t)
+
+		ctx, rec := newReqContext(t, "")
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, now))
+		require.False(t, hit)
+		require.NotNil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusMiss, rec.Header().Get(XCacheHeader))
+		cr.UpdateCacheFn(ctx, response)
+
+		// A refresh a few seconds later and a new requestId fall into the same cache entry.
+		ctx, rec = newReqContext(t, "")
+		req := newQueryRequest(`{}`, now.Add(10*time.Second))
+		req.Queries[0].JSON = []byte(`{"requestId":"2","expr":"up"}`)
+		hit, cr = s.HandleQueryRequest(ctx, req)
+		require.True(t, hit)
+		assert.Equal(t, StatusHit, rec.Header().Get(XCacheHeader))
+		require.Contains(t, cr.Response.Responses, "A")
+		assert.Equal(t, 2, cr.Response.Responses["A"].Frames[0].Rows())
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(reg.requests.WithLabelValues(cacheTypeQuery, StatusHit)))
+		assert.Equal(t, 1.0, testutil.ToFloat64(reg.requests.WithLabelValues(cacheTypeQuery, StatusMiss)))
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, _ := newReqContext(t, "")
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, now))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.ErrDataResponse(backend.StatusBadRequest, "boom")}})
+
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, now))
+		assert.False(t, hit)
+	})
+
+	t.Run("data source TTL is honoured", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ttl, ok := s.queryTTL(&backend.DataSourceInstanceSettings{JSONData: []byte(`{"queryCachingTTL": 300000}`)})
+		require.True(t, ok)
+		assert.Equal(t, 5*time.Minute, ttl)
+
+		ttl, ok = s.queryTTL(&backend.DataSourceInstanceSettings{JSONData: []byte(`{"queryCachingTTL": 86400000}`)})
+		require.True(t, ok)
+		assert.Equal(t, time.Hour, ttl, "TTL should be capped by max_ttl")
+	})
+
+	t.Run("bypass when data source opts out", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, rec := newReqContext(t, "")
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{"disableQueryCaching": true}`, now))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusBypass, rec.Header().Get(XCacheHeader))
+	})
+
+	t.Run("bypass when client sends Cache-Control: no-cache", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, rec := newReqContext(t, "no-cache")
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, now))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusBypass, rec.Header().Get(XCacheHeader))
+	})
+
+	t.Run("bypass when user credentials are forwarded", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, rec := newReqContext(t, "")
+		req := newQueryRequest(`{}`, now)
+		req.SetHTTPHeader(backend.OAuthIdentityTokenHeaderName, "Bearer token")
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		assert.False(t, hit)
+		assert.Equal(t, StatusBypass, rec.Header().Get(XCacheHeader))
+	})
+}
+
+func TestOSSCachingService_HandleResourceRequest(t *testing.T) {
+	newResourceRequest := func(method string) *backend.CallResourceRequest {
+		return &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				PluginID:                   "prometheus",
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "ds", JSONData: []byte(`{}`)},
+			},
+			Method: method,
+			Path:   "api/v1/labels",
+			URL:    "api/v1/labels?match[]=up",
+		}
+	}
+
+	t.Run("GET responses are cached", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, _ := newReqContext(t, "")
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		require.False(t, hit)
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`["job"]`)})
+
+		ctx, rec := newReqContext(t, "")
+		hit, cr = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		require.True(t, hit)
+		assert.Equal(t, []byte(`["job"]`), cr.Response.Body)
+		assert.Equal(t, StatusHit, rec.Header().Get(XCacheHeader))
+	})
+
+	t.Run("streamed responses are not cached", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, _ := newReqContext(t, "")
+		_, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`1`)})
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`2`)})
+
+		hit, _ := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		assert.False(t, hit)
+	})
+
+	t.Run("non-GET requests bypass the cache", func(t *testing.T) {
+		s, _ := newTestService(t)
+		ctx, rec := newReqContext(t, "")
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodPost))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusBypass, rec.Header().Get(XCacheHeader))
+	})
+}
+
+func newTestService(t *testing.T) (*OSSCachingService, *cacheMetrics) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.Caching = setting.CachingSettings{
+		Enabled:      true,
+		TTL:          time.Minute,
+		ResourcesTTL: time.Minute,
+		MaxTTL:       time.Hour,
+		MaxValueSize: 1024 * 1024,
+	}
+	s := ProvideCachingService(cfg, remotecache.NewFakeCacheStorage(), prometheus.NewRegistry())
+	return s, s.metrics
+}
+
+func newReqContext(t *testing.T, cacheControl string) (context.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "/api/ds/query", nil)
+	require.NoError(t, err)
+	if cacheControl != "" {
+		req.Header.Set("Cache-Control", cacheControl)
+	}
+	rec := httptest.NewRecorder()
+	reqCtx := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req:  req,
+			Resp: web.NewResponseWriter(req.Method, rec),
+		},
+	}
+	return ctxkey.Set(context.Background(), reqCtx), rec
+}
@@ -1240,6 +1240,14 @@ var (
 			Owner:        identityAccessTeam,
 			Created:      time.Date(2023, time.November, 29, 12, 0, 0, 0, time.UTC),
 		},
+		{
+			Name:         "sqlExpressions",
+			Description:  "Enables SQL expressions that query the results of other queries and expressions",
+			Stage:        FeatureStageExperimental,
+			FrontendOnly: false,
+			Owner:        grafanaAlertingSquad,
+			Created:      time.Date(2023, time.December, 4, 12, 0, 0, 0, time.UTC),
+		},
 	}
 )
 
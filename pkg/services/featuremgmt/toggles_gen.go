@@ -598,4 +598,8 @@ const (
 	// FlagDisplayAnonymousStats
 	// Enables anonymous stats to be shown in the UI for Grafana
 	FlagDisplayAnonymousStats = "displayAnonymousStats"
+
+	// FlagSqlExpressions
+	// Enables SQL expressions that query the results of other queries and expressions
+	FlagSqlExpressions = "sqlExpressions"
 )
@@ -63,6 +63,10 @@ func (r *RuleService) getRulesQueryEvaluator(rules ...*models.AlertRule) accessc
 				DatasourceUID == expr.OldDatasourceUID {
 				continue
 			}
+			// rule result queries use the results of rules of the same group, which do not require access to a data source.
+			if query.IsRuleResultQuery() {
+				continue
+			}
 			if _, ok := added[query.DatasourceUID]; ok {
 				continue
 			}
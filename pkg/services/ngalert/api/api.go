@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -39,6 +40,22 @@ type AlertingStore interface {
 	GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) (*models.AlertConfiguration, error)
 }
 
+// SilenceAuditStore records who created and expired silences.
+type SilenceAuditStore interface {
+	InsertSilenceAuditEntry(ctx context.Context, entry models.SilenceAuditEntry) error
+	ListSilenceAuditEntries(ctx context.Context, query models.ListSilenceAuditEntriesQuery) ([]models.SilenceAuditEntry, error)
+}
+
+// NotificationLogStore is the store of the delivery attempts of contact points.
+type NotificationLogStore interface {
+	ListNotificationDeliveries(ctx context.Context, query models.ListNotificationDeliveriesQuery) ([]models.NotificationDelivery, error)
+}
+
+// RuleEvaluationProfiles provides the evaluation statistics of alert rules. It is nil if the statistics are not collected.
+type RuleEvaluationProfiles interface {
+	Get(key models.AlertRuleKey) (schedule.RuleEvaluationProfile, bool)
+}
+
 type RuleAccessControlService interface {
 	HasAccessToRuleGroup(ctx context.Context, user identity.Requester, rules models.RulesGroup) (bool, error)
 	AuthorizeAccessToRuleGroup(ctx context.Context, user identity.Requester, rules models.RulesGroup) error
@@ -60,13 +77,17 @@ type API struct {
 	AdminConfigStore     store.AdminConfigurationStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
-	StateManager         *state.Manager
+	StateManager         state.AlertInstanceManager
 	AccessControl        ac.AccessControl
 	Policies             *provisioning.NotificationPolicyService
 	ContactPointService  *provisioning.ContactPointService
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	ScheduledSilences    *provisioning.ScheduledSilenceService
+	SilenceAudit         SilenceAuditStore
+	NotificationLog      NotificationLogStore
+	EvaluationProfiles   RuleEvaluationProfiles
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
 	FeatureManager       featuremgmt.FeatureToggles
@@ -91,13 +112,23 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		&AlertmanagerSrv{crypto: api.MultiOrgAlertmanager.Crypto, log: logger, ac: api.AccessControl, mam: api.MultiOrgAlertmanager},
+		&AlertmanagerSrv{
+			crypto:          api.MultiOrgAlertmanager.Crypto,
+			log:             logger,
+			ac:              api.AccessControl,
+			mam:             api.MultiOrgAlertmanager,
+			silenceAudit:    api.SilenceAudit,
+			notificationLog: api.NotificationLog,
+			ruleStore:       api.RuleStore,
+			authz:           ruleAuthzService,
+			cfg:             &api.Cfg.UnifiedAlerting,
+		},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkingProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, authz: ruleAuthzService},
+		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, authz: ruleAuthzService, profiles: api.EvaluationProfiles},
 	), m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkingRuler(
@@ -112,6 +143,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			log:                logger,
 			cfg:                &api.Cfg.UnifiedAlerting,
 			authz:              ruleAuthzService,
+			nsValidator:        notifier.NewNotificationSettingsValidationService(api.AlertingStore),
+			dsCache:            api.DatasourceCache,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -122,7 +155,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			authz:           ruleAuthzService,
 			evaluator:       api.EvaluatorFactory,
 			cfg:             &api.Cfg.UnifiedAlerting,
-			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Tracer),
+			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Tracer, api.Historian),
+			ruleStore:       api.RuleStore,
 			featureManager:  api.FeatureManager,
 			appUrl:          api.AppUrl,
 			tracer:          api.Tracer,
@@ -143,6 +177,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		scheduledSilences:   api.ScheduledSilences,
 	}), m)
 
 	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
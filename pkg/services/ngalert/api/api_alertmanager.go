@@ -17,8 +17,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -28,10 +30,16 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	log    log.Logger
-	ac     accesscontrol.AccessControl
-	mam    *notifier.MultiOrgAlertmanager
-	crypto notifier.Crypto
+	log          log.Logger
+	ac           accesscontrol.AccessControl
+	mam          *notifier.MultiOrgAlertmanager
+	crypto       notifier.Crypto
+	silenceAudit SilenceAuditStore
+	// notificationLog is the store of the notification delivery log. Can be nil.
+	notificationLog NotificationLogStore
+	ruleStore       RuleStore
+	authz           RuleAccessControlService
+	cfg             *setting.UnifiedAlertingSettings
 }
 
 type UnknownReceiverError struct {
@@ -87,6 +95,7 @@ func (srv AlertmanagerSrv) RouteCreateSilence(c *contextmodel.ReqContext, postab
 
 		return ErrResp(http.StatusInternalServerError, err, "failed to create silence")
 	}
+	srv.recordSilenceAudit(c, silenceID, ngmodels.SilenceAuditActionCreate)
 	return response.JSON(http.StatusAccepted, apimodels.PostSilencesOKBody{
 		SilenceID: silenceID,
 	})
@@ -118,9 +127,112 @@ func (srv AlertmanagerSrv) RouteDeleteSilence(c *contextmodel.ReqContext, silenc
 		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	srv.recordSilenceAudit(c, silenceID, ngmodels.SilenceAuditActionExpire)
 	return response.JSON(http.StatusOK, util.DynMap{"message": "silence deleted"})
 }
 
+func (srv AlertmanagerSrv) RouteGetSilencesAudit(c *contextmodel.ReqContext) response.Response {
+	if srv.silenceAudit == nil {
+		return ErrResp(http.StatusNotFound, errors.New("silence audit is not available"), "")
+	}
+	entries, err := srv.silenceAudit.ListSilenceAuditEntries(c.Req.Context(), ngmodels.ListSilenceAuditEntriesQuery{
+		OrgID:               c.SignedInUser.GetOrgID(),
+		SilenceID:           c.Query("silenceId"),
+		ScheduledSilenceUID: c.Query("scheduledSilenceUid"),
+		Limit:               c.QueryInt("limit"),
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the silence audit")
+	}
+	result := make(apimodels.GettableSilenceAuditEntries, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, apimodels.GettableSilenceAuditEntry{
+			SilenceID:           e.SilenceID,
+			Action:              string(e.Action),
+			User:                e.User,
+			ScheduledSilenceUID: e.ScheduledSilenceUID,
+			Created:             e.Created,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// defaultNotificationDeliveriesLimit is the number of delivery attempts returned if the request does not set a limit.
+const defaultNotificationDeliveriesLimit = 100
+
+func (srv AlertmanagerSrv) RouteGetNotificationDeliveries(c *contextmodel.ReqContext) response.Response {
+	if srv.notificationLog == nil || !srv.cfg.NotificationLog.Enabled {
+		return ErrResp(http.StatusNotFound, errors.New("the notification delivery log is not enabled"), "")
+	}
+	query := ngmodels.ListNotificationDeliveriesQuery{
+		OrgID:            c.SignedInUser.GetOrgID(),
+		Receiver:         c.Query("receiver"),
+		Integration:      c.Query("integration"),
+		IntegrationUID:   c.Query("integrationUid"),
+		AlertFingerprint: c.Query("alertFingerprint"),
+		Limit:            c.QueryInt("limit"),
+	}
+	switch status := c.Query("status"); status {
+	case "":
+	case "success", "failure":
+		success := status == "success"
+		query.Success = &success
+	default:
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid status %q, must be success or failure", status), "")
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(from, 0)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(to, 0)
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultNotificationDeliveriesLimit
+	}
+
+	deliveries, err := srv.notificationLog.ListNotificationDeliveries(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the notification delivery log")
+	}
+	result := make(apimodels.GettableNotificationDeliveries, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, apimodels.GettableNotificationDelivery{
+			Receiver:          d.Receiver,
+			Integration:       d.Integration,
+			IntegrationUID:    d.IntegrationUID,
+			IntegrationIndex:  d.IntegrationIndex,
+			GroupKey:          d.GroupKey,
+			AlertFingerprints: d.Fingerprints(),
+			PayloadHash:       d.PayloadHash,
+			StatusCode:        d.StatusCode,
+			DurationMs:        d.Duration,
+			Error:             d.Error,
+			Retry:             d.Retry,
+			Success:           d.Success,
+			AttemptedAt:       d.AttemptedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// recordSilenceAudit records the change of the silence made by the signed-in user. The silence is already changed at
+// this point, so a failure is only logged.
+func (srv AlertmanagerSrv) recordSilenceAudit(c *contextmodel.ReqContext, silenceID string, action ngmodels.SilenceAuditAction) {
+	if srv.silenceAudit == nil {
+		return
+	}
+	err := srv.silenceAudit.InsertSilenceAuditEntry(c.Req.Context(), ngmodels.SilenceAuditEntry{
+		OrgID:     c.SignedInUser.GetOrgID(),
+		SilenceID: silenceID,
+		Action:    action,
+		User:      c.SignedInUser.GetLogin(),
+		Created:   time.Now(),
+	})
+	if err != nil {
+		srv.log.Error("Failed to record the change of the silence", "silenceID", silenceID, "action", action, "error", err)
+	}
+}
+
 func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *contextmodel.ReqContext) response.Response {
 	config, err := srv.mam.GetAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID())
 	if err != nil {
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// RoutePostRoutingPreview returns how the Grafana Alertmanager would handle an alert with the given labels: the
+// notification policies it matches, the silences, mute timings and inhibition rules that apply to it, and the
+// receivers that would be notified. It uses the same matchers as the Alertmanager and the configuration that is
+// currently applied, including the policies generated for the notification settings of alert rules.
+func (srv AlertmanagerSrv) RoutePostRoutingPreview(c *contextmodel.ReqContext, body apimodels.RoutingPreviewRequest) response.Response {
+	if body.RuleUID == "" && len(body.Labels) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("labels or rule UID must be specified"), "")
+	}
+
+	lbls := make(model.LabelSet, len(body.Labels))
+	for k, v := range body.Labels {
+		lbls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	if body.RuleUID != "" {
+		ruleLabels, err := srv.getRuleLabels(c, body.RuleUID)
+		if err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return ErrResp(http.StatusNotFound, err, "")
+			}
+			return errorToResponse(err)
+		}
+		for k, v := range ruleLabels {
+			lbls[model.LabelName(k)] = model.LabelValue(v)
+		}
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
+	if errResp != nil {
+		return errResp
+	}
+
+	cfg := am.GetStatus().Config
+	if cfg == nil || cfg.Route == nil {
+		return ErrResp(http.StatusConflict, errors.New("the Alertmanager has no configuration"), "")
+	}
+
+	silences, err := am.ListSilences(c.Req.Context(), nil)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to list silences")
+	}
+	alerts, err := am.GetAlerts(c.Req.Context(), true, true, true, nil, "")
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alerts")
+	}
+
+	preview, err := newRoutingPreview(cfg, lbls, silences, alerts, timeNow())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to compute the routing preview")
+	}
+	return response.JSON(http.StatusOK, preview)
+}
+
+// getRuleLabels returns the labels of the alert rule and the labels that are added to all alerts of the rule. The
+// user must be authorized to access the rule group.
+func (srv AlertmanagerSrv) getRuleLabels(c *contextmodel.ReqContext, ruleUID string) (map[string]string, error) {
+	rules, err := srv.ruleStore.GetAlertRulesGroupByRuleUID(c.Req.Context(), &ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range rules {
+		if r.UID == ruleUID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return nil, ngmodels.ErrAlertRuleNotFound
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules); err != nil {
+		return nil, err
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel)
+	var folderTitle string
+	if includeFolder {
+		namespace, err := srv.ruleStore.GetNamespaceByUID(c.Req.Context(), rule.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return nil, errors.Join(errFolderAccess, err)
+		}
+		folderTitle = namespace.Title
+	}
+
+	// The labels of the rule are not expanded because the template data is known only when the rule is evaluated.
+	result := make(map[string]string, len(rule.Labels)+4)
+	for k, v := range rule.Labels {
+		result[k] = v
+	}
+	for k, v := range state.GetRuleExtraLabels(rule, folderTitle, includeFolder) {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// newRoutingPreview computes the routing preview of an alert with the labels lbls at the time now.
+func newRoutingPreview(cfg *apimodels.PostableApiAlertingConfig, lbls model.LabelSet, silences apimodels.GettableSilences, alerts apimodels.GettableAlerts, now time.Time) (apimodels.RoutingPreview, error) {
+	preview := apimodels.RoutingPreview{
+		Labels:      make(map[string]string, len(lbls)),
+		Routes:      []apimodels.RoutingPreviewRoute{},
+		Silences:    apimodels.GettableSilences{},
+		Inhibitions: []apimodels.RoutingPreviewInhibition{},
+		Receivers:   []string{},
+	}
+	for k, v := range lbls {
+		preview.Labels[string(k)] = string(v)
+	}
+
+	muteTimes := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		muteTimes[mt.Name] = mt.TimeIntervals
+	}
+
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	paths := map[*dispatch.Route][]int{root: {}}
+	root.Walk(func(r *dispatch.Route) {
+		for i, child := range r.Routes {
+			paths[child] = append(append(make([]int, 0, len(paths[r])+1), paths[r]...), i)
+		}
+	})
+
+	var notMuted []string
+	for _, r := range root.Match(lbls) {
+		route := apimodels.RoutingPreviewRoute{
+			Path:              paths[r],
+			Receiver:          r.RouteOpts.Receiver,
+			ObjectMatchers:    apimodels.ObjectMatchers(r.Matchers),
+			Continue:          r.Continue,
+			GroupWait:         model.Duration(r.RouteOpts.GroupWait),
+			GroupInterval:     model.Duration(r.RouteOpts.GroupInterval),
+			RepeatInterval:    model.Duration(r.RouteOpts.RepeatInterval),
+			MuteTimeIntervals: r.RouteOpts.MuteTimeIntervals,
+		}
+		if r.RouteOpts.GroupByAll {
+			route.GroupBy = []string{"..."}
+		} else {
+			for l := range r.RouteOpts.GroupBy {
+				route.GroupBy = append(route.GroupBy, string(l))
+			}
+			sort.Strings(route.GroupBy)
+		}
+		for _, name := range r.RouteOpts.MuteTimeIntervals {
+			intervals, ok := muteTimes[name]
+			if !ok {
+				return apimodels.RoutingPreview{}, fmt.Errorf("mute time interval %q does not exist", name)
+			}
+			for _, interval := range intervals {
+				if interval.ContainsTime(now.UTC()) {
+					route.MutedBy = append(route.MutedBy, name)
+					break
+				}
+			}
+		}
+		if len(route.MutedBy) == 0 {
+			notMuted = append(notMuted, route.Receiver)
+		}
+		preview.Routes = append(preview.Routes, route)
+	}
+
+	for _, s := range silences {
+		if s.Status == nil || s.Status.State == nil || *s.Status.State != amv2.SilenceStatusStateActive {
+			continue
+		}
+		matchers, err := silenceMatchers(s.Matchers)
+		if err != nil {
+			return apimodels.RoutingPreview{}, err
+		}
+		if matchers.Matches(lbls) {
+			preview.Silences = append(preview.Silences, s)
+		}
+	}
+	preview.Silenced = len(preview.Silences) > 0
+
+	for i, ir := range cfg.InhibitRules {
+		rule := inhibit.NewInhibitRule(ir)
+		if !rule.TargetMatchers.Matches(lbls) {
+			continue
+		}
+		inhibition := apimodels.RoutingPreviewInhibition{
+			Index:          i,
+			SourceMatchers: matchersToStrings(rule.SourceMatchers),
+			TargetMatchers: matchersToStrings(rule.TargetMatchers),
+		}
+		for l := range rule.Equal {
+			inhibition.Equal = append(inhibition.Equal, string(l))
+		}
+		sort.Strings(inhibition.Equal)
+		inhibition.SourceAlerts = inhibitingAlerts(rule, lbls, alerts, now)
+		inhibition.Active = len(inhibition.SourceAlerts) > 0
+		preview.Inhibited = preview.Inhibited || inhibition.Active
+		preview.Inhibitions = append(preview.Inhibitions, inhibition)
+	}
+
+	if !preview.Silenced && !preview.Inhibited {
+		seen := make(map[string]struct{}, len(notMuted))
+		for _, receiver := range notMuted {
+			if _, ok := seen[receiver]; ok {
+				continue
+			}
+			seen[receiver] = struct{}{}
+			preview.Receivers = append(preview.Receivers, receiver)
+		}
+	}
+	return preview, nil
+}
+
+// inhibitingAlerts returns the fingerprints of the alerts that are a source of the inhibition rule for an alert
+// with the labels lbls. It follows the same logic as the inhibitor of the Alertmanager: resolved alerts are ignored,
+// and if the alert matches the source matchers too then alerts that match both sides of the rule are ignored.
+func inhibitingAlerts(rule *inhibit.InhibitRule, lbls model.LabelSet, alerts apimodels.GettableAlerts, now time.Time) []string {
+	excludeTwoSidedMatch := rule.SourceMatchers.Matches(lbls)
+	fp := lbls.Fingerprint().String()
+
+	var result []string
+Outer:
+	for _, a := range alerts {
+		if a.Fingerprint == nil || *a.Fingerprint == fp {
+			continue
+		}
+		if a.EndsAt != nil && !time.Time(*a.EndsAt).IsZero() && time.Time(*a.EndsAt).Before(now) {
+			continue
+		}
+		source := make(model.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			source[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if !rule.SourceMatchers.Matches(source) {
+			continue
+		}
+		for l := range rule.Equal {
+			if source[l] != lbls[l] {
+				continue Outer
+			}
+		}
+		if excludeTwoSidedMatch && rule.TargetMatchers.Matches(source) {
+			continue
+		}
+		result = append(result, *a.Fingerprint)
+	}
+	return result
+}
+
+func silenceMatchers(matchers amv2.Matchers) (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Name == nil || m.Value == nil || m.IsRegex == nil {
+			return nil, errors.New("silence has an invalid matcher")
+		}
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		var t labels.MatchType
+		switch {
+		case !*m.IsRegex && isEqual:
+			t = labels.MatchEqual
+		case !*m.IsRegex && !isEqual:
+			t = labels.MatchNotEqual
+		case *m.IsRegex && isEqual:
+			t = labels.MatchRegexp
+		default:
+			t = labels.MatchNotRegexp
+		}
+		matcher, err := labels.NewMatcher(t, *m.Name, *m.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, matcher)
+	}
+	return result, nil
+}
+
+func matchersToStrings(matchers labels.Matchers) []string {
+	result := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		result = append(result, m.String())
+	}
+	return result
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const routingPreviewConfig = `{
+	"route": {
+		"receiver": "default",
+		"group_by": ["alertname"],
+		"routes": [{
+			"receiver": "team-a",
+			"object_matchers": [["team", "=", "a"]],
+			"continue": true,
+			"group_wait": "1m"
+		}, {
+			"receiver": "team-a-critical",
+			"object_matchers": [["team", "=", "a"]],
+			"routes": [{
+				"receiver": "pager",
+				"object_matchers": [["severity", "=", "critical"]],
+				"group_by": ["..."],
+				"mute_time_intervals": ["always"]
+			}]
+		}, {
+			"receiver": "team-b",
+			"object_matchers": [["team", "=", "b"]]
+		}]
+	},
+	"inhibit_rules": [{
+		"source_matchers": ["severity=critical"],
+		"target_matchers": ["severity=warning"],
+		"equal": ["cluster"]
+	}, {
+		"source_matchers": ["alertname=Maintenance"],
+		"target_matchers": ["team=b"]
+	}],
+	"mute_time_intervals": [{
+		"name": "always",
+		"time_intervals": [{"times": [{"start_time": "00:00", "end_time": "24:00"}]}]
+	}],
+	"receivers": [
+		{"name": "default"},
+		{"name": "team-a"},
+		{"name": "team-a-critical"},
+		{"name": "pager"},
+		{"name": "team-b"}
+	]
+}`
+
+func TestNewRoutingPreview(t *testing.T) {
+	var cfg apimodels.PostableApiAlertingConfig
+	require.NoError(t, json.Unmarshal([]byte(routingPreviewConfig), &cfg))
+	now := time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	silence := func(id string, state string, matchers ...*amv2.Matcher) *amv2.GettableSilence {
+		return &amv2.GettableSilence{
+			ID:        util.Pointer(id),
+			Status:    &amv2.SilenceStatus{State: util.Pointer(state)},
+			Silence:   amv2.Silence{Matchers: matchers},
+			UpdatedAt: util.Pointer(strfmt.DateTime(now)),
+		}
+	}
+	matcher := func(name, value string, isRegex, isEqual bool) *amv2.Matcher {
+		return &amv2.Matcher{Name: util.Pointer(name), Value: util.Pointer(value), IsRegex: util.Pointer(isRegex), IsEqual: util.Pointer(isEqual)}
+	}
+	alert := func(labels model.LabelSet, endsAt time.Time) *amv2.GettableAlert {
+		lbls := make(amv2.LabelSet, len(labels))
+		for k, v := range labels {
+			lbls[string(k)] = string(v)
+		}
+		return &amv2.GettableAlert{
+			Fingerprint: util.Pointer(labels.Fingerprint().String()),
+			EndsAt:      util.Pointer(strfmt.DateTime(endsAt)),
+			Alert:       amv2.Alert{Labels: lbls},
+		}
+	}
+
+	t.Run("should use the default policy if no policy matches", func(t *testing.T) {
+		preview, err := newRoutingPreview(&cfg, model.LabelSet{"alertname": "test"}, nil, nil, now)
+		require.NoError(t, err)
+		require.Len(t, preview.Routes, 1)
+		require.Equal(t, []int{}, preview.Routes[0].Path)
+		require.Equal(t, "default", preview.Routes[0].Receiver)
+		require.Equal(t, []string{"alertname"}, preview.Routes[0].GroupBy)
+		require.Equal(t, []string{"default"}, preview.Receivers)
+		require.Equal(t, map[string]string{"alertname": "test"}, preview.Labels)
+		require.Empty(t, preview.Inhibitions)
+		require.Empty(t, preview.Silences)
+	})
+
+	t.Run("should follow continue and nested policies, and report mute timings", func(t *testing.T) {
+		preview, err := newRoutingPreview(&cfg, model.LabelSet{"alertname": "test", "team": "a", "severity": "critical"}, nil, nil, now)
+		require.NoError(t, err)
+		require.Len(t, preview.Routes, 2)
+
+		require.Equal(t, []int{0}, preview.Routes[0].Path)
+		require.Equal(t, "team-a", preview.Routes[0].Receiver)
+		require.True(t, preview.Routes[0].Continue)
+		require.Equal(t, model.Duration(time.Minute), preview.Routes[0].GroupWait)
+		require.Empty(t, preview.Routes[0].MutedBy)
+
+		require.Equal(t, []int{1, 0}, preview.Routes[1].Path)
+		require.Equal(t, "pager", preview.Routes[1].Receiver)
+		require.Equal(t, []string{"..."}, preview.Routes[1].GroupBy)
+		require.Equal(t, []string{"always"}, preview.Routes[1].MuteTimeIntervals)
+		require.Equal(t, []string{"always"}, preview.Routes[1].MutedBy)
+
+		require.Equal(t, []string{"team-a"}, preview.Receivers)
+	})
+
+	t.Run("should report active silences that match the alert", func(t *testing.T) {
+		silences := apimodels.GettableSilences{
+			silence("matching", amv2.SilenceStatusStateActive, matcher("team", "b", false, true)),
+			silence("regex", amv2.SilenceStatusStateActive, matcher("alertname", "te.*", true, true)),
+			silence("expired", amv2.SilenceStatusStateExpired, matcher("team", "b", false, true)),
+			silence("not-matching", amv2.SilenceStatusStateActive, matcher("team", "b", false, false)),
+		}
+		preview, err := newRoutingPreview(&cfg, model.LabelSet{"alertname": "test", "team": "b"}, silences, nil, now)
+		require.NoError(t, err)
+		require.Len(t, preview.Silences, 2)
+		require.Equal(t, "matching", *preview.Silences[0].ID)
+		require.Equal(t, "regex", *preview.Silences[1].ID)
+		require.True(t, preview.Silenced)
+		require.Empty(t, preview.Receivers)
+		require.Len(t, preview.Routes, 1)
+		require.Equal(t, "team-b", preview.Routes[0].Receiver)
+	})
+
+	t.Run("should report inhibition rules that target the alert", func(t *testing.T) {
+		target := model.LabelSet{"alertname": "test", "severity": "warning", "cluster": "eu"}
+		alerts := apimodels.GettableAlerts{
+			alert(model.LabelSet{"alertname": "other", "severity": "critical", "cluster": "us"}, now.Add(time.Hour)),
+			alert(model.LabelSet{"alertname": "resolved", "severity": "critical", "cluster": "eu"}, now.Add(-time.Hour)),
+			alert(model.LabelSet{"alertname": "source", "severity": "critical", "cluster": "eu"}, now.Add(time.Hour)),
+		}
+		preview, err := newRoutingPreview(&cfg, target, nil, alerts, now)
+		require.NoError(t, err)
+		require.Len(t, preview.Inhibitions, 1)
+		inhibition := preview.Inhibitions[0]
+		require.Equal(t, 0, inhibition.Index)
+		require.Equal(t, []string{`severity="critical"`}, inhibition.SourceMatchers)
+		require.Equal(t, []string{`severity="warning"`}, inhibition.TargetMatchers)
+		require.Equal(t, []string{"cluster"}, inhibition.Equal)
+		require.Equal(t, []string{*alerts[2].Fingerprint}, inhibition.SourceAlerts)
+		require.True(t, inhibition.Active)
+		require.True(t, preview.Inhibited)
+		require.Empty(t, preview.Receivers)
+	})
+
+	t.Run("should report inactive inhibition rules", func(t *testing.T) {
+		preview, err := newRoutingPreview(&cfg, model.LabelSet{"alertname": "test", "team": "b"}, nil, nil, now)
+		require.NoError(t, err)
+		require.Len(t, preview.Inhibitions, 1)
+		require.Equal(t, 1, preview.Inhibitions[0].Index)
+		require.False(t, preview.Inhibitions[0].Active)
+		require.False(t, preview.Inhibited)
+		require.Equal(t, []string{"team-b"}, preview.Receivers)
+	})
+
+	t.Run("should ignore source alerts that also match the target if the alert matches both sides", func(t *testing.T) {
+		var cfg apimodels.PostableApiAlertingConfig
+		require.NoError(t, json.Unmarshal([]byte(routingPreviewConfig), &cfg))
+		cfg.InhibitRules = cfg.InhibitRules[1:]
+		alerts := apimodels.GettableAlerts{
+			alert(model.LabelSet{"alertname": "Maintenance", "team": "b", "instance": "2"}, now.Add(time.Hour)),
+		}
+		preview, err := newRoutingPreview(&cfg, model.LabelSet{"alertname": "Maintenance", "team": "b", "instance": "1"}, nil, alerts, now)
+		require.NoError(t, err)
+		require.Len(t, preview.Inhibitions, 1)
+		require.False(t, preview.Inhibitions[0].Active)
+		require.Equal(t, []string{"team-b"}, preview.Receivers)
+	})
+}
+
+func TestRoutePostRoutingPreview(t *testing.T) {
+	createSutWithRules := func(t *testing.T) (AlertmanagerSrv, *fakes.RuleStore) {
+		t.Helper()
+		sut := createSut(t)
+		ruleStore := fakes.NewRuleStore(t)
+		sut.ruleStore = ruleStore
+		sut.authz = fakeRuleAccessControlService{}
+		sut.cfg = &setting.UnifiedAlertingSettings{}
+		sut.log = log.NewNopLogger()
+		return sut, ruleStore
+	}
+
+	t.Run("should return 400 if neither labels nor rule are specified", func(t *testing.T) {
+		sut, _ := createSutWithRules(t)
+		resp := sut.RoutePostRoutingPreview(createRequestCtxInOrg(1), apimodels.RoutingPreviewRequest{})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should preview the routing of labels", func(t *testing.T) {
+		sut, _ := createSutWithRules(t)
+		resp := sut.RoutePostRoutingPreview(createRequestCtxInOrg(1), apimodels.RoutingPreviewRequest{Labels: map[string]string{"alertname": "test"}})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var preview apimodels.RoutingPreview
+		require.NoError(t, json.Unmarshal(resp.Body(), &preview))
+		require.Equal(t, []string{"grafana-default-email"}, preview.Receivers)
+		require.Len(t, preview.Routes, 1)
+	})
+
+	t.Run("should return 404 if the rule does not exist", func(t *testing.T) {
+		sut, _ := createSutWithRules(t)
+		resp := sut.RoutePostRoutingPreview(createRequestCtxInOrg(1), apimodels.RoutingPreviewRequest{RuleUID: "unknown"})
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should add the labels of the rule", func(t *testing.T) {
+		sut, ruleStore := createSutWithRules(t)
+		rule := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1), ngmodels.WithLabels(map[string]string{"team": "a"}))()
+		rule.NotificationSettings = nil
+		ruleStore.PutRule(createRequestCtxInOrg(1).Req.Context(), rule)
+
+		resp := sut.RoutePostRoutingPreview(createRequestCtxInOrg(1), apimodels.RoutingPreviewRequest{
+			RuleUID: rule.UID,
+			Labels:  map[string]string{"instance": "1", "team": "b"},
+		})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var preview apimodels.RoutingPreview
+		require.NoError(t, json.Unmarshal(resp.Body(), &preview))
+		require.Equal(t, "1", preview.Labels["instance"])
+		require.Equal(t, "a", preview.Labels["team"])
+		require.Equal(t, rule.Title, preview.Labels[model.AlertNameLabel])
+		require.Equal(t, rule.UID, preview.Labels["__alert_rule_uid__"])
+		require.Equal(t, ruleStore.Folders[1][0].Title, preview.Labels[ngmodels.FolderTitleLabel])
+	})
+
+	t.Run("should not add the folder label if it is disabled", func(t *testing.T) {
+		sut, ruleStore := createSutWithRules(t)
+		sut.cfg.ReservedLabels.DisabledLabels = map[string]struct{}{ngmodels.FolderTitleLabel: {}}
+		rule := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		ruleStore.PutRule(createRequestCtxInOrg(1).Req.Context(), rule)
+
+		resp := sut.RoutePostRoutingPreview(createRequestCtxInOrg(1), apimodels.RoutingPreviewRequest{RuleUID: rule.UID})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var preview apimodels.RoutingPreview
+		require.NoError(t, json.Unmarshal(resp.Body(), &preview))
+		require.NotContains(t, preview.Labels, ngmodels.FolderTitleLabel)
+	})
+}
@@ -20,15 +20,17 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/util"
 )
 
 type PrometheusSrv struct {
-	log     log.Logger
-	manager state.AlertInstanceManager
-	store   RuleStore
-	authz   RuleAccessControlService
+	log      log.Logger
+	manager  state.AlertInstanceManager
+	store    RuleStore
+	authz    RuleAccessControlService
+	profiles RuleEvaluationProfiles
 }
 
 const queryIncludeInternalLabels = "includeInternalLabels"
@@ -311,6 +313,16 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			recordingRule := srv.toRecordingRule(rule, labelOptions)
+			if recordingRule.Health == state.RecordingRuleHealthError {
+				rulesTotals[recordingRule.Health] += 1
+			}
+			newGroup.Rules = append(newGroup.Rules, recordingRule)
+			newGroup.Interval = float64(rule.IntervalSeconds)
+			continue
+		}
+
 		alertingRule := apimodels.AlertingRule{
 			State:       "inactive",
 			Name:        rule.Title,
@@ -427,6 +439,32 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 	return newGroup, rulesTotals
 }
 
+// toRecordingRule converts a recording rule to the Prometheus API model. Recording rules have no alerts,
+// their health reflects whether the last result could be written.
+func (srv PrometheusSrv) toRecordingRule(rule *ngmodels.AlertRule, labelOptions []ngmodels.LabelOption) apimodels.AlertingRule {
+	newRule := apimodels.Rule{
+		Name:   rule.Title,
+		Query:  ruleToQuery(srv.log, rule),
+		Labels: rule.GetLabels(labelOptions...),
+		Health: "unknown",
+		Type:   apiv1.RuleTypeRecording,
+	}
+	if status, ok := srv.manager.GetRecordingRuleStatus(rule.OrgID, rule.UID); ok {
+		newRule.Health = status.Health
+		newRule.LastEvaluation = status.LastEvaluationTime
+		newRule.EvaluationTime = status.EvaluationDuration.Seconds()
+		if status.LastError != nil {
+			newRule.LastError = status.LastError.Error()
+		}
+	}
+	return apimodels.AlertingRule{
+		Name:        rule.Title,
+		Query:       newRule.Query,
+		Annotations: rule.Annotations,
+		Rule:        newRule,
+	}
+}
+
 // ruleToQuery attempts to extract the datasource queries from the alert query model.
 // Returns the whole JSON model as a string if it fails to extract a minimum of 1 query.
 func ruleToQuery(logger log.Logger, rule *ngmodels.AlertRule) string {
@@ -468,3 +506,130 @@ func encodedQueriesOrError(rules []ngmodels.AlertQuery) string {
 
 	return err.Error()
 }
+
+// ruleEvaluationProfileOrders are the orders of RouteGetRuleEvaluationProfiles. They sort the most expensive rules first.
+var ruleEvaluationProfileOrders = map[string]func(a, b apimodels.RuleEvaluationProfile) bool{
+	"load": func(a, b apimodels.RuleEvaluationProfile) bool {
+		return a.Load > b.Load
+	},
+	"duration": func(a, b apimodels.RuleEvaluationProfile) bool {
+		return a.AverageDuration > b.AverageDuration
+	},
+	"query": func(a, b apimodels.RuleEvaluationProfile) bool {
+		return a.AverageQueryDuration > b.AverageQueryDuration
+	},
+	"series": func(a, b apimodels.RuleEvaluationProfile) bool {
+		return a.LastSeries > b.LastSeries
+	},
+}
+
+// RouteGetRuleEvaluationProfiles returns the evaluation statistics of the rules the user has access to, the most
+// expensive rules first. Only the rules evaluated by this instance have statistics.
+func (srv PrometheusSrv) RouteGetRuleEvaluationProfiles(c *contextmodel.ReqContext) response.Response {
+	if srv.profiles == nil {
+		return ErrResp(http.StatusNotFound, errors.New("evaluation profiling of alert rules is disabled"), "")
+	}
+	sortBy := c.Query("sort")
+	if sortBy == "" {
+		sortBy = "load"
+	}
+	less, ok := ruleEvaluationProfileOrders[sortBy]
+	if !ok {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid sort %q, must be one of load, duration, query or series", sortBy), "")
+	}
+	limit := c.QueryInt64WithDefault("limit", 100)
+	if limit <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit must be greater than zero"), "")
+	}
+
+	result := apimodels.RuleEvaluationProfilesResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{
+			Status: "success",
+		},
+		Data: []apimodels.RuleEvaluationProfile{},
+	}
+
+	namespaceMap, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	if len(namespaceMap) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	namespaceUIDs := make([]string, 0, len(namespaceMap))
+	for k := range namespaceMap {
+		namespaceUIDs = append(namespaceUIDs, k)
+	}
+	ruleList, err := srv.store.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		NamespaceUIDs: namespaceUIDs,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	groupedRules := make(map[ngmodels.AlertRuleGroupKey][]*ngmodels.AlertRule)
+	for _, rule := range ruleList {
+		groupKey := rule.GetGroupKey()
+		groupedRules[groupKey] = append(groupedRules[groupKey], rule)
+	}
+	for _, rules := range groupedRules {
+		ok, err := srv.authz.HasAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "cannot authorize access to rule group", err)
+		}
+		if !ok {
+			continue
+		}
+		for _, rule := range rules {
+			profile, ok := srv.profiles.Get(rule.GetKey())
+			if !ok {
+				continue
+			}
+			result.Data = append(result.Data, toRuleEvaluationProfile(rule, profile))
+		}
+	}
+
+	sort.SliceStable(result.Data, func(i, j int) bool {
+		return less(result.Data[i], result.Data[j])
+	})
+	if int64(len(result.Data)) > limit {
+		result.Data = result.Data[:limit]
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toRuleEvaluationProfile(rule *ngmodels.AlertRule, profile schedule.RuleEvaluationProfile) apimodels.RuleEvaluationProfile {
+	nodes := make([]apimodels.RuleNodeEvaluationProfile, 0, len(profile.Nodes))
+	for _, n := range profile.Nodes {
+		nodes = append(nodes, apimodels.RuleNodeEvaluationProfile{
+			RefID:           n.RefID,
+			IsQuery:         n.IsQuery,
+			Executions:      n.Executions,
+			LastDuration:    n.LastDuration.Seconds(),
+			AverageDuration: n.AverageDuration().Seconds(),
+			MaxDuration:     n.MaxDuration.Seconds(),
+			LastSeries:      n.LastSeries,
+		})
+	}
+	return apimodels.RuleEvaluationProfile{
+		UID:                       rule.UID,
+		Title:                     rule.Title,
+		FolderUID:                 rule.NamespaceUID,
+		RuleGroup:                 rule.RuleGroup,
+		Version:                   profile.Version,
+		IntervalSeconds:           profile.IntervalSeconds,
+		Evaluations:               profile.Evaluations,
+		Failures:                  profile.Failures,
+		Load:                      profile.Load(),
+		LastEvaluation:            profile.LastEvaluation,
+		LastDuration:              profile.LastDuration.Seconds(),
+		AverageDuration:           profile.AverageDuration().Seconds(),
+		MaxDuration:               profile.MaxDuration.Seconds(),
+		AverageQueryDuration:      profile.AverageQueryDuration().Seconds(),
+		AverageExpressionDuration: profile.AverageExpressionDuration().Seconds(),
+		LastSeries:                profile.LastSeries,
+		LastResults:               profile.LastResults,
+		Nodes:                     nodes,
+	}
+}
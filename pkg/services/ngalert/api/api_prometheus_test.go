@@ -10,12 +10,14 @@ import (
 	"testing"
 	"time"
 
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	alertingModels "github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
@@ -26,6 +28,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -1252,6 +1255,177 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 	})
 }
 
+type fakeRuleEvaluationProfiles map[ngmodels.AlertRuleKey]schedule.RuleEvaluationProfile
+
+func (f fakeRuleEvaluationProfiles) Get(key ngmodels.AlertRuleKey) (schedule.RuleEvaluationProfile, bool) {
+	p, ok := f[key]
+	return p, ok
+}
+
+func TestRouteGetRuleEvaluationProfiles(t *testing.T) {
+	orgID := int64(1)
+	ruleStore := fakes.NewRuleStore(t)
+	rules := ngmodels.GenerateAlertRules(3, ngmodels.AlertRuleGen(withOrgID(orgID), ngmodels.WithInterval(10*time.Second)))
+	ruleStore.PutRule(context.Background(), rules...)
+
+	profiles := fakeRuleEvaluationProfiles{
+		rules[0].GetKey(): {
+			RuleKey:            rules[0].GetKey(),
+			IntervalSeconds:    10,
+			Evaluations:        2,
+			TotalDuration:      2 * time.Second,
+			TotalQueryDuration: 1500 * time.Millisecond,
+			LastSeries:         100,
+			Nodes: []schedule.NodeEvaluationProfile{
+				{RefID: "A", IsQuery: true, Executions: 2, TotalDuration: 1500 * time.Millisecond, LastSeries: 100},
+			},
+		},
+		rules[1].GetKey(): {
+			RuleKey:            rules[1].GetKey(),
+			IntervalSeconds:    10,
+			Evaluations:        1,
+			TotalDuration:      5 * time.Second,
+			TotalQueryDuration: 5 * time.Second,
+			LastSeries:         10,
+		},
+	}
+	api := PrometheusSrv{
+		log:      log.NewNopLogger(),
+		manager:  NewFakeAlertInstanceManager(t),
+		store:    ruleStore,
+		authz:    &fakeRuleAccessControlService{},
+		profiles: profiles,
+	}
+
+	request := func(query string) response.Response {
+		req, err := http.NewRequest("GET", "/api/prometheus/grafana/api/v1/rules/profiles?"+query, nil)
+		require.NoError(t, err)
+		c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID}}
+		return api.RouteGetRuleEvaluationProfiles(c)
+	}
+	uids := func(r response.Response) []string {
+		t.Helper()
+		require.Equal(t, http.StatusOK, r.Status())
+		result := apimodels.RuleEvaluationProfilesResponse{}
+		require.NoError(t, json.Unmarshal(r.Body(), &result))
+		var uids []string
+		for _, p := range result.Data {
+			uids = append(uids, p.UID)
+		}
+		return uids
+	}
+
+	t.Run("should return the rules with statistics, the most expensive first", func(t *testing.T) {
+		resp := request("")
+		require.Equal(t, []string{rules[1].UID, rules[0].UID}, uids(resp))
+
+		result := apimodels.RuleEvaluationProfilesResponse{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		p := result.Data[1]
+		require.Equal(t, rules[0].Title, p.Title)
+		require.Equal(t, rules[0].NamespaceUID, p.FolderUID)
+		require.Equal(t, rules[0].RuleGroup, p.RuleGroup)
+		require.Equal(t, 1.0, p.AverageDuration)
+		require.Equal(t, 0.75, p.AverageQueryDuration)
+		require.Equal(t, 0.1, p.Load)
+		require.Equal(t, []apimodels.RuleNodeEvaluationProfile{{RefID: "A", IsQuery: true, Executions: 2, AverageDuration: 0.75, LastSeries: 100}}, p.Nodes)
+	})
+
+	t.Run("should sort and limit the rules", func(t *testing.T) {
+		require.Equal(t, []string{rules[0].UID, rules[1].UID}, uids(request("sort=series")))
+		require.Equal(t, []string{rules[1].UID}, uids(request("sort=query&limit=1")))
+	})
+
+	t.Run("should reject invalid parameters", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, request("sort=cost").Status())
+		require.Equal(t, http.StatusBadRequest, request("limit=0").Status())
+	})
+
+	t.Run("should return 404 if profiling is disabled", func(t *testing.T) {
+		api.profiles = nil
+		require.Equal(t, http.StatusNotFound, request("").Status())
+	})
+}
+
+func TestRouteGetRuleStatusesRecordingRules(t *testing.T) {
+	orgID := int64(1)
+	groupKey := ngmodels.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: "namespace", RuleGroup: "group"}
+	recordingRule := func(metric string) ngmodels.AlertRuleMutator {
+		return func(rule *ngmodels.AlertRule) {
+			rule.Record = &ngmodels.Record{Metric: metric, From: "A"}
+			rule.Labels = map[string]string{"team": "a"}
+		}
+	}
+	ruleStore := fakes.NewRuleStore(t)
+	failing := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey), ngmodels.WithInterval(30*time.Second), recordingRule("failing"))()
+	failing.RuleGroupIndex = 1
+	healthy := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey), ngmodels.WithInterval(30*time.Second), recordingRule("healthy"))()
+	healthy.RuleGroupIndex = 2
+	notEvaluated := ngmodels.AlertRuleGen(ngmodels.WithGroupKey(groupKey), ngmodels.WithInterval(30*time.Second), recordingRule("not_evaluated"))()
+	notEvaluated.RuleGroupIndex = 3
+	ruleStore.PutRule(context.Background(), failing, healthy, notEvaluated)
+
+	evaluatedAt := time.Date(2024, 3, 10, 14, 0, 0, 0, time.UTC)
+	manager := NewFakeAlertInstanceManager(t)
+	manager.SetRecordingRuleStatus(orgID, failing.UID, state.RecordingRuleStatus{
+		Health:              state.RecordingRuleHealthError,
+		LastError:           errors.New("remote write failed"),
+		LastEvaluationTime:  evaluatedAt,
+		EvaluationDuration:  2 * time.Second,
+		ConsecutiveFailures: 3,
+	})
+	manager.SetRecordingRuleStatus(orgID, healthy.UID, state.RecordingRuleStatus{
+		Health:             state.RecordingRuleHealthOK,
+		LastEvaluationTime: evaluatedAt,
+		EvaluationDuration: time.Second,
+		LastWriteTime:      evaluatedAt,
+	})
+	api := PrometheusSrv{
+		log:     log.NewNopLogger(),
+		manager: manager,
+		store:   ruleStore,
+		authz:   &fakeRuleAccessControlService{},
+	}
+
+	req, err := http.NewRequest("GET", "/api/v1/rules", nil)
+	require.NoError(t, err)
+	c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{OrgID: orgID}}
+	r := api.RouteGetRuleStatuses(c)
+	require.Equal(t, http.StatusOK, r.Status())
+
+	result := apimodels.RuleResponse{}
+	require.NoError(t, json.Unmarshal(r.Body(), &result))
+	require.Len(t, result.Data.RuleGroups, 1)
+	group := result.Data.RuleGroups[0]
+	require.Equal(t, float64(30), group.Interval)
+	require.Equal(t, map[string]int64{"error": 1}, group.Totals)
+	require.Equal(t, map[string]int64{"error": 1}, result.Data.Totals)
+	require.Len(t, group.Rules, 3)
+
+	for i, rule := range group.Rules {
+		require.Equal(t, apiv1.RuleTypeRecording, rule.Type)
+		require.Empty(t, rule.State)
+		require.Empty(t, rule.Alerts)
+		require.Nil(t, rule.Totals)
+		require.Equal(t, map[string]string{"team": "a"}, map[string]string(rule.Labels), i)
+	}
+
+	require.Equal(t, failing.Title, group.Rules[0].Name)
+	require.Equal(t, state.RecordingRuleHealthError, group.Rules[0].Health)
+	require.Equal(t, "remote write failed", group.Rules[0].LastError)
+	require.Equal(t, evaluatedAt, group.Rules[0].LastEvaluation)
+	require.Equal(t, 2.0, group.Rules[0].EvaluationTime)
+
+	require.Equal(t, healthy.Title, group.Rules[1].Name)
+	require.Equal(t, state.RecordingRuleHealthOK, group.Rules[1].Health)
+	require.Empty(t, group.Rules[1].LastError)
+	require.Equal(t, evaluatedAt, group.Rules[1].LastEvaluation)
+
+	require.Equal(t, notEvaluated.Title, group.Rules[2].Name)
+	require.Equal(t, "unknown", group.Rules[2].Health)
+	require.True(t, group.Rules[2].LastEvaluation.IsZero())
+}
+
 func setupAPI(t *testing.T) (*fakes.RuleStore, *fakeAlertInstanceManager, PrometheusSrv) {
 	fakeStore := fakes.NewRuleStore(t)
 	fakeAIM := NewFakeAlertInstanceManager(t)
@@ -28,6 +28,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	scheduledSilences   ScheduledSilenceService
 }
 
 type ContactPointService interface {
@@ -56,6 +57,14 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64) error
 }
 
+type ScheduledSilenceService interface {
+	GetScheduledSilences(ctx context.Context, orgID int64) ([]alerting_models.ScheduledSilence, map[string]alerting_models.Provenance, error)
+	GetScheduledSilence(ctx context.Context, orgID int64, uid string) (alerting_models.ScheduledSilence, alerting_models.Provenance, error)
+	CreateScheduledSilence(ctx context.Context, s alerting_models.ScheduledSilence, provenance alerting_models.Provenance) (alerting_models.ScheduledSilence, error)
+	UpdateScheduledSilence(ctx context.Context, s alerting_models.ScheduledSilence, provenance alerting_models.Provenance, user string) (alerting_models.ScheduledSilence, error)
+	DeleteScheduledSilence(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance, user string) error
+}
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, orgID int64) ([]*alerting_models.AlertRule, map[string]alerting_models.Provenance, error)
 	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -299,6 +308,82 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, na
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetScheduledSilences(c *contextmodel.ReqContext) response.Response {
+	silences, provenances, err := srv.scheduledSilences.GetScheduledSilences(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make(definitions.ScheduledSilences, 0, len(silences))
+	for _, s := range silences {
+		result = append(result, ApiScheduledSilenceFromScheduledSilence(s, provenances[s.UID]))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetScheduledSilence(c *contextmodel.ReqContext, UID string) response.Response {
+	s, provenance, err := srv.scheduledSilences.GetScheduledSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), UID)
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrScheduledSilenceNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiScheduledSilenceFromScheduledSilence(s, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePostScheduledSilence(c *contextmodel.ReqContext, ss definitions.ScheduledSilence) response.Response {
+	s, err := ScheduledSilenceFromApiScheduledSilence(ss)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	s.OrgID = c.SignedInUser.GetOrgID()
+	if s.CreatedBy == "" {
+		s.CreatedBy = c.SignedInUser.GetLogin()
+	}
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.scheduledSilences.CreateScheduledSilence(c.Req.Context(), s, provenance)
+	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, ApiScheduledSilenceFromScheduledSilence(created, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePutScheduledSilence(c *contextmodel.ReqContext, ss definitions.ScheduledSilence, UID string) response.Response {
+	s, err := ScheduledSilenceFromApiScheduledSilence(ss)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	s.OrgID = c.SignedInUser.GetOrgID()
+	s.UID = UID
+	if s.CreatedBy == "" {
+		s.CreatedBy = c.SignedInUser.GetLogin()
+	}
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	updated, err := srv.scheduledSilences.UpdateScheduledSilence(c.Req.Context(), s, provenance, c.SignedInUser.GetLogin())
+	if err != nil {
+		if errors.Is(err, alerting_models.ErrScheduledSilenceNotFound) {
+			return response.Empty(http.StatusNotFound)
+		}
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, ApiScheduledSilenceFromScheduledSilence(updated, provenance))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteScheduledSilence(c *contextmodel.ReqContext, UID string) response.Response {
+	provenance := alerting_models.Provenance(determineProvenance(c))
+	err := srv.scheduledSilences.DeleteScheduledSilence(c.Req.Context(), c.SignedInUser.GetOrgID(), UID, provenance, c.SignedInUser.GetLogin())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *contextmodel.ReqContext) response.Response {
 	rules, provenances, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.SignedInUser.GetOrgID())
 	if err != nil {
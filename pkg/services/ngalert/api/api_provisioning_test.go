@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	prometheus "github.com/prometheus/alertmanager/config"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/timeinterval"
@@ -236,6 +237,80 @@ func TestProvisioningApi(t *testing.T) {
 		})
 	})
 
+	t.Run("scheduled silences", func(t *testing.T) {
+		t.Run("POST creates scheduled silence on behalf of the user", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.SignedInUser.Login = "admin"
+
+			response := sut.RoutePostScheduledSilence(&rc, createScheduledSilence())
+
+			require.Equal(t, 201, response.Status())
+			var created definitions.ScheduledSilence
+			require.NoError(t, json.Unmarshal(response.Body(), &created))
+			require.NotEmpty(t, created.UID)
+			require.Equal(t, "admin", created.CreatedBy)
+			require.Equal(t, "weekly", created.Repeat.Frequency)
+
+			response = sut.RouteGetScheduledSilence(&rc, created.UID)
+			require.Equal(t, 200, response.Status())
+		})
+
+		t.Run("POST returns 400 if scheduled silence is invalid", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			silence := createScheduledSilence()
+			silence.Repeat.Frequency = "hourly"
+
+			response := sut.RoutePostScheduledSilence(&rc, silence)
+
+			require.Equal(t, 400, response.Status())
+		})
+
+		t.Run("POST returns 400 if matcher is incomplete", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			silence := createScheduledSilence()
+			silence.Matchers[0].Value = nil
+
+			response := sut.RoutePostScheduledSilence(&rc, silence)
+
+			require.Equal(t, 400, response.Status())
+		})
+
+		t.Run("PUT returns 404 if scheduled silence is missing", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RoutePutScheduledSilence(&rc, createScheduledSilence(), "unknown")
+
+			require.Equal(t, 404, response.Status())
+		})
+
+		t.Run("GET returns 404 if scheduled silence is missing", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+
+			response := sut.RouteGetScheduledSilence(&rc, "unknown")
+
+			require.Equal(t, 404, response.Status())
+		})
+
+		t.Run("DELETE returns 204", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			response := sut.RoutePostScheduledSilence(&rc, createScheduledSilence())
+			var created definitions.ScheduledSilence
+			require.NoError(t, json.Unmarshal(response.Body(), &created))
+
+			response = sut.RouteDeleteScheduledSilence(&rc, created.UID)
+
+			require.Equal(t, 204, response.Status())
+			response = sut.RouteGetScheduledSilence(&rc, created.UID)
+			require.Equal(t, 404, response.Status())
+		})
+	})
+
 	t.Run("alert rules", func(t *testing.T) {
 		t.Run("are invalid", func(t *testing.T) {
 			t.Run("POST returns 400 on wrong body params", func(t *testing.T) {
@@ -1493,7 +1568,27 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		contactPointService: provisioning.NewContactPointService(env.configs, env.secrets, env.prov, env.xact, env.log, env.ac),
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
-		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.dashboardService, env.quotas, env.xact, 60, 10, env.log),
+		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.dashboardService, env.quotas, env.xact, 60, 10, env.log, &provisioning.NotificationSettingsValidatorProviderFake{}),
+		scheduledSilences:   provisioning.NewScheduledSilenceService(env.store, env.prov, env.xact, nil, env.log),
+	}
+}
+
+func createScheduledSilence() definitions.ScheduledSilence {
+	start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+	return definitions.ScheduledSilence{
+		Matchers: amv2.Matchers{{
+			Name:    util.Pointer("env"),
+			Value:   util.Pointer("prod"),
+			IsRegex: util.Pointer(false),
+			IsEqual: util.Pointer(true),
+		}},
+		Comment:  "weekly maintenance",
+		StartsAt: start,
+		EndsAt:   start.Add(2 * time.Hour),
+		Repeat: &definitions.ScheduledSilenceRepeat{
+			Frequency: "weekly",
+			TimeZone:  "Europe/Berlin",
+		},
 	}
 }
 
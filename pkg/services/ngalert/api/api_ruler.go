@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -42,6 +43,8 @@ type RulerSrv struct {
 	cfg                *setting.UnifiedAlertingSettings
 	conditionValidator ConditionValidator
 	authz              RuleAccessControlService
+	nsValidator        provisioning.NotificationSettingsValidatorProvider
+	dsCache            datasources.CacheService
 }
 
 var (
@@ -259,127 +262,179 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 
+	if err := srv.validateNotificationSettings(c.Req.Context(), c.SignedInUser.GetOrgID(), rules); err != nil {
+		if errors.Is(err, ngmodels.ErrNotificationSettingsInvalid) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to validate notification settings")
+	}
+
 	groupKey := ngmodels.AlertRuleGroupKey{
 		OrgID:        c.SignedInUser.GetOrgID(),
 		NamespaceUID: namespace.UID,
 		RuleGroup:    ruleGroupConfig.Name,
 	}
 
-	return srv.updateAlertRulesInGroup(c, groupKey, rules)
+	return srv.updateAlertRulesInGroup(c, groupKey, rules, nil)
+}
+
+// validateNotificationSettings checks that the receivers and mute timings referenced by the notification settings of the rules exist.
+func (srv RulerSrv) validateNotificationSettings(ctx context.Context, orgID int64, rules []*ngmodels.AlertRuleWithOptionals) error {
+	var validator ngmodels.NotificationSettingsValidator
+	for _, rule := range rules {
+		if rule.NotificationSettings == nil {
+			continue
+		}
+		if validator == nil {
+			v, err := srv.nsValidator.Validator(ctx, orgID)
+			if err != nil {
+				return err
+			}
+			validator = v
+		}
+		if err := validator.Validate(*rule.NotificationSettings); err != nil {
+			return fmt.Errorf("invalid notification settings of rule '%s': %w", rule.Title, err)
+		}
+	}
+	return nil
 }
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
+// If the changes restore a version of a rule, restored must identify the version so that it is recorded in the new version of the rule.
 // All operations are performed in a single transaction
-func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
+func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals, restored *ngmodels.AlertRuleKeyWithVersion) response.Response {
 	var finalChanges *store.GroupDelta
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
-		userNamespace, id := c.SignedInUser.GetNamespacedID()
-		logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
-			groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
-		groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
-		if err != nil {
+		var err error
+		finalChanges, err = srv.calculateAuthorizedChanges(tranCtx, c, groupKey, rules)
+		if err != nil || finalChanges.IsEmpty() {
 			return err
 		}
+		return srv.saveGroupChanges(tranCtx, c, finalChanges, restored)
+	})
 
-		if groupChanges.IsEmpty() {
-			finalChanges = groupChanges
-			logger.Info("No changes detected in the request. Do nothing")
-			return nil
-		}
+	if err != nil {
+		return toUpdateRuleGroupErrorResponse(err)
+	}
+	return changesToResponse(finalChanges)
+}
 
-		err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
-		if err != nil {
-			return err
-		}
+// calculateAuthorizedChanges calculates the changes to the group and verifies that the user is authorized to make them,
+// that the queries of the rules are valid and that provisioned rules are not affected.
+func (srv RulerSrv) calculateAuthorizedChanges(ctx context.Context, c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) (*store.GroupDelta, error) {
+	groupChanges, err := store.CalculateChanges(ctx, srv.store, groupKey, rules)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
-			return err
-		}
+	if groupChanges.IsEmpty() {
+		srv.log.Info("No changes detected in the request. Do nothing", "namespace_uid", groupKey.NamespaceUID, "group", groupKey.RuleGroup, "org_id", groupKey.OrgID)
+		return groupChanges, nil
+	}
 
-		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
-			return err
-		}
+	err = srv.authz.AuthorizeRuleChanges(c.Req.Context(), c.SignedInUser, groupChanges)
+	if err != nil {
+		return nil, err
+	}
 
-		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
-		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+	if err := validateQueries(c.Req.Context(), groupChanges, srv.conditionValidator, c.SignedInUser); err != nil {
+		return nil, err
+	}
 
-		// Delete first as this could prevent future unique constraint violations.
-		if len(finalChanges.Delete) > 0 {
-			UIDs := make([]string, 0, len(finalChanges.Delete))
-			for _, rule := range finalChanges.Delete {
-				UIDs = append(UIDs, rule.UID)
-			}
+	if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
+		return nil, err
+	}
 
-			if err = srv.store.DeleteAlertRulesByUID(tranCtx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
-				return fmt.Errorf("failed to delete rules: %w", err)
-			}
+	return store.UpdateCalculatedRuleFields(groupChanges), nil
+}
+
+// saveGroupChanges writes the changes calculated by calculateAuthorizedChanges to the database. The UIDs of the new
+// rules are set to the UIDs they are stored with.
+func (srv RulerSrv) saveGroupChanges(ctx context.Context, c *contextmodel.ReqContext, finalChanges *store.GroupDelta, restored *ngmodels.AlertRuleKeyWithVersion) error {
+	groupKey := finalChanges.GroupKey
+	userNamespace, id := c.SignedInUser.GetNamespacedID()
+	logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
+		groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
+	userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
+
+	// Delete first as this could prevent future unique constraint violations.
+	if len(finalChanges.Delete) > 0 {
+		UIDs := make([]string, 0, len(finalChanges.Delete))
+		for _, rule := range finalChanges.Delete {
+			UIDs = append(UIDs, rule.UID)
 		}
 
-		if len(finalChanges.Update) > 0 {
-			updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
-			for _, update := range finalChanges.Update {
-				logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
-				updates = append(updates, ngmodels.UpdateRule{
-					Existing: update.Existing,
-					New:      *update.New,
-				})
-			}
-			err = srv.store.UpdateAlertRules(tranCtx, updates)
-			if err != nil {
-				return fmt.Errorf("failed to update rules: %w", err)
-			}
+		if err := srv.store.DeleteAlertRulesByUID(ctx, c.SignedInUser.GetOrgID(), UIDs...); err != nil {
+			return fmt.Errorf("failed to delete rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
-			for _, rule := range finalChanges.New {
-				inserts = append(inserts, *rule)
+	if len(finalChanges.Update) > 0 {
+		updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
+		for _, update := range finalChanges.Update {
+			logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
+			update.New.UpdatedBy = userID
+			upd := ngmodels.UpdateRule{
+				Existing: update.Existing,
+				New:      *update.New,
 			}
-			added, err := srv.store.InsertAlertRules(tranCtx, inserts)
-			if err != nil {
-				return fmt.Errorf("failed to add rules: %w", err)
-			}
-			if len(added) != len(finalChanges.New) {
-				logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
-			} else {
-				for i, newRule := range finalChanges.New {
-					newRule.ID = added[i].ID
-					newRule.UID = added[i].UID
-				}
+			if restored != nil && restored.UID == update.Existing.UID {
+				upd.RestoredFrom = restored.Version
 			}
+			updates = append(updates, upd)
+		}
+		if err := srv.store.UpdateAlertRules(ctx, updates); err != nil {
+			return fmt.Errorf("failed to update rules: %w", err)
 		}
+	}
 
-		if len(finalChanges.New) > 0 {
-			userID, _ := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
-			limitReached, err := srv.QuotaService.CheckQuotaReached(tranCtx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
-				OrgID:  c.SignedInUser.GetOrgID(),
-				UserID: userID,
-			}) // alert rule is table name
-			if err != nil {
-				return fmt.Errorf("failed to get alert rules quota: %w", err)
-			}
-			if limitReached {
-				return ngmodels.ErrQuotaReached
+	if len(finalChanges.New) > 0 {
+		inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
+		for _, rule := range finalChanges.New {
+			rule.UpdatedBy = userID
+			inserts = append(inserts, *rule)
+		}
+		added, err := srv.store.InsertAlertRules(ctx, inserts)
+		if err != nil {
+			return fmt.Errorf("failed to add rules: %w", err)
+		}
+		if len(added) != len(finalChanges.New) {
+			logger.Error("Cannot match inserted rules with final changes", "insertedCount", len(added), "changes", len(finalChanges.New))
+		} else {
+			for i, newRule := range finalChanges.New {
+				newRule.ID = added[i].ID
+				newRule.UID = added[i].UID
 			}
 		}
-		return nil
-	})
 
-	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
+		limitReached, err := srv.QuotaService.CheckQuotaReached(ctx, ngmodels.QuotaTargetSrv, &quota.ScopeParameters{
+			OrgID:  c.SignedInUser.GetOrgID(),
+			UserID: userID,
+		}) // alert rule is table name
+		if err != nil {
+			return fmt.Errorf("failed to get alert rules quota: %w", err)
+		}
+		if limitReached {
+			return ngmodels.ErrQuotaReached
 		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
-	return changesToResponse(finalChanges)
+	return nil
+}
+
+func toUpdateRuleGroupErrorResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -409,16 +464,19 @@ func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, name
 	rules.SortByGroupIndex()
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(rules))
 	var interval time.Duration
+	var sequential bool
 	if len(rules) > 0 {
 		interval = time.Duration(rules[0].IntervalSeconds) * time.Second
+		sequential = rules[0].SequentialEvaluation
 	}
 	for _, r := range rules {
 		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespaceID, provenanceRecords))
 	}
 	return apimodels.GettableRuleGroupConfig{
-		Name:     groupName,
-		Interval: model.Duration(interval),
-		Rules:    ruleNodes,
+		Name:                 groupName,
+		Interval:             model.Duration(interval),
+		SequentialEvaluation: sequential,
+		Rules:                ruleNodes,
 	}
 }
 
@@ -445,6 +503,9 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
 			Provenance:      apimodels.Provenance(provenance),
 			IsPaused:        r.IsPaused,
+			Record:          ApiRecordFromModelRecord(r.Record),
+
+			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -453,6 +514,10 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
 	return gettableExtendedRuleNode
 }
 
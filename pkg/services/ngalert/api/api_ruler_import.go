@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// maxImportBodySize is the maximum size of a rule file that can be imported.
+const maxImportBodySize = 10 << 20
+
+var errImportTitleConflict = errors.New("a rule of another group in the folder has the same title")
+
+// RoutePostPrometheusRulesForImport converts the rule groups of the Prometheus or Loki rule file in the request body to
+// Grafana-managed rule groups in the folder `namespaceTitle`. The queries of the rules use the data source set by the
+// query parameter "datasourceUid". A group of the folder that has the same name as an imported group is replaced, and
+// its rules that have the same title as an imported rule are updated instead of being recreated.
+// If the query parameter "dryRun" is true, nothing is saved and the response describes the changes the import would make.
+func (srv RulerSrv) RoutePostPrometheusRulesForImport(c *contextmodel.ReqContext, namespaceTitle string) response.Response {
+	dryRun := c.QueryBool("dryRun")
+	datasourceUID := c.Query("datasourceUid")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("the data source must be specified by the query parameter datasourceUid"), "")
+	}
+
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	ds, err := srv.dsCache.GetDatasourceByUID(c.Req.Context(), datasourceUID, c.SignedInUser, c.SkipDSCache)
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
+			return ErrResp(http.StatusForbidden, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get data source")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxImportBodySize+1))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read request body")
+	}
+	if len(body) > maxImportBodySize {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("rule file is larger than %d bytes", maxImportBodySize), "")
+	}
+	file, err := prom.ParseRuleFile(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	converter, err := prom.NewConverter(prom.Config{
+		OrgID:           c.SignedInUser.GetOrgID(),
+		NamespaceUID:    namespace.UID,
+		DatasourceUID:   ds.UID,
+		DatasourceType:  ds.Type,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+	})
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	groups, err := converter.ConvertRuleFile(file)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	for _, group := range groups {
+		if err := srv.validateImportedGroup(group); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+
+	result := apimodels.ImportRulesResponse{
+		Message: "rule groups imported successfully",
+		DryRun:  dryRun,
+		Groups:  make([]apimodels.ImportedRuleGroup, 0, len(groups)),
+	}
+	if dryRun {
+		result.Message = "rule groups can be imported, no changes were saved"
+	}
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		existing, err := srv.getRulesByGroupAndTitle(tranCtx, c.SignedInUser.GetOrgID(), namespace.UID, groups)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Title,
+			}
+			rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group.Rules))
+			for _, rule := range group.Rules {
+				if existingRule, ok := existing[group.Title][rule.Title]; ok {
+					rule.UID = existingRule.UID
+				}
+				rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: rule, HasRecord: true})
+			}
+
+			changes, err := srv.calculateAuthorizedChanges(tranCtx, c, groupKey, rules)
+			if err != nil {
+				return err
+			}
+			if !dryRun && !changes.IsEmpty() {
+				if err := srv.saveGroupChanges(tranCtx, c, changes, nil); err != nil {
+					return err
+				}
+			}
+
+			imported := make(ngmodels.RulesGroup, 0, len(rules))
+			for _, r := range rules {
+				imported = append(imported, &r.AlertRule)
+			}
+			result.Groups = append(result.Groups, toImportedRuleGroup(group.Title, changes, toGettableRuleGroupConfig(group.Title, imported, namespace.ID, nil)))
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errImportTitleConflict) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return toUpdateRuleGroupErrorResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, result)
+}
+
+// validateImportedGroup validates the converted group the same way as a rule group submitted to the ruler API.
+func (srv RulerSrv) validateImportedGroup(group ngmodels.AlertRuleGroup) error {
+	if len(group.Title) > store.AlertRuleMaxRuleGroupNameLength {
+		return fmt.Errorf("rule group name %q is too long. Max length is %d", group.Title, store.AlertRuleMaxRuleGroupNameLength)
+	}
+	if _, err := validateInterval(srv.cfg, time.Duration(group.Interval)*time.Second); err != nil {
+		return fmt.Errorf("rule group %q: %w", group.Title, err)
+	}
+	for _, rule := range group.Rules {
+		if len(rule.Title) > store.AlertRuleMaxTitleLength {
+			return fmt.Errorf("rule group %q: alert rule title %q is too long. Max length is %d", group.Title, rule.Title, store.AlertRuleMaxTitleLength)
+		}
+		if rule.Record != nil && !srv.cfg.RecordingRules.Enabled {
+			return fmt.Errorf("rule group %q: rule %q is a recording rule but recording rules are not enabled", group.Title, rule.Title)
+		}
+		if err := rule.ValidateAlertRule(*srv.cfg); err != nil {
+			return fmt.Errorf("rule group %q: invalid rule %q: %w", group.Title, rule.Title, err)
+		}
+	}
+	return nil
+}
+
+// getRulesByGroupAndTitle returns the rules of the imported groups that already exist in the folder, by group and title.
+// It fails if a rule of any other group of the folder has the same title as an imported rule, because titles must be
+// unique in a folder.
+func (srv RulerSrv) getRulesByGroupAndTitle(ctx context.Context, orgID int64, namespaceUID string, groups []ngmodels.AlertRuleGroup) (map[string]map[string]*ngmodels.AlertRule, error) {
+	rules, err := srv.store.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID:         orgID,
+		NamespaceUIDs: []string{namespaceUID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database for rules in the folder: %w", err)
+	}
+
+	result := make(map[string]map[string]*ngmodels.AlertRule, len(groups))
+	for _, group := range groups {
+		result[group.Title] = make(map[string]*ngmodels.AlertRule)
+	}
+	otherTitles := make(map[string]string)
+	for _, rule := range rules {
+		if byTitle, ok := result[rule.RuleGroup]; ok {
+			byTitle[rule.Title] = rule
+			continue
+		}
+		otherTitles[rule.Title] = rule.RuleGroup
+	}
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if other, ok := otherTitles[rule.Title]; ok {
+				return nil, fmt.Errorf("%w: rule %q of group %q conflicts with the rule of group %q", errImportTitleConflict, rule.Title, group.Title, other)
+			}
+		}
+	}
+	return result, nil
+}
+
+func toImportedRuleGroup(name string, changes *store.GroupDelta, group apimodels.GettableRuleGroupConfig) apimodels.ImportedRuleGroup {
+	result := apimodels.ImportedRuleGroup{
+		Name:  name,
+		Group: group,
+	}
+	for _, r := range changes.New {
+		result.Created = append(result.Created, r.Title)
+	}
+	for _, r := range changes.Update {
+		// the updates include the rules that did not change, see store.UpdateCalculatedRuleFields
+		if r.Existing == r.New {
+			continue
+		}
+		result.Updated = append(result.Updated, r.Existing.Title)
+	}
+	for _, r := range changes.Delete {
+		result.Deleted = append(result.Deleted, r.Title)
+	}
+	return result
+}
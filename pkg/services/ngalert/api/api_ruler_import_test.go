@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeds "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+const testPrometheusRuleFile = `
+groups:
+  - name: example
+    rules:
+      - alert: HighRequestRate
+        expr: rate(http_requests_total[5m]) > 100
+        labels:
+          severity: page
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+`
+
+func TestRoutePostPrometheusRulesForImport(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.AlertRuleGroupKey{OrgID: orgID, NamespaceUID: folder.UID, RuleGroup: "example"}
+
+	setup := func(t *testing.T) (*fakes.RuleStore, *RulerSrv, map[string]*models.AlertRule) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		rules := models.GenerateAlertRules(2, models.AlertRuleGen(withGroupKey(groupKey), models.WithUniqueGroupIndex(), func(r *models.AlertRule) {
+			r.IntervalSeconds = 60
+		}))
+		rules[0].Title = "HighRequestRate"
+		rules[1].Title = "Obsolete"
+		other := models.AlertRuleGen(withOrgID(orgID), withNamespace(folder), withGroup("other"))()
+		other.Title = "Other"
+		ruleStore.PutRule(context.Background(), append(rules, other)...)
+
+		svc := createService(ruleStore)
+		svc.cfg.DefaultRuleEvaluationInterval = time.Minute
+		svc.conditionValidator = &recordingConditionValidator{}
+		svc.QuotaService = quotatest.New(false, nil)
+		svc.dsCache = &fakeds.FakeCacheService{DataSources: []*datasources.DataSource{
+			{UID: "prom", Type: datasources.DS_PROMETHEUS},
+			{UID: "graphite", Type: datasources.DS_GRAPHITE},
+		}}
+		return ruleStore, svc, map[string]*models.AlertRule{rules[0].Title: rules[0], rules[1].Title: rules[1], other.Title: other}
+	}
+
+	createRequest := func(ruleStore *fakes.RuleStore, query url.Values, body string) *contextmodel.ReqContext {
+		permissions := createPermissionsForRules(ruleStore.Rules[orgID], orgID)
+		permissions[orgID][datasources.ActionQuery] = append(permissions[orgID][datasources.ActionQuery], datasources.ScopeProvider.GetResourceScopeUID("prom"))
+		scope := []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)}
+		permissions[orgID][ac.ActionAlertingRuleCreate] = scope
+		permissions[orgID][ac.ActionAlertingRuleUpdate] = scope
+		permissions[orgID][ac.ActionAlertingRuleDelete] = scope
+		req := createRequestContextWithPerms(orgID, permissions, nil)
+		req.Req.Form = query
+		req.Req.Body = io.NopCloser(strings.NewReader(body))
+		return req
+	}
+
+	writes := func(ruleStore *fakes.RuleStore) []any {
+		return ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			switch cmd.(type) {
+			case []models.AlertRule, []models.UpdateRule:
+				return cmd, true
+			}
+			if op, ok := cmd.(fakes.GenericRecordedQuery); ok && op.Name == "DeleteAlertRulesByUID" {
+				return cmd, true
+			}
+			return nil, false
+		})
+	}
+
+	t.Run("dry run should report changes without saving them", func(t *testing.T) {
+		ruleStore, svc, existing := setup(t)
+		req := createRequest(ruleStore, url.Values{"datasourceUid": {"prom"}, "dryRun": {"true"}}, testPrometheusRuleFile)
+
+		response := svc.RoutePostPrometheusRulesForImport(req, folder.Title)
+
+		require.Equal(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		var result apimodels.ImportRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		group := result.Groups[0]
+		require.Equal(t, "example", group.Name)
+		require.Equal(t, []string{"InstanceDown"}, group.Created)
+		require.Equal(t, []string{"HighRequestRate"}, group.Updated)
+		require.Equal(t, []string{"Obsolete"}, group.Deleted)
+		require.Len(t, group.Group.Rules, 2)
+		require.Equal(t, existing["HighRequestRate"].UID, group.Group.Rules[0].GrafanaManagedAlert.UID)
+		require.Equal(t, "C", group.Group.Rules[0].GrafanaManagedAlert.Condition)
+		require.Empty(t, writes(ruleStore))
+	})
+
+	t.Run("should replace the group with the converted rules", func(t *testing.T) {
+		ruleStore, svc, existing := setup(t)
+		req := createRequest(ruleStore, url.Values{"datasourceUid": {"prom"}}, testPrometheusRuleFile)
+
+		response := svc.RoutePostPrometheusRulesForImport(req, folder.Title)
+
+		require.Equal(t, http.StatusAccepted, response.Status(), string(response.Body()))
+		var result apimodels.ImportRulesResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.False(t, result.DryRun)
+
+		ops := writes(ruleStore)
+		require.Len(t, ops, 3)
+		require.Equal(t, []string{existing["Obsolete"].UID}, ops[0].(fakes.GenericRecordedQuery).Params[1])
+
+		updates := ops[1].([]models.UpdateRule)
+		require.Len(t, updates, 1)
+		updated := updates[0].New
+		require.Equal(t, existing["HighRequestRate"].UID, updated.UID)
+		require.Equal(t, map[string]string{"severity": "page"}, updated.Labels)
+		require.Equal(t, "prom", updated.Data[0].DatasourceUID)
+		require.Equal(t, existing["HighRequestRate"].IsPaused, updated.IsPaused)
+
+		inserts := ops[2].([]models.AlertRule)
+		require.Len(t, inserts, 1)
+		require.Equal(t, "InstanceDown", inserts[0].Title)
+		require.Equal(t, 5*time.Minute, inserts[0].For)
+		require.Equal(t, groupKey, inserts[0].GetGroupKey())
+		require.EqualValues(t, 60, inserts[0].IntervalSeconds)
+		require.Equal(t, 2, inserts[0].RuleGroupIndex)
+	})
+
+	t.Run("should return 409 if a rule of another group has the same title", func(t *testing.T) {
+		ruleStore, svc, _ := setup(t)
+		req := createRequest(ruleStore, url.Values{"datasourceUid": {"prom"}}, "groups:\n  - name: example\n    rules:\n      - alert: Other\n        expr: up == 0\n")
+
+		response := svc.RoutePostPrometheusRulesForImport(req, folder.Title)
+
+		require.Equal(t, http.StatusConflict, response.Status(), string(response.Body()))
+		require.Empty(t, writes(ruleStore))
+	})
+
+	testCases := []struct {
+		name   string
+		query  url.Values
+		body   string
+		status int
+	}{
+		{
+			name:   "data source is not set",
+			query:  url.Values{},
+			body:   testPrometheusRuleFile,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "data source does not exist",
+			query:  url.Values{"datasourceUid": {"missing"}},
+			body:   testPrometheusRuleFile,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "data source is not Prometheus or Loki",
+			query:  url.Values{"datasourceUid": {"graphite"}},
+			body:   testPrometheusRuleFile,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "rule file is invalid",
+			query:  url.Values{"datasourceUid": {"prom"}},
+			body:   "groups:\n  - name: example\n    rules:\n      - alert: Down\n        expr: up ==\n",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "interval is not a multiple of the base interval",
+			query:  url.Values{"datasourceUid": {"prom"}},
+			body:   "groups:\n  - name: example\n    interval: 15s\n    rules:\n      - alert: Down\n        expr: up == 0\n",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "recording rules are not enabled",
+			query:  url.Values{"datasourceUid": {"prom"}},
+			body:   "groups:\n  - name: example\n    rules:\n      - record: job:up\n        expr: sum by (job) (up)\n",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run("should return 400 if "+tc.name, func(t *testing.T) {
+			ruleStore, svc, _ := setup(t)
+			req := createRequest(ruleStore, tc.query, tc.body)
+
+			response := svc.RoutePostPrometheusRulesForImport(req, folder.Title)
+
+			require.Equal(t, tc.status, response.Status(), string(response.Body()))
+			require.Empty(t, writes(ruleStore))
+		})
+	}
+}
@@ -603,7 +603,8 @@ func createService(store *fakes.RuleStore) *RulerSrv {
 		cfg: &setting.UnifiedAlertingSettings{
 			BaseInterval: 10 * time.Second,
 		},
-		authz: accesscontrol.NewRuleService(acimpl.ProvideAccessControl(setting.NewCfg())),
+		authz:       accesscontrol.NewRuleService(acimpl.ProvideAccessControl(setting.NewCfg())),
+		nsValidator: &provisioning.NotificationSettingsValidatorProviderFake{},
 	}
 }
 
@@ -75,6 +75,11 @@ func validateRuleNode(
 		} else {
 			return nil, fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 		}
+	} else if ruleNode.GrafanaManagedAlert.Record != nil {
+		err = validateRecord(ruleNode, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+		}
 	} else {
 		err = validateCondition(ruleNode.GrafanaManagedAlert.Condition, ruleNode.GrafanaManagedAlert.Data)
 		if err != nil {
@@ -85,22 +90,41 @@ func validateRuleNode(
 	queries := AlertQueriesFromApiAlertQueries(ruleNode.GrafanaManagedAlert.Data)
 
 	newAlertRule := ngmodels.AlertRule{
-		OrgID:           orgId,
-		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
-		Data:            queries,
-		UID:             ruleNode.GrafanaManagedAlert.UID,
-		IntervalSeconds: intervalSeconds,
-		NamespaceUID:    namespace.UID,
-		RuleGroup:       groupName,
-		NoDataState:     noDataState,
-		ExecErrState:    errorState,
+		OrgID:                orgId,
+		Title:                ruleNode.GrafanaManagedAlert.Title,
+		Condition:            ruleNode.GrafanaManagedAlert.Condition,
+		Data:                 queries,
+		UID:                  ruleNode.GrafanaManagedAlert.UID,
+		IntervalSeconds:      intervalSeconds,
+		NamespaceUID:         namespace.UID,
+		RuleGroup:            groupName,
+		NoDataState:          noDataState,
+		ExecErrState:         errorState,
+		Record:               ModelRecordFromApiRecord(ruleNode.GrafanaManagedAlert.Record),
+		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(ruleNode.GrafanaManagedAlert.NotificationSettings),
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
 	if err != nil {
 		return nil, err
 	}
+	newAlertRule.KeepFiringFor, err = validateKeepFiringFor(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+	if newAlertRule.NotificationSettings != nil {
+		if newAlertRule.Record != nil {
+			return nil, fmt.Errorf("%w: recording rules cannot have notification settings", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := newAlertRule.NotificationSettings.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+	}
+	if newAlertRule.Record != nil {
+		// recording rules do not have a pending or keep firing period, do not patch them from the existing rule.
+		newAlertRule.For = 0
+		newAlertRule.KeepFiringFor = 0
+	}
 
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
@@ -114,6 +138,28 @@ func validateRuleNode(
 	return &newAlertRule, nil
 }
 
+// validateRecord validates the definition of a recording rule. Recording rules do not have a condition,
+// instead they write the result of the query or expression referenced by Record.From.
+func validateRecord(ruleNode *apimodels.PostableExtendedRuleNode, cfg *setting.UnifiedAlertingSettings) error {
+	if !cfg.RecordingRules.Enabled {
+		return errors.New("recording rules are not enabled")
+	}
+	rule := ruleNode.GrafanaManagedAlert
+	if rule.Condition != "" {
+		return errors.New("recording rules cannot have a condition")
+	}
+	if ruleNode.ApiRuleNode != nil && ruleNode.ApiRuleNode.For != nil && *ruleNode.ApiRuleNode.For != 0 {
+		return errors.New("recording rules cannot have a pending period")
+	}
+	if ruleNode.ApiRuleNode != nil && ruleNode.ApiRuleNode.KeepFiringFor != nil && *ruleNode.ApiRuleNode.KeepFiringFor != 0 {
+		return errors.New("recording rules cannot have a keep firing period")
+	}
+	if !ngmodels.IsValidMetricName(rule.Record.Metric) {
+		return fmt.Errorf("metric name %q is not a valid Prometheus metric name", rule.Record.Metric)
+	}
+	return validateCondition(rule.Record.From, rule.Data)
+}
+
 func validateCondition(condition string, queries []apimodels.AlertQuery) error {
 	if condition == "" {
 		return errors.New("condition cannot be empty")
@@ -173,6 +219,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringFor validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringFor(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -216,22 +277,34 @@ func validateRuleGroup(
 			uids[rule.UID] = idx
 		}
 
-		var hasPause, isPaused bool
+		var hasPause, isPaused, hasRecord bool
 		original := ruleGroupConfig.Rules[idx]
 		if alert := original.GrafanaManagedAlert; alert != nil {
 			if alert.IsPaused != nil {
 				isPaused = *alert.IsPaused
 				hasPause = true
 			}
+			// A rule that defines its queries defines its type as well: without a record it is an alerting rule.
+			hasRecord = alert.Record != nil || len(alert.Data) > 0
 		}
 
 		ruleWithOptionals := ngmodels.AlertRuleWithOptionals{}
 		rule.IsPaused = isPaused
 		rule.RuleGroupIndex = idx + 1
+		rule.SequentialEvaluation = ruleGroupConfig.SequentialEvaluation
 		ruleWithOptionals.AlertRule = *rule
 		ruleWithOptionals.HasPause = hasPause
+		ruleWithOptionals.HasRecord = hasRecord
 
 		result = append(result, &ruleWithOptionals)
 	}
+
+	rules := make([]*ngmodels.AlertRule, 0, len(result))
+	for _, r := range result {
+		rules = append(rules, &r.AlertRule)
+	}
+	if err := ngmodels.ValidateRuleResultQueries(rules); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
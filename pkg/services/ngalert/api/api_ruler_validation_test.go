@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"testing"
@@ -223,6 +224,28 @@ func TestValidateRuleGroup(t *testing.T) {
 			require.True(t, alert.HasPause)
 		}
 	})
+
+	t.Run("should evaluate all rules sequentially if the group is sequential", func(t *testing.T) {
+		r1 := validRule()
+		r2 := withRuleResultQuery(validRule(), r1.GrafanaManagedAlert.UID)
+		g := validGroup(cfg, r1, r2)
+		g.SequentialEvaluation = true
+		alerts, err := validateRuleGroup(&g, orgId, folder, cfg)
+		require.NoError(t, err)
+		for _, alert := range alerts {
+			require.True(t, alert.SequentialEvaluation)
+		}
+	})
+}
+
+// withRuleResultQuery makes the rule use the state of the rule with the given UID.
+func withRuleResultQuery(rule apimodels.PostableExtendedRuleNode, ruleUID string) apimodels.PostableExtendedRuleNode {
+	rule.GrafanaManagedAlert.Data = append(rule.GrafanaManagedAlert.Data, apimodels.AlertQuery{
+		RefID:         "REF",
+		DatasourceUID: models.RuleResultDatasourceUID,
+		Model:         json.RawMessage(fmt.Sprintf(`{"ruleUid": %q}`, ruleUID)),
+	})
+	return rule
 }
 
 func TestValidateRuleGroupFailures(t *testing.T) {
@@ -284,6 +307,29 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				require.Contains(t, err.Error(), apiModel.Rules[0].GrafanaManagedAlert.UID)
 			},
 		},
+		{
+			name: "fail if a rule uses the result of another rule and the group is not sequential",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				g := validGroup(cfg, r1, withRuleResultQuery(validRule(), r1.GrafanaManagedAlert.UID))
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			},
+		},
+		{
+			name: "fail if a rule uses the result of a rule after it",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r2 := validRule()
+				g := validGroup(cfg, withRuleResultQuery(validRule(), r2.GrafanaManagedAlert.UID), r2)
+				g.SequentialEvaluation = true
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -543,6 +589,17 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if notification settings are invalid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{}
+				return &r
+			},
+			assert: func(t *testing.T, model *apimodels.PostableExtendedRuleNode, err error) {
+				require.ErrorIs(t, err, models.ErrNotificationSettingsInvalid)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -790,3 +847,142 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleNode_Record(t *testing.T) {
+	recordingRule := func() apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		forDuration := model.Duration(0)
+		r.ApiRuleNode.For = &forDuration
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+		return r
+	}
+
+	t.Run("accepts a valid recording rule", func(t *testing.T) {
+		cfg := config(t)
+		cfg.RecordingRules.Enabled = true
+		r := recordingRule()
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "test_metric", From: "A"}, alert.Record)
+		require.Equal(t, models.RuleTypeRecording, alert.Type())
+		require.Zero(t, alert.For)
+	})
+
+	testCases := []struct {
+		name   string
+		enable bool
+		rule   func() apimodels.PostableExtendedRuleNode
+	}{
+		{
+			name:   "fail if recording rules are disabled",
+			enable: false,
+			rule:   recordingRule,
+		},
+		{
+			name:   "fail if recording rule has a condition",
+			enable: true,
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Condition = "A"
+				return r
+			},
+		},
+		{
+			name:   "fail if recording rule has a pending period",
+			enable: true,
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				forDuration := model.Duration(time.Minute)
+				r.ApiRuleNode.For = &forDuration
+				return r
+			},
+		},
+		{
+			name:   "fail if recording rule has notification settings",
+			enable: true,
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{Receiver: "receiver"}
+				return r
+			},
+		},
+		{
+			name:   "fail if recording rule has a keep firing period",
+			enable: true,
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				keepFiringFor := model.Duration(time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return r
+			},
+		},
+		{
+			name:   "fail if metric name is invalid",
+			enable: true,
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.Metric = "invalid metric"
+				return r
+			},
+		},
+		{
+			name:   "fail if from does not reference a query",
+			enable: true,
+			rule: func() apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.From = "B"
+				return r
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg := config(t)
+			cfg.RecordingRules.Enabled = testCase.enable
+			r := testCase.rule()
+			_, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidateRuleNode_KeepFiringFor(t *testing.T) {
+	cfg := config(t)
+
+	t.Run("should use the keep firing period of the rule", func(t *testing.T) {
+		r := validRule()
+		keepFiringFor := model.Duration(5 * time.Minute)
+		r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+	})
+
+	t.Run("should be 0 for new rules if not specified", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.UID = ""
+		r.ApiRuleNode.KeepFiringFor = nil
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Zero(t, alert.KeepFiringFor)
+	})
+
+	t.Run("should be -1 for existing rules if not specified so it is patched from the current version", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.UID = util.GenerateShortUID()
+		r.ApiRuleNode.KeepFiringFor = nil
+		alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(-1), alert.KeepFiringFor)
+	})
+
+	t.Run("should fail if negative", func(t *testing.T) {
+		r := validRule()
+		keepFiringFor := model.Duration(-time.Minute)
+		r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+		_, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval, rand.Int63(), randFolder(), cfg)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+// ruleVersionFieldsToIgnoreInDiff contains the fields that are different for every version of a rule and therefore
+// are not reported as changes.
+var ruleVersionFieldsToIgnoreInDiff = append(store.AlertRuleFieldsToIgnoreInDiff[:], "RuleGroupIndex")
+
+// RouteGetRuleVersions returns the versions of the rule, the most recent first. Each version includes the paths of
+// the fields that changed compared to its parent version.
+func (srv RulerSrv) RouteGetRuleVersions(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+
+	byVersion := make(map[int64]*ngmodels.AlertRuleVersion, len(versions))
+	for _, v := range versions {
+		byVersion[v.Version] = v
+	}
+
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		rule := v.ToAlertRule()
+		var changes []string
+		if parent, ok := byVersion[v.ParentVersion]; ok {
+			changes = diffRuleVersions(parent, v).Paths()
+		}
+		result = append(result, apimodels.GettableRuleVersion{
+			Version:       v.Version,
+			ParentVersion: v.ParentVersion,
+			RestoredFrom:  v.RestoredFrom,
+			Created:       v.Created,
+			CreatedBy:     v.CreatedBy,
+			Changes:       changes,
+			Rule:          toGettableExtendedRuleNode(rule, 0, nil),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionsDiff compares two versions of the rule field by field. The versions are set by the query
+// parameters "base" and "new". If "new" is not set, the base version is compared with the most recent version.
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	baseVersion := c.QueryInt64("base")
+	if baseVersion <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the base version must be specified"), "")
+	}
+	newVersion := c.QueryInt64("new")
+
+	versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+	if newVersion <= 0 {
+		newVersion = versions[0].Version
+	}
+
+	base, err := findRuleVersion(versions, baseVersion)
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+	newer, err := findRuleVersion(versions, newVersion)
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+
+	diffs := diffRuleVersions(base, newer)
+	result := apimodels.RuleVersionsDiff{
+		Base:  baseVersion,
+		New:   newVersion,
+		Diffs: make([]apimodels.RuleVersionFieldDiff, 0, len(diffs)),
+	}
+	for _, d := range diffs {
+		result.Diffs = append(result.Diffs, apimodels.RuleVersionFieldDiff{
+			Field: d.Path,
+			Base:  diffValue(d.Left),
+			New:   diffValue(d.Right),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RoutePostRestoreRuleVersion restores the rule to the specified version. The rule stays in its current group, so
+// the folder, group, evaluation interval, and position in the group are not restored. The change is validated,
+// authorized and saved in the same way as changes made by RoutePostNameRulesConfig.
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *contextmodel.ReqContext, ruleUID string, versionParam string) response.Response {
+	version, err := strconv.ParseInt(versionParam, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("failed to parse version: %w", err), "")
+	}
+
+	ctx := c.Req.Context()
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+	versions, err := srv.store.GetAlertRuleVersions(ctx, &ngmodels.GetAlertRuleVersionsQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+	target, err := findRuleVersion(versions, version)
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+
+	restored := target.ToAlertRule()
+	restored.ID = rule.ID
+	restored.Version = rule.Version
+	restored.Updated = rule.Updated
+	restored.NamespaceUID = rule.NamespaceUID
+	restored.RuleGroup = rule.RuleGroup
+	restored.RuleGroupIndex = rule.RuleGroupIndex
+	restored.IntervalSeconds = rule.IntervalSeconds
+	restored.SequentialEvaluation = rule.SequentialEvaluation
+	if err := restored.SetDashboardAndPanelFromAnnotations(); err != nil {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error()), "")
+	}
+
+	group, err := srv.getAuthorizedRuleGroup(ctx, c, rule.GetGroupKey())
+	if err != nil {
+		return toRuleVersionsErrorResponse(err)
+	}
+	group.SortByGroupIndex()
+	rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group))
+	groupRules := make([]*ngmodels.AlertRule, 0, len(group))
+	for _, r := range group {
+		if r.UID == ruleUID {
+			r = &restored
+		}
+		rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: *r, HasPause: true, HasRecord: true})
+		groupRules = append(groupRules, r)
+	}
+
+	// the restored version can use the results of rules that are no longer before it in the group.
+	if err := ngmodels.ValidateRuleResultQueries(groupRules); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	if err := srv.validateNotificationSettings(ctx, c.SignedInUser.GetOrgID(), rules); err != nil {
+		if errors.Is(err, ngmodels.ErrNotificationSettingsInvalid) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to validate notification settings")
+	}
+
+	return srv.updateAlertRulesInGroup(c, rule.GetGroupKey(), rules, &ngmodels.AlertRuleKeyWithVersion{
+		Version:      version,
+		AlertRuleKey: rule.GetKey(),
+	})
+}
+
+// getAuthorizedRuleVersions returns the versions of the rule, the most recent first, if the user is authorized to
+// access the rule. The versions of deleted rules cannot be accessed.
+func (srv RulerSrv) getAuthorizedRuleVersions(ctx context.Context, c *contextmodel.ReqContext, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	if _, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID); err != nil {
+		return nil, err
+	}
+	return srv.store.GetAlertRuleVersions(ctx, &ngmodels.GetAlertRuleVersionsQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+}
+
+func findRuleVersion(versions []*ngmodels.AlertRuleVersion, version int64) (*ngmodels.AlertRuleVersion, error) {
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: version %d", ngmodels.ErrAlertRuleVersionNotFound, version)
+}
+
+func diffRuleVersions(base, newer *ngmodels.AlertRuleVersion) cmputil.DiffReport {
+	baseRule, newRule := base.ToAlertRule(), newer.ToAlertRule()
+	return baseRule.Diff(&newRule, ruleVersionFieldsToIgnoreInDiff...)
+}
+
+// diffValue returns the value of a field reported by cmputil.DiffReporter, or nil if the field is absent.
+func diffValue(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func toRuleVersionsErrorResponse(err error) response.Response {
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) || errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	return errorToResponse(err)
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestRuleVersions(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+
+	setup := func(t *testing.T) (*fakes.RuleStore, *models.AlertRule) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		rules := models.GenerateAlertRules(2, models.AlertRuleGen(withGroupKey(groupKey), models.WithUniqueGroupIndex()))
+		rule := rules[0]
+		rule.Version = 3
+		rule.NotificationSettings = nil
+		ruleStore.PutRule(context.Background(), rules...)
+
+		version := func(v int64, title string, labels map[string]string) *models.AlertRuleVersion {
+			return &models.AlertRuleVersion{
+				RuleOrgID:        rule.OrgID,
+				RuleUID:          rule.UID,
+				RuleNamespaceUID: rule.NamespaceUID,
+				RuleGroup:        rule.RuleGroup,
+				RuleGroupIndex:   rule.RuleGroupIndex,
+				ParentVersion:    v - 1,
+				Version:          v,
+				Created:          time.Unix(v, 0),
+				CreatedBy:        v * 10,
+				Title:            title,
+				Condition:        rule.Condition,
+				Data:             rule.Data,
+				IntervalSeconds:  rule.IntervalSeconds,
+				NoDataState:      rule.NoDataState,
+				ExecErrState:     rule.ExecErrState,
+				For:              rule.For,
+				Annotations:      rule.Annotations,
+				Labels:           labels,
+			}
+		}
+		ruleStore.Versions[orgID] = []*models.AlertRuleVersion{
+			version(1, "first", map[string]string{"team": "a"}),
+			version(2, "second", map[string]string{"team": "a"}),
+			version(3, rule.Title, rule.Labels),
+		}
+		return ruleStore, rule
+	}
+
+	t.Run("should list versions with the changed fields", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules(ruleStore.Rules[orgID], orgID), nil)
+
+		response := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.GettableRuleVersions
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 3)
+		require.Equal(t, int64(3), result[0].Version)
+		require.Equal(t, int64(20), result[1].CreatedBy)
+		require.Equal(t, "second", result[1].Rule.GrafanaManagedAlert.Title)
+		require.Equal(t, []string{"Title"}, result[1].Changes)
+		require.Empty(t, result[2].Changes)
+	})
+
+	t.Run("should return 404 if rule does not exist", func(t *testing.T) {
+		ruleStore, _ := setup(t)
+		req := createRequestContext(orgID, nil)
+
+		response := createService(ruleStore).RouteGetRuleVersions(req, "unknown")
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 401 if user cannot access the rule", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContextWithPerms(orgID, map[int64]map[string][]string{}, nil)
+
+		response := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+
+		require.Equal(t, http.StatusUnauthorized, response.Status())
+	})
+
+	t.Run("should compare two versions", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules(ruleStore.Rules[orgID], orgID), nil)
+		req.Req.Form = url.Values{"base": []string{"1"}, "new": []string{"2"}}
+
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+		require.Equal(t, http.StatusOK, response.Status())
+		var result apimodels.RuleVersionsDiff
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, apimodels.RuleVersionsDiff{
+			Base:  1,
+			New:   2,
+			Diffs: []apimodels.RuleVersionFieldDiff{{Field: "Title", Base: "first", New: "second"}},
+		}, result)
+	})
+
+	t.Run("should return 400 if base version is not specified", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules(ruleStore.Rules[orgID], orgID), nil)
+
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return 404 if version does not exist", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules(ruleStore.Rules[orgID], orgID), nil)
+		req.Req.Form = url.Values{"base": []string{"10"}}
+
+		response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should restore a version", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		permissions := createPermissionsForRules(ruleStore.Rules[orgID], orgID)
+		permissions[orgID][ac.ActionAlertingRuleUpdate] = []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)}
+		req := createRequestContextWithPerms(orgID, permissions, nil)
+		svc := createService(ruleStore)
+		svc.conditionValidator = &recordingConditionValidator{}
+
+		response := svc.RoutePostRestoreRuleVersion(req, rule.UID, "1")
+
+		require.Equal(t, http.StatusAccepted, response.Status())
+		var result apimodels.UpdateRuleGroupResponse
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Contains(t, result.Updated, rule.UID)
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			u, ok := cmd.([]models.UpdateRule)
+			return u, ok
+		})
+		require.Len(t, updates, 1)
+		var restored *models.UpdateRule
+		for _, u := range updates[0].([]models.UpdateRule) {
+			if u.Existing.UID == rule.UID {
+				u := u
+				restored = &u
+				continue
+			}
+			require.Zero(t, u.RestoredFrom)
+		}
+		require.NotNil(t, restored)
+		require.Equal(t, int64(1), restored.RestoredFrom)
+		require.Equal(t, "first", restored.New.Title)
+		require.Equal(t, map[string]string{"team": "a"}, restored.New.Labels)
+		require.Equal(t, rule.ID, restored.New.ID)
+		require.Equal(t, rule.RuleGroup, restored.New.RuleGroup)
+		require.Equal(t, rule.IntervalSeconds, restored.New.IntervalSeconds)
+	})
+
+	t.Run("should return 404 if restored version does not exist", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules(ruleStore.Rules[orgID], orgID), nil)
+
+		response := createService(ruleStore).RoutePostRestoreRuleVersion(req, rule.UID, "10")
+
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 400 if version is invalid", func(t *testing.T) {
+		ruleStore, rule := setup(t)
+		req := createRequestContext(orgID, nil)
+
+		response := createService(ruleStore).RoutePostRestoreRuleVersion(req, rule.UID, "abc")
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
@@ -38,6 +38,7 @@ type TestingApiSrv struct {
 	evaluator       eval.EvaluatorFactory
 	cfg             *setting.UnifiedAlertingSettings
 	backtesting     *backtesting.Engine
+	ruleStore       RuleStore
 	featureManager  featuremgmt.FeatureToggles
 	appUrl          *url.URL
 	tracer          tracing.Tracer
@@ -254,3 +255,108 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// BacktestRuleGroup tests the rules of a rule group over the time range and compares the results of rules that
+// have the UID of an existing rule with the state history of that rule.
+func (srv TestingApiSrv) BacktestRuleGroup(c *contextmodel.ReqContext, cmd apimodels.BacktestBatchConfig) response.Response {
+	if !srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingBacktesting) {
+		return ErrResp(http.StatusNotFound, nil, "Backtesting API is not enabled")
+	}
+
+	if !cmd.From.Before(cmd.To) {
+		return ErrResp(http.StatusBadRequest, nil, "From must be less than To")
+	}
+	if cmd.FlapWindow < 0 {
+		return ErrResp(http.StatusBadRequest, nil, "Bad flap window")
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	namespace, err := srv.ruleStore.GetNamespaceByUID(c.Req.Context(), cmd.FolderUID, orgID, c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	rules, err := validateRuleGroup(&cmd.RuleGroup, orgID, namespace, srv.cfg)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	group := make(ngmodels.RulesGroup, 0, len(rules))
+	for _, r := range rules {
+		group = append(group, &r.AlertRule)
+	}
+	if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, group); err != nil {
+		return errorToResponse(err)
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel)
+	batch := make([]backtesting.BatchRule, 0, len(group))
+	for _, rule := range group {
+		historyUID := ""
+		if rule.UID != "" {
+			existing, err := srv.ruleStore.GetAlertRulesGroupByRuleUID(c.Req.Context(), &ngmodels.GetAlertRulesGroupByRuleUIDQuery{UID: rule.UID, OrgID: orgID})
+			if err != nil {
+				return ErrResp(http.StatusInternalServerError, err, "Failed to get the existing rule")
+			}
+			if len(existing) > 0 {
+				if err := srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, existing); err != nil {
+					return errorToResponse(err)
+				}
+				historyUID = rule.UID
+			}
+		}
+		// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs (like expression engine, evaluator, state manager etc)
+		rule.UID = "backtesting-" + util.GenerateShortUID()
+		batch = append(batch, backtesting.BatchRule{
+			Rule:           rule,
+			ExtraLabels:    state.GetRuleExtraLabels(rule, namespace.Title, includeFolder),
+			HistoryRuleUID: historyUID,
+		})
+	}
+
+	reports := srv.backtesting.TestBatch(c.Req.Context(), c.SignedInUser, batch, cmd.From, cmd.To, time.Duration(cmd.FlapWindow))
+	result := apimodels.BacktestBatchResult{Rules: make([]apimodels.BacktestRuleResult, 0, len(reports))}
+	for i, report := range reports {
+		result.Rules = append(result.Rules, toBacktestRuleResult(batch[i].HistoryRuleUID, report))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toBacktestRuleResult(uid string, report backtesting.RuleReport) apimodels.BacktestRuleResult {
+	result := apimodels.BacktestRuleResult{
+		UID:      uid,
+		Title:    report.Rule.Title,
+		Backtest: toBacktestStats(report.Backtest),
+		Warning:  report.Warning,
+	}
+	if report.Error != nil {
+		result.Error = report.Error.Error()
+		return result
+	}
+	if report.History == nil {
+		return result
+	}
+	history := toBacktestStats(*report.History)
+	result.History = &history
+	result.Diff = &apimodels.BacktestStats{
+		FiringEpisodes: report.Backtest.FiringEpisodes - report.History.FiringEpisodes,
+		Notifications:  report.Backtest.Notifications - report.History.Notifications,
+		Flaps:          report.Backtest.Flaps - report.History.Flaps,
+	}
+	for _, instance := range report.Instances {
+		result.Instances = append(result.Instances, apimodels.BacktestInstanceResult{
+			Labels:   instance.Labels,
+			Backtest: toBacktestStats(instance.Backtest),
+			History:  toBacktestStats(instance.History),
+		})
+	}
+	return result
+}
+
+func toBacktestStats(s backtesting.Stats) apimodels.BacktestStats {
+	return apimodels.BacktestStats{
+		FiringEpisodes: s.FiringEpisodes,
+		Notifications:  s.Notifications,
+		Flaps:          s.Flaps,
+	}
+}
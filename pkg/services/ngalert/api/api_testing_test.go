@@ -1,12 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -16,11 +19,14 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -268,6 +274,98 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+func TestBacktestRuleGroup(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+	permissions := []ac.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID("DATASOURCE_TEST")},
+	}
+	from := time.Unix(1700000000, 0)
+
+	createSrv := func(t *testing.T, ruleStore *ngfakes.RuleStore, features featuremgmt.FeatureToggles) *TestingApiSrv {
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(eval.Results{}, nil)
+		srv := createTestingApiSrv(t, nil, acMock.New().WithPermissions(permissions), eval_mocks.NewEvaluatorFactory(evaluator))
+		srv.ruleStore = ruleStore
+		srv.featureManager = features
+		srv.backtesting = backtesting.NewEngine(nil, srv.evaluator, srv.tracer, &fakeBacktestHistorian{})
+		return srv
+	}
+
+	t.Run("should return 404 if backtesting is disabled", func(t *testing.T) {
+		srv := createSrv(t, ngfakes.NewRuleStore(t), featuremgmt.WithFeatures())
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestBatchConfig{From: from, To: from.Add(time.Hour)})
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("should return 400 if the time range is invalid", func(t *testing.T) {
+		srv := createSrv(t, ngfakes.NewRuleStore(t), featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting))
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestBatchConfig{From: from, To: from})
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("should return 401 if user cannot access the existing rule", func(t *testing.T) {
+		ruleStore := ngfakes.NewRuleStore(t)
+		existing := models.AlertRuleGen(models.WithOrgID(1))()
+		ruleStore.PutRule(context.Background(), existing)
+		srv := createSrv(t, ruleStore, featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting))
+
+		rule := validRule()
+		rule.GrafanaManagedAlert.UID = existing.UID
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestBatchConfig{
+			From:      from,
+			To:        from.Add(time.Hour),
+			FolderUID: existing.NamespaceUID,
+			RuleGroup: validGroup(srv.cfg, rule),
+		})
+		require.Equal(t, http.StatusUnauthorized, response.Status())
+	})
+
+	t.Run("should compare existing rules with their state history", func(t *testing.T) {
+		ruleStore := ngfakes.NewRuleStore(t)
+		existing := models.AlertRuleGen(models.WithOrgID(1), models.WithQuery(models.AlertQuery{RefID: "A", DatasourceUID: "DATASOURCE_TEST"}))()
+		ruleStore.PutRule(context.Background(), existing)
+		srv := createSrv(t, ruleStore, featuremgmt.WithFeatures(featuremgmt.FlagAlertingBacktesting))
+
+		edited := validRule()
+		edited.GrafanaManagedAlert.UID = existing.UID
+		added := validRule()
+		added.GrafanaManagedAlert.UID = ""
+		group := validGroup(srv.cfg, edited, added)
+		group.Interval = model.Duration(srv.cfg.BaseInterval)
+
+		response := srv.BacktestRuleGroup(rc, definitions.BacktestBatchConfig{
+			From:      from,
+			To:        from.Add(10 * srv.cfg.BaseInterval),
+			FolderUID: existing.NamespaceUID,
+			RuleGroup: group,
+		})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result definitions.BacktestBatchResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Rules, 2)
+		require.Equal(t, existing.UID, result.Rules[0].UID)
+		require.Empty(t, result.Rules[0].Error)
+		require.NotNil(t, result.Rules[0].History)
+		require.Empty(t, result.Rules[1].UID)
+		require.Empty(t, result.Rules[1].Error)
+		require.Nil(t, result.Rules[1].History)
+	})
+}
+
+type fakeBacktestHistorian struct{}
+
+func (f *fakeBacktestHistorian) Query(_ context.Context, _ models.HistoryQuery) (*data.Frame, error) {
+	return data.NewFrame("states"), nil
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -14,43 +16,51 @@ import (
 // AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule
 func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (models.AlertRule, error) {
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         AlertQueriesFromApiAlertQueries(a.Data),
-		Updated:      a.Updated,
-		NoDataState:  models.NoDataState(a.NoDataState),          // TODO there must be a validation
-		ExecErrState: models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
-		For:          time.Duration(a.For),
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
-		IsPaused:     a.IsPaused,
+		ID:            a.ID,
+		UID:           a.UID,
+		OrgID:         a.OrgID,
+		NamespaceUID:  a.FolderUID,
+		RuleGroup:     a.RuleGroup,
+		Title:         a.Title,
+		Condition:     a.Condition,
+		Data:          AlertQueriesFromApiAlertQueries(a.Data),
+		Updated:       a.Updated,
+		NoDataState:   models.NoDataState(a.NoDataState),          // TODO there must be a validation
+		ExecErrState:  models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
+		For:           time.Duration(a.For),
+		KeepFiringFor: time.Duration(a.KeepFiringFor),
+		Annotations:   a.Annotations,
+		Labels:        a.Labels,
+		IsPaused:      a.IsPaused,
+		Record:        ModelRecordFromApiRecord(a.Record),
+
+		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
 	}, nil
 }
 
 // ProvisionedAlertRuleFromAlertRule converts models.AlertRule to definitions.ProvisionedAlertRule and sets provided provenance status
 func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.Provenance) definitions.ProvisionedAlertRule {
 	return definitions.ProvisionedAlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         ApiAlertQueriesFromAlertQueries(rule.Data),
-		Updated:      rule.Updated,
-		NoDataState:  definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
-		ExecErrState: definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   definitions.Provenance(provenance), // TODO validate enum conversion?
-		IsPaused:     rule.IsPaused,
+		ID:            rule.ID,
+		UID:           rule.UID,
+		OrgID:         rule.OrgID,
+		FolderUID:     rule.NamespaceUID,
+		RuleGroup:     rule.RuleGroup,
+		Title:         rule.Title,
+		For:           model.Duration(rule.For),
+		KeepFiringFor: model.Duration(rule.KeepFiringFor),
+		Condition:     rule.Condition,
+		Data:          ApiAlertQueriesFromAlertQueries(rule.Data),
+		Updated:       rule.Updated,
+		NoDataState:   definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
+		ExecErrState:  definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
+		Annotations:   rule.Annotations,
+		Labels:        rule.Labels,
+		Provenance:    definitions.Provenance(provenance), // TODO validate enum conversion?
+		IsPaused:      rule.IsPaused,
+		Record:        ApiRecordFromModelRecord(rule.Record),
+
+		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
 	}
 }
 
@@ -63,6 +73,58 @@ func ProvisionedAlertRuleFromAlertRules(rules []*models.AlertRule, provenances m
 	return result
 }
 
+// ModelRecordFromApiRecord converts definitions.Record to models.Record
+func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
+	if r == nil {
+		return nil
+	}
+	return &models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ApiRecordFromModelRecord converts models.Record to definitions.Record
+func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
+	if r == nil {
+		return nil
+	}
+	return &definitions.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// NotificationSettingsFromAlertRuleNotificationSettings converts definitions.AlertRuleNotificationSettings to models.NotificationSettings
+func NotificationSettingsFromAlertRuleNotificationSettings(ns *definitions.AlertRuleNotificationSettings) *models.NotificationSettings {
+	if ns == nil {
+		return nil
+	}
+	return &models.NotificationSettings{
+		Receiver:          ns.Receiver,
+		GroupBy:           ns.GroupBy,
+		GroupWait:         ns.GroupWait,
+		GroupInterval:     ns.GroupInterval,
+		RepeatInterval:    ns.RepeatInterval,
+		MuteTimeIntervals: ns.MuteTimeIntervals,
+	}
+}
+
+// AlertRuleNotificationSettingsFromNotificationSettings converts models.NotificationSettings to definitions.AlertRuleNotificationSettings
+func AlertRuleNotificationSettingsFromNotificationSettings(ns *models.NotificationSettings) *definitions.AlertRuleNotificationSettings {
+	if ns == nil {
+		return nil
+	}
+	return &definitions.AlertRuleNotificationSettings{
+		Receiver:          ns.Receiver,
+		GroupBy:           ns.GroupBy,
+		GroupWait:         ns.GroupWait,
+		GroupInterval:     ns.GroupInterval,
+		RepeatInterval:    ns.RepeatInterval,
+		MuteTimeIntervals: ns.MuteTimeIntervals,
+	}
+}
+
 // AlertQueriesFromApiAlertQueries converts a collection of definitions.AlertQuery to collection of models.AlertQuery
 func AlertQueriesFromApiAlertQueries(queries []definitions.AlertQuery) []models.AlertQuery {
 	result := make([]models.AlertQuery, 0, len(queries))
@@ -101,9 +163,10 @@ func ApiAlertQueriesFromAlertQueries(queries []models.AlertQuery) []definitions.
 
 func AlertRuleGroupFromApiAlertRuleGroup(a definitions.AlertRuleGroup) (models.AlertRuleGroup, error) {
 	ruleGroup := models.AlertRuleGroup{
-		Title:     a.Title,
-		FolderUID: a.FolderUID,
-		Interval:  a.Interval,
+		Title:                a.Title,
+		FolderUID:            a.FolderUID,
+		Interval:             a.Interval,
+		SequentialEvaluation: a.SequentialEvaluation,
 	}
 	for i := range a.Rules {
 		converted, err := AlertRuleFromProvisionedAlertRule(a.Rules[i])
@@ -121,10 +184,11 @@ func ApiAlertRuleGroupFromAlertRuleGroup(d models.AlertRuleGroup) definitions.Al
 		rules = append(rules, ProvisionedAlertRuleFromAlertRule(d.Rules[i], d.Provenance))
 	}
 	return definitions.AlertRuleGroup{
-		Title:     d.Title,
-		FolderUID: d.FolderUID,
-		Interval:  d.Interval,
-		Rules:     rules,
+		Title:                d.Title,
+		FolderUID:            d.FolderUID,
+		Interval:             d.Interval,
+		SequentialEvaluation: d.SequentialEvaluation,
+		Rules:                rules,
 	}
 }
 
@@ -152,13 +216,14 @@ func AlertRuleGroupExportFromAlertRuleGroupWithFolderTitle(d models.AlertRuleGro
 		rules = append(rules, alert)
 	}
 	return definitions.AlertRuleGroupExport{
-		OrgID:           d.OrgID,
-		Name:            d.Title,
-		Folder:          d.FolderTitle,
-		FolderUID:       d.FolderUID,
-		Interval:        model.Duration(time.Duration(d.Interval) * time.Second),
-		IntervalSeconds: d.Interval,
-		Rules:           rules,
+		OrgID:                d.OrgID,
+		Name:                 d.Title,
+		Folder:               d.FolderTitle,
+		FolderUID:            d.FolderUID,
+		Interval:             model.Duration(time.Duration(d.Interval) * time.Second),
+		IntervalSeconds:      d.Interval,
+		SequentialEvaluation: d.SequentialEvaluation,
+		Rules:                rules,
 	}, nil
 }
 
@@ -188,15 +253,46 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringFor = model.Duration(rule.KeepFiringFor)
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
 	if rule.Labels != nil {
 		result.Labels = &rule.Labels
 	}
+	if rule.Record != nil {
+		result.Record = &definitions.AlertRecordExport{
+			Metric: rule.Record.Metric,
+			From:   rule.Record.From,
+		}
+	}
+	if rule.NotificationSettings != nil {
+		result.NotificationSettings = AlertRuleNotificationSettingsExportFromNotificationSettings(*rule.NotificationSettings)
+	}
 	return result, nil
 }
 
+// AlertRuleNotificationSettingsExportFromNotificationSettings creates a definitions.AlertRuleNotificationSettingsExport DTO from models.NotificationSettings.
+func AlertRuleNotificationSettingsExportFromNotificationSettings(ns models.NotificationSettings) *definitions.AlertRuleNotificationSettingsExport {
+	toStringPtr := func(d *model.Duration) *string {
+		if d == nil {
+			return nil
+		}
+		return util.Pointer(d.String())
+	}
+	return &definitions.AlertRuleNotificationSettingsExport{
+		Receiver:          ns.Receiver,
+		GroupBy:           ns.GroupBy,
+		GroupWait:         toStringPtr(ns.GroupWait),
+		GroupInterval:     toStringPtr(ns.GroupInterval),
+		RepeatInterval:    toStringPtr(ns.RepeatInterval),
+		MuteTimeIntervals: ns.MuteTimeIntervals,
+	}
+}
+
 // AlertQueryExportFromAlertQuery creates a definitions.AlertQueryExport DTO from models.AlertQuery.
 func AlertQueryExportFromAlertQuery(query models.AlertQuery) (definitions.AlertQueryExport, error) {
 	// We unmarshal the json.RawMessage model into a map in order to facilitate yaml marshalling.
@@ -323,6 +419,67 @@ func RouteExportFromRoute(route *definitions.Route) *definitions.RouteExport {
 	return &export
 }
 
+// ScheduledSilenceFromApiScheduledSilence converts definitions.ScheduledSilence to models.ScheduledSilence
+func ScheduledSilenceFromApiScheduledSilence(s definitions.ScheduledSilence) (models.ScheduledSilence, error) {
+	matchers := make([]models.SilenceMatcher, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		if m == nil || m.Name == nil || m.Value == nil || m.IsRegex == nil {
+			return models.ScheduledSilence{}, errors.New("matcher must have name, value and isRegex")
+		}
+		isEqual := true
+		if m.IsEqual != nil {
+			isEqual = *m.IsEqual
+		}
+		matchers = append(matchers, models.SilenceMatcher{Name: *m.Name, Value: *m.Value, IsRegex: *m.IsRegex, IsEqual: isEqual})
+	}
+	result := models.ScheduledSilence{
+		UID:       s.UID,
+		Matchers:  matchers,
+		Comment:   s.Comment,
+		CreatedBy: s.CreatedBy,
+		StartsAt:  s.StartsAt,
+		EndsAt:    s.EndsAt,
+		TimeZone:  "UTC",
+	}
+	if s.Repeat != nil {
+		result.Frequency = models.ScheduledSilenceFrequency(s.Repeat.Frequency)
+		result.Interval = s.Repeat.Interval
+		result.RepeatUntil = s.Repeat.Until
+		if s.Repeat.TimeZone != "" {
+			result.TimeZone = s.Repeat.TimeZone
+		}
+	}
+	return result, nil
+}
+
+// ApiScheduledSilenceFromScheduledSilence converts models.ScheduledSilence to definitions.ScheduledSilence and sets provided provenance status
+func ApiScheduledSilenceFromScheduledSilence(s models.ScheduledSilence, provenance models.Provenance) definitions.ScheduledSilence {
+	matchers := make(amv2.Matchers, 0, len(s.Matchers))
+	for _, m := range s.Matchers {
+		m := m
+		matchers = append(matchers, &amv2.Matcher{Name: &m.Name, Value: &m.Value, IsRegex: &m.IsRegex, IsEqual: &m.IsEqual})
+	}
+	result := definitions.ScheduledSilence{
+		UID:        s.UID,
+		Matchers:   matchers,
+		Comment:    s.Comment,
+		CreatedBy:  s.CreatedBy,
+		StartsAt:   s.StartsAt,
+		EndsAt:     s.EndsAt,
+		SilenceID:  s.SilenceID,
+		Provenance: definitions.Provenance(provenance),
+	}
+	if s.Frequency != models.ScheduledSilenceOnce {
+		result.Repeat = &definitions.ScheduledSilenceRepeat{
+			Frequency: string(s.Frequency),
+			Interval:  s.Interval,
+			Until:     s.RepeatUntil,
+			TimeZone:  s.TimeZone,
+		}
+	}
+	return result
+}
+
 // OmitDefault returns nil if the value is the default.
 func OmitDefault[T comparable](v *T) *T {
 	var def T
@@ -98,6 +98,13 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 			errs = append(errs, err)
 		}
 	}
+	for _, i := range cp.Mqtt {
+		el, err := marshallIntegration(j, "mqtt", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	for _, i := range cp.Opsgenie {
 		el, err := marshallIntegration(j, "opsgenie", i, i.DisableResolveMessage)
 		integration = append(integration, el)
@@ -259,6 +266,11 @@ func parseIntegration(json jsoniter.API, result *definitions.ContactPoint, recei
 		if err = json.Unmarshal(data, &integration); err == nil {
 			result.Line = append(result.Line, integration)
 		}
+	case "mqtt":
+		integration := definitions.MqttIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.Mqtt = append(result.Mqtt, integration)
+		}
 	case "opsgenie":
 		integration := definitions.OpsgenieIntegration{DisableResolveMessage: disable}
 		if err = json.Unmarshal(data, &integration); err == nil {
@@ -357,6 +369,12 @@ func (c contactPointsExtension) UpdateStructDescriptor(structDescriptor *jsonite
 			desc.Encoder = codec
 		}
 	}
+	if structDescriptor.Type == reflect2.TypeOf(definitions.MqttIntegration{}) {
+		codec := &numberAsStringCodec{}
+		desc := structDescriptor.GetField("QoS")
+		desc.Decoder = codec
+		desc.Encoder = codec
+	}
 	if structDescriptor.Type == reflect2.TypeOf(definitions.WebhookIntegration{}) {
 		codec := &numberAsStringCodec{ignoreError: true}
 		desc := structDescriptor.GetField("MaxAlerts")
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/mqtt"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -89,6 +91,45 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 		})
 	}
 
+	// MQTT is not supported by the alerting module, therefore, it is not in the configs for testing.
+	t.Run("mqtt", func(t *testing.T) {
+		settings := `{
+			"brokerUrl": "ssl://localhost:8883",
+			"clientId": "grafana",
+			"topic": "alerts/{{ .CommonLabels.alertname }}",
+			"message": "test-message",
+			"qos": "2",
+			"retain": true,
+			"username": "test-user",
+			"password": "test-password",
+			"tlsInsecureSkipVerify": true
+		}`
+		export := definitions.ContactPointExport{
+			Name: "test",
+			Receivers: []definitions.ReceiverExport{
+				{
+					Type:     "mqtt",
+					Settings: definitions.RawMessage(settings),
+				},
+			},
+		}
+		result, err := ContactPointFromContactPointExport(export)
+		require.NoError(t, err)
+		require.Len(t, result.Mqtt, 1)
+		require.Equal(t, int64(2), *result.Mqtt[0].QoS)
+
+		back, err := ContactPointToContactPointExport(result)
+		require.NoError(t, err)
+		require.Len(t, back.Integrations, 1)
+
+		noDecrypt := func(key string, fallback string) string { return fallback }
+		expected, err := mqtt.NewConfig(json.RawMessage(settings), noDecrypt)
+		require.NoError(t, err)
+		actual, err := mqtt.NewConfig(back.Integrations[0].Settings, noDecrypt)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
 	t.Run("pushover optional numbers as string", func(t *testing.T) {
 		export := definitions.ContactPointExport{
 			Name: "test",
@@ -175,6 +175,14 @@ func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilences(ctx *contextmodel
 	return f.GrafanaSvc.RouteGetSilences(ctx)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaNotificationDeliveries(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetNotificationDeliveries(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilencesAudit(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSilencesAudit(ctx)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostGrafanaAlertingConfig(ctx *contextmodel.ReqContext, conf apimodels.PostableUserConfig) response.Response {
 	if !conf.AlertmanagerConfig.ReceiverType().Can(apimodels.GrafanaReceiverType) {
 		return errorToResponse(backendTypeDoesNotMatchPayloadTypeError(apimodels.GrafanaBackend, conf.AlertmanagerConfig.ReceiverType().String()))
@@ -186,6 +194,10 @@ func (f *AlertmanagerApiHandler) handleRouteGetGrafanaReceivers(ctx *contextmode
 	return f.GrafanaSvc.RouteGetReceivers(ctx)
 }
 
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaRoutingPreview(ctx *contextmodel.ReqContext, conf apimodels.RoutingPreviewRequest) response.Response {
+	return f.GrafanaSvc.RoutePostRoutingPreview(ctx, conf)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext, conf apimodels.TestReceiversConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
@@ -46,6 +46,10 @@ func (f *PrometheusApiHandler) handleRouteGetGrafanaRuleStatuses(ctx *contextmod
 	return f.GrafanaSvc.RouteGetRuleStatuses(ctx)
 }
 
+func (f *PrometheusApiHandler) handleRouteGetGrafanaRuleEvaluationProfiles(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetRuleEvaluationProfiles(ctx)
+}
+
 func (f *PrometheusApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexProm, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
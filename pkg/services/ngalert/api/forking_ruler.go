@@ -113,6 +113,22 @@ func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqCont
 	return f.GrafanaRuler.ExportRules(ctx)
 }
 
+func (f *RulerApiHandler) handleRouteGetRuleVersionsGrafana(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersions(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleVersionsDiffGrafana(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsDiff(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRoutePostPrometheusRulesForImport(ctx *contextmodel.ReqContext, namespace string) response.Response {
+	return f.GrafanaRuler.RoutePostPrometheusRulesForImport(ctx, namespace)
+}
+
+func (f *RulerApiHandler) handleRoutePostRestoreRuleVersionGrafana(ctx *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	return f.GrafanaRuler.RoutePostRestoreRuleVersion(ctx, ruleUID, version)
+}
+
 func (f *RulerApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexRuler, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
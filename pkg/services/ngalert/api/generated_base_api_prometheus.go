@@ -21,6 +21,7 @@ import (
 type PrometheusApi interface {
 	RouteGetAlertStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertStatuses(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaRuleEvaluationProfiles(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaRuleStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetRuleStatuses(*contextmodel.ReqContext) response.Response
 }
@@ -33,6 +34,9 @@ func (f *PrometheusApiHandler) RouteGetAlertStatuses(ctx *contextmodel.ReqContex
 func (f *PrometheusApiHandler) RouteGetGrafanaAlertStatuses(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaAlertStatuses(ctx)
 }
+func (f *PrometheusApiHandler) RouteGetGrafanaRuleEvaluationProfiles(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaRuleEvaluationProfiles(ctx)
+}
 func (f *PrometheusApiHandler) RouteGetGrafanaRuleStatuses(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaRuleStatuses(ctx)
 }
@@ -68,6 +72,18 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/api/v1/rules/profiles"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/rules/profiles"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/api/v1/rules/profiles",
+				api.Hooks.Wrap(srv.RouteGetGrafanaRuleEvaluationProfiles),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/prometheus/grafana/api/v1/rules"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
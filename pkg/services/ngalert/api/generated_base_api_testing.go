@@ -20,12 +20,21 @@ import (
 )
 
 type TestingApi interface {
+	BacktestBatch(*contextmodel.ReqContext) response.Response
 	BacktestConfig(*contextmodel.ReqContext) response.Response
 	RouteEvalQueries(*contextmodel.ReqContext) response.Response
 	RouteTestRuleConfig(*contextmodel.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*contextmodel.ReqContext) response.Response
 }
 
+func (f *TestingApiHandler) BacktestBatch(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.BacktestBatchConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleBacktestBatch(ctx, conf)
+}
 func (f *TestingApiHandler) BacktestConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.BacktestConfig{}
@@ -63,6 +72,18 @@ func (f *TestingApiHandler) RouteTestRuleGrafanaConfig(ctx *contextmodel.ReqCont
 
 func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/v1/rule/backtest/batch"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rule/backtest/batch"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/backtest/batch",
+				api.Hooks.Wrap(srv.BacktestBatch),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/backtest"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -127,3 +127,23 @@ func (f *ProvisioningApiHandler) handleRouteGetAlertRuleGroupExport(ctx *context
 func (f *ProvisioningApiHandler) handleRoutePutAlertRuleGroup(ctx *contextmodel.ReqContext, ag apimodels.AlertRuleGroup, folder, group string) response.Response {
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag, folder, group)
 }
+
+func (f *ProvisioningApiHandler) handleRouteGetScheduledSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetScheduledSilences(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetScheduledSilence(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteGetScheduledSilence(ctx, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostScheduledSilence(ctx *contextmodel.ReqContext, silence apimodels.ScheduledSilence) response.Response {
+	return f.svc.RoutePostScheduledSilence(ctx, silence)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutScheduledSilence(ctx *contextmodel.ReqContext, silence apimodels.ScheduledSilence, UID string) response.Response {
+	return f.svc.RoutePutScheduledSilence(ctx, silence, UID)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteScheduledSilence(ctx *contextmodel.ReqContext, UID string) response.Response {
+	return f.svc.RouteDeleteScheduledSilence(ctx, UID)
+}
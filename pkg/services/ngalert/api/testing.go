@@ -22,13 +22,16 @@ type fakeAlertInstanceManager struct {
 	mtx sync.Mutex
 	// orgID -> RuleID -> States
 	states map[int64]map[string][]*state.State
+	// orgID -> RuleID -> recording rule status
+	recordingStatuses map[int64]map[string]state.RecordingRuleStatus
 }
 
 func NewFakeAlertInstanceManager(t *testing.T) *fakeAlertInstanceManager {
 	t.Helper()
 
 	return &fakeAlertInstanceManager{
-		states: map[int64]map[string][]*state.State{},
+		states:            map[int64]map[string][]*state.State{},
+		recordingStatuses: map[int64]map[string]state.RecordingRuleStatus{},
 	}
 }
 
@@ -52,6 +55,22 @@ func (f *fakeAlertInstanceManager) GetStatesForRuleUID(orgID int64, alertRuleUID
 	return f.states[orgID][alertRuleUID]
 }
 
+func (f *fakeAlertInstanceManager) GetRecordingRuleStatus(orgID int64, ruleUID string) (state.RecordingRuleStatus, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	status, ok := f.recordingStatuses[orgID][ruleUID]
+	return status, ok
+}
+
+func (f *fakeAlertInstanceManager) SetRecordingRuleStatus(orgID int64, ruleUID string, status state.RecordingRuleStatus) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, ok := f.recordingStatuses[orgID]; !ok {
+		f.recordingStatuses[orgID] = map[string]state.RecordingRuleStatus{}
+	}
+	f.recordingStatuses[orgID][ruleUID] = status
+}
+
 // forEachState represents the callback used when generating alert instances that allows us to modify the generated result
 type forEachState func(s *state.State) *state.State
 
@@ -151,6 +151,15 @@ import (
 //     Responses:
 //       200: receiversResponse
 
+// swagger:route GET /api/alertmanager/grafana/notifications/deliveries alertmanager RouteGetGrafanaNotificationDeliveries
+//
+// get the delivery attempts of the contact points, the most recent attempts first
+//
+//     Responses:
+//       200: GettableNotificationDeliveries
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route POST /api/alertmanager/grafana/config/api/v1/receivers/test alertmanager RoutePostTestGrafanaReceivers
 //
 // Test Grafana managed receivers without saving them.
@@ -178,6 +187,20 @@ import (
 //       403: PermissionDenied
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/routing/preview alertmanager RoutePostGrafanaRoutingPreview
+//
+// Preview how an alert would be routed by the Grafana Alertmanager.
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//
+//       200: RoutingPreview
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:route GET /api/alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -186,6 +209,13 @@ import (
 //       200: gettableSilences
 //       400: ValidationError
 
+// swagger:route GET /api/alertmanager/grafana/silences/audit alertmanager RouteGetGrafanaSilencesAudit
+//
+// get the audit trail of silences, the most recent changes first
+//
+//     Responses:
+//       200: GettableSilenceAuditEntries
+
 // swagger:route GET /api/alertmanager/{DatasourceUID}/api/v2/silences alertmanager RouteGetSilences
 //
 // get silences
@@ -341,6 +371,83 @@ type TestTemplatesErrorResult struct {
 	Message string `json:"message"`
 }
 
+// swagger:parameters RoutePostGrafanaRoutingPreview
+type RoutingPreviewParams struct {
+	// in:body
+	Body RoutingPreviewRequest
+}
+
+// RoutingPreviewRequest describes the alert to preview. If RuleUID is set, the labels of the alert rule, and the
+// labels that Grafana adds to the alerts of the rule, are merged with Labels.
+type RoutingPreviewRequest struct {
+	// Labels of the alert instance.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// UID of the alert rule.
+	RuleUID string `json:"ruleUid,omitempty"`
+}
+
+// swagger:model
+type RoutingPreview struct {
+	// Labels of the alert that were used to compute the preview.
+	Labels map[string]string `json:"labels"`
+
+	// Routes of the notification policy tree that match the alert.
+	Routes []RoutingPreviewRoute `json:"routes"`
+
+	// Active silences that match the alert.
+	Silences GettableSilences `json:"silences"`
+
+	// Inhibition rules that target the alert.
+	Inhibitions []RoutingPreviewInhibition `json:"inhibitions"`
+
+	// Receivers that would be notified. It is empty if the alert is silenced or inhibited, or if it is muted on all
+	// matching routes.
+	Receivers []string `json:"receivers"`
+
+	// Silenced is true if at least one of the silences is active.
+	Silenced bool `json:"silenced"`
+
+	// Inhibited is true if at least one of the inhibition rules is active.
+	Inhibited bool `json:"inhibited"`
+}
+
+type RoutingPreviewRoute struct {
+	// Path is the position of the route in the notification policy tree, as the indices of the child routes that
+	// lead to it from the default policy.
+	Path []int `json:"path"`
+
+	Receiver       string         `json:"receiver"`
+	ObjectMatchers ObjectMatchers `json:"object_matchers,omitempty"`
+	Continue       bool           `json:"continue"`
+
+	// Group labels and timings that apply to the route, including those inherited from its parents.
+	GroupBy        []string       `json:"group_by,omitempty"`
+	GroupWait      model.Duration `json:"group_wait"`
+	GroupInterval  model.Duration `json:"group_interval"`
+	RepeatInterval model.Duration `json:"repeat_interval"`
+
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty"`
+
+	// MutedBy is the list of mute timings that are active now.
+	MutedBy []string `json:"muted_by,omitempty"`
+}
+
+type RoutingPreviewInhibition struct {
+	// Index of the inhibition rule in the configuration.
+	Index int `json:"index"`
+
+	SourceMatchers []string `json:"source_matchers"`
+	TargetMatchers []string `json:"target_matchers"`
+	Equal          []string `json:"equal,omitempty"`
+
+	// SourceAlerts are the fingerprints of the firing alerts that inhibit the alert.
+	SourceAlerts []string `json:"source_alerts,omitempty"`
+
+	// Active is true if the rule inhibits the alert.
+	Active bool `json:"active"`
+}
+
 // swagger:enum TemplateErrorKind
 type TemplateErrorKind string
 
@@ -367,6 +474,87 @@ type GetSilencesParams struct {
 	Filter []string `json:"filter"`
 }
 
+// swagger:parameters RouteGetGrafanaSilencesAudit
+type GetSilencesAuditParams struct {
+	// Limit response to the changes of the silence with this ID.
+	// in:query
+	SilenceID string `json:"silenceId"`
+	// Limit response to the changes of the silences created for the scheduled silence with this UID.
+	// in:query
+	ScheduledSilenceUID string `json:"scheduledSilenceUid"`
+	// Limit response to n entries.
+	// in:query
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableSilenceAuditEntries []GettableSilenceAuditEntry
+
+// GettableSilenceAuditEntry records who created or expired a silence.
+type GettableSilenceAuditEntry struct {
+	SilenceID string `json:"silenceId"`
+	// enum: create,expire
+	Action string `json:"action"`
+	// The login of the user who made the change.
+	User                string    `json:"user"`
+	ScheduledSilenceUID string    `json:"scheduledSilenceUid,omitempty"`
+	Created             time.Time `json:"created"`
+}
+
+// swagger:parameters RouteGetGrafanaNotificationDeliveries
+type GetNotificationDeliveriesParams struct {
+	// Limit response to the attempts of the contact point with this name.
+	// in:query
+	Receiver string `json:"receiver"`
+	// Limit response to the attempts of integrations of this type, for example "slack".
+	// in:query
+	Integration string `json:"integration"`
+	// Limit response to the attempts of the integration with this UID.
+	// in:query
+	IntegrationUID string `json:"integrationUid"`
+	// Limit response to the attempts that notified the alert with this fingerprint.
+	// in:query
+	AlertFingerprint string `json:"alertFingerprint"`
+	// Limit response to successful or failed attempts.
+	// in:query
+	// enum: success,failure
+	Status string `json:"status"`
+	// Limit response to the attempts made at or after this time, in Unix seconds.
+	// in:query
+	From int64 `json:"from"`
+	// Limit response to the attempts made at or before this time, in Unix seconds.
+	// in:query
+	To int64 `json:"to"`
+	// Limit response to n attempts. Defaults to 100.
+	// in:query
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableNotificationDeliveries []GettableNotificationDelivery
+
+// GettableNotificationDelivery is an attempt of an integration of a contact point to deliver a notification.
+type GettableNotificationDelivery struct {
+	Receiver         string `json:"receiver"`
+	Integration      string `json:"integration"`
+	IntegrationUID   string `json:"integrationUid,omitempty"`
+	IntegrationIndex int    `json:"integrationIndex"`
+	// The key of the aggregation group of the notified alerts. It is empty for test notifications.
+	GroupKey          string   `json:"groupKey,omitempty"`
+	AlertFingerprints []string `json:"alertFingerprints"`
+	// The SHA-256 hash of the requests sent by the integration, if it sends webhooks.
+	PayloadHash string `json:"payloadHash,omitempty"`
+	// The HTTP status code of the last response, if the integration received one.
+	StatusCode int `json:"statusCode,omitempty"`
+	// The time the attempt took, in milliseconds.
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+	// The number of failed attempts to send the same notification before this attempt.
+	Retry       int       `json:"retry"`
+	Success     bool      `json:"success"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
 // swagger:model
 type GettableStatus struct {
 	// cluster
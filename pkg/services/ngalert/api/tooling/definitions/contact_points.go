@@ -85,6 +85,24 @@ type LineIntegration struct {
 	Description *string `json:"description,omitempty" yaml:"description,omitempty" hcl:"description"`
 }
 
+type MqttIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	BrokerURL string `json:"brokerUrl" yaml:"brokerUrl" hcl:"broker_url"`
+	Topic     string `json:"topic" yaml:"topic" hcl:"topic"`
+
+	ClientID              *string `json:"clientId,omitempty" yaml:"clientId,omitempty" hcl:"client_id"`
+	Message               *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	QoS                   *int64  `json:"qos,omitempty" yaml:"qos,omitempty" hcl:"qos"`
+	Retain                *bool   `json:"retain,omitempty" yaml:"retain,omitempty" hcl:"retain"`
+	Username              *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password              *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	TLSCACertificate      *string `json:"tlsCaCertificate,omitempty" yaml:"tlsCaCertificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate  *string `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey          *Secret `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty" hcl:"tls_client_key"`
+	TLSInsecureSkipVerify *bool   `json:"tlsInsecureSkipVerify,omitempty" yaml:"tlsInsecureSkipVerify,omitempty" hcl:"tls_insecure_skip_verify"`
+}
+
 type OnCallIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -276,6 +294,7 @@ type ContactPoint struct {
 	Googlechat   []GooglechatIntegration   `json:"googlechat" yaml:"googlechat" hcl:"googlechat,block"`
 	Kafka        []KafkaIntegration        `json:"kafka" yaml:"kafka" hcl:"kafka,block"`
 	Line         []LineIntegration         `json:"line" yaml:"line" hcl:"line,block"`
+	Mqtt         []MqttIntegration         `json:"mqtt" yaml:"mqtt" hcl:"mqtt,block"`
 	Opsgenie     []OpsgenieIntegration     `json:"opsgenie" yaml:"opsgenie" hcl:"opsgenie,block"`
 	Pagerduty    []PagerdutyIntegration    `json:"pagerduty" yaml:"pagerduty" hcl:"pagerduty,block"`
 	OnCall       []OnCallIntegration       `json:"oncall" yaml:"oncall" hcl:"oncall,block"`
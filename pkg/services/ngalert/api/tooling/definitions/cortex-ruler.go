@@ -66,6 +66,21 @@ import (
 //       200: AlertingFileExport
 //       404: description: Not found.
 
+// swagger:route POST /api/ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostPrometheusRulesForImport
+//
+// Imports the rule groups of a Prometheus or Loki rule file as Grafana-managed rule groups. The body is the rule file in YAML or JSON format.
+// Rule groups of the folder that have the same name as an imported group are replaced.
+//
+//     Consumes:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       202: ImportRulesResponse
+//       400: ValidationError
+//       404: description: Not found.
+//       409: description: A rule of another group in the folder has the same title as an imported rule.
+
 // swagger:route POST /api/ruler/{DatasourceUID}/api/v1/rules/{Namespace} ruler RoutePostNameRulesConfig
 //
 // Creates or updates a rule group
@@ -150,6 +165,38 @@ import (
 //       202: Ack
 //       404: NotFound
 
+// swagger:route Get /api/ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersionsGrafana
+//
+// List the versions of a rule, the most recent first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       404: description: Not found.
+
+// swagger:route Get /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff ruler RouteGetRuleVersionsDiffGrafana
+//
+// Compare two versions of a rule field by field
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionsDiff
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route POST /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RoutePostRestoreRuleVersionGrafana
+//
+// Restore a version of a rule
+//
+//     Responses:
+//       202: UpdateRuleGroupResponse
+//       400: ValidationError
+//       404: description: Not found.
+
 // swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig RoutePostRulesGroupForExport
 type NamespaceConfig struct {
 	// in:path
@@ -158,6 +205,20 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RoutePostPrometheusRulesForImport
+type ImportRulesParams struct {
+	// in:path
+	Namespace string
+	// The UID of the Prometheus or Loki data source that the queries of the imported rules use.
+	// in:query
+	// required:true
+	DatasourceUID string `json:"datasourceUid"`
+	// If true, nothing is saved and the response describes the changes the import would make.
+	// in:query
+	// required:false
+	DryRun bool `json:"dryRun"`
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// in: path
@@ -172,6 +233,32 @@ type PathRouleGroupConfig struct {
 	Groupname string
 }
 
+// swagger:parameters RouteGetRuleVersionsGrafana
+type PathRuleVersionsParams struct {
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RouteGetRuleVersionsDiffGrafana
+type RuleVersionsDiffParams struct {
+	// in: path
+	RuleUID string
+	// The version to compare against.
+	// in: query
+	Base int64 `json:"base"`
+	// The version to compare. Defaults to the most recent version.
+	// in: query
+	New int64 `json:"new"`
+}
+
+// swagger:parameters RoutePostRestoreRuleVersionGrafana
+type PathRestoreRuleVersionParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+}
+
 // swagger:parameters RouteGetRulesConfig RouteGetGrafanaRulesConfig
 type PathGetRulesParams struct {
 	// in: query
@@ -190,9 +277,12 @@ type NamespaceConfigResponse map[string][]GettableRuleGroupConfig
 
 // swagger:model
 type PostableRuleGroupConfig struct {
-	Name     string                     `yaml:"name" json:"name"`
-	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
-	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// SequentialEvaluation makes Grafana evaluate the rules of the group one after another in the order of the group.
+	// Rules can then use the latest results of the rules that are evaluated before them.
+	SequentialEvaluation bool                       `yaml:"sequential_evaluation,omitempty" json:"sequential_evaluation,omitempty"`
+	Rules                []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -231,15 +321,20 @@ func (c *PostableRuleGroupConfig) validate() error {
 	if hasGrafRules && hasLotexRules {
 		return fmt.Errorf("cannot mix Grafana & Prometheus style rules")
 	}
+
+	if hasLotexRules && c.SequentialEvaluation {
+		return fmt.Errorf("sequential evaluation is only supported by Grafana managed rules")
+	}
 	return nil
 }
 
 // swagger:model
 type GettableRuleGroupConfig struct {
-	Name          string                     `yaml:"name" json:"name"`
-	Interval      model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
-	SourceTenants []string                   `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
-	Rules         []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	Name                 string                     `yaml:"name" json:"name"`
+	Interval             model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
+	SourceTenants        []string                   `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
+	SequentialEvaluation bool                       `yaml:"sequential_evaluation,omitempty" json:"sequential_evaluation,omitempty"`
+	Rules                []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -398,6 +493,9 @@ type PostableGrafanaRule struct {
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused     *bool               `json:"is_paused" yaml:"is_paused"`
+	Record       *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 }
 
 // swagger:model
@@ -418,6 +516,47 @@ type GettableGrafanaRule struct {
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	Provenance      Provenance          `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	Record          *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+}
+
+// Record defines how a recording rule writes its result.
+// swagger:model
+type Record struct {
+	// Name of the metric the result is written to.
+	// required: true
+	// example: grafana_requests:rate5m
+	Metric string `json:"metric" yaml:"metric"`
+	// RefID of the query or expression whose result is written.
+	// required: true
+	// example: A
+	From string `json:"from" yaml:"from"`
+}
+
+// AlertRuleNotificationSettings sends the alerts of a rule directly to a contact point instead of routing them
+// through the notification policy tree.
+// swagger:model
+type AlertRuleNotificationSettings struct {
+	// Name of the receiver to send notifications to.
+	// required: true
+	// example: grafana-default-email
+	Receiver string `json:"receiver" yaml:"receiver"`
+	// Labels to group alerts by. If empty, the grouping of the default notification policy is used.
+	// example: ["alertname", "grafana_folder"]
+	GroupBy []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	// Time to wait before sending the first notification of a new group. If empty, the value of the default notification policy is used.
+	// example: 30s
+	GroupWait *model.Duration `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	// Time to wait before sending a notification about new alerts of a group. If empty, the value of the default notification policy is used.
+	// example: 5m
+	GroupInterval *model.Duration `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	// Time to wait before resending a notification. If empty, the value of the default notification policy is used.
+	// example: 4h
+	RepeatInterval *model.Duration `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+	// Names of the mute timings that mute the notifications.
+	// example: ["maintenance"]
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -487,6 +626,40 @@ func (d *Duration) UnmarshalYAML(unmarshal func(any) error) error {
 	}
 }
 
+// swagger:model
+type GettableRuleVersions []GettableRuleVersion
+
+// GettableRuleVersion is a version of a Grafana managed rule.
+type GettableRuleVersion struct {
+	Version       int64 `json:"version"`
+	ParentVersion int64 `json:"parentVersion"`
+	// RestoredFrom is the version that was restored by this version, if any.
+	RestoredFrom int64     `json:"restoredFrom,omitempty"`
+	Created      time.Time `json:"created"`
+	// CreatedBy is the ID of the user who made the change, or 0 if it was made by provisioning or by Grafana.
+	CreatedBy int64 `json:"createdBy"`
+	// Changes are the paths of the fields that changed compared to the parent version.
+	Changes []string                 `json:"changes,omitempty"`
+	Rule    GettableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type RuleVersionsDiff struct {
+	Base  int64                  `json:"base"`
+	New   int64                  `json:"new"`
+	Diffs []RuleVersionFieldDiff `json:"diffs"`
+}
+
+// RuleVersionFieldDiff is the difference of a single field between two versions of a rule.
+type RuleVersionFieldDiff struct {
+	// Field is the path of the field, for example, `Labels[team]` or `Data[0].Model`.
+	Field string `json:"field"`
+	// Base is the value of the field in the base version. It is absent if the field was added.
+	Base any `json:"base,omitempty"`
+	// New is the value of the field in the new version. It is absent if the field was removed.
+	New any `json:"new,omitempty"`
+}
+
 // swagger:model
 type UpdateRuleGroupResponse struct {
 	Message string   `json:"message"`
@@ -494,3 +667,20 @@ type UpdateRuleGroupResponse struct {
 	Updated []string `json:"updated,omitempty"`
 	Deleted []string `json:"deleted,omitempty"`
 }
+
+// swagger:model
+type ImportRulesResponse struct {
+	Message string              `json:"message"`
+	DryRun  bool                `json:"dryRun"`
+	Groups  []ImportedRuleGroup `json:"groups"`
+}
+
+// ImportedRuleGroup describes the changes an import makes to a rule group. The rules are identified by their title.
+type ImportedRuleGroup struct {
+	Name    string   `json:"name"`
+	Created []string `json:"created,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+	// Group is the rule group as it is after the import.
+	Group GettableRuleGroupConfig `json:"group"`
+}
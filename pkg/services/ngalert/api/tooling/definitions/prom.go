@@ -16,6 +16,15 @@ import (
 //     Responses:
 //       200: RuleResponse
 
+// swagger:route GET /api/prometheus/grafana/api/v1/rules/profiles prometheus RouteGetGrafanaRuleEvaluationProfiles
+//
+// gets the evaluation statistics of the rules, the most expensive rules first
+//
+//     Responses:
+//       200: RuleEvaluationProfilesResponse
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route GET /api/prometheus/{DatasourceUID}/api/v1/rules prometheus RouteGetRuleStatuses
 //
 // gets the evaluation statuses of all rules
@@ -47,6 +56,68 @@ type RuleResponse struct {
 	Data RuleDiscovery `json:"data"`
 }
 
+// swagger:parameters RouteGetGrafanaRuleEvaluationProfiles
+type GetRuleEvaluationProfilesParams struct {
+	// Order of the rules: load, duration, query or series.
+	// in: query
+	// required: false
+	// default: load
+	Sort string `json:"sort"`
+	// Maximum number of rules to return.
+	// in: query
+	// required: false
+	// default: 100
+	Limit int64 `json:"limit"`
+}
+
+// swagger:model
+type RuleEvaluationProfilesResponse struct {
+	// in: body
+	DiscoveryBase
+	// in: body
+	Data []RuleEvaluationProfile `json:"data"`
+}
+
+// RuleEvaluationProfile contains the evaluation statistics of a rule since it was last updated.
+// The durations are in seconds.
+// swagger:model
+type RuleEvaluationProfile struct {
+	UID             string `json:"uid"`
+	Title           string `json:"title"`
+	FolderUID       string `json:"folderUid"`
+	RuleGroup       string `json:"ruleGroup"`
+	Version         int64  `json:"version"`
+	IntervalSeconds int64  `json:"intervalSeconds"`
+	Evaluations     int64  `json:"evaluations"`
+	Failures        int64  `json:"failures"`
+	// Load is the fraction of time the rule is being evaluated: the average duration divided by the interval.
+	Load                      float64   `json:"load"`
+	LastEvaluation            time.Time `json:"lastEvaluation"`
+	LastDuration              float64   `json:"lastDuration"`
+	AverageDuration           float64   `json:"averageDuration"`
+	MaxDuration               float64   `json:"maxDuration"`
+	AverageQueryDuration      float64   `json:"averageQueryDuration"`
+	AverageExpressionDuration float64   `json:"averageExpressionDuration"`
+	// LastSeries is the number of series returned by the queries in the last evaluation.
+	LastSeries int `json:"lastSeries"`
+	// LastResults is the number of alert instances of the last evaluation.
+	LastResults int                         `json:"lastResults"`
+	Nodes       []RuleNodeEvaluationProfile `json:"nodes"`
+}
+
+// RuleNodeEvaluationProfile contains the execution statistics of a query or expression of a rule.
+// The durations are in seconds.
+// swagger:model
+type RuleNodeEvaluationProfile struct {
+	RefID           string  `json:"refId"`
+	IsQuery         bool    `json:"isQuery"`
+	Executions      int64   `json:"executions"`
+	LastDuration    float64 `json:"lastDuration"`
+	AverageDuration float64 `json:"averageDuration"`
+	MaxDuration     float64 `json:"maxDuration"`
+	LastSeries      int     `json:"lastSeries"`
+}
+
 // swagger:model
 type AlertResponse struct {
 	// in: body
@@ -148,6 +148,8 @@ type ProvisionedAlertRule struct {
 	ExecErrState ExecutionErrorState `json:"execErrState"`
 	// required: true
 	For model.Duration `json:"for"`
+	// example: 5m
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -156,6 +158,10 @@ type ProvisionedAlertRule struct {
 	Provenance Provenance `json:"provenance,omitempty"`
 	// example: false
 	IsPaused bool `json:"isPaused"`
+	// Record is set for recording rules.
+	Record *Record `json:"record,omitempty"`
+	// NotificationSettings sends the alerts of the rule directly to a contact point.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty"`
 }
 
 // swagger:route GET /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -215,21 +221,24 @@ type AlertRuleGroupMetadata struct {
 
 // swagger:model
 type AlertRuleGroup struct {
-	Title     string                 `json:"title"`
-	FolderUID string                 `json:"folderUid"`
-	Interval  int64                  `json:"interval"`
-	Rules     []ProvisionedAlertRule `json:"rules"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	Interval  int64  `json:"interval"`
+	// SequentialEvaluation makes Grafana evaluate the rules of the group one after another in the order of the group.
+	SequentialEvaluation bool                   `json:"sequentialEvaluation,omitempty"`
+	Rules                []ProvisionedAlertRule `json:"rules"`
 }
 
 // AlertRuleGroupExport is the provisioned file export of AlertRuleGroupV1.
 type AlertRuleGroupExport struct {
-	OrgID           int64             `json:"orgId" yaml:"orgId" hcl:"org_id"`
-	Name            string            `json:"name" yaml:"name" hcl:"name"`
-	Folder          string            `json:"folder" yaml:"folder"`
-	FolderUID       string            `json:"-" yaml:"-" hcl:"folder_uid"`
-	Interval        model.Duration    `json:"interval" yaml:"interval"`
-	IntervalSeconds int64             `json:"-" yaml:"-" hcl:"interval_seconds"`
-	Rules           []AlertRuleExport `json:"rules" yaml:"rules" hcl:"rule,block"`
+	OrgID                int64             `json:"orgId" yaml:"orgId" hcl:"org_id"`
+	Name                 string            `json:"name" yaml:"name" hcl:"name"`
+	Folder               string            `json:"folder" yaml:"folder"`
+	FolderUID            string            `json:"-" yaml:"-" hcl:"folder_uid"`
+	Interval             model.Duration    `json:"interval" yaml:"interval"`
+	IntervalSeconds      int64             `json:"-" yaml:"-" hcl:"interval_seconds"`
+	SequentialEvaluation bool              `json:"sequentialEvaluation,omitempty" yaml:"sequentialEvaluation,omitempty"`
+	Rules                []AlertRuleExport `json:"rules" yaml:"rules" hcl:"rule,block"`
 }
 
 // AlertRuleExport is the provisioned file export of models.AlertRule.
@@ -246,10 +255,31 @@ type AlertRuleExport struct {
 	// ForString is used to:
 	// - Only export the for field for HCL if it is non-zero.
 	// - Format the Prometheus model.Duration type properly for HCL.
-	ForString   *string            `json:"-" yaml:"-" hcl:"for"`
-	Annotations *map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
-	Labels      *map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
-	IsPaused    bool               `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
+	ForString           *string            `json:"-" yaml:"-" hcl:"for"`
+	KeepFiringFor       model.Duration     `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	KeepFiringForString *string            `json:"-" yaml:"-" hcl:"keep_firing_for"`
+	Annotations         *map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
+	Labels              *map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
+	IsPaused            bool               `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
+	Record              *AlertRecordExport `json:"record,omitempty" yaml:"record,omitempty" hcl:"record,block"`
+
+	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
+}
+
+// AlertRecordExport is the provisioned export of models.Record.
+type AlertRecordExport struct {
+	Metric string `json:"metric" yaml:"metric" hcl:"metric"`
+	From   string `json:"from" yaml:"from" hcl:"from"`
+}
+
+// AlertRuleNotificationSettingsExport is the provisioned export of models.NotificationSettings.
+type AlertRuleNotificationSettingsExport struct {
+	Receiver          string   `yaml:"receiver,omitempty" json:"receiver,omitempty" hcl:"contact_point"`
+	GroupBy           []string `yaml:"group_by,omitempty" json:"group_by,omitempty" hcl:"group_by"`
+	GroupWait         *string  `yaml:"group_wait,omitempty" json:"group_wait,omitempty" hcl:"group_wait,optional"`
+	GroupInterval     *string  `yaml:"group_interval,omitempty" json:"group_interval,omitempty" hcl:"group_interval,optional"`
+	RepeatInterval    *string  `yaml:"repeat_interval,omitempty" json:"repeat_interval,omitempty" hcl:"repeat_interval,optional"`
+	MuteTimeIntervals []string `yaml:"mute_time_intervals,omitempty" json:"mute_time_intervals,omitempty" hcl:"mute_timings"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
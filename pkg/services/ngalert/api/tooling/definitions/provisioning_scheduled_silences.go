@@ -0,0 +1,121 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// swagger:route GET /api/v1/provisioning/scheduled-silences provisioning stable RouteGetScheduledSilences
+//
+// Get all the scheduled silences.
+//
+//     Responses:
+//       200: ScheduledSilences
+
+// swagger:route GET /api/v1/provisioning/scheduled-silences/{UID} provisioning stable RouteGetScheduledSilence
+//
+// Get a scheduled silence.
+//
+//     Responses:
+//       200: ScheduledSilence
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/scheduled-silences provisioning stable RoutePostScheduledSilence
+//
+// Create a new scheduled silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: ScheduledSilence
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/scheduled-silences/{UID} provisioning stable RoutePutScheduledSilence
+//
+// Replace an existing scheduled silence.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: ScheduledSilence
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/scheduled-silences/{UID} provisioning stable RouteDeleteScheduledSilence
+//
+// Delete a scheduled silence and expire its current silence.
+//
+//     Responses:
+//       204: description: The scheduled silence was deleted successfully.
+
+// swagger:parameters RouteGetScheduledSilence RoutePutScheduledSilence RouteDeleteScheduledSilence
+type ScheduledSilenceUIDParam struct {
+	// Scheduled silence UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostScheduledSilence RoutePutScheduledSilence
+type ScheduledSilencePayload struct {
+	// in:body
+	Body ScheduledSilence
+}
+
+// swagger:model
+type ScheduledSilences []ScheduledSilence
+
+// ScheduledSilence is a silence that Grafana creates ahead of every window of its schedule.
+// swagger:model
+type ScheduledSilence struct {
+	// required: false
+	UID string `json:"uid"`
+	// required: true
+	Matchers amv2.Matchers `json:"matchers"`
+	// required: true
+	Comment string `json:"comment"`
+	// The silences are created on behalf of this user.
+	// required: false
+	CreatedBy string `json:"createdBy"`
+	// The start of the first window.
+	// required: true
+	StartsAt time.Time `json:"startsAt"`
+	// The end of the first window.
+	// required: true
+	EndsAt time.Time `json:"endsAt"`
+	// If not set, the silence has only one window.
+	// required: false
+	Repeat *ScheduledSilenceRepeat `json:"repeat,omitempty"`
+	// The ID of the silence created for the current or the next window.
+	// readonly: true
+	SilenceID string `json:"silenceId,omitempty"`
+	// readonly: true
+	Provenance Provenance `json:"provenance,omitempty"`
+}
+
+// ScheduledSilenceRepeat defines how the windows of a scheduled silence repeat. The next windows start at the same
+// local time in TimeZone as the first one.
+type ScheduledSilenceRepeat struct {
+	// required: true
+	// enum: daily,weekly,monthly
+	Frequency string `json:"frequency"`
+	// The number of days, weeks or months between two windows. Defaults to 1.
+	// required: false
+	Interval int `json:"interval,omitempty"`
+	// No windows start after this time.
+	// required: false
+	Until *time.Time `json:"until,omitempty"`
+	// The IANA time zone. Defaults to UTC.
+	// required: false
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+func (s *ScheduledSilence) ResourceType() string {
+	return "scheduledSilence"
+}
+
+func (s *ScheduledSilence) ResourceID() string {
+	return s.UID
+}
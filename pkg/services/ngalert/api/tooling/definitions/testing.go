@@ -68,6 +68,21 @@ import (
 //     Responses:
 //       200: BacktestResult
 
+// swagger:route Post /api/v1/rule/backtest/batch testing BacktestBatch
+//
+// Test a rule group over historical data and compare the results with the state history of its rules
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestBatchResult
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -233,3 +248,55 @@ type BacktestConfig struct {
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:parameters BacktestBatch
+type BacktestBatchRequest struct {
+	// in:body
+	Body BacktestBatchConfig
+}
+
+// swagger:model
+type BacktestBatchConfig struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// FolderUID is the UID of the folder of the rule group.
+	FolderUID string `json:"folderUid"`
+	// RuleGroup is the rule group to test. The results of rules that have the UID of an existing rule are compared
+	// with the state history of that rule, which allows testing proposed changes of existing rules.
+	RuleGroup PostableRuleGroupConfig `json:"group"`
+	// FlapWindow is the time after an alert resolves within which a new firing episode is counted as a flap.
+	// Defaults to five evaluation intervals of the rule.
+	FlapWindow model.Duration `json:"flapWindow,omitempty"`
+}
+
+// swagger:model
+type BacktestBatchResult struct {
+	Rules []BacktestRuleResult `json:"rules"`
+}
+
+type BacktestRuleResult struct {
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title"`
+	// Error is set if the rule could not be tested.
+	Error string `json:"error,omitempty"`
+	// Warning is set if the results were compared with an incomplete state history.
+	Warning  string         `json:"warning,omitempty"`
+	Backtest BacktestStats  `json:"backtest"`
+	History  *BacktestStats `json:"history,omitempty"`
+	// Diff is the difference between the backtest and the history.
+	Diff *BacktestStats `json:"diff,omitempty"`
+	// Instances contains the alert instances whose results differ from the history.
+	Instances []BacktestInstanceResult `json:"instances,omitempty"`
+}
+
+type BacktestStats struct {
+	FiringEpisodes int `json:"firingEpisodes"`
+	Notifications  int `json:"notifications"`
+	Flaps          int `json:"flaps"`
+}
+
+type BacktestInstanceResult struct {
+	Labels   map[string]string `json:"labels"`
+	Backtest BacktestStats     `json:"backtest"`
+	History  BacktestStats     `json:"history"`
+}
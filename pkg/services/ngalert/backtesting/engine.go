@@ -37,14 +37,21 @@ type stateManager interface {
 	ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *models.AlertRule, results eval.Results, extraLabels data.Labels) []state.StateTransition
 }
 
+// historian queries the state history of alert rules.
+type historian interface {
+	Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+}
+
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
 	createStateManager func() stateManager
+	history            historian
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer, history historian) *Engine {
 	return &Engine{
 		evalFactory: evalFactory,
+		history:     history,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:                 nil,
@@ -63,37 +70,15 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 }
 
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
-	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
-	logger := logger.FromContext(ctx)
-
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
-	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
-	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
-
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
+	length, err := evaluations(rule, from, to)
 	if err != nil {
-		return nil, errors.Join(ErrInvalidInputData, err)
+		return nil, err
 	}
 
-	stateManager := e.createStateManager()
-
-	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
-
-	start := time.Now()
-
 	tsField := data.NewField("Time", nil, make([]time.Time, length))
 	valueFields := make(map[string]*data.Field)
 
-	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
-		if idx >= length {
-			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
-			return nil
-		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+	err = e.replay(ctx, user, rule, from, to, length, nil, func(idx int, currentTime time.Time, states []state.StateTransition) {
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -110,20 +95,158 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 				continue
 			}
 		}
-		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
 	fields := make([]*data.Field, 0, len(valueFields)+1)
 	fields = append(fields, tsField)
 	for _, f := range valueFields {
 		fields = append(fields, f)
 	}
-	result := data.NewFrame("Testing results", fields...)
+	return data.NewFrame("Testing results", fields...), nil
+}
 
+// evaluations returns the number of evaluations of the rule in the time range.
+func evaluations(rule *models.AlertRule, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds), nil
+}
+
+// replay evaluates the rule the given number of times starting at from and passes the state transitions of every
+// evaluation to the callback.
+func (e *Engine) replay(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, length int, extraLabels data.Labels, callback func(idx int, now time.Time, states []state.StateTransition)) error {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ctx)
+
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
 	if err != nil {
-		return nil, err
+		return errors.Join(ErrInvalidInputData, err)
+	}
+
+	stateManager := e.createStateManager()
+
+	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
+
+	start := time.Now()
+
+	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
+		if idx >= length {
+			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
+			return nil
+		}
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels)
+		callback(idx, currentTime, states)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
-	return result, nil
+	return nil
+}
+
+// BatchRule is a rule that is tested by TestBatch.
+type BatchRule struct {
+	Rule *models.AlertRule
+	// ExtraLabels are added to the labels of the alert instances.
+	ExtraLabels data.Labels
+	// HistoryRuleUID is the UID of the rule whose state history the results are compared with.
+	// If it is empty, the results are not compared.
+	HistoryRuleUID string
+}
+
+// TestBatch evaluates every rule over the time range and compares the results with the state history of the rule
+// identified by BatchRule.HistoryRuleUID. A firing episode that starts no later than flapWindow after the previous
+// episode of the same alert instance resolved is a flap. If flapWindow is not positive, defaultFlapWindowIntervals
+// evaluation intervals of the rule are used. Rules are tested independently, so an error of a rule is reported
+// in its RuleReport.
+func (e *Engine) TestBatch(ctx context.Context, user identity.Requester, rules []BatchRule, from, to time.Time, flapWindow time.Duration) []RuleReport {
+	reports := make([]RuleReport, 0, len(rules))
+	for _, r := range rules {
+		window := flapWindow
+		if window <= 0 {
+			window = defaultFlapWindowIntervals * time.Duration(r.Rule.IntervalSeconds) * time.Second
+		}
+		report := RuleReport{Rule: r.Rule}
+		if r.Rule.Type() == models.RuleTypeRecording {
+			report.Error = fmt.Errorf("%w: recording rules cannot be backtested", ErrInvalidInputData)
+			reports = append(reports, report)
+			continue
+		}
+
+		length, err := evaluations(r.Rule, from, to)
+		if err != nil {
+			report.Error = err
+			reports = append(reports, report)
+			continue
+		}
+
+		instances := make(map[string]*instanceHistory)
+		err = e.replay(ctx, user, r.Rule, from, to, length, r.ExtraLabels, func(_ int, now time.Time, states []state.StateTransition) {
+			for _, s := range states {
+				key, lbls := instanceKey(s.Labels)
+				h, ok := instances[key]
+				if !ok {
+					h = &instanceHistory{labels: lbls, initial: s.PreviousState}
+					instances[key] = h
+				}
+				h.add(now, s.State.State)
+			}
+		})
+		if err != nil {
+			report.Error = err
+			reports = append(reports, report)
+			continue
+		}
+
+		var history map[string]*instanceHistory
+		if r.HistoryRuleUID != "" {
+			var truncated bool
+			history, truncated, err = e.queryHistory(ctx, user, r.Rule.OrgID, r.HistoryRuleUID, from, to)
+			if err != nil {
+				report.Error = err
+				reports = append(reports, report)
+				continue
+			}
+			if truncated {
+				report.Warning = fmt.Sprintf("the state history has more than %d entries in the time range and only %d of them were compared, use a shorter time range", historyQueryLimit, historyQueryLimit)
+			}
+		}
+		report.compare(instances, history, r.HistoryRuleUID != "", window)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// queryHistory returns the state history of the rule. It also reports whether the history was truncated because it
+// has more entries than historyQueryLimit.
+func (e *Engine) queryHistory(ctx context.Context, user identity.Requester, orgID int64, ruleUID string, from, to time.Time) (map[string]*instanceHistory, bool, error) {
+	if e.history == nil {
+		return nil, false, errors.New("state history is not available")
+	}
+	frame, err := e.history.Query(ctx, models.HistoryQuery{
+		RuleUID:      ruleUID,
+		OrgID:        orgID,
+		From:         from,
+		To:           to,
+		Limit:        historyQueryLimit,
+		SignedInUser: user,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query state history: %w", err)
+	}
+	history, err := parseHistory(frame)
+	if err != nil {
+		return nil, false, err
+	}
+	return history, frame != nil && frame.Rows() >= historyQueryLimit, nil
 }
 
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingEvaluator, error) {
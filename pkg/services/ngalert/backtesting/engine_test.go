@@ -403,3 +403,145 @@ func (f *fakeBacktestingEvaluator) Eval(_ context.Context, from time.Time, inter
 	}
 	return nil
 }
+
+func TestEngineTestBatch(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	from := time.Unix(0, 0)
+	to := from.Add(10 * time.Second)
+	lbls := data.Labels{"alertname": "test", "instance": "a", "__alert_rule_uid__": "backtesting-uid"}
+
+	// the instance fires at the first and third evaluation
+	pattern := []eval.State{eval.Normal, eval.Alerting, eval.Normal, eval.Alerting, eval.Normal}
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			idx := int(now.Sub(from).Seconds())
+			current, previous := eval.Normal, eval.Normal
+			if idx < len(pattern) {
+				current = pattern[idx]
+			}
+			if idx > 0 && idx-1 < len(pattern) {
+				previous = pattern[idx-1]
+			}
+			return []state.StateTransition{{
+				State:         &state.State{Labels: lbls, State: current},
+				PreviousState: previous,
+			}}
+		},
+	}
+
+	history := &fakeHistorian{
+		frame: annotationHistoryFrame(
+			annotationEntry{at: from.Add(time.Second), text: "test {alertname=test, instance=a} - A=1.000000", prev: "Normal", next: "Alerting"},
+			annotationEntry{at: from.Add(2 * time.Second), text: "test {alertname=test, instance=a} - A=0.000000", prev: "Alerting", next: "Normal"},
+		),
+	}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+		history: history,
+	}
+	newRule := func() *models.AlertRule {
+		return models.AlertRuleGen(models.WithInterval(time.Second), models.WithOrgID(1))()
+	}
+
+	t.Run("should count firing episodes, notifications and flaps", func(t *testing.T) {
+		reports := engine.TestBatch(context.Background(), nil, []BatchRule{{Rule: newRule()}}, from, to, 0)
+		require.Len(t, reports, 1)
+		require.NoError(t, reports[0].Error)
+		require.Equal(t, Stats{FiringEpisodes: 2, Notifications: 4, Flaps: 1}, reports[0].Backtest)
+		require.Nil(t, reports[0].History)
+		require.Empty(t, reports[0].Instances)
+	})
+
+	t.Run("should use the flap window", func(t *testing.T) {
+		reports := engine.TestBatch(context.Background(), nil, []BatchRule{{Rule: newRule()}}, from, to, time.Millisecond)
+		require.Equal(t, Stats{FiringEpisodes: 2, Notifications: 4, Flaps: 0}, reports[0].Backtest)
+	})
+
+	t.Run("should compare with the state history", func(t *testing.T) {
+		rule := newRule()
+		reports := engine.TestBatch(context.Background(), nil, []BatchRule{{Rule: rule, HistoryRuleUID: "existing"}}, from, to, 0)
+		require.Len(t, reports, 1)
+		require.NoError(t, reports[0].Error)
+		require.Equal(t, "existing", history.query.RuleUID)
+		require.Equal(t, rule.OrgID, history.query.OrgID)
+		require.Equal(t, from, history.query.From)
+		require.Equal(t, to, history.query.To)
+		require.Equal(t, &Stats{FiringEpisodes: 1, Notifications: 2}, reports[0].History)
+		require.Equal(t, []InstanceDiff{{
+			Labels:   data.Labels{"alertname": "test", "instance": "a"},
+			Backtest: Stats{FiringEpisodes: 2, Notifications: 4, Flaps: 1},
+			History:  Stats{FiringEpisodes: 1, Notifications: 2},
+		}}, reports[0].Instances)
+	})
+
+	t.Run("should warn if the state history reaches the query limit", func(t *testing.T) {
+		entries := make([]annotationEntry, 0, historyQueryLimit)
+		for i := 0; i < historyQueryLimit; i++ {
+			prev, next := "Normal", "Alerting"
+			if i%2 == 1 {
+				prev, next = next, prev
+			}
+			entries = append(entries, annotationEntry{at: from.Add(time.Duration(i) * time.Millisecond), text: "test {alertname=test, instance=a} - A=1.000000", prev: prev, next: next})
+		}
+		limited := &Engine{
+			createStateManager: engine.createStateManager,
+			history:            &fakeHistorian{frame: annotationHistoryFrame(entries...)},
+		}
+		reports := limited.TestBatch(context.Background(), nil, []BatchRule{{Rule: newRule(), HistoryRuleUID: "existing"}}, from, to, 0)
+		require.Len(t, reports, 1)
+		require.NoError(t, reports[0].Error)
+		require.NotNil(t, reports[0].History)
+		require.Contains(t, reports[0].Warning, "more than 5000 entries")
+
+		reports = engine.TestBatch(context.Background(), nil, []BatchRule{{Rule: newRule(), HistoryRuleUID: "existing"}}, from, to, 0)
+		require.Empty(t, reports[0].Warning)
+	})
+
+	t.Run("should report errors per rule", func(t *testing.T) {
+		failing := &Engine{
+			createStateManager: engine.createStateManager,
+			history:            &fakeHistorian{err: errors.New("test-error")},
+		}
+		recording := newRule()
+		recording.Record = &models.Record{Metric: "metric", From: "A"}
+		reports := failing.TestBatch(context.Background(), nil, []BatchRule{
+			{Rule: newRule(), HistoryRuleUID: "existing"},
+			{Rule: recording},
+			{Rule: newRule()},
+		}, from, to, 0)
+		require.Len(t, reports, 3)
+		require.ErrorContains(t, reports[0].Error, "test-error")
+		require.ErrorIs(t, reports[1].Error, ErrInvalidInputData)
+		require.NoError(t, reports[2].Error)
+	})
+
+	t.Run("should fail if the state history is not available", func(t *testing.T) {
+		noHistory := &Engine{createStateManager: engine.createStateManager}
+		reports := noHistory.TestBatch(context.Background(), nil, []BatchRule{{Rule: newRule(), HistoryRuleUID: "existing"}}, from, to, 0)
+		require.ErrorContains(t, reports[0].Error, "state history is not available")
+	})
+}
+
+type fakeHistorian struct {
+	query models.HistoryQuery
+	frame *data.Frame
+	err   error
+}
+
+func (f *fakeHistorian) Query(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.query = query
+	return f.frame, f.err
+}
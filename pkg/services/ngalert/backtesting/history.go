@@ -0,0 +1,173 @@
+package backtesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+type historyEntry struct {
+	at     time.Time
+	labels data.Labels
+	prev   eval.State
+	next   eval.State
+}
+
+// parseHistory converts the frame returned by the state historian into the histories of alert instances.
+// It supports the formats of the Loki and annotation historians. Entries that cannot be parsed are skipped.
+func parseHistory(frame *data.Frame) (map[string]*instanceHistory, error) {
+	result := make(map[string]*instanceHistory)
+	if frame == nil || frame.Rows() == 0 {
+		return result, nil
+	}
+
+	var entries []historyEntry
+	var err error
+	switch {
+	case hasFields(frame, "time", "line"):
+		entries, err = parseLokiHistory(frame)
+	case hasFields(frame, "time", "text", "prev", "next"):
+		entries, err = parseAnnotationHistory(frame)
+	default:
+		return nil, fmt.Errorf("unsupported format of the state history")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].at.Before(entries[j].at)
+	})
+	for _, e := range entries {
+		key, lbls := instanceKey(e.labels)
+		h, ok := result[key]
+		if !ok {
+			h = &instanceHistory{labels: lbls, initial: e.prev}
+			result[key] = h
+		}
+		h.add(e.at, e.next)
+	}
+	return result, nil
+}
+
+func parseLokiHistory(frame *data.Frame) ([]historyEntry, error) {
+	times, lines := fieldByName(frame, "time"), fieldByName(frame, "line")
+	entries := make([]historyEntry, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		at, ok := times.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of the time field %s", times.Type())
+		}
+		line, ok := lines.At(i).(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of the line field %s", lines.Type())
+		}
+		var entry struct {
+			Previous string            `json:"previous"`
+			Current  string            `json:"current"`
+			Labels   map[string]string `json:"labels"`
+		}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			logger.Debug("Skipping state history entry that cannot be parsed", "error", err)
+			continue
+		}
+		prev, ok := parseState(entry.Previous)
+		if !ok {
+			continue
+		}
+		next, ok := parseState(entry.Current)
+		if !ok {
+			continue
+		}
+		entries = append(entries, historyEntry{at: at, labels: entry.Labels, prev: prev, next: next})
+	}
+	return entries, nil
+}
+
+func parseAnnotationHistory(frame *data.Frame) ([]historyEntry, error) {
+	times, texts := fieldByName(frame, "time"), fieldByName(frame, "text")
+	prevs, nexts := fieldByName(frame, "prev"), fieldByName(frame, "next")
+	entries := make([]historyEntry, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		at, ok := times.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of the time field %s", times.Type())
+		}
+		text, _ := texts.At(i).(string)
+		lbls, err := annotationLabels(text)
+		if err != nil {
+			logger.Debug("Skipping state history entry that cannot be parsed", "error", err)
+			continue
+		}
+		prevText, _ := prevs.At(i).(string)
+		prev, ok := parseState(prevText)
+		if !ok {
+			continue
+		}
+		nextText, _ := nexts.At(i).(string)
+		next, ok := parseState(nextText)
+		if !ok {
+			continue
+		}
+		entries = append(entries, historyEntry{at: at, labels: lbls, prev: prev, next: next})
+	}
+	return entries, nil
+}
+
+// annotationLabels parses the labels from the text of a state history annotation, which has the format
+// "<title> {<labels>} - <values>".
+func annotationLabels(text string) (data.Labels, error) {
+	end := strings.LastIndex(text, "} - ")
+	if end < 0 {
+		return nil, fmt.Errorf("no labels in annotation text %q", text)
+	}
+	start := strings.LastIndex(text[:end], "{")
+	if start < 0 {
+		return nil, fmt.Errorf("no labels in annotation text %q", text)
+	}
+	lbls, err := data.LabelsFromString(text[start+1 : end])
+	if err != nil {
+		return nil, err
+	}
+	if lbls == nil {
+		lbls = data.Labels{}
+	}
+	return lbls, nil
+}
+
+// parseState parses a state formatted by state.FormatStateAndReason. The reason is ignored.
+func parseState(s string) (eval.State, bool) {
+	if idx := strings.Index(s, " ("); idx >= 0 {
+		s = s[:idx]
+	}
+	for _, st := range []eval.State{eval.Normal, eval.Alerting, eval.Pending, eval.NoData, eval.Error} {
+		if st.String() == s {
+			return st, true
+		}
+	}
+	return eval.Normal, false
+}
+
+func hasFields(frame *data.Frame, names ...string) bool {
+	for _, name := range names {
+		if fieldByName(frame, name) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldByName(frame *data.Frame, name string) *data.Field {
+	for _, f := range frame.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package backtesting
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+type annotationEntry struct {
+	at   time.Time
+	text string
+	prev string
+	next string
+}
+
+func annotationHistoryFrame(entries ...annotationEntry) *data.Frame {
+	frame := data.NewFrame("states",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("text", nil, []string{}),
+		data.NewField("prev", nil, []string{}),
+		data.NewField("next", nil, []string{}),
+		data.NewField("data", nil, []string{}),
+	)
+	for _, e := range entries {
+		frame.AppendRow(e.at, e.text, e.prev, e.next, "{}")
+	}
+	return frame
+}
+
+func TestParseHistory(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("should return empty history for empty frame", func(t *testing.T) {
+		result, err := parseHistory(data.NewFrame("states"))
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("should fail for unknown format", func(t *testing.T) {
+		_, err := parseHistory(data.NewFrame("states", data.NewField("value", nil, []string{"test"})))
+		require.Error(t, err)
+	})
+
+	t.Run("should parse annotation history", func(t *testing.T) {
+		frame := annotationHistoryFrame(
+			annotationEntry{at: now.Add(2 * time.Minute), text: "rule {alertname=rule, instance=a} - A=0.000000", prev: "Alerting", next: "Normal (MissingSeries)"},
+			annotationEntry{at: now, text: "rule {alertname=rule, instance=a} - A=1.000000", prev: "Pending", next: "Alerting"},
+			annotationEntry{at: now, text: "rule {alertname=rule, instance=b} - No data", prev: "Normal", next: "NoData"},
+			annotationEntry{at: now, text: "unparseable", prev: "Normal", next: "Alerting"},
+			annotationEntry{at: now, text: "rule {alertname=rule, instance=c} - Error", prev: "Normal", next: "Unknown"},
+		)
+
+		result, err := parseHistory(frame)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		a := result["instance=a"]
+		require.NotNil(t, a)
+		require.Equal(t, data.Labels{"alertname": "rule", "instance": "a"}, a.labels)
+		require.Equal(t, eval.Pending, a.initial)
+		require.Equal(t, []stateChange{
+			{at: now, state: eval.Alerting},
+			{at: now.Add(2 * time.Minute), state: eval.Normal},
+		}, a.changes)
+
+		b := result["instance=b"]
+		require.NotNil(t, b)
+		require.Equal(t, []stateChange{{at: now, state: eval.NoData}}, b.changes)
+	})
+
+	t.Run("should parse loki history", func(t *testing.T) {
+		line := func(prev, next string, lbls map[string]string) json.RawMessage {
+			b, err := json.Marshal(map[string]any{"previous": prev, "current": next, "labels": lbls})
+			require.NoError(t, err)
+			return b
+		}
+		lbls := map[string]string{"alertname": "rule", "instance": "a", "grafana_folder": "folder"}
+		frame := data.NewFrame("states",
+			data.NewField("time", nil, []time.Time{now, now.Add(time.Minute)}),
+			data.NewField("line", nil, []json.RawMessage{
+				line("Normal", "Alerting", lbls),
+				line("Alerting", "Normal (Paused)", lbls),
+			}),
+			data.NewField("labels", nil, []json.RawMessage{json.RawMessage("{}"), json.RawMessage("{}")}),
+		)
+
+		result, err := parseHistory(frame)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		a := result["instance=a"]
+		require.NotNil(t, a)
+		require.Equal(t, data.Labels(lbls), a.labels)
+		require.Equal(t, eval.Normal, a.initial)
+		require.Equal(t, []stateChange{
+			{at: now, state: eval.Alerting},
+			{at: now.Add(time.Minute), state: eval.Normal},
+		}, a.changes)
+	})
+}
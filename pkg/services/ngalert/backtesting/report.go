@@ -0,0 +1,169 @@
+package backtesting
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// defaultFlapWindowIntervals is the number of evaluation intervals used as the flap window if it is not specified.
+	defaultFlapWindowIntervals = 5
+	// historyQueryLimit is the maximum number of state history entries that are compared with the results of a rule.
+	historyQueryLimit = 5000
+)
+
+// Stats summarizes the notifications of alert instances.
+type Stats struct {
+	// FiringEpisodes is the number of times alert instances started firing.
+	FiringEpisodes int
+	// Notifications is the number of firing and resolved notifications. Repeated notifications are not counted.
+	Notifications int
+	// Flaps is the number of firing episodes that started within the flap window after the previous episode resolved.
+	Flaps int
+}
+
+func (s *Stats) add(other Stats) {
+	s.FiringEpisodes += other.FiringEpisodes
+	s.Notifications += other.Notifications
+	s.Flaps += other.Flaps
+}
+
+// InstanceDiff contains the stats of an alert instance whose backtest results differ from its state history.
+type InstanceDiff struct {
+	Labels   data.Labels
+	Backtest Stats
+	History  Stats
+}
+
+// RuleReport is the result of the batch backtesting of a rule.
+type RuleReport struct {
+	Rule     *models.AlertRule
+	Backtest Stats
+	// History is nil if the results were not compared with the state history.
+	History *Stats
+	// Instances contains only the alert instances whose stats differ.
+	Instances []InstanceDiff
+	// Warning is set if the results were compared with an incomplete state history.
+	Warning string
+	Error   error
+}
+
+// compare sets the stats of the report. If compareHistory is false, the history is ignored.
+func (r *RuleReport) compare(backtest, history map[string]*instanceHistory, compareHistory bool, flapWindow time.Duration) {
+	for _, h := range backtest {
+		r.Backtest.add(h.stats(flapWindow))
+	}
+	if !compareHistory {
+		return
+	}
+
+	r.History = &Stats{}
+	for _, h := range history {
+		r.History.add(h.stats(flapWindow))
+	}
+
+	keys := make(map[string]struct{}, len(backtest)+len(history))
+	for key := range backtest {
+		keys[key] = struct{}{}
+	}
+	for key := range history {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		diff := InstanceDiff{}
+		if h, ok := backtest[key]; ok {
+			diff.Labels = h.labels
+			diff.Backtest = h.stats(flapWindow)
+		}
+		if h, ok := history[key]; ok {
+			diff.Labels = h.labels
+			diff.History = h.stats(flapWindow)
+		}
+		if diff.Backtest != diff.History {
+			r.Instances = append(r.Instances, diff)
+		}
+	}
+}
+
+type stateChange struct {
+	at    time.Time
+	state eval.State
+}
+
+// instanceHistory is the sequence of states of an alert instance.
+type instanceHistory struct {
+	labels  data.Labels
+	initial eval.State
+	changes []stateChange
+}
+
+// add records the state of the instance at the given time. It is ignored if the state did not change.
+func (h *instanceHistory) add(at time.Time, s eval.State) {
+	last := h.initial
+	if len(h.changes) > 0 {
+		last = h.changes[len(h.changes)-1].state
+	}
+	if last == s {
+		return
+	}
+	h.changes = append(h.changes, stateChange{at: at, state: s})
+}
+
+func (h *instanceHistory) stats(flapWindow time.Duration) Stats {
+	result := Stats{}
+	firing := h.initial == eval.Alerting
+	if firing {
+		result.FiringEpisodes++
+		result.Notifications++
+	}
+	var resolvedAt *time.Time
+	for _, c := range h.changes {
+		switch {
+		case !firing && c.state == eval.Alerting:
+			firing = true
+			result.FiringEpisodes++
+			result.Notifications++
+			if resolvedAt != nil && c.at.Sub(*resolvedAt) <= flapWindow {
+				result.Flaps++
+			}
+		case firing && c.state != eval.Alerting:
+			firing = false
+			result.Notifications++
+			at := c.at
+			resolvedAt = &at
+		}
+	}
+	return result
+}
+
+// instanceKey returns the key that identifies an alert instance in the backtest results and the state history,
+// and the labels of the instance without private labels. The title and folder of the rule are not part of the key,
+// so the instances of a rule can be compared with the history of the rule before it was renamed or moved.
+func instanceKey(lbls data.Labels) (string, data.Labels) {
+	result := make(data.Labels, len(lbls))
+	key := make(data.Labels, len(lbls))
+	for k, v := range lbls {
+		if strings.HasPrefix(k, "__") && strings.HasSuffix(k, "__") {
+			continue
+		}
+		result[k] = v
+		if k == prometheusModel.AlertNameLabel || k == models.FolderTitleLabel {
+			continue
+		}
+		key[k] = v
+	}
+	return key.String(), result
+}
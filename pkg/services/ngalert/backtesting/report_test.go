@@ -0,0 +1,106 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+func TestInstanceHistoryStats(t *testing.T) {
+	start := time.Unix(0, 0)
+	history := func(initial eval.State, states ...eval.State) *instanceHistory {
+		h := &instanceHistory{initial: initial}
+		for i, s := range states {
+			h.add(start.Add(time.Duration(i)*time.Minute), s)
+		}
+		return h
+	}
+
+	testCases := []struct {
+		name     string
+		history  *instanceHistory
+		expected Stats
+	}{
+		{
+			name:    "no changes",
+			history: history(eval.Normal, eval.Normal, eval.Pending, eval.Normal),
+		},
+		{
+			name:     "firing from the start",
+			history:  history(eval.Alerting, eval.Alerting),
+			expected: Stats{FiringEpisodes: 1, Notifications: 1},
+		},
+		{
+			name:     "firing and resolved",
+			history:  history(eval.Normal, eval.Pending, eval.Alerting, eval.Alerting, eval.Normal),
+			expected: Stats{FiringEpisodes: 1, Notifications: 2},
+		},
+		{
+			name:     "firing within the flap window",
+			history:  history(eval.Normal, eval.Alerting, eval.Normal, eval.Alerting, eval.NoData, eval.Alerting),
+			expected: Stats{FiringEpisodes: 3, Notifications: 5, Flaps: 2},
+		},
+		{
+			name:     "firing after the flap window",
+			history:  history(eval.Normal, eval.Alerting, eval.Normal, eval.Normal, eval.Normal, eval.Alerting),
+			expected: Stats{FiringEpisodes: 2, Notifications: 3},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.history.stats(2*time.Minute))
+		})
+	}
+}
+
+func TestInstanceKey(t *testing.T) {
+	key, lbls := instanceKey(data.Labels{
+		"alertname":          "rule",
+		"grafana_folder":     "folder",
+		"instance":           "a",
+		"__alert_rule_uid__": "uid",
+	})
+	require.Equal(t, "instance=a", key)
+	require.Equal(t, data.Labels{"alertname": "rule", "grafana_folder": "folder", "instance": "a"}, lbls)
+
+	renamed, _ := instanceKey(data.Labels{"alertname": "renamed", "grafana_folder": "other", "instance": "a"})
+	require.Equal(t, key, renamed)
+}
+
+func TestRuleReportCompare(t *testing.T) {
+	start := time.Unix(0, 0)
+	firing := func(lbls data.Labels) *instanceHistory {
+		h := &instanceHistory{labels: lbls}
+		h.add(start, eval.Alerting)
+		return h
+	}
+	a, b := data.Labels{"instance": "a"}, data.Labels{"instance": "b"}
+
+	t.Run("should not compare without history", func(t *testing.T) {
+		r := RuleReport{}
+		r.compare(map[string]*instanceHistory{"a": firing(a)}, nil, false, time.Minute)
+		require.Equal(t, Stats{FiringEpisodes: 1, Notifications: 1}, r.Backtest)
+		require.Nil(t, r.History)
+		require.Empty(t, r.Instances)
+	})
+
+	t.Run("should return only instances that differ", func(t *testing.T) {
+		r := RuleReport{}
+		r.compare(
+			map[string]*instanceHistory{"a": firing(a), "b": firing(b)},
+			map[string]*instanceHistory{"a": firing(a), "c": firing(data.Labels{"instance": "c"})},
+			true,
+			time.Minute,
+		)
+		require.Equal(t, Stats{FiringEpisodes: 2, Notifications: 2}, r.Backtest)
+		require.Equal(t, &Stats{FiringEpisodes: 2, Notifications: 2}, r.History)
+		require.Equal(t, []InstanceDiff{
+			{Labels: b, Backtest: Stats{FiringEpisodes: 1, Notifications: 1}},
+			{Labels: data.Labels{"instance": "c"}, History: Stats{FiringEpisodes: 1, Notifications: 1}},
+		}, r.Instances)
+	})
+}
@@ -10,6 +10,9 @@ import (
 type EvaluationContext struct {
 	Ctx  context.Context
 	User identity.Requester
+	// RuleResults provides the latest results of alert rules to rule result queries. If it is nil, rule result
+	// queries return no data.
+	RuleResults RuleResultsReader
 }
 
 func NewContext(ctx context.Context, user identity.Requester) EvaluationContext {
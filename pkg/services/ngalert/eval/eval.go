@@ -73,7 +73,14 @@ func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (re
 		defer cancel()
 		execCtx = timeoutCtx
 	}
-	return r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	stats := evaluationStatsFromContext(ctx)
+	if stats == nil {
+		return r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	}
+	execStats := &expr.ExecutionStats{}
+	resp, err = r.expressionService.ExecutePipeline(expr.WithExecutionStats(execCtx, execStats), now, r.pipeline)
+	stats.setNodes(execStats, resp)
+	return resp, err
 }
 
 // Evaluate evaluates the condition and converts the response to Results
@@ -83,7 +90,11 @@ func (r *conditionEvaluator) Evaluate(ctx context.Context, now time.Time) (Resul
 		return nil, err
 	}
 	execResults := queryDataResponseToExecutionResults(r.condition, response)
-	return evaluateExecutionResult(execResults, now), nil
+	results := evaluateExecutionResult(execResults, now)
+	if stats := evaluationStatsFromContext(ctx); stats != nil {
+		stats.Results = len(results)
+	}
+	return results, nil
 }
 
 type evaluatorImpl struct {
@@ -279,6 +290,15 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 	datasources := make(map[string]*datasources.DataSource, len(data))
 
 	for _, q := range data {
+		if q.IsRuleResultQuery() {
+			query, err := getRuleResultQuery(ctx, q)
+			if err != nil {
+				return nil, err
+			}
+			req.Queries = append(req.Queries, query)
+			continue
+		}
+
 		model, err := q.GetModel()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model from '%s': %w", q.RefID, err)
@@ -717,7 +737,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 			if !found {
 				return fmt.Errorf("datasource refID %s could not be found: %w", query.RefID, plugins.ErrPluginUnavailable)
 			}
-		case expr.TypeCMDNode:
+		case expr.TypeCMDNode, expr.TypeInputNode:
 		}
 	}
 	_, err = e.create(condition, req)
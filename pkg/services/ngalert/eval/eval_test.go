@@ -769,6 +769,43 @@ func TestEvaluateRaw(t *testing.T) {
 	})
 }
 
+func TestEvaluationStats(t *testing.T) {
+	t.Run("should record the number of results", func(t *testing.T) {
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					return &backend.QueryDataResponse{
+						Responses: backend.Responses{
+							"A": {Frames: data.Frames{
+								data.NewFrame("", data.NewField("Value", data.Labels{"foo": "1"}, []*float64{util.Pointer(1.0)})),
+								data.NewFrame("", data.NewField("Value", data.Labels{"foo": "2"}, []*float64{util.Pointer(0.0)})),
+							}},
+						},
+					}, nil
+				},
+			},
+			condition:   models.Condition{Condition: "A", Data: []models.AlertQuery{{RefID: "A", DatasourceUID: expr.DatasourceUID}}},
+			evalTimeout: -1,
+		}
+		stats := &EvaluationStats{}
+		results, err := e.Evaluate(WithEvaluationStats(context.Background(), stats), time.Now())
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Equal(t, 2, stats.Results)
+	})
+
+	t.Run("should sum the statistics of queries and expressions", func(t *testing.T) {
+		stats := EvaluationStats{Nodes: []NodeStats{
+			{RefID: "A", IsQuery: true, Duration: 2 * time.Second, Series: 10},
+			{RefID: "B", IsQuery: true, Duration: time.Second, Series: 5},
+			{RefID: "C", Duration: 100 * time.Millisecond, Series: 15},
+		}}
+		require.Equal(t, 3*time.Second, stats.QueryDuration())
+		require.Equal(t, 100*time.Millisecond, stats.ExpressionDuration())
+		require.Equal(t, 15, stats.Series())
+	})
+}
+
 type fakeExpressionService struct {
 	hook func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error)
 }
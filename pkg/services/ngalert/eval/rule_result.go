@@ -0,0 +1,75 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleResult is the latest result of an alert instance of a rule.
+type RuleResult struct {
+	Labels data.Labels
+	State  State
+	// Values are the values of the queries and expressions of the rule, mapped by RefID.
+	Values map[string]float64
+}
+
+// RuleResultsReader provides the latest results of alert rules.
+type RuleResultsReader interface {
+	GetRuleResults(orgID int64, ruleUID string) []RuleResult
+}
+
+// getRuleResultQuery converts a query that uses the latest result of another rule into a query of the expression
+// service whose data is the numeric value of each alert instance of the rule.
+func getRuleResultQuery(ctx EvaluationContext, q models.AlertQuery) (expr.Query, error) {
+	ref, err := q.GetRuleResultQuery()
+	if err != nil {
+		return expr.Query{}, fmt.Errorf("failed to build query '%s': %w", q.RefID, err)
+	}
+	ds, err := expr.DataSourceModelFromNodeType(expr.TypeInputNode)
+	if err != nil {
+		return expr.Query{}, fmt.Errorf("failed to build query '%s': %w", q.RefID, err)
+	}
+	var frames data.Frames
+	if ctx.RuleResults != nil {
+		frames = ruleResultsToFrames(ref, ctx.RuleResults.GetRuleResults(ctx.User.GetOrgID(), ref.RuleUID))
+	}
+	return expr.Query{
+		RefID:      q.RefID,
+		DataSource: ds,
+		JSON:       q.Model,
+		Frames:     frames,
+	}, nil
+}
+
+// ruleResultsToFrames returns a numeric multi frame per result. If the query uses the state of the rule, the value is
+// 1 if the alert instance is firing and 0 otherwise. If it uses the value of an expression, results that do not have
+// the value are skipped.
+func ruleResultsToFrames(ref models.RuleResultQuery, results []RuleResult) data.Frames {
+	frames := make(data.Frames, 0, len(results))
+	for _, r := range results {
+		var v float64
+		switch ref.Result {
+		case models.RuleResultValue:
+			value, ok := r.Values[ref.Expression]
+			if !ok {
+				continue
+			}
+			v = value
+		default:
+			if r.State == Alerting {
+				v = 1
+			}
+		}
+		frame := data.NewFrame("", data.NewField("", r.Labels.Copy(), []float64{v}))
+		frame.SetMeta(&data.FrameMeta{
+			Type:        data.FrameTypeNumericMulti,
+			TypeVersion: data.FrameTypeVersion{0, 1},
+		})
+		frames = append(frames, frame)
+	}
+	return frames
+}
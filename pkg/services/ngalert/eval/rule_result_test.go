@@ -0,0 +1,122 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeRuleResultsReader map[string][]RuleResult
+
+func (f fakeRuleResultsReader) GetRuleResults(_ int64, ruleUID string) []RuleResult {
+	return f[ruleUID]
+}
+
+func TestRuleResultQuery(t *testing.T) {
+	results := fakeRuleResultsReader{
+		"service-down": {
+			{Labels: data.Labels{"service": "api"}, State: Alerting, Values: map[string]float64{"B": 3}},
+			{Labels: data.Labels{"service": "web"}, State: Normal, Values: map[string]float64{"B": 0}},
+			{Labels: data.Labels{"service": "db"}, State: Pending},
+		},
+		"deployment": {
+			{Labels: data.Labels{"service": "web"}, State: Alerting},
+		},
+	}
+
+	math := func(refID, expression string) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(`{"type": "math", "expression": "` + expression + `"}`),
+		}
+	}
+
+	evaluate := func(t *testing.T, reader RuleResultsReader, condition models.Condition) Results {
+		t.Helper()
+		factory := NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, &fakes.FakeCacheService{}, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, &featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest()), &pluginstore.FakePluginStore{})
+		evalCtx := NewContext(context.Background(), &user.SignedInUser{OrgID: 1})
+		evalCtx.RuleResults = reader
+		require.NoError(t, factory.Validate(evalCtx, condition))
+		ev, err := factory.Create(evalCtx, condition)
+		require.NoError(t, err)
+		res, err := ev.Evaluate(context.Background(), time.Now())
+		require.NoError(t, err)
+		return res
+	}
+
+	statesByService := func(res Results) map[string]State {
+		m := make(map[string]State, len(res))
+		for _, r := range res {
+			m[r.Instance["service"]] = r.State
+		}
+		return m
+	}
+
+	t.Run("uses the state of a rule", func(t *testing.T) {
+		res := evaluate(t, results, models.Condition{
+			Condition: "B",
+			Data: []models.AlertQuery{
+				models.CreateRuleResultQuery("A", "service-down", models.RuleResultState, ""),
+				math("B", "$A == 1"),
+			},
+		})
+		require.Equal(t, map[string]State{"api": Alerting, "web": Normal, "db": Normal}, statesByService(res))
+	})
+
+	t.Run("uses the value of an expression of a rule", func(t *testing.T) {
+		res := evaluate(t, results, models.Condition{
+			Condition: "B",
+			Data: []models.AlertQuery{
+				models.CreateRuleResultQuery("A", "service-down", models.RuleResultValue, "B"),
+				math("B", "$A > 1"),
+			},
+		})
+		require.Equal(t, map[string]State{"api": Alerting, "web": Normal}, statesByService(res))
+	})
+
+	t.Run("can combine the results of several rules", func(t *testing.T) {
+		res := evaluate(t, results, models.Condition{
+			Condition: "C",
+			Data: []models.AlertQuery{
+				models.CreateRuleResultQuery("A", "service-down", models.RuleResultState, ""),
+				models.CreateRuleResultQuery("B", "deployment", models.RuleResultState, ""),
+				math("C", "$A == 1 && $B == 0"),
+			},
+		})
+		// only the services that are in the results of both rules are evaluated
+		require.Equal(t, map[string]State{"web": Normal}, statesByService(res))
+	})
+
+	t.Run("is no data if the rule has no results", func(t *testing.T) {
+		res := evaluate(t, nil, models.Condition{
+			Condition: "B",
+			Data: []models.AlertQuery{
+				models.CreateRuleResultQuery("A", "service-down", models.RuleResultState, ""),
+				math("B", "$A == 1"),
+			},
+		})
+		require.Len(t, res, 1)
+		require.Equal(t, NoData, res[0].State)
+	})
+
+	t.Run("fails if the query is invalid", func(t *testing.T) {
+		_, err := getExprRequest(NewContext(context.Background(), &user.SignedInUser{}), []models.AlertQuery{
+			{RefID: "A", DatasourceUID: models.RuleResultDatasourceUID, Model: json.RawMessage(`{}`)},
+		}, &fakes.FakeCacheService{})
+		require.ErrorContains(t, err, "failed to build query 'A'")
+	})
+}
@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+// EvaluationStats contains the statistics of an evaluation of a condition. It is filled by the evaluator
+// if it is passed in the context by WithEvaluationStats.
+type EvaluationStats struct {
+	// Nodes contains the statistics of the queries and expressions in the order of execution.
+	Nodes []NodeStats
+	// Results is the number of results of the evaluation, which is the number of alert instances.
+	// It is zero if the condition is evaluated by EvaluateRaw.
+	Results int
+}
+
+// NodeStats contains the statistics of the execution of a query or expression.
+type NodeStats struct {
+	RefID string
+	// IsQuery is true if the node is a query of a data source, and false if it is an expression.
+	IsQuery  bool
+	Duration time.Duration
+	// Series is the number of data frames returned by the node. This is the number of series for most data sources.
+	Series int
+}
+
+// QueryDuration returns the time spent executing the queries.
+func (s *EvaluationStats) QueryDuration() time.Duration {
+	var d time.Duration
+	for _, n := range s.Nodes {
+		if n.IsQuery {
+			d += n.Duration
+		}
+	}
+	return d
+}
+
+// ExpressionDuration returns the time spent executing the expressions.
+func (s *EvaluationStats) ExpressionDuration() time.Duration {
+	var d time.Duration
+	for _, n := range s.Nodes {
+		if !n.IsQuery {
+			d += n.Duration
+		}
+	}
+	return d
+}
+
+// Series returns the number of series returned by the queries.
+func (s *EvaluationStats) Series() int {
+	var series int
+	for _, n := range s.Nodes {
+		if n.IsQuery {
+			series += n.Series
+		}
+	}
+	return series
+}
+
+type evaluationStatsKey struct{}
+
+// WithEvaluationStats returns a context that makes the evaluator record the statistics of the evaluation in stats.
+func WithEvaluationStats(ctx context.Context, stats *EvaluationStats) context.Context {
+	return context.WithValue(ctx, evaluationStatsKey{}, stats)
+}
+
+func evaluationStatsFromContext(ctx context.Context) *EvaluationStats {
+	stats, _ := ctx.Value(evaluationStatsKey{}).(*EvaluationStats)
+	return stats
+}
+
+// setNodes sets the statistics of the nodes from the statistics of the execution of the pipeline and its response.
+func (s *EvaluationStats) setNodes(execStats *expr.ExecutionStats, resp *backend.QueryDataResponse) {
+	nodes := execStats.Nodes()
+	s.Nodes = make([]NodeStats, 0, len(nodes))
+	for _, n := range nodes {
+		stats := NodeStats{
+			RefID:    n.RefID,
+			IsQuery:  n.NodeType != expr.TypeCMDNode,
+			Duration: n.Duration,
+		}
+		if resp != nil {
+			stats.Series = len(resp.Responses[n.RefID].Frames)
+		}
+		s.Nodes = append(s.Nodes, stats)
+	}
+}
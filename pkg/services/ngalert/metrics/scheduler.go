@@ -27,6 +27,9 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	RecordingWrites                     *prometheus.CounterVec
+	RecordingWriteFailures              *prometheus.CounterVec
+	RecordingWriteDuration              *prometheus.HistogramVec
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -143,5 +146,33 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		RecordingWrites: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_writes_total",
+				Help:      "The total number of recording rule results written to the remote write endpoint.",
+			},
+			[]string{"org"},
+		),
+		RecordingWriteFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_write_failures_total",
+				Help:      "The total number of recording rule results that failed to be written to the remote write endpoint.",
+			},
+			[]string{"org"},
+		),
+		RecordingWriteDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "recording_rule_write_duration_seconds",
+				Help:      "The time to write the result of a recording rule.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 15, 30},
+			},
+			[]string{"org"},
+		),
 	}
 }
@@ -312,6 +312,10 @@ func (ms *migrationStore) RevertAllOrgs(ctx context.Context) error {
 				return err
 			}
 
+			if _, err := sess.Exec("DELETE FROM alert_rule_state"); err != nil {
+				return err
+			}
+
 			if _, err := sess.Exec("DELETE FROM kv_store WHERE namespace = ?", notifier.KVNamespace); err != nil {
 				return err
 			}
@@ -278,7 +278,7 @@ func (aq *AlertQuery) PreSave() error {
 		return err
 	}
 
-	if ok := isExpression || aq.RelativeTimeRange.isValid(); !ok {
+	if ok := isExpression || aq.IsRuleResultQuery() || aq.RelativeTimeRange.isValid(); !ok {
 		return fmt.Errorf("invalid relative time range: %+v", aq.RelativeTimeRange)
 	}
 	return nil
@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
@@ -21,6 +22,8 @@ import (
 var (
 	// ErrAlertRuleNotFound is an error for an unknown alert rule.
 	ErrAlertRuleNotFound = fmt.Errorf("could not find alert rule")
+	// ErrAlertRuleVersionNotFound is an error for an unknown version of an alert rule.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
 	// ErrAlertRuleFailedGenerateUniqueUID is an error for failure to generate alert rule UID
 	ErrAlertRuleFailedGenerateUniqueUID = errors.New("failed to generate alert rule UID")
 	// ErrCannotEditNamespace is an error returned if the user does not have permissions to edit the namespace
@@ -114,6 +117,7 @@ const (
 	StateReasonPaused        = "Paused"
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
+	StateReasonKeepFiring    = "KeepFiring"
 )
 
 var (
@@ -135,7 +139,9 @@ type AlertRuleGroup struct {
 	FolderUID  string
 	Interval   int64
 	Provenance Provenance
-	Rules      []AlertRule
+	// SequentialEvaluation is set if the rules of the group are evaluated one after another. See AlertRule.SequentialEvaluation.
+	SequentialEvaluation bool
+	Rules                []AlertRule
 }
 
 // AlertRuleGroupWithFolderTitle extends AlertRuleGroup with orgID and folder title
@@ -148,15 +154,18 @@ type AlertRuleGroupWithFolderTitle struct {
 func NewAlertRuleGroupWithFolderTitle(groupKey AlertRuleGroupKey, rules []AlertRule, folderTitle string) AlertRuleGroupWithFolderTitle {
 	SortAlertRulesByGroupIndex(rules)
 	var interval int64
+	var sequential bool
 	if len(rules) > 0 {
 		interval = rules[0].IntervalSeconds
+		sequential = rules[0].SequentialEvaluation
 	}
 	var result = AlertRuleGroupWithFolderTitle{
 		AlertRuleGroup: &AlertRuleGroup{
-			Title:     groupKey.RuleGroup,
-			FolderUID: groupKey.NamespaceUID,
-			Interval:  interval,
-			Rules:     rules,
+			Title:                groupKey.RuleGroup,
+			FolderUID:            groupKey.NamespaceUID,
+			Interval:             interval,
+			SequentialEvaluation: sequential,
+			Rules:                rules,
 		},
 		FolderTitle: folderTitle,
 		OrgID:       groupKey.OrgID,
@@ -202,10 +211,47 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For           time.Duration
+	KeepFiringFor time.Duration
+	Annotations   map[string]string
+	Labels        map[string]string
+	IsPaused      bool
+	// Record is set for recording rules. Recording rules write the result of a query or expression
+	// as a new metric instead of producing alerts.
+	Record *Record `xorm:"record"`
+	// NotificationSettings is set if the alerts of the rule are sent to a receiver directly
+	// instead of being routed by the notification policy tree.
+	NotificationSettings *NotificationSettings `xorm:"notification_settings"`
+	// SequentialEvaluation is set if the rules of the group are evaluated one after another in the order of the group
+	// so that a rule can use the results of the rules evaluated before it. It is the same for all rules of a group.
+	SequentialEvaluation bool `xorm:"sequential_evaluation"`
+	// UpdatedBy is the ID of the user who made the last change to the rule. It is 0 if the rule was changed by
+	// provisioning or by Grafana itself.
+	UpdatedBy int64 `xorm:"updated_by"`
+}
+
+// RuleType is the type of the alert rule.
+type RuleType string
+
+const (
+	RuleTypeAlerting  RuleType = "alerting"
+	RuleTypeRecording RuleType = "recording"
+)
+
+// Record contains the information needed to evaluate a recording rule.
+type Record struct {
+	// Metric is the name of the metric the result is written to.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose result is written.
+	From string `json:"from"`
+}
+
+func (r *Record) FromDB(data []byte) error {
+	return json.Unmarshal(data, r)
+}
+
+func (r *Record) ToDB() ([]byte, error) {
+	return json.Marshal(r)
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -217,6 +263,8 @@ type AlertRuleWithOptionals struct {
 	// This parameter is to know if an optional API field was sent and, therefore, patch it with the current field from
 	// DB in case it was not sent.
 	HasPause bool
+	// HasRecord is true if the API request defined whether the rule is a recording rule.
+	HasRecord bool
 }
 
 // AlertsRulesBy is a function that defines the ordering of alert rules.
@@ -289,7 +337,23 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// Type returns whether the rule is an alerting or a recording rule.
+func (alertRule *AlertRule) Type() RuleType {
+	if alertRule.Record != nil {
+		return RuleTypeRecording
+	}
+	return RuleTypeAlerting
+}
+
+// GetEvalCondition returns the condition to evaluate. For recording rules it points to the query or expression
+// whose result is written.
 func (alertRule *AlertRule) GetEvalCondition() Condition {
+	if alertRule.Type() == RuleTypeRecording {
+		return Condition{
+			Condition: alertRule.Record.From,
+			Data:      alertRule.Data,
+		}
+	}
 	return Condition{
 		Condition: alertRule.Condition,
 		Data:      alertRule.Data,
@@ -424,6 +488,17 @@ func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 	return nil
 }
 
+// AfterLoad is called by xorm after the rule is loaded from the database. Optional fields of rules that do not set them
+// are stored as JSON null, which xorm decodes into empty values. Those are reset to nil.
+func (alertRule *AlertRule) AfterLoad() {
+	if alertRule.Record != nil && *alertRule.Record == (Record{}) {
+		alertRule.Record = nil
+	}
+	if alertRule.NotificationSettings != nil && alertRule.NotificationSettings.Receiver == "" {
+		alertRule.NotificationSettings = nil
+	}
+}
+
 // ValidateAlertRule validates various alert rule fields.
 func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSettings) error {
 	if len(alertRule.Data) == 0 {
@@ -446,6 +521,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: cannot have Panel ID without a Dashboard UID", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.Type() == RuleTypeRecording {
+		return alertRule.validateRecord()
+	}
+
 	if _, err := ErrStateFromString(string(alertRule.ExecErrState)); err != nil {
 		return err
 	}
@@ -457,9 +536,53 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.NotificationSettings != nil {
+		if err := alertRule.NotificationSettings.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrAlertRuleFailedValidation, err)
+		}
+	}
+	return nil
+}
+
+func (alertRule *AlertRule) validateRecord() error {
+	if !IsValidMetricName(alertRule.Record.Metric) {
+		return fmt.Errorf("%w: %q is not a valid Prometheus metric name", ErrAlertRuleFailedValidation, alertRule.Record.Metric)
+	}
+	if alertRule.Record.From == "" {
+		return fmt.Errorf("%w: recording rule must specify the query or expression to record", ErrAlertRuleFailedValidation)
+	}
+	found := false
+	for _, q := range alertRule.Data {
+		if q.RefID == alertRule.Record.From {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: query or expression %s to record does not exist", ErrAlertRuleFailedValidation, alertRule.Record.From)
+	}
+	if alertRule.For != 0 {
+		return fmt.Errorf("%w: field `for` cannot be set on a recording rule", ErrAlertRuleFailedValidation)
+	}
+	if alertRule.KeepFiringFor != 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be set on a recording rule", ErrAlertRuleFailedValidation)
+	}
+	if alertRule.NotificationSettings != nil {
+		return fmt.Errorf("%w: notification settings cannot be set on a recording rule", ErrAlertRuleFailedValidation)
+	}
 	return nil
 }
 
+// IsValidMetricName reports whether name is a valid Prometheus metric name.
+func IsValidMetricName(name string) bool {
+	return model.IsValidMetricName(model.LabelValue(name))
+}
+
 func (alertRule *AlertRule) ResourceType() string {
 	return "alertRule"
 }
@@ -485,6 +608,7 @@ type AlertRuleVersion struct {
 	Version          int64
 
 	Created         time.Time
+	CreatedBy       int64 `xorm:"created_by"`
 	Title           string
 	Condition       string
 	Data            []AlertQuery
@@ -493,10 +617,59 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For                  time.Duration
+	KeepFiringFor        time.Duration
+	Annotations          map[string]string
+	Labels               map[string]string
+	IsPaused             bool
+	Record               *Record               `xorm:"record"`
+	NotificationSettings *NotificationSettings `xorm:"notification_settings"`
+	SequentialEvaluation bool                  `xorm:"sequential_evaluation"`
+}
+
+// AfterLoad is called by xorm after the version is loaded from the database. See AlertRule.AfterLoad.
+func (v *AlertRuleVersion) AfterLoad() {
+	if v.Record != nil && *v.Record == (Record{}) {
+		v.Record = nil
+	}
+	if v.NotificationSettings != nil && v.NotificationSettings.Receiver == "" {
+		v.NotificationSettings = nil
+	}
+}
+
+// ToAlertRule returns the alert rule as it was at the version. The dashboard and panel of the rule are not stored
+// with the version and can be set from the annotations.
+func (v *AlertRuleVersion) ToAlertRule() AlertRule {
+	return AlertRule{
+		OrgID:                v.RuleOrgID,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		Updated:              v.Created,
+		IntervalSeconds:      v.IntervalSeconds,
+		Version:              v.Version,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		RuleGroupIndex:       v.RuleGroupIndex,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		KeepFiringFor:        v.KeepFiringFor,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		IsPaused:             v.IsPaused,
+		Record:               v.Record,
+		NotificationSettings: v.NotificationSettings,
+		SequentialEvaluation: v.SequentialEvaluation,
+		UpdatedBy:            v.CreatedBy,
+	}
+}
+
+// GetAlertRuleVersionsQuery is the query for retrieving the versions of an alert rule by UID and organisation ID.
+type GetAlertRuleVersionsQuery struct {
+	UID   string
+	OrgID int64
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -560,6 +733,8 @@ type ListOrgRuleGroupsQuery struct {
 type UpdateRule struct {
 	Existing *AlertRule
 	New      AlertRule
+	// RestoredFrom is the version of the rule the update restores, if any.
+	RestoredFrom int64
 }
 
 // Condition contains backend expressions and queries and the RefID
@@ -612,6 +787,12 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
+	if !ruleToPatch.HasRecord {
+		ruleToPatch.Record = existingRule.Record
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -18,3 +18,33 @@ type HistoryQuery struct {
 	Limit        int
 	SignedInUser identity.Requester
 }
+
+// StateHistoryEntry is a state transition of an alert instance that is stored by the database state history backend.
+type StateHistoryEntry struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	RuleUID      string `xorm:"rule_uid"`
+	NamespaceUID string `xorm:"namespace_uid"`
+	RuleGroup    string `xorm:"rule_group"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	// Labels are the labels of the alert instance without private labels, encoded as a JSON object.
+	Labels     string `xorm:"labels"`
+	LabelsHash string `xorm:"labels_hash"`
+
+	PreviousState  string `xorm:"previous_state"`
+	PreviousReason string `xorm:"previous_reason"`
+	CurrentState   string `xorm:"current_state"`
+	CurrentReason  string `xorm:"current_reason"`
+	// Values are the values of the evaluation, encoded as a JSON object.
+	Values    string `xorm:"result_values"`
+	Error     string `xorm:"error"`
+	Condition string `xorm:"condition"`
+	// TransitionTime is the time of the evaluation that changed the state, in Unix milliseconds.
+	TransitionTime int64 `xorm:"transition_time"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *StateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
@@ -14,6 +14,16 @@ type AlertInstance struct {
 	CurrentStateSince time.Time
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
+	// KeepFiringSince is the time the alert started to keep firing, or zero if it does not keep firing.
+	KeepFiringSince time.Time
+}
+
+// KeepFiringSinceUnix returns the keep firing time as Unix seconds, or nil if it is zero, so that it is stored as NULL.
+func (a AlertInstance) KeepFiringSinceUnix() any {
+	if a.KeepFiringSince.IsZero() {
+		return nil
+	}
+	return a.KeepFiringSince.Unix()
 }
 
 type AlertInstanceKey struct {
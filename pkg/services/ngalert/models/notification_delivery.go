@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// NotificationDelivery is an attempt of an integration of a contact point to deliver a notification.
+type NotificationDelivery struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	OrgID    int64  `xorm:"org_id"`
+	Receiver string `xorm:"receiver"`
+	// Integration is the type of the integration, for example "slack".
+	Integration      string `xorm:"integration"`
+	IntegrationUID   string `xorm:"integration_uid"`
+	IntegrationIndex int    `xorm:"integration_index"`
+	// GroupKey is the key of the aggregation group of the alerts. It is empty for test notifications.
+	GroupKey string `xorm:"group_key"`
+	// AlertFingerprints are the fingerprints of the notified alerts, separated by commas.
+	AlertFingerprints string `xorm:"alert_fingerprints"`
+	// PayloadHash is the SHA-256 hash of the requests sent by the integration, if it sends webhooks.
+	PayloadHash string `xorm:"payload_hash"`
+	// StatusCode is the HTTP status code of the last response, or zero if the integration did not receive one.
+	StatusCode int `xorm:"status_code"`
+	// Duration is the time the attempt took, in milliseconds.
+	Duration int64  `xorm:"duration_ms"`
+	Error    string `xorm:"error"`
+	// Retry is the number of failed attempts to send the same notification before this attempt.
+	Retry       int       `xorm:"retry"`
+	Success     bool      `xorm:"success"`
+	AttemptedAt time.Time `xorm:"attempted_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (d *NotificationDelivery) TableName() string {
+	return "alert_notification_delivery"
+}
+
+// Fingerprints returns the fingerprints of the notified alerts.
+func (d *NotificationDelivery) Fingerprints() []string {
+	if d.AlertFingerprints == "" {
+		return nil
+	}
+	return strings.Split(d.AlertFingerprints, ",")
+}
+
+// ListNotificationDeliveriesQuery is the query for the notification delivery log of an organization.
+type ListNotificationDeliveriesQuery struct {
+	OrgID            int64
+	Receiver         string
+	Integration      string
+	IntegrationUID   string
+	AlertFingerprint string
+	// Success limits the result to successful or failed attempts if it is set.
+	Success *bool
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// AutogeneratedRouteLabel is added to alerts of rules that have notification settings.
+	// The auto-generated routes of the Alertmanager match on this label.
+	AutogeneratedRouteLabel = "__grafana_autogenerated__"
+	// AutogeneratedRouteReceiverNameLabel contains the name of the receiver of the rule's notification settings.
+	AutogeneratedRouteReceiverNameLabel = "__grafana_receiver__"
+	// AutogeneratedRouteSettingsHashLabel contains the fingerprint of the rule's notification settings.
+	// Rules with the same notification settings share the same auto-generated route.
+	AutogeneratedRouteSettingsHashLabel = "__grafana_route_settings_hash__"
+)
+
+var (
+	ErrNotificationSettingsInvalid = errors.New("invalid notification settings")
+)
+
+// NotificationSettings configures how the alerts of a rule are routed. If a rule has notification settings, its alerts
+// are sent to the receiver by a route that is generated for the settings instead of by the notification policy tree.
+type NotificationSettings struct {
+	// Receiver is the name of the contact point the alerts are sent to.
+	Receiver string `json:"receiver"`
+	// GroupBy, GroupWait, GroupInterval and RepeatInterval override the values of the root notification policy.
+	GroupBy           []string        `json:"group_by,omitempty"`
+	GroupWait         *model.Duration `json:"group_wait,omitempty"`
+	GroupInterval     *model.Duration `json:"group_interval,omitempty"`
+	RepeatInterval    *model.Duration `json:"repeat_interval,omitempty"`
+	MuteTimeIntervals []string        `json:"mute_time_intervals,omitempty"`
+}
+
+func (s *NotificationSettings) FromDB(data []byte) error {
+	return json.Unmarshal(data, s)
+}
+
+func (s *NotificationSettings) ToDB() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// Validate checks that the settings are well-formed. It does not check that the receiver and mute timings exist.
+func (s NotificationSettings) Validate() error {
+	if s.Receiver == "" {
+		return fmt.Errorf("%w: receiver must be specified", ErrNotificationSettingsInvalid)
+	}
+	if slices.Contains(s.GroupBy, "...") && len(s.GroupBy) > 1 {
+		return fmt.Errorf("%w: group_by cannot contain other labels when '...' is used", ErrNotificationSettingsInvalid)
+	}
+	for _, l := range s.GroupBy {
+		if l != "..." && !model.LabelName(l).IsValid() {
+			return fmt.Errorf("%w: invalid label name %q in group_by", ErrNotificationSettingsInvalid, l)
+		}
+	}
+	if s.GroupWait != nil && *s.GroupWait < 0 {
+		return fmt.Errorf("%w: group_wait cannot be negative", ErrNotificationSettingsInvalid)
+	}
+	if s.GroupInterval != nil && *s.GroupInterval <= 0 {
+		return fmt.Errorf("%w: group_interval must be positive", ErrNotificationSettingsInvalid)
+	}
+	if s.RepeatInterval != nil && *s.RepeatInterval <= 0 {
+		return fmt.Errorf("%w: repeat_interval must be positive", ErrNotificationSettingsInvalid)
+	}
+	for _, interval := range s.MuteTimeIntervals {
+		if interval == "" {
+			return fmt.Errorf("%w: mute time interval name cannot be empty", ErrNotificationSettingsInvalid)
+		}
+	}
+	return nil
+}
+
+// Fingerprint returns a hash of the settings that is stable across restarts.
+func (s NotificationSettings) Fingerprint() string {
+	h := fnv.New64()
+	tmp := make([]byte, 8)
+	// the hash never returns errors, therefore they are ignored
+	writeString := func(s string) {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{255})
+	}
+	writeDuration := func(d *model.Duration) {
+		if d == nil {
+			_, _ = h.Write([]byte{255})
+			return
+		}
+		binary.LittleEndian.PutUint64(tmp, uint64(*d))
+		_, _ = h.Write(tmp)
+	}
+	writeString(s.Receiver)
+	for _, l := range s.GroupBy {
+		writeString(l)
+	}
+	_, _ = h.Write([]byte{254})
+	writeDuration(s.GroupWait)
+	writeDuration(s.GroupInterval)
+	writeDuration(s.RepeatInterval)
+	for _, interval := range s.MuteTimeIntervals {
+		writeString(interval)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// ToLabels returns the labels that route the alerts of a rule to the auto-generated route of the settings.
+func (s NotificationSettings) ToLabels() map[string]string {
+	return map[string]string{
+		AutogeneratedRouteLabel:             "true",
+		AutogeneratedRouteReceiverNameLabel: s.Receiver,
+		AutogeneratedRouteSettingsHashLabel: s.Fingerprint(),
+	}
+}
+
+// NotificationSettingsValidator validates notification settings against the configuration of an Alertmanager.
+type NotificationSettingsValidator interface {
+	Validate(settings NotificationSettings) error
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationSettingsValidate(t *testing.T) {
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+
+	testCases := []struct {
+		name     string
+		settings NotificationSettings
+		err      string
+	}{
+		{
+			name:     "valid settings",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"alertname"}, GroupWait: duration(0), RepeatInterval: duration(time.Hour)},
+		},
+		{
+			name:     "group by all",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"..."}},
+		},
+		{
+			name:     "missing receiver",
+			settings: NotificationSettings{},
+			err:      "receiver must be specified",
+		},
+		{
+			name:     "group by all with other labels",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"...", "alertname"}},
+			err:      "group_by cannot contain other labels",
+		},
+		{
+			name:     "invalid label name",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"invalid-label"}},
+			err:      "invalid label name",
+		},
+		{
+			name:     "negative group wait",
+			settings: NotificationSettings{Receiver: "receiver", GroupWait: duration(-time.Second)},
+			err:      "group_wait cannot be negative",
+		},
+		{
+			name:     "zero group interval",
+			settings: NotificationSettings{Receiver: "receiver", GroupInterval: duration(0)},
+			err:      "group_interval must be positive",
+		},
+		{
+			name:     "zero repeat interval",
+			settings: NotificationSettings{Receiver: "receiver", RepeatInterval: duration(0)},
+			err:      "repeat_interval must be positive",
+		},
+		{
+			name:     "empty mute time interval",
+			settings: NotificationSettings{Receiver: "receiver", MuteTimeIntervals: []string{""}},
+			err:      "mute time interval name cannot be empty",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrNotificationSettingsInvalid)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestNotificationSettingsFingerprint(t *testing.T) {
+	wait := model.Duration(time.Minute)
+	settings := NotificationSettings{
+		Receiver:          "receiver",
+		GroupBy:           []string{"alertname", "grafana_folder"},
+		GroupWait:         &wait,
+		MuteTimeIntervals: []string{"weekends"},
+	}
+	require.Equal(t, settings.Fingerprint(), settings.Fingerprint())
+
+	changes := map[string]func(s *NotificationSettings){
+		"receiver":            func(s *NotificationSettings) { s.Receiver = "other" },
+		"group_by":            func(s *NotificationSettings) { s.GroupBy = []string{"alertname"} },
+		"group_wait":          func(s *NotificationSettings) { s.GroupWait = nil },
+		"group_interval":      func(s *NotificationSettings) { s.GroupInterval = &wait },
+		"repeat_interval":     func(s *NotificationSettings) { s.RepeatInterval = &wait },
+		"mute_time_intervals": func(s *NotificationSettings) { s.MuteTimeIntervals = nil },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			changed := settings
+			change(&changed)
+			require.NotEqual(t, settings.Fingerprint(), changed.Fingerprint())
+		})
+	}
+
+	t.Run("labels should contain the receiver and fingerprint", func(t *testing.T) {
+		require.Equal(t, map[string]string{
+			AutogeneratedRouteLabel:             "true",
+			AutogeneratedRouteReceiverNameLabel: "receiver",
+			AutogeneratedRouteSettingsHashLabel: settings.Fingerprint(),
+		}, settings.ToLabels())
+	})
+}
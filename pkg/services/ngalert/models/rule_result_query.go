@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RuleResultDatasourceUID is the data source UID of queries that use the latest result of another alert rule of the
+// same group. Such queries can only be used by rules of groups that are evaluated sequentially.
+const RuleResultDatasourceUID = "__alert_rule__"
+
+// RuleResultType defines which part of the latest result of a rule is used by a rule result query.
+type RuleResultType string
+
+const (
+	// RuleResultState returns 1 for each alert instance of the rule that is firing and 0 for all others.
+	RuleResultState RuleResultType = "state"
+	// RuleResultValue returns the value of a query or expression of the rule for each alert instance.
+	RuleResultValue RuleResultType = "value"
+)
+
+// RuleResultQuery is the model of a query whose data source is RuleResultDatasourceUID.
+type RuleResultQuery struct {
+	// RuleUID is the UID of the rule whose latest result is used.
+	RuleUID string `json:"ruleUid"`
+	// Result is what part of the result is used. Defaults to RuleResultState.
+	Result RuleResultType `json:"result,omitempty"`
+	// Expression is the RefID of the query or expression of the referenced rule whose value is used.
+	// It is required if Result is RuleResultValue.
+	Expression string `json:"expression,omitempty"`
+}
+
+// IsRuleResultQuery returns true if the alert query uses the latest result of another alert rule.
+func (aq *AlertQuery) IsRuleResultQuery() bool {
+	return aq.DatasourceUID == RuleResultDatasourceUID
+}
+
+// GetRuleResultQuery parses the model of a rule result query and validates it.
+func (aq *AlertQuery) GetRuleResultQuery() (RuleResultQuery, error) {
+	var q RuleResultQuery
+	if err := json.Unmarshal(aq.Model, &q); err != nil {
+		return q, fmt.Errorf("failed to unmarshal rule result query model: %w", err)
+	}
+	if q.RuleUID == "" {
+		return q, errors.New("rule result query must specify the UID of a rule")
+	}
+	switch q.Result {
+	case "":
+		q.Result = RuleResultState
+	case RuleResultState:
+	case RuleResultValue:
+		if q.Expression == "" {
+			return q, errors.New("rule result query must specify the query or expression whose value is used")
+		}
+	default:
+		return q, fmt.Errorf("unknown rule result %q, must be one of [%s, %s]", q.Result, RuleResultState, RuleResultValue)
+	}
+	return q, nil
+}
+
+// ValidateRuleResultQueries validates the rule result queries of the rules of a group. The rules must be sorted by
+// their index in the group. A rule can only use the results of the alerting rules that are before it in a group that
+// is evaluated sequentially.
+func ValidateRuleResultQueries(rules []*AlertRule) error {
+	preceding := make(map[string]*AlertRule, len(rules))
+	for _, rule := range rules {
+		for _, q := range rule.Data {
+			if !q.IsRuleResultQuery() {
+				continue
+			}
+			ref, err := q.GetRuleResultQuery()
+			if err != nil {
+				return fmt.Errorf("%w: invalid query %s of rule '%s': %w", ErrAlertRuleFailedValidation, q.RefID, rule.Title, err)
+			}
+			if !rule.SequentialEvaluation {
+				return fmt.Errorf("%w: query %s of rule '%s' uses the result of another rule but the group is not evaluated sequentially", ErrAlertRuleFailedValidation, q.RefID, rule.Title)
+			}
+			target, ok := preceding[ref.RuleUID]
+			if !ok {
+				return fmt.Errorf("%w: query %s of rule '%s' uses the result of rule %s that is not evaluated before it in the same group", ErrAlertRuleFailedValidation, q.RefID, rule.Title, ref.RuleUID)
+			}
+			if target.Type() == RuleTypeRecording {
+				return fmt.Errorf("%w: query %s of rule '%s' cannot use the result of recording rule %s", ErrAlertRuleFailedValidation, q.RefID, rule.Title, ref.RuleUID)
+			}
+			if ref.Result == RuleResultValue && !target.hasQuery(ref.Expression) {
+				return fmt.Errorf("%w: query %s of rule '%s' uses the value of %s but rule %s has no such query or expression", ErrAlertRuleFailedValidation, q.RefID, rule.Title, ref.Expression, ref.RuleUID)
+			}
+		}
+		if rule.UID != "" {
+			preceding[rule.UID] = rule
+		}
+	}
+	return nil
+}
+
+func (alertRule *AlertRule) hasQuery(refID string) bool {
+	for _, q := range alertRule.Data {
+		if q.RefID == refID {
+			return true
+		}
+	}
+	return false
+}
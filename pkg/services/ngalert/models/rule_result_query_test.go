@@ -0,0 +1,149 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertQuery_GetRuleResultQuery(t *testing.T) {
+	testCases := []struct {
+		name     string
+		model    string
+		expected RuleResultQuery
+		err      string
+	}{
+		{
+			name:     "defaults to the state of the rule",
+			model:    `{"ruleUid": "rule-1"}`,
+			expected: RuleResultQuery{RuleUID: "rule-1", Result: RuleResultState},
+		},
+		{
+			name:     "can use the value of an expression",
+			model:    `{"ruleUid": "rule-1", "result": "value", "expression": "B"}`,
+			expected: RuleResultQuery{RuleUID: "rule-1", Result: RuleResultValue, Expression: "B"},
+		},
+		{
+			name:  "fails if the rule is missing",
+			model: `{"result": "state"}`,
+			err:   "must specify the UID of a rule",
+		},
+		{
+			name:  "fails if the expression of a value is missing",
+			model: `{"ruleUid": "rule-1", "result": "value"}`,
+			err:   "must specify the query or expression",
+		},
+		{
+			name:  "fails if the result is unknown",
+			model: `{"ruleUid": "rule-1", "result": "labels"}`,
+			err:   "unknown rule result",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := AlertQuery{RefID: "A", DatasourceUID: RuleResultDatasourceUID, Model: json.RawMessage(tc.model)}
+			require.True(t, q.IsRuleResultQuery())
+			actual, err := q.GetRuleResultQuery()
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("does not require a relative time range", func(t *testing.T) {
+		q := AlertQuery{RefID: "A", DatasourceUID: RuleResultDatasourceUID, Model: json.RawMessage(`{"ruleUid": "rule-1"}`)}
+		require.NoError(t, q.PreSave())
+
+		q = AlertQuery{RefID: "A", DatasourceUID: "datasource", Model: json.RawMessage(`{}`)}
+		require.ErrorContains(t, q.PreSave(), "invalid relative time range")
+
+		q.RelativeTimeRange = RelativeTimeRange{From: Duration(time.Minute)}
+		require.NoError(t, q.PreSave())
+	})
+}
+
+func TestValidateRuleResultQueries(t *testing.T) {
+	math := AlertQuery{RefID: "B", DatasourceUID: "__expr__", Model: json.RawMessage(`{"type": "math", "expression": "$A"}`)}
+	newRule := func(uid string, queries ...AlertQuery) *AlertRule {
+		return &AlertRule{UID: uid, Title: uid, SequentialEvaluation: true, Data: append(queries, math)}
+	}
+
+	t.Run("passes if rules use the results of the rules before them", func(t *testing.T) {
+		require.NoError(t, ValidateRuleResultQueries([]*AlertRule{
+			newRule("rule-1"),
+			newRule("rule-2", CreateRuleResultQuery("A", "rule-1", RuleResultState, "")),
+			newRule("rule-3", CreateRuleResultQuery("A", "rule-2", RuleResultValue, "B")),
+		}))
+	})
+
+	t.Run("passes if no rule uses the results of other rules", func(t *testing.T) {
+		rule := newRule("rule-1")
+		rule.SequentialEvaluation = false
+		require.NoError(t, ValidateRuleResultQueries([]*AlertRule{rule}))
+	})
+
+	testCases := []struct {
+		name  string
+		rules func() []*AlertRule
+		err   string
+	}{
+		{
+			name: "fails if the group is not sequential",
+			rules: func() []*AlertRule {
+				rule := newRule("rule-2", CreateRuleResultQuery("A", "rule-1", RuleResultState, ""))
+				rule.SequentialEvaluation = false
+				return []*AlertRule{newRule("rule-1"), rule}
+			},
+			err: "the group is not evaluated sequentially",
+		},
+		{
+			name: "fails if the rule is after it",
+			rules: func() []*AlertRule {
+				return []*AlertRule{newRule("rule-1", CreateRuleResultQuery("A", "rule-2", RuleResultState, "")), newRule("rule-2")}
+			},
+			err: "is not evaluated before it",
+		},
+		{
+			name: "fails if the rule uses its own result",
+			rules: func() []*AlertRule {
+				return []*AlertRule{newRule("rule-1", CreateRuleResultQuery("A", "rule-1", RuleResultState, ""))}
+			},
+			err: "is not evaluated before it",
+		},
+		{
+			name: "fails if the rule is a recording rule",
+			rules: func() []*AlertRule {
+				recording := newRule("rule-1")
+				recording.Record = &Record{Metric: "metric", From: "B"}
+				return []*AlertRule{recording, newRule("rule-2", CreateRuleResultQuery("A", "rule-1", RuleResultState, ""))}
+			},
+			err: "cannot use the result of recording rule",
+		},
+		{
+			name: "fails if the expression does not exist",
+			rules: func() []*AlertRule {
+				return []*AlertRule{newRule("rule-1"), newRule("rule-2", CreateRuleResultQuery("A", "rule-1", RuleResultValue, "C"))}
+			},
+			err: "has no such query or expression",
+		},
+		{
+			name: "fails if the query is invalid",
+			rules: func() []*AlertRule {
+				return []*AlertRule{newRule("rule-1", AlertQuery{RefID: "A", DatasourceUID: RuleResultDatasourceUID, Model: json.RawMessage(`{}`)})}
+			},
+			err: "invalid query A",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRuleResultQueries(tc.rules())
+			require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
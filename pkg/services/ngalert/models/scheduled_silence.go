@@ -0,0 +1,224 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrScheduledSilenceNotFound = errors.New("scheduled silence not found")
+	ErrScheduledSilenceInvalid  = errors.New("invalid scheduled silence")
+)
+
+// ScheduledSilenceFrequency defines how often the windows of a scheduled silence repeat.
+type ScheduledSilenceFrequency string
+
+const (
+	// ScheduledSilenceOnce is the frequency of a scheduled silence that has only one window.
+	ScheduledSilenceOnce    ScheduledSilenceFrequency = ""
+	ScheduledSilenceDaily   ScheduledSilenceFrequency = "daily"
+	ScheduledSilenceWeekly  ScheduledSilenceFrequency = "weekly"
+	ScheduledSilenceMonthly ScheduledSilenceFrequency = "monthly"
+)
+
+// SilenceMatcher is a matcher of a silence.
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// ScheduledSilence is a silence that is created by Grafana ahead of every window of its schedule. The first window
+// starts at StartsAt and ends at EndsAt. The next windows start at the same local time in TimeZone and have the same
+// duration.
+type ScheduledSilence struct {
+	ID        int64            `xorm:"pk autoincr 'id'"`
+	UID       string           `xorm:"uid"`
+	OrgID     int64            `xorm:"org_id"`
+	Matchers  []SilenceMatcher `xorm:"matchers"`
+	Comment   string           `xorm:"comment"`
+	CreatedBy string           `xorm:"created_by"`
+	StartsAt  time.Time        `xorm:"starts_at"`
+	EndsAt    time.Time        `xorm:"ends_at"`
+	// Frequency and Interval define the period of the windows. For example, weekly frequency with interval 2 means
+	// that the silence repeats every two weeks.
+	Frequency ScheduledSilenceFrequency `xorm:"frequency"`
+	Interval  int                       `xorm:"repeat_interval"`
+	// RepeatUntil is the time after which no more windows start. If it is nil, the silence repeats forever.
+	RepeatUntil *time.Time `xorm:"repeat_until"`
+	TimeZone    string     `xorm:"time_zone"`
+	// SilenceID is the ID of the silence created for the window that starts at WindowStart.
+	SilenceID string `xorm:"silence_id"`
+	// WindowStart is the start of the last window for which a silence was created, in Unix seconds.
+	WindowStart int64     `xorm:"window_start"`
+	Updated     time.Time `xorm:"updated"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (s *ScheduledSilence) TableName() string {
+	return "alert_scheduled_silence"
+}
+
+func (s *ScheduledSilence) ResourceType() string {
+	return "scheduledSilence"
+}
+
+func (s *ScheduledSilence) ResourceID() string {
+	return s.UID
+}
+
+// Validate returns an error if the scheduled silence cannot be scheduled.
+func (s *ScheduledSilence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("%w: at least one matcher is required", ErrScheduledSilenceInvalid)
+	}
+	for _, m := range s.Matchers {
+		if m.Name == "" {
+			return fmt.Errorf("%w: matcher name must not be empty", ErrScheduledSilenceInvalid)
+		}
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("%w: end of the silence must be after its start", ErrScheduledSilenceInvalid)
+	}
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrScheduledSilenceInvalid, s.TimeZone)
+	}
+	if s.Frequency == ScheduledSilenceOnce {
+		return nil
+	}
+	if s.Interval < 0 {
+		return fmt.Errorf("%w: interval must not be negative", ErrScheduledSilenceInvalid)
+	}
+	period, ok := s.minPeriod()
+	if !ok {
+		return fmt.Errorf("%w: unknown frequency %q", ErrScheduledSilenceInvalid, s.Frequency)
+	}
+	if s.EndsAt.Sub(s.StartsAt) >= period {
+		return fmt.Errorf("%w: duration of the silence must be shorter than the period of its repetitions", ErrScheduledSilenceInvalid)
+	}
+	if s.RepeatUntil != nil && s.RepeatUntil.Before(s.StartsAt) {
+		return fmt.Errorf("%w: repetitions must end after the start of the silence", ErrScheduledSilenceInvalid)
+	}
+	return nil
+}
+
+// Window returns the start and the end of the n-th window of the silence, where 0 is the first window.
+func (s *ScheduledSilence) Window(n int) (time.Time, time.Time) {
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start := s.StartsAt.In(loc)
+	step := n * s.interval()
+	switch s.Frequency {
+	case ScheduledSilenceDaily:
+		start = start.AddDate(0, 0, step)
+	case ScheduledSilenceWeekly:
+		start = start.AddDate(0, 0, 7*step)
+	case ScheduledSilenceMonthly:
+		start = addMonths(start, step)
+	}
+	return start, start.Add(s.EndsAt.Sub(s.StartsAt))
+}
+
+// addMonths adds months to t. Unlike time.AddDate, the day is clamped to the last day of the target month, so a
+// window that starts on the 31st starts on the last day of shorter months instead of overflowing into the next month.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	// the day 0 of the following month is the last day of the target month
+	lastDay := time.Date(year, month+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month+time.Month(months), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// NextWindow returns the first window of the silence that ends after t. Returns false if all windows end before t.
+func (s *ScheduledSilence) NextWindow(t time.Time) (time.Time, time.Time, bool) {
+	n := 0
+	// skip the windows that have certainly ended, the rest is found by iterating over the windows.
+	if period, ok := s.maxPeriod(); ok && s.Frequency != ScheduledSilenceOnce && t.After(s.EndsAt) {
+		n = int(t.Sub(s.EndsAt) / period)
+	}
+	for {
+		start, end := s.Window(n)
+		if n > 0 && (s.Frequency == ScheduledSilenceOnce || (s.RepeatUntil != nil && start.After(*s.RepeatUntil))) {
+			return time.Time{}, time.Time{}, false
+		}
+		if end.After(t) {
+			return start, end, true
+		}
+		n++
+	}
+}
+
+func (s *ScheduledSilence) interval() int {
+	if s.Interval <= 0 {
+		return 1
+	}
+	return s.Interval
+}
+
+// minPeriod returns the shortest time between the starts of two consecutive windows.
+func (s *ScheduledSilence) minPeriod() (time.Duration, bool) {
+	day := 23 * time.Hour // a day can be shorter because of daylight saving time
+	switch s.Frequency {
+	case ScheduledSilenceDaily:
+		return time.Duration(s.interval()) * day, true
+	case ScheduledSilenceWeekly:
+		return time.Duration(s.interval()) * (6*24*time.Hour + day), true
+	case ScheduledSilenceMonthly:
+		return time.Duration(s.interval()) * (27*24*time.Hour + day), true
+	}
+	return 0, false
+}
+
+// maxPeriod returns the longest time between the starts of two consecutive windows.
+func (s *ScheduledSilence) maxPeriod() (time.Duration, bool) {
+	day := 25 * time.Hour
+	switch s.Frequency {
+	case ScheduledSilenceDaily:
+		return time.Duration(s.interval()) * day, true
+	case ScheduledSilenceWeekly:
+		return time.Duration(s.interval()) * (6*24*time.Hour + day), true
+	case ScheduledSilenceMonthly:
+		return time.Duration(s.interval()) * (30*24*time.Hour + day), true
+	}
+	return 0, false
+}
+
+// SilenceAuditAction is an action recorded in the silence audit trail.
+type SilenceAuditAction string
+
+const (
+	SilenceAuditActionCreate SilenceAuditAction = "create"
+	SilenceAuditActionExpire SilenceAuditAction = "expire"
+)
+
+// SilenceAuditEntry records who created or expired a silence.
+type SilenceAuditEntry struct {
+	ID        int64              `xorm:"pk autoincr 'id'"`
+	OrgID     int64              `xorm:"org_id"`
+	SilenceID string             `xorm:"silence_id"`
+	Action    SilenceAuditAction `xorm:"action"`
+	// User is the login of the user who made the change.
+	User string `xorm:"user_login"`
+	// ScheduledSilenceUID is set if the silence belongs to a scheduled silence.
+	ScheduledSilenceUID string    `xorm:"scheduled_silence_uid"`
+	Created             time.Time `xorm:"created"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *SilenceAuditEntry) TableName() string {
+	return "alert_silence_audit"
+}
+
+// ListSilenceAuditEntriesQuery is the query for the silence audit trail of an organization.
+type ListSilenceAuditEntriesQuery struct {
+	OrgID               int64
+	SilenceID           string
+	ScheduledSilenceUID string
+	Limit               int
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledSilenceNextWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	t.Run("one-off silence has a single window", func(t *testing.T) {
+		start := time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)
+		s := ScheduledSilence{StartsAt: start, EndsAt: start.Add(time.Hour), TimeZone: "UTC"}
+
+		from, to, ok := s.NextWindow(start.Add(-time.Hour))
+		require.True(t, ok)
+		require.Equal(t, start, from)
+		require.Equal(t, start.Add(time.Hour), to)
+
+		_, _, ok = s.NextWindow(start.Add(time.Hour))
+		require.False(t, ok)
+	})
+
+	t.Run("weekly silence keeps local time across daylight saving time", func(t *testing.T) {
+		start := time.Date(2024, 3, 17, 2, 0, 0, 0, berlin) // Sunday before DST starts
+		s := ScheduledSilence{StartsAt: start, EndsAt: start.Add(2 * time.Hour), Frequency: ScheduledSilenceWeekly, TimeZone: "Europe/Berlin"}
+
+		from, to, ok := s.NextWindow(start.Add(3 * time.Hour))
+		require.True(t, ok)
+		require.Equal(t, time.Date(2024, 3, 24, 2, 0, 0, 0, berlin).Unix(), from.Unix())
+		require.Equal(t, 2*time.Hour, to.Sub(from))
+
+		from, _, ok = s.NextWindow(time.Date(2024, 4, 1, 0, 0, 0, 0, berlin))
+		require.True(t, ok)
+		require.Equal(t, time.Date(2024, 4, 7, 2, 0, 0, 0, berlin), from)
+	})
+
+	t.Run("window in progress is returned", func(t *testing.T) {
+		start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+		s := ScheduledSilence{StartsAt: start, EndsAt: start.Add(2 * time.Hour), Frequency: ScheduledSilenceDaily, Interval: 2, TimeZone: "UTC"}
+
+		from, _, ok := s.NextWindow(start.AddDate(0, 0, 100).Add(time.Hour))
+		require.True(t, ok)
+		require.Equal(t, start.AddDate(0, 0, 100), from)
+	})
+
+	t.Run("monthly silence uses calendar months", func(t *testing.T) {
+		start := time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)
+		s := ScheduledSilence{StartsAt: start, EndsAt: start.Add(4 * time.Hour), Frequency: ScheduledSilenceMonthly, TimeZone: "UTC"}
+
+		from, _, ok := s.NextWindow(time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC))
+		require.True(t, ok)
+		require.Equal(t, time.Date(2024, 12, 15, 22, 0, 0, 0, time.UTC), from)
+	})
+
+	t.Run("monthly silence starting on the last day of a month is clamped to shorter months", func(t *testing.T) {
+		start := time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC)
+		s := ScheduledSilence{StartsAt: start, EndsAt: start.Add(4 * time.Hour), Frequency: ScheduledSilenceMonthly, TimeZone: "UTC"}
+
+		expected := []time.Time{
+			start,
+			time.Date(2024, 2, 29, 22, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 31, 22, 0, 0, 0, time.UTC),
+		}
+		for n, e := range expected {
+			from, to := s.Window(n)
+			require.Equal(t, e, from)
+			require.Equal(t, 4*time.Hour, to.Sub(from))
+		}
+
+		from, _, ok := s.NextWindow(time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC))
+		require.True(t, ok)
+		require.Equal(t, time.Date(2025, 2, 28, 22, 0, 0, 0, time.UTC), from)
+	})
+
+	t.Run("no window starts after repeat until", func(t *testing.T) {
+		start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+		until := start.AddDate(0, 0, 7)
+		s := ScheduledSilence{StartsAt: start, EndsAt: start.Add(time.Hour), Frequency: ScheduledSilenceWeekly, RepeatUntil: &until, TimeZone: "UTC"}
+
+		from, _, ok := s.NextWindow(start.Add(time.Hour))
+		require.True(t, ok)
+		require.Equal(t, until, from)
+
+		_, _, ok = s.NextWindow(until.Add(time.Hour))
+		require.False(t, ok)
+	})
+}
+
+func TestScheduledSilenceValidate(t *testing.T) {
+	start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+	valid := func() ScheduledSilence {
+		return ScheduledSilence{
+			Matchers:  []SilenceMatcher{{Name: "env", Value: "prod", IsEqual: true}},
+			StartsAt:  start,
+			EndsAt:    start.Add(2 * time.Hour),
+			Frequency: ScheduledSilenceWeekly,
+			TimeZone:  "UTC",
+		}
+	}
+	s := valid()
+	require.NoError(t, s.Validate())
+
+	testCases := []struct {
+		name   string
+		mutate func(s *ScheduledSilence)
+	}{
+		{name: "no matchers", mutate: func(s *ScheduledSilence) { s.Matchers = nil }},
+		{name: "empty matcher name", mutate: func(s *ScheduledSilence) { s.Matchers[0].Name = "" }},
+		{name: "end before start", mutate: func(s *ScheduledSilence) { s.EndsAt = s.StartsAt.Add(-time.Minute) }},
+		{name: "unknown time zone", mutate: func(s *ScheduledSilence) { s.TimeZone = "Mars/Olympus" }},
+		{name: "unknown frequency", mutate: func(s *ScheduledSilence) { s.Frequency = "hourly" }},
+		{name: "negative interval", mutate: func(s *ScheduledSilence) { s.Interval = -1 }},
+		{name: "windows overlap", mutate: func(s *ScheduledSilence) { s.EndsAt = s.StartsAt.AddDate(0, 0, 8) }},
+		{name: "repeat until before start", mutate: func(s *ScheduledSilence) { u := s.StartsAt.Add(-time.Hour); s.RepeatUntil = &u }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := valid()
+			tc.mutate(&s)
+			require.ErrorIs(t, s.Validate(), ErrScheduledSilenceInvalid)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+// SchedulerPeer is an instance of Grafana that takes part in the sharded evaluation of alert rules.
+type SchedulerPeer struct {
+	InstanceID string `xorm:"pk 'instance_id'"`
+	// LastSeen is the time of the last heartbeat of the instance, in Unix seconds.
+	LastSeen int64 `xorm:"last_seen"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (p *SchedulerPeer) TableName() string {
+	return "alert_scheduler_peer"
+}
@@ -202,6 +202,19 @@ func WithForNTimes(timesOfInterval int64) AlertRuleMutator {
 	}
 }
 
+func WithKeepFiringForNTimes(timesOfInterval int64) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = time.Duration(rule.IntervalSeconds*timesOfInterval) * time.Second
+	}
+}
+
+// WithNotificationSettings sets the notification settings of the rule.
+func WithNotificationSettings(settings NotificationSettings) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.NotificationSettings = &settings
+	}
+}
+
 func WithNoDataExecAs(nodata NoDataState) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.NoDataState = nodata
@@ -353,20 +366,23 @@ func GenerateGroupKey(orgID int64) AlertRuleGroupKey {
 // CopyRule creates a deep copy of AlertRule
 func CopyRule(r *AlertRule) *AlertRule {
 	result := AlertRule{
-		ID:              r.ID,
-		OrgID:           r.OrgID,
-		Title:           r.Title,
-		Condition:       r.Condition,
-		Updated:         r.Updated,
-		IntervalSeconds: r.IntervalSeconds,
-		Version:         r.Version,
-		UID:             r.UID,
-		NamespaceUID:    r.NamespaceUID,
-		RuleGroup:       r.RuleGroup,
-		RuleGroupIndex:  r.RuleGroupIndex,
-		NoDataState:     r.NoDataState,
-		ExecErrState:    r.ExecErrState,
-		For:             r.For,
+		ID:                   r.ID,
+		OrgID:                r.OrgID,
+		Title:                r.Title,
+		Condition:            r.Condition,
+		Updated:              r.Updated,
+		IntervalSeconds:      r.IntervalSeconds,
+		Version:              r.Version,
+		UID:                  r.UID,
+		NamespaceUID:         r.NamespaceUID,
+		RuleGroup:            r.RuleGroup,
+		RuleGroupIndex:       r.RuleGroupIndex,
+		NoDataState:          r.NoDataState,
+		ExecErrState:         r.ExecErrState,
+		For:                  r.For,
+		KeepFiringFor:        r.KeepFiringFor,
+		UpdatedBy:            r.UpdatedBy,
+		SequentialEvaluation: r.SequentialEvaluation,
 	}
 
 	if r.DashboardUID != nil {
@@ -404,6 +420,18 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	if r.Record != nil {
+		record := *r.Record
+		result.Record = &record
+	}
+
+	if r.NotificationSettings != nil {
+		settings := *r.NotificationSettings
+		settings.GroupBy = append([]string(nil), r.NotificationSettings.GroupBy...)
+		settings.MuteTimeIntervals = append([]string(nil), r.NotificationSettings.MuteTimeIntervals...)
+		result.NotificationSettings = &settings
+	}
+
 	return &result
 }
 
@@ -469,6 +497,16 @@ func CreateReduceExpression(refID string, inputRefID string, reducer string) Ale
 	}
 }
 
+// CreateRuleResultQuery creates a query that uses the latest result of the rule with the given UID.
+func CreateRuleResultQuery(refID string, ruleUID string, result RuleResultType, expression string) AlertQuery {
+	model, _ := json.Marshal(RuleResultQuery{RuleUID: ruleUID, Result: result, Expression: expression})
+	return AlertQuery{
+		RefID:         refID,
+		DatasourceUID: RuleResultDatasourceUID,
+		Model:         model,
+	}
+}
+
 func CreatePrometheusQuery(refID string, expr string, intervalMs int64, maxDataPoints int64, isInstant bool, datasourceUID string) AlertQuery {
 	return AlertQuery{
 		RefID:         refID,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -39,12 +40,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func ProvideService(
@@ -149,6 +152,8 @@ type AlertNG struct {
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	AlertsRouter         *sender.AlertsRouter
+	scheduledSilencer    *notifier.ScheduledSilencer
+	deliveryLog          *notifier.DeliveryLog
 	accesscontrol        accesscontrol.AccessControl
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
@@ -173,7 +178,11 @@ func (ng *AlertNG) init() error {
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 
-	var overrides []notifier.Option
+	overrides := []notifier.Option{notifier.WithRuleStore(ng.store)}
+	if ng.Cfg.UnifiedAlerting.NotificationLog.Enabled {
+		ng.deliveryLog = notifier.NewDeliveryLog(ng.store, log.New("ngalert.notifier.delivery-log"))
+		overrides = append(overrides, notifier.WithDeliveryLog(ng.deliveryLog))
+	}
 	if ng.Cfg.UnifiedAlerting.RemoteAlertmanager.Enable {
 		override := notifier.WithAlertmanagerOverride(func(ctx context.Context, orgID int64) (notifier.Alertmanager, error) {
 			externalAMCfg := remote.AlertmanagerConfig{}
@@ -234,17 +243,46 @@ func (ng *AlertNG) init() error {
 		Log:                  log.New("ngalert.scheduler"),
 	}
 
+	if ng.Cfg.UnifiedAlerting.HAShardedEvaluation {
+		schedCfg.Sharding = &schedule.ShardingCfg{
+			InstanceID:  schedulerInstanceID(),
+			PeerStore:   ng.store,
+			PeerTimeout: ng.Cfg.UnifiedAlerting.HAShardedEvaluationPeerTimeout,
+		}
+		ng.Log.Info("Sharded evaluation of alert rules is enabled", "instance", schedCfg.Sharding.InstanceID)
+	}
+
+	var evaluationProfiler *schedule.EvaluationProfiler
+	if ng.Cfg.UnifiedAlerting.EvaluationProfiling.Enabled {
+		evaluationProfiler = schedule.NewEvaluationProfiler()
+		schedCfg.Profiler = evaluationProfiler
+		schedCfg.ProfileMetricsTopRules = ng.Cfg.UnifiedAlerting.EvaluationProfiling.MetricsTopRules
+	}
+
+	recordingWriter, err := configureRecordingWriter(ng.Cfg.UnifiedAlerting.RecordingRules, ng.Log)
+	if err != nil {
+		return err
+	}
+	if recordingWriter != nil {
+		schedCfg.RecordingWriter = recordingWriter
+	}
+
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	applyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log)
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log)
+	if err != nil {
+		return err
+	}
+	// The conversion of the saved state can take longer than the deadline of initCtx on large installations.
+	instanceStore, err := configureInstanceStore(context.Background(), ng.Cfg.UnifiedAlerting, ng.store, ng.Log)
 	if err != nil {
 		return err
 	}
 	cfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
-		InstanceStore:                  ng.store,
+		InstanceStore:                  instanceStore,
 		Images:                         ng.ImageService,
 		Clock:                          clk,
 		Historian:                      history,
@@ -270,9 +308,17 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(ng.store, ng.SecretsService, ng.store, ng.store, ng.Log, ng.accesscontrol)
 	templateService := provisioning.NewTemplateService(ng.store, ng.store, ng.store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(ng.store, ng.store, ng.store, ng.Log)
+	ng.scheduledSilencer = notifier.NewScheduledSilencer(ng.store, ng.MultiOrgAlertmanager, log.New("ngalert.scheduled-silences"))
+	scheduledSilenceService := provisioning.NewScheduledSilenceService(ng.store, ng.store, ng.store, ng.scheduledSilencer, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(ng.store, ng.store, ng.dashboardService, ng.QuotaService, ng.store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
-		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
+		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log, notifier.NewNotificationSettingsValidationService(ng.store))
+
+	// If the evaluation is sharded, each instance caches only the state of its own rules.
+	var stateReader state.AlertInstanceManager = ng.stateManager
+	if ng.Cfg.UnifiedAlerting.HAShardedEvaluation {
+		stateReader = state.NewShardedStateReader(ng.stateManager)
+	}
 
 	ng.api = &api.API{
 		Cfg:                  ng.Cfg,
@@ -287,13 +333,16 @@ func (ng *AlertNG) init() error {
 		AdminConfigStore:     ng.store,
 		ProvenanceStore:      ng.store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
-		StateManager:         ng.stateManager,
+		StateManager:         stateReader,
 		AccessControl:        ng.accesscontrol,
 		Policies:             policyService,
 		ContactPointService:  contactPointService,
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		ScheduledSilences:    scheduledSilenceService,
+		SilenceAudit:         ng.store,
+		NotificationLog:      ng.store,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
 		FeatureManager:       ng.FeatureToggles,
@@ -302,6 +351,9 @@ func (ng *AlertNG) init() error {
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 	}
+	if evaluationProfiler != nil {
+		ng.api.EvaluationProfiles = evaluationProfiler
+	}
 	ng.api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
 	if err := RegisterQuotas(ng.Cfg, ng.QuotaService, ng.store); err != nil {
@@ -353,6 +405,14 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.scheduledSilencer.Run(subCtx)
+	})
+	if ng.deliveryLog != nil {
+		children.Go(func() error {
+			return ng.deliveryLog.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -364,7 +424,10 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 		// Also note that this runs synchronously to ensure state is loaded
 		// before rule evaluation begins, hence we use ctx and not subCtx.
 		//
-		ng.stateManager.Warm(ctx, ng.store)
+		// If the evaluation is sharded, the state of a rule is loaded when the instance starts evaluating it.
+		if !ng.Cfg.UnifiedAlerting.HAShardedEvaluation {
+			ng.stateManager.Warm(ctx, ng.store)
+		}
 
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
@@ -389,7 +452,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, hs store.StateHistoryStore, met *metrics.Historian, l log.Logger) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -404,7 +467,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, hs, met, l)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -413,7 +476,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, hs, met, l)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -427,6 +490,9 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		store := historian.NewAnnotationStore(ar, ds, met)
 		return historian.NewAnnotationBackend(store, rs, met), nil
 	}
+	if backend == historian.BackendTypeDatabase {
+		return historian.NewDatabaseBackend(hs, met), nil
+	}
 	if backend == historian.BackendTypeLoki {
 		lcfg, err := historian.NewLokiConfig(cfg)
 		if err != nil {
@@ -446,6 +512,49 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
 
+// configureInstanceStore returns the store of the alert state. The state saved by the other store is moved to the
+// returned one, so the state is kept when the compact state persistence is enabled or disabled.
+func configureInstanceStore(ctx context.Context, cfg setting.UnifiedAlertingSettings, dbStore *store.DBstore, l log.Logger) (state.InstanceStore, error) {
+	compactStore := &store.CompactInstanceStore{
+		SQLStore:       dbStore.SQLStore,
+		FeatureToggles: dbStore.FeatureToggles,
+		Logger:         l,
+	}
+	if !cfg.CompactStatePersistence {
+		count, err := compactStore.ExportAlertInstances(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the compact alert state: %w", err)
+		}
+		if count > 0 {
+			l.Info("Converted the compact alert state", "instances", count)
+		}
+		return dbStore, nil
+	}
+
+	count, err := compactStore.ImportAlertInstances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the alert state to the compact format: %w", err)
+	}
+	if count > 0 {
+		l.Info("Converted the alert state to the compact format", "instances", count)
+	}
+	l.Info("Compact persistence of the alert state is enabled")
+	return compactStore, nil
+}
+
+// configureRecordingWriter returns the writer for the results of recording rules, or nil if recording rules are disabled.
+func configureRecordingWriter(cfg setting.RecordingRuleSettings, l log.Logger) (*writer.PrometheusWriter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	wcfg, err := writer.NewPrometheusWriterConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording rules configuration: %w", err)
+	}
+	l.Info("Recording rules are enabled", "url", wcfg.URL.Redacted())
+	return writer.NewPrometheusWriter(wcfg, log.New("ngalert.writer")), nil
+}
+
 // applyStateHistoryFeatureToggles edits state history configuration to comply with currently active feature toggles.
 func applyStateHistoryFeatureToggles(cfg *setting.UnifiedAlertingStateHistorySettings, ft featuremgmt.FeatureToggles, logger log.Logger) {
 	backend, _ := historian.ParseBackendType(cfg.Backend)
@@ -490,3 +599,13 @@ func applyStateHistoryFeatureToggles(cfg *setting.UnifiedAlertingStateHistorySet
 		return
 	}
 }
+
+// schedulerInstanceID returns a unique identifier of this instance of the scheduler. The identifier is generated on
+// every start, so a restarted instance does not take over the heartbeat of its previous run.
+func schedulerInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "grafana"
+	}
+	return hostname + "-" + util.GenerateShortUID()
+}
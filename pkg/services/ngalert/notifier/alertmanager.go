@@ -21,6 +21,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/mqtt"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -53,6 +54,10 @@ type alertmanager struct {
 	Store               AlertingStore
 	fileStore           *FileStore
 	NotificationService notifications.Service
+	// ruleStore is used to generate the routes for the notification settings of alert rules. Can be nil.
+	ruleStore autogenRuleStore
+	// deliveryLog records the delivery attempts of the integrations. Can be nil.
+	deliveryLog *DeliveryLog
 
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
@@ -85,7 +90,7 @@ func (m maintenanceOptions) MaintenanceFunc(state alertingNotify.State) (int64,
 
 func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store AlertingStore, kvStore kvstore.KVStore,
 	peer alertingNotify.ClusterPeer, decryptFn alertingNotify.GetDecryptedValueFn, ns notifications.Service,
-	m *metrics.Alertmanager) (*alertmanager, error) {
+	m *metrics.Alertmanager, ruleStore autogenRuleStore, deliveryLog *DeliveryLog) (*alertmanager, error) {
 	workingPath := filepath.Join(cfg.DataPath, workingDir, strconv.Itoa(int(orgID)))
 	fileStore := NewFileStore(orgID, kvStore, workingPath)
 
@@ -139,6 +144,8 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		Settings:            cfg,
 		Store:               store,
 		NotificationService: ns,
+		ruleStore:           ruleStore,
+		deliveryLog:         deliveryLog,
 		orgID:               orgID,
 		decryptFn:           decryptFn,
 		fileStore:           fileStore,
@@ -179,7 +186,7 @@ func (am *alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
+			_, err := am.applyConfig(ctx, cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
 			return err
 		})
 		if err != nil {
@@ -209,7 +216,7 @@ func (am *alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.P
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, rawConfig)
+			_, err := am.applyConfig(ctx, cfg, rawConfig)
 			return err
 		})
 		if err != nil {
@@ -281,11 +288,27 @@ func (am *alertmanager) aggregateInhibitMatchers(rules []config.InhibitRule, amu
 // applyConfig applies a new configuration by re-initializing all components using the configuration provided.
 // It returns a boolean indicating whether the user config was changed and an error.
 // It is not safe to call concurrently.
-func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+func (am *alertmanager) applyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+	if am.ruleStore != nil {
+		// The routes for the notification settings of alert rules are not part of the stored configuration,
+		// therefore they are added to a copy.
+		cfgCopy := *cfg
+		added, err := addAutogenConfig(ctx, am.logger, am.ruleStore, am.orgID, &cfgCopy.AlertmanagerConfig)
+		if err != nil {
+			return false, err
+		}
+		if added {
+			cfg = &cfgCopy
+			// The raw configuration must include the generated routes to detect when they change.
+			rawConfig = nil
+		}
+	}
+
 	// First, let's make sure this config is not already loaded
 	var amConfigChanged bool
 	if rawConfig == nil {
-		enc, err := json.Marshal(cfg.AlertmanagerConfig)
+		// The raw configuration is returned by GetStatus, therefore, it must have the same format as the stored one.
+		enc, err := json.Marshal(cfg)
 		if err != nil {
 			// In theory, this should never happen.
 			return false, err
@@ -332,7 +355,7 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 
 // applyAndMarkConfig applies a configuration and marks it as applied if no errors occur.
 func (am *alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig, rawConfig []byte) error {
-	configChanged, err := am.applyConfig(cfg, rawConfig)
+	configChanged, err := am.applyConfig(ctx, cfg, rawConfig)
 	if err != nil {
 		return err
 	}
@@ -354,7 +377,19 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
-	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
+	// MQTT integrations are not supported by the alerting module and are built separately.
+	var mqttIntegrations []*alertingNotify.GrafanaIntegrationConfig
+	rcv := *receiver
+	rcv.Integrations = make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(receiver.Integrations))
+	for _, integration := range receiver.Integrations {
+		if mqtt.IsMQTT(integration) {
+			mqttIntegrations = append(mqttIntegrations, integration)
+			continue
+		}
+		rcv.Integrations = append(rcv.Integrations, integration)
+	}
+
+	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), &rcv, am.decryptFn)
 	if err != nil {
 		return nil, err
 	}
@@ -377,6 +412,37 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
+
+	for idx, integration := range mqttIntegrations {
+		cfg, err := mqtt.BuildConfig(context.Background(), integration, am.decryptFn)
+		if err != nil {
+			return nil, alertingNotify.IntegrationValidationError{Integration: integration, Err: err}
+		}
+		meta := receivers.Metadata{
+			UID:                   integration.UID,
+			Name:                  integration.Name,
+			Type:                  integration.Type,
+			DisableResolveMessage: integration.DisableResolveMessage,
+		}
+		n := mqtt.New(cfg, meta, tmpl, LoggerFactory("ngalert.notifier."+meta.Type, "notifierUID", meta.UID))
+		integrations = append(integrations, alertingNotify.NewIntegration(n, n, meta.Type, idx, meta.Name))
+	}
+
+	if am.deliveryLog != nil {
+		integrations = am.deliveryLog.wrapIntegrations(am.orgID, receiver.Name, integrations, func(typ string, idx int) string {
+			// Integrations of the same type are indexed in the order of the receiver configuration.
+			for _, integration := range receiver.Integrations {
+				if integration.Type != typ {
+					continue
+				}
+				if idx == 0 {
+					return integration.UID
+				}
+				idx--
+			}
+			return ""
+		})
+	}
 	return integrations, nil
 }
 
@@ -2,9 +2,11 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	alertingNotify "github.com/grafana/alerting/notify"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
@@ -41,7 +44,7 @@ func setupAMTest(t *testing.T) *alertmanager {
 	kvStore := fakes.NewFakeKVStore(t)
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
 	decryptFn := secretsService.GetDecryptedValue
-	am, err := newAlertmanager(context.Background(), 1, cfg, s, kvStore, &NilPeer{}, decryptFn, nil, m)
+	am, err := newAlertmanager(context.Background(), 1, cfg, s, kvStore, &NilPeer{}, decryptFn, nil, m, s, nil)
 	require.NoError(t, err)
 	return am
 }
@@ -50,3 +53,54 @@ func TestAlertmanager_newAlertmanager(t *testing.T) {
 	am := setupAMTest(t)
 	require.False(t, am.Ready())
 }
+
+func TestAlertmanager_buildReceiverIntegrations(t *testing.T) {
+	am := setupAMTest(t)
+
+	t.Run("builds MQTT integrations next to the integrations of the alerting module", func(t *testing.T) {
+		integrations, err := am.buildReceiverIntegrations(&alertingNotify.APIReceiver{
+			ConfigReceiver: alertingNotify.ConfigReceiver{Name: "iot"},
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+				Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+					{UID: "mqtt-1", Name: "iot", Type: "mqtt", Settings: json.RawMessage(`{"brokerUrl": "tcp://localhost:1883", "topic": "alerts"}`)},
+					{UID: "email", Name: "iot", Type: "email", Settings: json.RawMessage(`{"addresses": "test@grafana.com"}`)},
+					{UID: "mqtt-2", Name: "iot", Type: "mqtt", Settings: json.RawMessage(`{"brokerUrl": "tcp://localhost:1883", "topic": "alerts", "qos": 1}`)},
+				},
+			},
+		}, nil)
+		require.NoError(t, err)
+		require.Len(t, integrations, 3)
+		require.Equal(t, "email", integrations[0].Name())
+		require.Equal(t, "mqtt[0]", integrations[1].String())
+		require.Equal(t, "mqtt[1]", integrations[2].String())
+	})
+
+	t.Run("returns validation error for invalid MQTT integration", func(t *testing.T) {
+		_, err := am.buildReceiverIntegrations(&alertingNotify.APIReceiver{
+			ConfigReceiver: alertingNotify.ConfigReceiver{Name: "iot"},
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+				Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+					{UID: "mqtt-1", Name: "iot", Type: "mqtt", Settings: json.RawMessage(`{"brokerUrl": "tcp://localhost:1883"}`)},
+				},
+			},
+		}, nil)
+		var validationErr alertingNotify.IntegrationValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, "mqtt-1", validationErr.Integration.UID)
+	})
+}
+
+func TestAlertmanager_GetStatus(t *testing.T) {
+	am := setupAMTest(t)
+
+	err := am.ApplyConfig(context.Background(), &ngmodels.AlertConfiguration{
+		AlertmanagerConfiguration: setting.GetAlertmanagerDefaultConfiguration(),
+		OrgID:                     1,
+	})
+	require.NoError(t, err)
+
+	status := am.GetStatus()
+	require.NotNil(t, status.Config.Route)
+	require.Equal(t, "grafana-default-email", status.Config.Route.Receiver)
+	require.Len(t, status.Config.Receivers, 1)
+}
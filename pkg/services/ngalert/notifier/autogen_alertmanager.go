@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// autogenRuleStore is used to get the notification settings of alert rules.
+type autogenRuleStore interface {
+	ListNotificationSettings(ctx context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error)
+}
+
+// addAutogenConfig adds the routes for the notification settings of the organization's alert rules to the configuration.
+// The routes are added as the first child of the root route, therefore alerts of rules with notification settings are
+// never routed by the notification policy tree. Settings that reference a receiver or mute timing that does not exist
+// are skipped. It returns true if any route was added.
+//
+// The root route of the configuration is replaced by a copy, so the routes are not added to other users of the route.
+func addAutogenConfig(ctx context.Context, logger log.Logger, store autogenRuleStore, orgID int64, cfg *apimodels.PostableApiAlertingConfig) (bool, error) {
+	if cfg.Route == nil {
+		return false, nil
+	}
+	settings, err := store.ListNotificationSettings(ctx, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get notification settings of alert rules: %w", err)
+	}
+	if len(settings) == 0 {
+		return false, nil
+	}
+
+	validator := NewNotificationSettingsValidator(cfg)
+	unique := make(map[string]models.NotificationSettings, len(settings))
+	for key, s := range settings {
+		if err := validator.Validate(s); err != nil {
+			logger.Warn("Skipping notification settings of alert rule", "rule_uid", key.UID, "error", err)
+			continue
+		}
+		unique[s.Fingerprint()] = s
+	}
+	if len(unique) == 0 {
+		return false, nil
+	}
+
+	fingerprints := make([]string, 0, len(unique))
+	for fp := range unique {
+		fingerprints = append(fingerprints, fp)
+	}
+	sort.Strings(fingerprints)
+
+	receiverRoutes := map[string]*apimodels.Route{}
+	for _, fp := range fingerprints {
+		s := unique[fp]
+		receiverRoute, ok := receiverRoutes[s.Receiver]
+		if !ok {
+			receiverRoute = &apimodels.Route{
+				Receiver:       s.Receiver,
+				ObjectMatchers: apimodels.ObjectMatchers{equalMatcher(models.AutogeneratedRouteReceiverNameLabel, s.Receiver)},
+			}
+			receiverRoutes[s.Receiver] = receiverRoute
+		}
+		receiverRoute.Routes = append(receiverRoute.Routes, settingsRoute(fp, s))
+	}
+
+	autogenRoute := &apimodels.Route{
+		Receiver:       cfg.Route.Receiver,
+		ObjectMatchers: apimodels.ObjectMatchers{equalMatcher(models.AutogeneratedRouteLabel, "true")},
+		Routes:         make([]*apimodels.Route, 0, len(receiverRoutes)),
+	}
+	for _, r := range receiverRoutes {
+		autogenRoute.Routes = append(autogenRoute.Routes, r)
+	}
+	sort.Slice(autogenRoute.Routes, func(i, j int) bool {
+		return autogenRoute.Routes[i].Receiver < autogenRoute.Routes[j].Receiver
+	})
+
+	root := *cfg.Route
+	root.Routes = append([]*apimodels.Route{autogenRoute}, cfg.Route.Routes...)
+	cfg.Route = &root
+	return true, nil
+}
+
+// settingsRoute creates the route that matches the alerts of rules with the given notification settings.
+func settingsRoute(fingerprint string, s models.NotificationSettings) *apimodels.Route {
+	route := &apimodels.Route{
+		Receiver:          s.Receiver,
+		ObjectMatchers:    apimodels.ObjectMatchers{equalMatcher(models.AutogeneratedRouteSettingsHashLabel, fingerprint)},
+		GroupByStr:        s.GroupBy,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+	for _, l := range s.GroupBy {
+		if l == "..." {
+			route.GroupByAll = true
+			continue
+		}
+		route.GroupBy = append(route.GroupBy, model.LabelName(l))
+	}
+	return route
+}
+
+func equalMatcher(name, value string) *labels.Matcher {
+	return &labels.Matcher{Type: labels.MatchEqual, Name: name, Value: value}
+}
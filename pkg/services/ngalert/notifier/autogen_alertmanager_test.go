@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeAutogenRuleStore struct {
+	settings map[models.AlertRuleKey]models.NotificationSettings
+	err      error
+}
+
+func (f *fakeAutogenRuleStore) ListNotificationSettings(_ context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := map[models.AlertRuleKey]models.NotificationSettings{}
+	for key, s := range f.settings {
+		if key.OrgID == orgID {
+			result[key] = s
+		}
+	}
+	return result, nil
+}
+
+func autogenTestConfig() *apimodels.PostableApiAlertingConfig {
+	return &apimodels.PostableApiAlertingConfig{
+		Config: apimodels.Config{
+			Route: &apimodels.Route{
+				Receiver: "default",
+				Routes:   []*apimodels.Route{{Receiver: "team-b"}},
+			},
+			MuteTimeIntervals: []config.MuteTimeInterval{{Name: "weekends"}},
+		},
+		Receivers: []*apimodels.PostableApiReceiver{
+			{Receiver: config.Receiver{Name: "default"}},
+			{Receiver: config.Receiver{Name: "team-a"}},
+			{Receiver: config.Receiver{Name: "team-b"}},
+		},
+	}
+}
+
+func TestAddAutogenConfig(t *testing.T) {
+	logger := log.NewNopLogger()
+
+	t.Run("should not change the configuration if no rule has notification settings", func(t *testing.T) {
+		cfg := autogenTestConfig()
+		root := cfg.Route
+		added, err := addAutogenConfig(context.Background(), logger, &fakeAutogenRuleStore{}, 1, cfg)
+		require.NoError(t, err)
+		require.False(t, added)
+		require.Same(t, root, cfg.Route)
+	})
+
+	t.Run("should return the error of the store", func(t *testing.T) {
+		_, err := addAutogenConfig(context.Background(), logger, &fakeAutogenRuleStore{err: errors.New("test")}, 1, autogenTestConfig())
+		require.ErrorContains(t, err, "test")
+	})
+
+	t.Run("should add a route per receiver and settings", func(t *testing.T) {
+		repeat := model.Duration(time.Hour)
+		teamA := models.NotificationSettings{Receiver: "team-a"}
+		teamB := models.NotificationSettings{Receiver: "team-b", GroupBy: []string{"alertname"}, RepeatInterval: &repeat, MuteTimeIntervals: []string{"weekends"}}
+		teamBAll := models.NotificationSettings{Receiver: "team-b", GroupBy: []string{"..."}}
+		store := &fakeAutogenRuleStore{settings: map[models.AlertRuleKey]models.NotificationSettings{
+			{OrgID: 1, UID: "rule-1"}: teamA,
+			{OrgID: 1, UID: "rule-2"}: teamA,
+			{OrgID: 1, UID: "rule-3"}: teamB,
+			{OrgID: 1, UID: "rule-4"}: teamBAll,
+			{OrgID: 1, UID: "rule-5"}: {Receiver: "unknown"},
+			{OrgID: 1, UID: "rule-6"}: {Receiver: "team-a", MuteTimeIntervals: []string{"unknown"}},
+			{OrgID: 2, UID: "rule-7"}: {Receiver: "team-c"},
+		}}
+
+		cfg := autogenTestConfig()
+		original := cfg.Route
+		added, err := addAutogenConfig(context.Background(), logger, store, 1, cfg)
+		require.NoError(t, err)
+		require.True(t, added)
+
+		require.Len(t, original.Routes, 1, "the original route must not be changed")
+		require.Equal(t, "default", cfg.Route.Receiver)
+		require.Len(t, cfg.Route.Routes, 2)
+		require.Same(t, original.Routes[0], cfg.Route.Routes[1])
+
+		autogen := cfg.Route.Routes[0]
+		require.Equal(t, "default", autogen.Receiver)
+		require.False(t, autogen.Continue)
+		require.Equal(t, `__grafana_autogenerated__="true"`, autogen.ObjectMatchers[0].String())
+		require.Len(t, autogen.Routes, 2)
+
+		receiverA := autogen.Routes[0]
+		require.Equal(t, "team-a", receiverA.Receiver)
+		require.Equal(t, models.AutogeneratedRouteReceiverNameLabel, receiverA.ObjectMatchers[0].Name)
+		require.Equal(t, "team-a", receiverA.ObjectMatchers[0].Value)
+		require.Len(t, receiverA.Routes, 1)
+		require.Equal(t, teamA.Fingerprint(), receiverA.Routes[0].ObjectMatchers[0].Value)
+
+		receiverB := autogen.Routes[1]
+		require.Equal(t, "team-b", receiverB.Receiver)
+		require.Len(t, receiverB.Routes, 2)
+		for _, r := range receiverB.Routes {
+			require.Equal(t, models.AutogeneratedRouteSettingsHashLabel, r.ObjectMatchers[0].Name)
+			switch r.ObjectMatchers[0].Value {
+			case teamB.Fingerprint():
+				require.Equal(t, []string{"alertname"}, r.GroupByStr)
+				require.Equal(t, []model.LabelName{"alertname"}, r.GroupBy)
+				require.Equal(t, &repeat, r.RepeatInterval)
+				require.Equal(t, []string{"weekends"}, r.MuteTimeIntervals)
+			case teamBAll.Fingerprint():
+				require.True(t, r.GroupByAll)
+				require.Empty(t, r.GroupBy)
+			default:
+				t.Fatalf("unexpected route %v", r.ObjectMatchers)
+			}
+		}
+	})
+
+	t.Run("should generate the same routes for the same settings", func(t *testing.T) {
+		store := &fakeAutogenRuleStore{settings: map[models.AlertRuleKey]models.NotificationSettings{}}
+		for _, uid := range []string{"a", "b", "c", "d", "e"} {
+			store.settings[models.AlertRuleKey{OrgID: 1, UID: uid}] = models.NotificationSettings{Receiver: "team-" + uid, GroupBy: []string{uid}}
+		}
+		store.settings[models.AlertRuleKey{OrgID: 1, UID: "f"}] = models.NotificationSettings{Receiver: "team-a"}
+		store.settings[models.AlertRuleKey{OrgID: 1, UID: "g"}] = models.NotificationSettings{Receiver: "team-b"}
+
+		first := autogenTestConfig()
+		_, err := addAutogenConfig(context.Background(), logger, store, 1, first)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			cfg := autogenTestConfig()
+			_, err := addAutogenConfig(context.Background(), logger, store, 1, cfg)
+			require.NoError(t, err)
+			require.Equal(t, first, cfg)
+		}
+	})
+}
@@ -1374,6 +1374,112 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "mqtt",
+			Name:        "MQTT",
+			Description: "Publishes notifications to an MQTT broker",
+			Heading:     "MQTT settings",
+			Info:        "Notifications are published with MQTT 3.1.1",
+			Options: []NotifierOption{
+				{
+					Label:        "Broker URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The URL of the MQTT broker. Use the tcp or mqtt scheme for plain connections and the ssl, tls or mqtts scheme for TLS connections.",
+					Placeholder:  "tcp://localhost:1883",
+					PropertyName: "brokerUrl",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "Templated topic to publish the notifications to. Wildcards are not allowed.",
+					Placeholder:  "grafana/alerts",
+					PropertyName: "topic",
+					Required:     true,
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Description:  "Templated payload of the message",
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+				{
+					Label:        "QoS",
+					Element:      ElementTypeSelect,
+					InputType:    InputTypeText,
+					Description:  "The quality of service level of the messages. By default 0 will be used.",
+					PropertyName: "qos",
+					SelectOptions: []SelectOption{
+						{
+							Value: "0",
+							Label: "At most once (0)",
+						},
+						{
+							Value: "1",
+							Label: "At least once (1)",
+						},
+						{
+							Value: "2",
+							Label: "Exactly once (2)",
+						},
+					},
+				},
+				{
+					Label:        "Retain",
+					Element:      ElementTypeCheckbox,
+					Description:  "Ask the broker to retain the last message of the topic",
+					PropertyName: "retain",
+				},
+				{
+					Label:        "Client ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Description:  "The client identifier to connect with. By default a random identifier is used for every connection.",
+					PropertyName: "clientId",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS CA certificate",
+					Element:      ElementTypeTextArea,
+					Description:  "PEM encoded certificate of the CA that signed the certificate of the broker",
+					PropertyName: "tlsCaCertificate",
+				},
+				{
+					Label:        "TLS client certificate",
+					Element:      ElementTypeTextArea,
+					Description:  "PEM encoded client certificate",
+					PropertyName: "tlsClientCertificate",
+				},
+				{
+					Label:        "TLS client key",
+					Element:      ElementTypeTextArea,
+					Description:  "PEM encoded private key of the client certificate",
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+				},
+				{
+					Label:        "Skip TLS verification",
+					Element:      ElementTypeCheckbox,
+					Description:  "Do not verify the certificate of the broker",
+					PropertyName: "tlsInsecureSkipVerify",
+				},
+			},
+		},
 	}
 }
 
@@ -0,0 +1,270 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	// deliveryLogBufferSize is the number of delivery attempts that can wait to be written to the database.
+	// Attempts are dropped when the buffer is full, so that a slow database never delays notifications.
+	deliveryLogBufferSize = 1000
+	// deliveryLogBatchSize is the maximum number of delivery attempts written at once.
+	deliveryLogBatchSize = 100
+	// deliveryLogFlushInterval is how often the buffered delivery attempts are written to the database.
+	deliveryLogFlushInterval = 5 * time.Second
+	// deliveryLogWriteTimeout is the timeout of writing a batch of delivery attempts.
+	deliveryLogWriteTimeout = 10 * time.Second
+)
+
+// DeliveryLogStore is the store of the notification delivery log.
+type DeliveryLogStore interface {
+	InsertNotificationDeliveries(ctx context.Context, deliveries []models.NotificationDelivery) error
+}
+
+// DeliveryLog records every attempt of the integrations of the Grafana Alertmanagers to deliver a notification.
+// The attempts are buffered and written to the database in batches by Run.
+type DeliveryLog struct {
+	store  DeliveryLogStore
+	buffer chan models.NotificationDelivery
+	clock  clock.Clock
+	logger log.Logger
+}
+
+func NewDeliveryLog(store DeliveryLogStore, logger log.Logger) *DeliveryLog {
+	return &DeliveryLog{
+		store:  store,
+		buffer: make(chan models.NotificationDelivery, deliveryLogBufferSize),
+		clock:  clock.New(),
+		logger: logger,
+	}
+}
+
+// Run writes the delivery attempts to the database until the context is cancelled. The attempts that are still
+// buffered at that point are written before it returns.
+func (d *DeliveryLog) Run(ctx context.Context) error {
+	d.logger.Info("Starting notification delivery log")
+	ticker := d.clock.Ticker(deliveryLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.NotificationDelivery, 0, deliveryLogBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for len(d.buffer) > 0 {
+				batch = d.flushIfFull(append(batch, <-d.buffer))
+			}
+			d.flush(batch)
+			d.logger.Info("Stopped notification delivery log")
+			return nil
+		case entry := <-d.buffer:
+			batch = d.flushIfFull(append(batch, entry))
+		case <-ticker.C:
+			d.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (d *DeliveryLog) flushIfFull(batch []models.NotificationDelivery) []models.NotificationDelivery {
+	if len(batch) < deliveryLogBatchSize {
+		return batch
+	}
+	d.flush(batch)
+	return batch[:0]
+}
+
+func (d *DeliveryLog) flush(batch []models.NotificationDelivery) {
+	if len(batch) == 0 {
+		return
+	}
+	// The context of Run is not used, so that the buffered attempts are written when Grafana shuts down.
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryLogWriteTimeout)
+	defer cancel()
+	if err := d.store.InsertNotificationDeliveries(ctx, batch); err != nil {
+		d.logger.Error("Failed to write notification delivery attempts", "count", len(batch), "error", err)
+		return
+	}
+	d.logger.Debug("Wrote notification delivery attempts", "count", len(batch))
+}
+
+func (d *DeliveryLog) record(entry models.NotificationDelivery) {
+	select {
+	case d.buffer <- entry:
+	default:
+		d.logger.Warn("Dropping notification delivery attempt because the buffer is full", "receiver", entry.Receiver, "integration", entry.Integration)
+	}
+}
+
+// wrapIntegrations returns integrations that record the delivery attempts of the given integrations of a receiver.
+// uidFn returns the UID of the integration with the given type and index.
+func (d *DeliveryLog) wrapIntegrations(orgID int64, receiver string, integrations []*alertingNotify.Integration, uidFn func(typ string, idx int) string) []*alertingNotify.Integration {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		n := &deliveryLoggingNotifier{
+			log:         d,
+			integration: integration,
+			orgID:       orgID,
+			receiver:    receiver,
+			uid:         uidFn(integration.Name(), integration.Index()),
+			clock:       d.clock,
+			attempts:    make(map[string]flushAttempts),
+		}
+		result = append(result, alertingNotify.NewIntegration(n, n, integration.Name(), integration.Index(), receiver))
+	}
+	return result
+}
+
+// flushAttempts counts the failed attempts to send the notification of a flush of an aggregation group.
+type flushAttempts struct {
+	now    time.Time
+	failed int
+}
+
+// deliveryLoggingNotifier records the attempts of an integration to deliver a notification in the DeliveryLog.
+type deliveryLoggingNotifier struct {
+	log         *DeliveryLog
+	integration *alertingNotify.Integration
+	orgID       int64
+	receiver    string
+	uid         string
+	clock       clock.Clock
+
+	mtx sync.Mutex
+	// attempts are the attempts of the current flush, by aggregation group. The notify pipeline retries failed
+	// notifications with the same context, so the retries of a notification have the same flush time.
+	attempts map[string]flushAttempts
+}
+
+func (n *deliveryLoggingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	rec := &deliveryRecorder{}
+	start := n.clock.Now()
+	retry, err := n.integration.Notify(withDeliveryRecorder(ctx, rec), alerts...)
+	duration := n.clock.Since(start)
+
+	fingerprints := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		fingerprints = append(fingerprints, a.Fingerprint().String())
+	}
+	groupKey, _ := notify.GroupKey(ctx)
+	entry := models.NotificationDelivery{
+		OrgID:             n.orgID,
+		Receiver:          n.receiver,
+		Integration:       n.integration.Name(),
+		IntegrationUID:    n.uid,
+		IntegrationIndex:  n.integration.Index(),
+		GroupKey:          groupKey,
+		AlertFingerprints: strings.Join(fingerprints, ","),
+		Duration:          duration.Milliseconds(),
+		Retry:             n.retries(ctx, groupKey, err == nil || !retry),
+		Success:           err == nil,
+		AttemptedAt:       start,
+	}
+	entry.StatusCode, entry.PayloadHash = rec.result()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	n.log.record(entry)
+	return retry, err
+}
+
+func (n *deliveryLoggingNotifier) SendResolved() bool {
+	return n.integration.SendResolved()
+}
+
+// retries returns the number of failed attempts to send the same notification before the current attempt.
+// If done is true, the current attempt is the last one.
+func (n *deliveryLoggingNotifier) retries(ctx context.Context, groupKey string, done bool) int {
+	now, ok := notify.Now(ctx)
+	if !ok {
+		// Test notifications are not sent by the notify pipeline and are not retried.
+		return 0
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	a, ok := n.attempts[groupKey]
+	if !ok || !a.now.Equal(now) {
+		a = flushAttempts{now: now}
+	}
+	retries := a.failed
+	if done {
+		delete(n.attempts, groupKey)
+		return retries
+	}
+	a.failed++
+	n.attempts[groupKey] = a
+	return retries
+}
+
+type deliveryRecorderKey struct{}
+
+// deliveryRecorder records the webhooks that an integration sends for a delivery attempt.
+type deliveryRecorder struct {
+	mtx        sync.Mutex
+	statusCode int
+	payload    hash.Hash
+}
+
+func withDeliveryRecorder(ctx context.Context, rec *deliveryRecorder) context.Context {
+	return context.WithValue(ctx, deliveryRecorderKey{}, rec)
+}
+
+func deliveryRecorderFromContext(ctx context.Context) (*deliveryRecorder, bool) {
+	rec, ok := ctx.Value(deliveryRecorderKey{}).(*deliveryRecorder)
+	return rec, ok
+}
+
+func (r *deliveryRecorder) recordPayload(body string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.payload == nil {
+		r.payload = sha256.New()
+	}
+	_, _ = r.payload.Write([]byte(body))
+}
+
+func (r *deliveryRecorder) recordStatusCode(statusCode int) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.statusCode = statusCode
+}
+
+// result returns the status code of the last response and the hash of the payloads of all webhooks.
+func (r *deliveryRecorder) result() (int, string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.payload == nil {
+		return r.statusCode, ""
+	}
+	return r.statusCode, hex.EncodeToString(r.payload.Sum(nil))
+}
+
+// DeleteExpiredDeliveriesService is a service to delete the delivery attempts that are older than the retention of
+// the notification delivery log.
+type DeleteExpiredDeliveriesService struct {
+	store interface {
+		DeleteExpiredNotificationDeliveries(context.Context) (int64, error)
+	}
+}
+
+func (s *DeleteExpiredDeliveriesService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.store.DeleteExpiredNotificationDeliveries(ctx)
+}
+
+func ProvideDeleteExpiredDeliveriesService(store *store.DBstore) *DeleteExpiredDeliveriesService {
+	return &DeleteExpiredDeliveriesService{store: store}
+}
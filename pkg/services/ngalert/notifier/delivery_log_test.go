@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type fakeDeliveryLogStore struct {
+	mtx        sync.Mutex
+	deliveries []models.NotificationDelivery
+}
+
+func (f *fakeDeliveryLogStore) InsertNotificationDeliveries(_ context.Context, deliveries []models.NotificationDelivery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deliveries = append(f.deliveries, deliveries...)
+	return nil
+}
+
+// webhookNotifier sends the same webhook for every notification, like the webhook integration.
+type webhookNotifier struct {
+	sender receivers.WebhookSender
+}
+
+func (n webhookNotifier) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	err := n.sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: "http://localhost", Body: `{"status":"firing"}`})
+	return err != nil, err
+}
+
+func (n webhookNotifier) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryLog(t *testing.T) {
+	statusCodes := []int{503, 502, 200}
+	ns := &notifications.NotificationServiceMock{
+		WebhookHandler: func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+			statusCode := statusCodes[0]
+			statusCodes = statusCodes[1:]
+			if err := cmd.Validation(nil, statusCode); err != nil {
+				return err
+			}
+			if statusCode != 200 {
+				return fmt.Errorf("webhook response status %d", statusCode)
+			}
+			return nil
+		},
+	}
+	n := webhookNotifier{sender: &sender{ns}}
+
+	store := &fakeDeliveryLogStore{}
+	d := NewDeliveryLog(store, log.NewNopLogger())
+	integrations := d.wrapIntegrations(1, "team-a", []*alertingNotify.Integration{alertingNotify.NewIntegration(n, n, "webhook", 0, "team-a")}, func(typ string, idx int) string {
+		require.Equal(t, "webhook", typ)
+		require.Equal(t, 0, idx)
+		return "webhook-uid"
+	})
+	require.Len(t, integrations, 1)
+	integration := integrations[0]
+	require.Equal(t, "webhook", integration.Name())
+	require.True(t, integration.SendResolved())
+
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+	now := time.Now()
+	ctx := notify.WithNow(notify.WithGroupKey(context.Background(), "{}:{alertname=\"test\"}"), now)
+	for i := 0; i < 3; i++ {
+		retry, err := integration.Notify(ctx, alert)
+		if i < 2 {
+			require.Error(t, err)
+			require.True(t, retry)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	// The next flush of the group starts counting retries again.
+	statusCodes = []int{200}
+	_, err := integration.Notify(notify.WithNow(ctx, now.Add(time.Minute)), alert)
+	require.NoError(t, err)
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, d.Run(runCtx))
+
+	require.Len(t, store.deliveries, 4)
+	hash := sha256.Sum256([]byte(`{"status":"firing"}`))
+	for i, delivery := range store.deliveries {
+		require.Equal(t, int64(1), delivery.OrgID)
+		require.Equal(t, "team-a", delivery.Receiver)
+		require.Equal(t, "webhook", delivery.Integration)
+		require.Equal(t, "webhook-uid", delivery.IntegrationUID)
+		require.Equal(t, "{}:{alertname=\"test\"}", delivery.GroupKey)
+		require.Equal(t, alert.Fingerprint().String(), delivery.AlertFingerprints)
+		require.Equal(t, hex.EncodeToString(hash[:]), delivery.PayloadHash)
+		require.False(t, delivery.AttemptedAt.IsZero(), i)
+	}
+	require.Equal(t, []int{503, 502, 200, 200}, []int{store.deliveries[0].StatusCode, store.deliveries[1].StatusCode, store.deliveries[2].StatusCode, store.deliveries[3].StatusCode})
+	require.Equal(t, []int{0, 1, 2, 0}, []int{store.deliveries[0].Retry, store.deliveries[1].Retry, store.deliveries[2].Retry, store.deliveries[3].Retry})
+	require.Equal(t, []bool{false, false, true, true}, []bool{store.deliveries[0].Success, store.deliveries[1].Success, store.deliveries[2].Success, store.deliveries[3].Success})
+	require.NotEmpty(t, store.deliveries[0].Error)
+	require.Empty(t, store.deliveries[2].Error)
+
+	t.Run("test notifications are not retried", func(t *testing.T) {
+		statusCodes = []int{200}
+		_, err := integration.Notify(context.Background(), alert)
+		require.NoError(t, err)
+		entry := <-d.buffer
+		require.Zero(t, entry.Retry)
+		require.Empty(t, entry.GroupKey)
+	})
+}
@@ -0,0 +1,94 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// keepAlive is the interval of the pings that the client sends to the broker while the connection is open.
+	keepAlive = 30 * time.Second
+	// defaultTimeout is used when the context has no deadline.
+	defaultTimeout = 30 * time.Second
+	// protocolVersion is MQTT 3.1.1. It is set explicitly, so the client does not fall back to MQTT 3.1 with a second
+	// connection when the broker refuses the connection.
+	protocolVersion = 4
+	// disconnectQuiesce is the time in milliseconds that the client waits for pending work before it disconnects.
+	disconnectQuiesce = 250
+)
+
+// message is a message published to the broker.
+type message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// publish connects to the broker, publishes the message and disconnects. For QoS 1 and 2 it returns only after the
+// broker acknowledged the message.
+//
+// A connection is opened for every notification instead of keeping one open. Notifiers are created again whenever
+// the configuration of the Alertmanager changes, and they cannot close a long-lived connection when they are
+// replaced. Therefore, the client does not reconnect: a notification that fails is retried by the Alertmanager,
+// which opens a new connection.
+func publish(ctx context.Context, cfg Config, msg message) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = randomClientID()
+	}
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.brokerURL()).
+		SetClientID(clientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetProtocolVersion(protocolVersion).
+		SetCleanSession(true).
+		SetKeepAlive(keepAlive).
+		SetConnectTimeout(time.Until(deadline)).
+		SetAutoReconnect(false)
+	if cfg.tlsConfig != nil {
+		opts.SetTLSConfig(cfg.tlsConfig)
+	}
+
+	client := paho.NewClient(opts)
+	if err := wait(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("failed to connect to the broker: %w", err)
+	}
+	defer client.Disconnect(disconnectQuiesce)
+
+	if err := wait(ctx, client.Publish(msg.Topic, msg.QoS, msg.Retain, msg.Payload)); err != nil {
+		return fmt.Errorf("failed to publish the message: %w", err)
+	}
+	return nil
+}
+
+// wait waits until the operation of the token completes or the context is done.
+func wait(ctx context.Context, token paho.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// randomClientID returns a new client identifier. A random identifier prevents the broker from disconnecting
+// concurrent clients of the same Grafana instance or of other instances in high availability mode.
+func randomClientID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "grafana-" + hex.EncodeToString(b)
+}
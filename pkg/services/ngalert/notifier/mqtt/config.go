@@ -0,0 +1,135 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	defaultPort    = "1883"
+	defaultTLSPort = "8883"
+)
+
+// Config is the configuration of the MQTT integration.
+type Config struct {
+	BrokerURL string                   `json:"brokerUrl,omitempty" yaml:"brokerUrl,omitempty"`
+	ClientID  string                   `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+	Topic     string                   `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Message   string                   `json:"message,omitempty" yaml:"message,omitempty"`
+	QoS       receivers.OptionalNumber `json:"qos,omitempty" yaml:"qos,omitempty"`
+	Retain    bool                     `json:"retain,omitempty" yaml:"retain,omitempty"`
+	Username  string                   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password  string                   `json:"password,omitempty" yaml:"password,omitempty"`
+
+	TLSCACertificate      string `json:"tlsCaCertificate,omitempty" yaml:"tlsCaCertificate,omitempty"`
+	TLSClientCertificate  string `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty"`
+	TLSClientKey          string `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty"`
+	TLSInsecureSkipVerify bool   `json:"tlsInsecureSkipVerify,omitempty" yaml:"tlsInsecureSkipVerify,omitempty"`
+
+	// address is the host:port of the broker parsed from BrokerURL.
+	address string
+	// tlsConfig is nil if the connection to the broker is not encrypted.
+	tlsConfig *tls.Config
+	qos       byte
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	var settings Config
+	err := json.Unmarshal(jsonData, &settings)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	if settings.BrokerURL == "" {
+		return Config{}, errors.New("could not find broker URL property in settings")
+	}
+	if settings.Topic == "" {
+		return Config{}, errors.New("could not find topic property in settings")
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	settings.Password = decryptFn("password", settings.Password)
+	settings.TLSClientKey = decryptFn("tlsClientKey", settings.TLSClientKey)
+
+	qos, err := settings.QoS.Int64()
+	if err != nil || qos < 0 || qos > 2 {
+		return Config{}, fmt.Errorf("invalid QoS %q, must be 0, 1 or 2", settings.QoS)
+	}
+	settings.qos = byte(qos)
+
+	u, err := url.Parse(settings.BrokerURL)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid broker URL: %w", err)
+	}
+	var useTLS bool
+	switch strings.ToLower(u.Scheme) {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS = true
+	default:
+		return Config{}, fmt.Errorf("unsupported broker URL scheme %q, must be one of tcp, mqtt, ssl, tls or mqtts", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return Config{}, errors.New("broker URL has no host")
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+		if useTLS {
+			port = defaultTLSPort
+		}
+	}
+	settings.address = net.JoinHostPort(u.Hostname(), port)
+
+	if useTLS {
+		settings.tlsConfig, err = settings.buildTLSConfig(u.Hostname())
+		if err != nil {
+			return Config{}, err
+		}
+	} else if settings.TLSCACertificate != "" || settings.TLSClientCertificate != "" || settings.TLSClientKey != "" {
+		return Config{}, errors.New("TLS certificates are configured but the broker URL does not use TLS, use the ssl, tls or mqtts scheme")
+	}
+	return settings, nil
+}
+
+func (c Config) buildTLSConfig(serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName: serverName,
+		// nolint:gosec
+		InsecureSkipVerify: c.TLSInsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if c.TLSCACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.TLSCACertificate)) {
+			return nil, errors.New("failed to parse the CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if c.TLSClientCertificate != "" || c.TLSClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(c.TLSClientCertificate), []byte(c.TLSClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the client certificate and key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// brokerURL returns the URL of the broker in the form expected by the MQTT client.
+func (c Config) brokerURL() string {
+	if c.tlsConfig != nil {
+		return "ssl://" + c.address
+	}
+	return "tcp://" + c.address
+}
@@ -0,0 +1,146 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"testing"
+
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedAddress   string
+		expectedTLS       bool
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if broker URL is empty",
+			settings:          `{"topic": "grafana/alerts"}`,
+			expectedInitError: `could not find broker URL property in settings`,
+		},
+		{
+			name:              "Error if topic is empty",
+			settings:          `{"brokerUrl": "tcp://localhost"}`,
+			expectedInitError: `could not find topic property in settings`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{"brokerUrl": "tcp://localhost", "topic": "grafana/alerts"}`,
+			expectedConfig: Config{
+				BrokerURL: "tcp://localhost",
+				Topic:     "grafana/alerts",
+				Message:   templates.DefaultMessageEmbed,
+			},
+			expectedAddress: "localhost:1883",
+		},
+		{
+			name:     "Default TLS port",
+			settings: `{"brokerUrl": "mqtts://localhost", "topic": "grafana/alerts"}`,
+			expectedConfig: Config{
+				BrokerURL: "mqtts://localhost",
+				Topic:     "grafana/alerts",
+				Message:   templates.DefaultMessageEmbed,
+			},
+			expectedAddress: "localhost:8883",
+			expectedTLS:     true,
+		},
+		{
+			name: "All fields with secrets",
+			settings: `{
+				"brokerUrl": "ssl://broker:1234",
+				"clientId": "grafana",
+				"topic": "alerts/{{ .CommonLabels.alertname }}",
+				"message": "{{ .Status }}",
+				"qos": "2",
+				"retain": true,
+				"username": "user",
+				"password": "plain",
+				"tlsInsecureSkipVerify": true
+			}`,
+			secureSettings: map[string][]byte{
+				"password": []byte("secret"),
+			},
+			expectedConfig: Config{
+				BrokerURL:             "ssl://broker:1234",
+				ClientID:              "grafana",
+				Topic:                 "alerts/{{ .CommonLabels.alertname }}",
+				Message:               "{{ .Status }}",
+				QoS:                   "2",
+				Retain:                true,
+				Username:              "user",
+				Password:              "secret",
+				TLSInsecureSkipVerify: true,
+			},
+			expectedAddress: "broker:1234",
+			expectedTLS:     true,
+		},
+		{
+			name:     "QoS as a number",
+			settings: `{"brokerUrl": "tcp://localhost", "topic": "grafana/alerts", "qos": 1}`,
+			expectedConfig: Config{
+				BrokerURL: "tcp://localhost",
+				Topic:     "grafana/alerts",
+				Message:   templates.DefaultMessageEmbed,
+				QoS:       "1",
+			},
+			expectedAddress: "localhost:1883",
+		},
+		{
+			name:              "Error if QoS is invalid",
+			settings:          `{"brokerUrl": "tcp://localhost", "topic": "grafana/alerts", "qos": "3"}`,
+			expectedInitError: `invalid QoS "3", must be 0, 1 or 2`,
+		},
+		{
+			name:              "Error if scheme is not supported",
+			settings:          `{"brokerUrl": "http://localhost", "topic": "grafana/alerts"}`,
+			expectedInitError: `unsupported broker URL scheme "http"`,
+		},
+		{
+			name:              "Error if URL has no host",
+			settings:          `{"brokerUrl": "tcp://:1883", "topic": "grafana/alerts"}`,
+			expectedInitError: `broker URL has no host`,
+		},
+		{
+			name:              "Error if CA certificate is invalid",
+			settings:          `{"brokerUrl": "tls://localhost", "topic": "grafana/alerts", "tlsCaCertificate": "invalid"}`,
+			expectedInitError: `failed to parse the CA certificate`,
+		},
+		{
+			name:              "Error if client key is missing",
+			settings:          `{"brokerUrl": "tls://localhost", "topic": "grafana/alerts", "tlsClientCertificate": "invalid"}`,
+			expectedInitError: `failed to parse the client certificate and key`,
+		},
+		{
+			name:              "Error if certificates are configured without TLS",
+			settings:          `{"brokerUrl": "tcp://localhost", "topic": "grafana/alerts", "tlsCaCertificate": "invalid"}`,
+			expectedInitError: `TLS certificates are configured but the broker URL does not use TLS`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedAddress, actual.address)
+			require.Equal(t, c.expectedTLS, actual.tlsConfig != nil)
+			actual.address, actual.tlsConfig, actual.qos = "", nil, 0
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
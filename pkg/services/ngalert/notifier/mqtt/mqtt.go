@@ -0,0 +1,109 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// Type is the type of the MQTT integration.
+const Type = "mqtt"
+
+// Notifier is responsible for publishing alert notifications to an MQTT broker.
+type Notifier struct {
+	*receivers.Base
+	log      logging.Logger
+	tmpl     *templates.Template
+	settings Config
+}
+
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// Notify publishes the alert notification to the topic of the broker.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	topic := tmpl(n.settings.Topic)
+	if tmplErr != nil {
+		n.log.Warn("failed to template MQTT topic", "error", tmplErr.Error())
+		tmplErr = nil
+	}
+	if err := validateTopic(topic); err != nil {
+		return false, err
+	}
+
+	payload := tmpl(n.settings.Message)
+	if tmplErr != nil {
+		n.log.Warn("failed to template MQTT message", "error", tmplErr.Error())
+	}
+
+	err := publish(ctx, n.settings, message{
+		Topic:   topic,
+		Payload: []byte(payload),
+		QoS:     n.settings.qos,
+		Retain:  n.settings.Retain,
+	})
+	if err != nil {
+		n.log.Error("Failed to publish notification to MQTT broker", "broker", n.settings.BrokerURL, "topic", topic, "error", err)
+		// the broker may be temporarily unavailable, therefore, the notification is retried.
+		return true, err
+	}
+	return true, nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// validateTopic returns an error if the topic cannot be used to publish a message.
+func validateTopic(topic string) error {
+	if topic == "" {
+		return errors.New("topic is empty after templating")
+	}
+	if len(topic) > math.MaxUint16 {
+		return errors.New("topic is too long")
+	}
+	if strings.ContainsAny(topic, "+#\x00") {
+		return fmt.Errorf("topic %q must not contain wildcards", topic)
+	}
+	return nil
+}
+
+// BuildConfig parses, decrypts and validates the settings of an MQTT integration. The secure settings of the
+// integration are expected to be base64 encoded, like in alertingNotify.BuildReceiverConfiguration.
+func BuildConfig(ctx context.Context, integration *alertingNotify.GrafanaIntegrationConfig, decrypt alertingNotify.GetDecryptedValueFn) (Config, error) {
+	secureSettings := make(map[string][]byte, len(integration.SecureSettings))
+	for k, v := range integration.SecureSettings {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to decode secure settings key %s: %w", k, err)
+		}
+		secureSettings[k] = d
+	}
+	return NewConfig(integration.Settings, func(key string, fallback string) string {
+		return decrypt(ctx, secureSettings, key, fallback)
+	})
+}
+
+// IsMQTT returns true if the integration is an MQTT integration. These integrations are not supported by
+// alertingNotify.BuildReceiverConfiguration and must be built with BuildConfig and New.
+func IsMQTT(integration *alertingNotify.GrafanaIntegrationConfig) bool {
+	return strings.EqualFold(integration.Type, Type)
+}
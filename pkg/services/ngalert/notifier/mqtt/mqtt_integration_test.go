@@ -0,0 +1,152 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegrationMQTTBroker publishes notifications to a real broker, for example, the one of the mosquitto block of
+// devenv:
+//
+//	MQTT_BROKER_URL=tcp://localhost:1883 MQTT_TLS_BROKER_URL=tls://localhost:8883 \
+//	MQTT_TLS_CERTS=devenv/docker/blocks/mosquitto/certs go test -run IntegrationMQTT ./pkg/services/ngalert/notifier/mqtt
+func TestIntegrationMQTTBroker(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	brokerURL, ok := os.LookupEnv("MQTT_BROKER_URL")
+	if !ok || brokerURL == "" {
+		t.Skip("No MQTT broker URL supplied")
+	}
+
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "HighTemperature", "machine": "press-1"},
+				Annotations: model.LabelSet{"summary": "too hot"},
+			},
+		},
+	}
+
+	// publishAndReceive publishes the alerts with the settings and returns the message that a subscriber of the topic received.
+	publishAndReceive := func(t *testing.T, settings map[string]any, secrets map[string][]byte, subscriber *paho.ClientOptions) paho.Message {
+		t.Helper()
+		topic := fmt.Sprintf("grafana/test/%d", time.Now().UnixNano())
+		settings["topic"] = topic + "/{{ .CommonLabels.machine }}"
+		settings["message"] = "{{ .Status }}: {{ .CommonAnnotations.summary }}"
+
+		received := make(chan paho.Message, 1)
+		client := paho.NewClient(subscriber.SetClientID(fmt.Sprintf("grafana-test-%d", time.Now().UnixNano())))
+		token := client.Connect()
+		require.True(t, token.WaitTimeout(10*time.Second))
+		require.NoError(t, token.Error())
+		t.Cleanup(func() { client.Disconnect(0) })
+		token = client.Subscribe(topic+"/#", 2, func(_ paho.Client, m paho.Message) {
+			received <- m
+		})
+		require.True(t, token.WaitTimeout(10*time.Second))
+		require.NoError(t, token.Error())
+
+		raw, err := json.Marshal(settings)
+		require.NoError(t, err)
+		cfg, err := NewConfig(raw, receiversTesting.DecryptForTesting(secrets))
+		require.NoError(t, err)
+		n := New(cfg, receivers.Metadata{Type: Type}, tmpl, &logging.FakeLogger{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		ctx = notify.WithGroupKey(ctx, "alertname")
+		_, err = n.Notify(notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "HighTemperature"}), alerts...)
+		require.NoError(t, err)
+
+		select {
+		case m := <-received:
+			require.Equal(t, topic+"/press-1", m.Topic())
+			return m
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for the message")
+			return nil
+		}
+	}
+
+	for _, qos := range []byte{0, 1, 2} {
+		t.Run(fmt.Sprintf("publishes message with QoS %d", qos), func(t *testing.T) {
+			m := publishAndReceive(t, map[string]any{"brokerUrl": brokerURL, "qos": qos}, nil, paho.NewClientOptions().AddBroker(brokerURL))
+			require.Equal(t, "firing: too hot", string(m.Payload()))
+			require.Equal(t, qos, m.Qos())
+		})
+	}
+
+	t.Run("publishes message with QoS 2 over TLS with a client certificate", func(t *testing.T) {
+		tlsBrokerURL, certs := os.Getenv("MQTT_TLS_BROKER_URL"), os.Getenv("MQTT_TLS_CERTS")
+		if tlsBrokerURL == "" || certs == "" {
+			t.Skip("No MQTT TLS broker URL or certificates supplied")
+		}
+		ca, err := os.ReadFile(filepath.Join(certs, "ca.crt"))
+		require.NoError(t, err)
+		cert, err := os.ReadFile(filepath.Join(certs, "client.crt"))
+		require.NoError(t, err)
+		key, err := os.ReadFile(filepath.Join(certs, "client.key"))
+		require.NoError(t, err)
+
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM(ca))
+		keyPair, err := tls.X509KeyPair(cert, key)
+		require.NoError(t, err)
+		subscriber := paho.NewClientOptions().AddBroker(tlsBrokerURL).SetTLSConfig(&tls.Config{
+			RootCAs:      pool,
+			Certificates: []tls.Certificate{keyPair},
+			MinVersion:   tls.VersionTLS12,
+		})
+
+		m := publishAndReceive(t, map[string]any{
+			"brokerUrl":            tlsBrokerURL,
+			"qos":                  2,
+			"tlsCaCertificate":     string(ca),
+			"tlsClientCertificate": string(cert),
+		}, map[string][]byte{"tlsClientKey": key}, subscriber)
+		require.Equal(t, "firing: too hot", string(m.Payload()))
+		require.Equal(t, byte(2), m.Qos())
+	})
+
+	t.Run("fails without a client certificate", func(t *testing.T) {
+		tlsBrokerURL, certs := os.Getenv("MQTT_TLS_BROKER_URL"), os.Getenv("MQTT_TLS_CERTS")
+		if tlsBrokerURL == "" || certs == "" {
+			t.Skip("No MQTT TLS broker URL or certificates supplied")
+		}
+		ca, err := os.ReadFile(filepath.Join(certs, "ca.crt"))
+		require.NoError(t, err)
+		raw, err := json.Marshal(map[string]any{"brokerUrl": tlsBrokerURL, "topic": "grafana/test", "tlsCaCertificate": string(ca)})
+		require.NoError(t, err)
+		cfg, err := NewConfig(raw, receiversTesting.DecryptForTesting(nil))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(notify.WithGroupKey(context.Background(), "alertname"), 10*time.Second)
+		defer cancel()
+		retry, err := New(cfg, receivers.Metadata{Type: Type}, tmpl, &logging.FakeLogger{}).Notify(ctx, alerts...)
+		require.ErrorContains(t, err, "failed to connect to the broker")
+		require.True(t, retry)
+	})
+}
@@ -0,0 +1,363 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "HighTemperature", "machine": "press-1"},
+				Annotations: model.LabelSet{"summary": "too hot"},
+			},
+		},
+	}
+
+	newNotifier := func(t *testing.T, settings string, secrets map[string][]byte) *Notifier {
+		t.Helper()
+		cfg, err := NewConfig(json.RawMessage(settings), receiversTesting.DecryptForTesting(secrets))
+		require.NoError(t, err)
+		return New(cfg, receivers.Metadata{Type: Type}, tmpl, &logging.FakeLogger{})
+	}
+
+	testCtx := func() context.Context {
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		return notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "HighTemperature"})
+	}
+
+	for _, qos := range []byte{0, 1, 2} {
+		t.Run(fmt.Sprintf("publishes message with QoS %d", qos), func(t *testing.T) {
+			broker := newFakeBroker(t, nil, 0)
+			n := newNotifier(t, fmt.Sprintf(`{
+				"brokerUrl": "tcp://%s",
+				"clientId": "grafana-test",
+				"topic": "factory/{{ .CommonLabels.machine }}/alerts",
+				"message": "{{ .Status }}: {{ .CommonAnnotations.summary }}",
+				"qos": %d,
+				"retain": true,
+				"username": "grafana"
+			}`, broker.addr, qos), map[string][]byte{"password": []byte("secret")})
+
+			retry, err := n.Notify(testCtx(), alerts...)
+			require.NoError(t, err)
+			require.True(t, retry)
+
+			s := broker.wait(t)
+			require.NoError(t, s.err)
+			require.Equal(t, "grafana-test", s.clientID)
+			require.Equal(t, "grafana", s.username)
+			require.Equal(t, "secret", s.password)
+			require.Equal(t, "factory/press-1/alerts", s.topic)
+			require.Equal(t, "firing: too hot", s.payload)
+			require.Equal(t, qos, s.qos)
+			require.True(t, s.retain)
+			require.True(t, s.disconnected)
+		})
+	}
+
+	t.Run("uses the default message and a random client id", func(t *testing.T) {
+		broker := newFakeBroker(t, nil, 0)
+		n := newNotifier(t, fmt.Sprintf(`{"brokerUrl": "mqtt://%s", "topic": "alerts"}`, broker.addr), nil)
+
+		_, err := n.Notify(testCtx(), alerts...)
+		require.NoError(t, err)
+
+		s := broker.wait(t)
+		require.NoError(t, s.err)
+		require.Contains(t, s.clientID, "grafana-")
+		require.Empty(t, s.username)
+		require.Contains(t, s.payload, "HighTemperature")
+		require.Equal(t, byte(0), s.qos)
+		require.False(t, s.retain)
+	})
+
+	t.Run("publishes over TLS with a client certificate", func(t *testing.T) {
+		certPEM, keyPEM := generateTestCertificate(t)
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM(certPEM))
+		broker := newFakeBroker(t, &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    pool,
+			MinVersion:   tls.VersionTLS12,
+		}, 0)
+		settings, err := json.Marshal(map[string]any{
+			"brokerUrl":            "tls://" + broker.addr,
+			"topic":                "alerts",
+			"tlsCaCertificate":     string(certPEM),
+			"tlsClientCertificate": string(certPEM),
+		})
+		require.NoError(t, err)
+		n := newNotifier(t, string(settings), map[string][]byte{"tlsClientKey": keyPEM})
+
+		_, err = n.Notify(testCtx(), alerts...)
+		require.NoError(t, err)
+
+		s := broker.wait(t)
+		require.NoError(t, s.err)
+		require.Equal(t, "alerts", s.topic)
+	})
+
+	t.Run("fails if broker refuses the connection", func(t *testing.T) {
+		broker := newFakeBroker(t, nil, 5)
+		n := newNotifier(t, fmt.Sprintf(`{"brokerUrl": "tcp://%s", "topic": "alerts"}`, broker.addr), nil)
+
+		retry, err := n.Notify(testCtx(), alerts...)
+		require.ErrorContains(t, err, "failed to connect to the broker: not Authorized")
+		require.True(t, retry)
+	})
+
+	t.Run("fails if broker is not reachable", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+		n := newNotifier(t, fmt.Sprintf(`{"brokerUrl": "tcp://%s", "topic": "alerts"}`, addr), nil)
+
+		retry, err := n.Notify(testCtx(), alerts...)
+		require.ErrorContains(t, err, "failed to connect to the broker")
+		require.True(t, retry)
+	})
+
+	t.Run("fails if templated topic contains wildcards", func(t *testing.T) {
+		n := newNotifier(t, `{"brokerUrl": "tcp://localhost", "topic": "alerts/{{ .CommonLabels.machine }}/#"}`, nil)
+
+		retry, err := n.Notify(testCtx(), alerts...)
+		require.ErrorContains(t, err, "must not contain wildcards")
+		require.False(t, retry)
+	})
+}
+
+// session is what the fake broker received from the client.
+type session struct {
+	clientID, username, password string
+	topic, payload               string
+	qos                          byte
+	retain                       bool
+	disconnected                 bool
+	err                          error
+}
+
+const (
+	packetConnect    byte = 1
+	packetConnack    byte = 2
+	packetPublish    byte = 3
+	packetPuback     byte = 4
+	packetPubrec     byte = 5
+	packetPubrel     byte = 6
+	packetPubcomp    byte = 7
+	packetDisconnect byte = 14
+
+	protocolLevel = 4
+)
+
+// fakeBroker implements the MQTT 3.1.1 broker side of a single session:
+// http://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+type fakeBroker struct {
+	addr        string
+	connackCode byte
+	sessions    chan session
+}
+
+func newFakeBroker(t *testing.T, tlsConfig *tls.Config, connackCode byte) *fakeBroker {
+	t.Helper()
+	var ln net.Listener
+	var err error
+	if tlsConfig != nil {
+		ln, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		ln, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	b := &fakeBroker{addr: ln.Addr().String(), connackCode: connackCode, sessions: make(chan session, 1)}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+		var s session
+		s.err = b.serve(conn, &s)
+		b.sessions <- s
+	}()
+	return b
+}
+
+func (b *fakeBroker) wait(t *testing.T) session {
+	t.Helper()
+	select {
+	case s := <-b.sessions:
+		return s
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for the session")
+		return session{}
+	}
+}
+
+func (b *fakeBroker) serve(conn net.Conn, s *session) error {
+	r := bufio.NewReader(conn)
+	header, body, err := readPacket(r)
+	if err != nil {
+		return err
+	}
+	if header>>4 != packetConnect {
+		return fmt.Errorf("expected CONNECT, got %d", header>>4)
+	}
+	body = body[len("MQTT")+2:]
+	if body[0] != protocolLevel {
+		return fmt.Errorf("unexpected protocol level %d", body[0])
+	}
+	flags := body[1]
+	body = body[4:]
+	s.clientID, body = readString(body)
+	if flags&0x80 != 0 {
+		s.username, body = readString(body)
+	}
+	if flags&0x40 != 0 {
+		s.password, _ = readString(body)
+	}
+	if _, err := conn.Write([]byte{packetConnack << 4, 2, 0, b.connackCode}); err != nil {
+		return err
+	}
+	if b.connackCode != 0 {
+		return nil
+	}
+
+	header, body, err = readPacket(r)
+	if err != nil {
+		return err
+	}
+	if header>>4 != packetPublish {
+		return fmt.Errorf("expected PUBLISH, got %d", header>>4)
+	}
+	s.qos = (header >> 1) & 0x03
+	s.retain = header&0x01 != 0
+	s.topic, body = readString(body)
+	if s.qos > 0 {
+		id := body[:2]
+		body = body[2:]
+		ack := packetPuback
+		if s.qos == 2 {
+			ack = packetPubrec
+		}
+		if _, err := conn.Write(append([]byte{ack << 4, 2}, id...)); err != nil {
+			return err
+		}
+		if s.qos == 2 {
+			header, _, err := readPacket(r)
+			if err != nil {
+				return err
+			}
+			if header != packetPubrel<<4|0x02 {
+				return fmt.Errorf("expected PUBREL, got %d", header>>4)
+			}
+			if _, err := conn.Write(append([]byte{packetPubcomp << 4, 2}, id...)); err != nil {
+				return err
+			}
+		}
+	}
+	s.payload = string(body)
+
+	header, _, err = readPacket(r)
+	if err != nil {
+		return err
+	}
+	s.disconnected = header>>4 == packetDisconnect
+	return nil
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, err := readRemainingLength(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+func readRemainingLength(r io.ByteReader) (int, error) {
+	var length, multiplier = 0, 1
+	for i := 0; i < 4; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			return length, nil
+		}
+		multiplier *= 128
+	}
+	return 0, errors.New("malformed remaining length")
+}
+
+func readString(b []byte) (string, []byte) {
+	l := binary.BigEndian.Uint16(b)
+	return string(b[2 : 2+l]), b[2+l:]
+}
+
+// generateTestCertificate returns a self-signed certificate for 127.0.0.1 that can be used by both the broker and the client.
+func generateTestCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "grafana-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -75,6 +75,8 @@ type MultiOrgAlertmanager struct {
 	settleCancel context.CancelFunc
 
 	configStore AlertingStore
+	ruleStore   autogenRuleStore
+	deliveryLog *DeliveryLog
 	orgStore    store.OrgStore
 	kvStore     kvstore.KVStore
 	factory     orgAlertmanagerFactory
@@ -95,6 +97,20 @@ func WithAlertmanagerOverride(f orgAlertmanagerFactory) Option {
 	}
 }
 
+// WithRuleStore sets the store that is used to generate the routes for the notification settings of alert rules.
+func WithRuleStore(s autogenRuleStore) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.ruleStore = s
+	}
+}
+
+// WithDeliveryLog sets the log that records the delivery attempts of the integrations of the Alertmanagers.
+func WithDeliveryLog(d *DeliveryLog) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.deliveryLog = d
+	}
+}
+
 func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgStore store.OrgStore,
 	kvStore kvstore.KVStore, provStore provisioningStore, decryptFn alertingNotify.GetDecryptedValueFn,
 	m *metrics.MultiOrgAlertmanager, ns notifications.Service, l log.Logger, s secrets.Service, opts ...Option,
@@ -122,7 +138,7 @@ func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore AlertingStore, orgSto
 	// Set up the default per tenant Alertmanager factory.
 	moa.factory = func(ctx context.Context, orgID int64) (Alertmanager, error) {
 		m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID))
-		return newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m)
+		return newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m, moa.ruleStore, moa.deliveryLog)
 	}
 
 	for _, opt := range opts {
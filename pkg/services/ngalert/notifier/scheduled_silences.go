@@ -0,0 +1,177 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// scheduledSilencesSyncInterval is how often the scheduled silences are checked for windows that need a silence.
+	scheduledSilencesSyncInterval = time.Minute
+	// scheduledSilencesLookahead is how long before the start of a window its silence is created.
+	scheduledSilencesLookahead = time.Hour
+)
+
+// ScheduledSilenceStore is the store used by the ScheduledSilencer.
+type ScheduledSilenceStore interface {
+	GetAllScheduledSilences(ctx context.Context) ([]models.ScheduledSilence, error)
+	ClaimScheduledSilenceWindow(ctx context.Context, orgID int64, uid string, prevWindowStart, windowStart int64) (bool, error)
+	ReleaseScheduledSilenceWindow(ctx context.Context, orgID int64, uid string, windowStart, prevWindowStart int64) error
+	SetScheduledSilenceWindowSilence(ctx context.Context, orgID int64, uid string, windowStart int64, silenceID string) error
+	InsertSilenceAuditEntry(ctx context.Context, entry models.SilenceAuditEntry) error
+}
+
+// AlertmanagerProvider returns the Alertmanager of an organization.
+type AlertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (Alertmanager, error)
+}
+
+// ScheduledSilencer creates real silences in the Alertmanager of the organization ahead of every window of the
+// scheduled silences. Every window is claimed in the database, so that only one instance creates its silence when
+// Grafana runs in high availability mode.
+type ScheduledSilencer struct {
+	store  ScheduledSilenceStore
+	ams    AlertmanagerProvider
+	clock  clock.Clock
+	logger log.Logger
+}
+
+func NewScheduledSilencer(store ScheduledSilenceStore, ams AlertmanagerProvider, logger log.Logger) *ScheduledSilencer {
+	return &ScheduledSilencer{
+		store:  store,
+		ams:    ams,
+		clock:  clock.New(),
+		logger: logger,
+	}
+}
+
+// Run creates the silences of the scheduled silences until the context is cancelled.
+func (s *ScheduledSilencer) Run(ctx context.Context) error {
+	s.logger.Info("Starting scheduled silences")
+	ticker := s.clock.Ticker(scheduledSilencesSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping scheduled silences")
+			return nil
+		case <-ticker.C:
+			s.Sync(ctx)
+		}
+	}
+}
+
+// Sync creates the silences of the windows that start within the lookahead and have no silence yet.
+func (s *ScheduledSilencer) Sync(ctx context.Context) {
+	silences, err := s.store.GetAllScheduledSilences(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch scheduled silences", "error", err)
+		return
+	}
+	now := s.clock.Now()
+	for _, silence := range silences {
+		start, end, ok := silence.NextWindow(now)
+		if !ok || start.Unix() == silence.WindowStart || start.After(now.Add(scheduledSilencesLookahead)) {
+			continue
+		}
+		if err := s.createSilence(ctx, silence, start, end); err != nil {
+			s.logger.Error("Failed to create the silence of the scheduled silence", "org", silence.OrgID, "uid", silence.UID, "windowStart", start, "error", err)
+		}
+	}
+}
+
+// createSilence claims the window before it creates its silence, so that no other instance creates a duplicate
+// silence that is gossiped to the peers. If the silence cannot be created, the claim is released and the silence is
+// created again at the next sync.
+func (s *ScheduledSilencer) createSilence(ctx context.Context, silence models.ScheduledSilence, start, end time.Time) error {
+	am, err := s.ams.AlertmanagerFor(silence.OrgID)
+	if err != nil {
+		return err
+	}
+	claimed, err := s.store.ClaimScheduledSilenceWindow(ctx, silence.OrgID, silence.UID, silence.WindowStart, start.Unix())
+	if err != nil || !claimed {
+		// another instance creates the silence of this window, or the scheduled silence was changed.
+		return err
+	}
+	silenceID, err := am.CreateSilence(ctx, postableSilence(silence, start, end))
+	if err != nil {
+		if err := s.store.ReleaseScheduledSilenceWindow(ctx, silence.OrgID, silence.UID, start.Unix(), silence.WindowStart); err != nil {
+			s.logger.Error("Failed to release the window of the scheduled silence", "org", silence.OrgID, "uid", silence.UID, "windowStart", start, "error", err)
+		}
+		return err
+	}
+	if err := s.store.SetScheduledSilenceWindowSilence(ctx, silence.OrgID, silence.UID, start.Unix(), silenceID); err != nil {
+		return fmt.Errorf("failed to store the ID of silence %s: %w", silenceID, err)
+	}
+	s.logger.Debug("Created the silence of the scheduled silence", "org", silence.OrgID, "uid", silence.UID, "silenceID", silenceID, "startsAt", start, "endsAt", end)
+	return s.store.InsertSilenceAuditEntry(ctx, models.SilenceAuditEntry{
+		OrgID:               silence.OrgID,
+		SilenceID:           silenceID,
+		Action:              models.SilenceAuditActionCreate,
+		User:                silence.CreatedBy,
+		ScheduledSilenceUID: silence.UID,
+		Created:             s.clock.Now(),
+	})
+}
+
+// ExpireScheduledSilence expires the silence that was created for the current window of the scheduled silence.
+func (s *ScheduledSilencer) ExpireScheduledSilence(ctx context.Context, silence models.ScheduledSilence, user string) error {
+	if silence.SilenceID == "" {
+		return nil
+	}
+	am, err := s.ams.AlertmanagerFor(silence.OrgID)
+	if err != nil {
+		return err
+	}
+	if err := am.DeleteSilence(ctx, silence.SilenceID); err != nil {
+		if errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to expire silence %s: %w", silence.SilenceID, err)
+	}
+	return s.store.InsertSilenceAuditEntry(ctx, models.SilenceAuditEntry{
+		OrgID:               silence.OrgID,
+		SilenceID:           silence.SilenceID,
+		Action:              models.SilenceAuditActionExpire,
+		User:                user,
+		ScheduledSilenceUID: silence.UID,
+		Created:             s.clock.Now(),
+	})
+}
+
+func postableSilence(silence models.ScheduledSilence, start, end time.Time) *apimodels.PostableSilence {
+	matchers := make(amv2.Matchers, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		m := m
+		matchers = append(matchers, &amv2.Matcher{
+			Name:    &m.Name,
+			Value:   &m.Value,
+			IsRegex: &m.IsRegex,
+			IsEqual: &m.IsEqual,
+		})
+	}
+	startsAt := strfmt.DateTime(start)
+	endsAt := strfmt.DateTime(end)
+	comment := silence.Comment
+	createdBy := silence.CreatedBy
+	return &apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			Matchers:  matchers,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+		},
+	}
+}
@@ -0,0 +1,230 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestScheduledSilencer(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC) // Sunday
+	weekly := models.ScheduledSilence{
+		UID:       "maintenance",
+		OrgID:     1,
+		Matchers:  []models.SilenceMatcher{{Name: "env", Value: "prod", IsEqual: true}},
+		Comment:   "weekly maintenance",
+		CreatedBy: "admin",
+		StartsAt:  start,
+		EndsAt:    start.Add(2 * time.Hour),
+		Frequency: models.ScheduledSilenceWeekly,
+		TimeZone:  "UTC",
+	}
+
+	setup := func(now time.Time, silences ...models.ScheduledSilence) (*ScheduledSilencer, *fakeScheduledSilenceStore, *fakeSilencesAlertmanager) {
+		store := &fakeScheduledSilenceStore{silences: silences}
+		am := &fakeSilencesAlertmanager{}
+		clk := clock.NewMock()
+		clk.Set(now)
+		return &ScheduledSilencer{
+			store:  store,
+			ams:    fakeAlertmanagerProvider{am: am},
+			clock:  clk,
+			logger: log.NewNopLogger(),
+		}, store, am
+	}
+
+	t.Run("should not create silence before lookahead", func(t *testing.T) {
+		s, _, am := setup(start.Add(-2*time.Hour), weekly)
+
+		s.Sync(ctx)
+
+		require.Empty(t, am.created)
+	})
+
+	t.Run("should create silence ahead of the window", func(t *testing.T) {
+		s, store, am := setup(start.AddDate(0, 0, 7).Add(-30*time.Minute), weekly)
+
+		s.Sync(ctx)
+
+		require.Len(t, am.created, 1)
+		created := am.created[0]
+		require.Equal(t, start.AddDate(0, 0, 7), time.Time(*created.StartsAt).UTC())
+		require.Equal(t, start.AddDate(0, 0, 7).Add(2*time.Hour), time.Time(*created.EndsAt).UTC())
+		require.Equal(t, "admin", *created.CreatedBy)
+		require.Equal(t, "env", *created.Matchers[0].Name)
+		require.Equal(t, "silence-1", store.silences[0].SilenceID)
+		require.Equal(t, start.AddDate(0, 0, 7).Unix(), store.silences[0].WindowStart)
+		require.Len(t, store.audit, 1)
+		require.Equal(t, models.SilenceAuditActionCreate, store.audit[0].Action)
+		require.Equal(t, "maintenance", store.audit[0].ScheduledSilenceUID)
+
+		s.Sync(ctx)
+		require.Len(t, am.created, 1, "silence should be created only once per window")
+	})
+
+	t.Run("should create silence of a window in progress", func(t *testing.T) {
+		s, _, am := setup(start.AddDate(0, 0, 14).Add(time.Hour), weekly)
+
+		s.Sync(ctx)
+
+		require.Len(t, am.created, 1)
+		require.Equal(t, start.AddDate(0, 0, 14), time.Time(*am.created[0].StartsAt).UTC())
+	})
+
+	t.Run("should not create silence if window is claimed by another instance", func(t *testing.T) {
+		s, store, am := setup(start.Add(-30*time.Minute), weekly)
+		store.claimed = true
+
+		s.Sync(ctx)
+
+		require.Empty(t, am.created)
+		require.Empty(t, am.deleted)
+		require.Empty(t, store.audit)
+	})
+
+	t.Run("should release window if silence cannot be created", func(t *testing.T) {
+		s, store, am := setup(start.Add(-30*time.Minute), weekly)
+		am.createErr = errors.New("alertmanager is not ready")
+
+		s.Sync(ctx)
+
+		require.Equal(t, 1, store.released)
+		require.Zero(t, store.silences[0].WindowStart)
+		require.Empty(t, store.silences[0].SilenceID)
+		require.Empty(t, store.audit)
+
+		am.createErr = nil
+		s.Sync(ctx)
+
+		require.Len(t, am.created, 1)
+		require.Equal(t, "silence-1", store.silences[0].SilenceID)
+		require.Equal(t, start.Unix(), store.silences[0].WindowStart)
+	})
+
+	t.Run("should not create silence after the last repetition", func(t *testing.T) {
+		until := start.AddDate(0, 0, 7)
+		limited := weekly
+		limited.RepeatUntil = &until
+		s, _, am := setup(start.AddDate(0, 0, 14).Add(-30*time.Minute), limited)
+
+		s.Sync(ctx)
+
+		require.Empty(t, am.created)
+	})
+
+	t.Run("should expire silence and record audit entry", func(t *testing.T) {
+		s, store, am := setup(start)
+		materialized := weekly
+		materialized.SilenceID = "silence-1"
+
+		require.NoError(t, s.ExpireScheduledSilence(ctx, materialized, "editor"))
+
+		require.Equal(t, []string{"silence-1"}, am.deleted)
+		require.Len(t, store.audit, 1)
+		require.Equal(t, models.SilenceAuditActionExpire, store.audit[0].Action)
+		require.Equal(t, "editor", store.audit[0].User)
+	})
+
+	t.Run("should ignore silence that no longer exists", func(t *testing.T) {
+		s, store, am := setup(start)
+		am.deleteErr = alertingNotify.ErrSilenceNotFound
+		materialized := weekly
+		materialized.SilenceID = "silence-1"
+
+		require.NoError(t, s.ExpireScheduledSilence(ctx, materialized, "editor"))
+		require.Empty(t, store.audit)
+	})
+}
+
+type fakeScheduledSilenceStore struct {
+	silences []models.ScheduledSilence
+	audit    []models.SilenceAuditEntry
+	// claimed simulates a window that was claimed by another instance.
+	claimed  bool
+	released int
+}
+
+func (f *fakeScheduledSilenceStore) GetAllScheduledSilences(_ context.Context) ([]models.ScheduledSilence, error) {
+	return append([]models.ScheduledSilence{}, f.silences...), nil
+}
+
+func (f *fakeScheduledSilenceStore) ClaimScheduledSilenceWindow(_ context.Context, orgID int64, uid string, prevWindowStart, windowStart int64) (bool, error) {
+	if f.claimed {
+		return false, nil
+	}
+	return f.setWindowStart(orgID, uid, prevWindowStart, windowStart), nil
+}
+
+func (f *fakeScheduledSilenceStore) ReleaseScheduledSilenceWindow(_ context.Context, orgID int64, uid string, windowStart, prevWindowStart int64) error {
+	f.released++
+	f.setWindowStart(orgID, uid, windowStart, prevWindowStart)
+	return nil
+}
+
+func (f *fakeScheduledSilenceStore) SetScheduledSilenceWindowSilence(_ context.Context, orgID int64, uid string, windowStart int64, silenceID string) error {
+	for i, s := range f.silences {
+		if s.OrgID == orgID && s.UID == uid && s.WindowStart == windowStart {
+			f.silences[i].SilenceID = silenceID
+		}
+	}
+	return nil
+}
+
+func (f *fakeScheduledSilenceStore) setWindowStart(orgID int64, uid string, from, to int64) bool {
+	for i, s := range f.silences {
+		if s.OrgID == orgID && s.UID == uid && s.WindowStart == from {
+			f.silences[i].WindowStart = to
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeScheduledSilenceStore) InsertSilenceAuditEntry(_ context.Context, entry models.SilenceAuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+type fakeAlertmanagerProvider struct {
+	am Alertmanager
+}
+
+func (f fakeAlertmanagerProvider) AlertmanagerFor(int64) (Alertmanager, error) {
+	return f.am, nil
+}
+
+// fakeSilencesAlertmanager implements only the silences of the Alertmanager.
+type fakeSilencesAlertmanager struct {
+	Alertmanager
+	created   []*apimodels.PostableSilence
+	deleted   []string
+	createErr error
+	deleteErr error
+}
+
+func (f *fakeSilencesAlertmanager) CreateSilence(_ context.Context, ps *apimodels.PostableSilence) (string, error) {
+	if f.createErr != nil {
+		return "", f.createErr
+	}
+	f.created = append(f.created, ps)
+	return fmt.Sprintf("silence-%d", len(f.created)), nil
+}
+
+func (f *fakeSilencesAlertmanager) DeleteSilence(_ context.Context, silenceID string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.deleted = append(f.deleted, silenceID)
+	return nil
+}
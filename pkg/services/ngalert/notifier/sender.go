@@ -13,6 +13,18 @@ type sender struct {
 }
 
 func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
+	validation := cmd.Validation
+	if rec, ok := deliveryRecorderFromContext(ctx); ok {
+		// The validation receives the status code of every response, so it records it for the notification delivery log.
+		rec.recordPayload(cmd.Body)
+		validation = func(body []byte, statusCode int) error {
+			rec.recordStatusCode(statusCode)
+			if cmd.Validation != nil {
+				return cmd.Validation(body, statusCode)
+			}
+			return nil
+		}
+	}
 	return s.ns.SendWebhookSync(ctx, &notifications.SendWebhookSync{
 		Url:         cmd.URL,
 		User:        cmd.User,
@@ -21,7 +33,7 @@ func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSetti
 		HttpMethod:  cmd.HTTPMethod,
 		HttpHeader:  cmd.HTTPHeader,
 		ContentType: cmd.ContentType,
-		Validation:  cmd.Validation,
+		Validation:  validation,
 	})
 }
 
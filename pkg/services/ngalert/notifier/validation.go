@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// NotificationSettingsValidator checks that the receiver and mute timings of notification settings exist in an
+// Alertmanager configuration.
+type NotificationSettingsValidator struct {
+	receivers   map[string]struct{}
+	muteTimings map[string]struct{}
+}
+
+// NewNotificationSettingsValidator creates a NotificationSettingsValidator for the configuration.
+func NewNotificationSettingsValidator(cfg *apimodels.PostableApiAlertingConfig) NotificationSettingsValidator {
+	v := NotificationSettingsValidator{
+		receivers:   make(map[string]struct{}, len(cfg.Receivers)),
+		muteTimings: make(map[string]struct{}, len(cfg.MuteTimeIntervals)),
+	}
+	for _, r := range cfg.Receivers {
+		v.receivers[r.Name] = struct{}{}
+	}
+	for _, mt := range cfg.MuteTimeIntervals {
+		v.muteTimings[mt.Name] = struct{}{}
+	}
+	return v
+}
+
+// Validate returns an error if the settings are invalid or reference a receiver or mute timing that does not exist.
+func (v NotificationSettingsValidator) Validate(s models.NotificationSettings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if _, ok := v.receivers[s.Receiver]; !ok {
+		return fmt.Errorf("%w: receiver '%s' does not exist", models.ErrNotificationSettingsInvalid, s.Receiver)
+	}
+	for _, mt := range s.MuteTimeIntervals {
+		if _, ok := v.muteTimings[mt]; !ok {
+			return fmt.Errorf("%w: mute time interval '%s' does not exist", models.ErrNotificationSettingsInvalid, mt)
+		}
+	}
+	return nil
+}
+
+type latestConfigStore interface {
+	GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) (*models.AlertConfiguration, error)
+}
+
+// NotificationSettingsValidationService provides validators that check notification settings against the latest
+// Alertmanager configuration of an organization.
+type NotificationSettingsValidationService struct {
+	store latestConfigStore
+}
+
+func NewNotificationSettingsValidationService(store latestConfigStore) *NotificationSettingsValidationService {
+	return &NotificationSettingsValidationService{
+		store: store,
+	}
+}
+
+// Validator returns a validator for the notification settings of the organization's alert rules.
+func (v *NotificationSettingsValidationService) Validator(ctx context.Context, orgID int64) (models.NotificationSettingsValidator, error) {
+	query := &models.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	amCfg, err := v.store.GetLatestAlertmanagerConfiguration(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	cfg, err := Load([]byte(amCfg.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, err
+	}
+	return NewNotificationSettingsValidator(&cfg.AlertmanagerConfig), nil
+}
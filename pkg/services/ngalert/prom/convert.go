@@ -0,0 +1,350 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// queryRefID is the RefID of the data source query of converted rules.
+	queryRefID = "A"
+	// reduceRefID is the RefID of the expression that reduces the result of the query to a single value per series.
+	reduceRefID = "B"
+	// conditionRefID is the RefID of the condition of converted alerting rules.
+	conditionRefID = "C"
+
+	// defaultQueryRange is the relative time range of the instant queries of converted rules.
+	defaultQueryRange = 10 * time.Minute
+)
+
+// valueRegexp matches $value in templates. $values is not matched.
+var valueRegexp = regexp.MustCompile(`\$value\b`)
+
+// Config configures how Prometheus rules are converted to Grafana-managed rules.
+type Config struct {
+	OrgID        int64
+	NamespaceUID string
+	// DatasourceUID is the UID of the data source the queries of the converted rules are bound to.
+	DatasourceUID string
+	// DatasourceType is the type of the data source. It must be either prometheus or loki.
+	DatasourceType string
+	// DefaultInterval is the evaluation interval of groups that do not define their own.
+	DefaultInterval time.Duration
+}
+
+// Converter converts Prometheus and Loki rule groups to Grafana-managed rule groups.
+//
+// An alerting rule is converted to a query of its expression (A), a reduce expression that takes the last value of
+// every series (B), and a condition (C). If the expression of a Prometheus rule compares a query with a number,
+// such as `rate(errors[5m]) > 10`, the query is the left-hand side of the comparison and the condition is a
+// threshold or math expression with the number. Otherwise, the condition is met for every series the expression
+// returns, which is how Prometheus evaluates alerting rules. A recording rule records the result of B.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, errors.New("data source UID is required")
+	}
+	if cfg.DatasourceType != datasources.DS_PROMETHEUS && cfg.DatasourceType != datasources.DS_LOKI {
+		return nil, fmt.Errorf("data source type %q is not supported, must be one of [%s, %s]", cfg.DatasourceType, datasources.DS_PROMETHEUS, datasources.DS_LOKI)
+	}
+	if cfg.DefaultInterval <= 0 {
+		return nil, errors.New("default interval must be positive")
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// ConvertRuleFile converts all groups of the file. The titles of the converted rules are unique, as Grafana requires
+// for rules of the same folder: if several rules have the same name, a number is appended to the title of all but
+// the first one.
+func (c *Converter) ConvertRuleFile(file RuleFile) ([]models.AlertRuleGroup, error) {
+	groups := make([]models.AlertRuleGroup, 0, len(file.Groups))
+	names := make(map[string]struct{}, len(file.Groups))
+	titles := make(map[string]int)
+	for _, g := range file.Groups {
+		if _, ok := names[g.Name]; ok {
+			return nil, fmt.Errorf("rule group %q is defined more than once", g.Name)
+		}
+		names[g.Name] = struct{}{}
+		group, err := c.ConvertRuleGroup(g)
+		if err != nil {
+			return nil, err
+		}
+		for i := range group.Rules {
+			rule := &group.Rules[i]
+			titles[rule.Title]++
+			if n := titles[rule.Title]; n > 1 {
+				rule.Title = fmt.Sprintf("%s (%d)", rule.Title, n)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ConvertRuleGroup converts a group of Prometheus rules to a group of Grafana-managed rules. The rules of the group
+// keep their order.
+func (c *Converter) ConvertRuleGroup(group RuleGroup) (models.AlertRuleGroup, error) {
+	if group.Name == "" {
+		return models.AlertRuleGroup{}, errors.New("rule group name cannot be empty")
+	}
+	if group.Limit != 0 {
+		return models.AlertRuleGroup{}, fmt.Errorf("rule group %q: limit is not supported", group.Name)
+	}
+	interval := time.Duration(group.Interval)
+	if interval == 0 {
+		interval = c.cfg.DefaultInterval
+	}
+	var offset time.Duration
+	if group.QueryOffset != nil {
+		offset = time.Duration(*group.QueryOffset)
+	}
+
+	rules := make([]models.AlertRule, 0, len(group.Rules))
+	for idx, r := range group.Rules {
+		rule, err := c.convertRule(r, offset)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("rule group %q: invalid rule at index [%d]: %w", group.Name, idx, err)
+		}
+		rule.OrgID = c.cfg.OrgID
+		rule.NamespaceUID = c.cfg.NamespaceUID
+		rule.RuleGroup = group.Name
+		rule.RuleGroupIndex = idx + 1
+		rule.IntervalSeconds = int64(interval.Seconds())
+		rules = append(rules, rule)
+	}
+	return models.AlertRuleGroup{
+		Title:     group.Name,
+		FolderUID: c.cfg.NamespaceUID,
+		Interval:  int64(interval.Seconds()),
+		Rules:     rules,
+	}, nil
+}
+
+func (c *Converter) convertRule(r Rule, offset time.Duration) (models.AlertRule, error) {
+	if r.Expr == "" {
+		return models.AlertRule{}, errors.New("expr cannot be empty")
+	}
+	if (r.Alert == "") == (r.Record == "") {
+		return models.AlertRule{}, errors.New("exactly one of alert or record must be set")
+	}
+
+	var rule models.AlertRule
+	if r.Record != "" {
+		if r.For != 0 || r.KeepFiringFor != 0 || len(r.Annotations) > 0 {
+			return models.AlertRule{}, fmt.Errorf("recording rule %q cannot have for, keep_firing_for or annotations", r.Record)
+		}
+		query, err := c.newQuery(r.Expr, offset)
+		if err != nil {
+			return models.AlertRule{}, err
+		}
+		rule.Title = r.Record
+		rule.Labels = r.Labels
+		rule.Record = &models.Record{Metric: r.Record, From: reduceRefID}
+		rule.Data = []models.AlertQuery{query, newReduceExpression()}
+		return rule, nil
+	}
+
+	queryExpr, condition, err := c.splitCondition(r.Expr)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	query, err := c.newQuery(queryExpr, offset)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule.Title = r.Alert
+	rule.Condition = conditionRefID
+	rule.Data = []models.AlertQuery{query, newReduceExpression(), condition}
+	rule.For = time.Duration(r.For)
+	rule.KeepFiringFor = time.Duration(r.KeepFiringFor)
+	// Prometheus does not create alerts if the query returns no data, and reports errors as the health of the rule.
+	rule.NoDataState = models.OK
+	rule.ExecErrState = models.ErrorErrState
+	rule.Labels = convertTemplates(r.Labels)
+	rule.Annotations = convertTemplates(r.Annotations)
+	return rule, nil
+}
+
+// splitCondition returns the query and the condition of an alerting rule. Only Prometheus expressions are split,
+// the condition of any other expression is met for every series it returns.
+func (c *Converter) splitCondition(e string) (string, models.AlertQuery, error) {
+	everySeries := newMathExpression(fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", reduceRefID))
+	if c.cfg.DatasourceType != datasources.DS_PROMETHEUS {
+		return e, everySeries, nil
+	}
+
+	parsed, err := parser.ParseExpr(e)
+	if err != nil {
+		return "", models.AlertQuery{}, fmt.Errorf("failed to parse expr: %w", err)
+	}
+	binary, ok := parsed.(*parser.BinaryExpr)
+	if !ok || !binary.Op.IsComparisonOperator() || binary.ReturnBool {
+		return e, everySeries, nil
+	}
+
+	op := binary.Op
+	query := binary.LHS
+	threshold, ok := numberLiteral(binary.RHS)
+	if !ok {
+		if threshold, ok = numberLiteral(binary.LHS); !ok {
+			return e, everySeries, nil
+		}
+		query = binary.RHS
+		op = flipComparison(op)
+	}
+	if query.Type() != parser.ValueTypeVector || math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+		return e, everySeries, nil
+	}
+
+	pos := query.PositionRange()
+	queryExpr := strings.TrimSpace(e[pos.Start:pos.End])
+	switch op {
+	case parser.GTR:
+		return queryExpr, newThresholdExpression(expr.ThresholdIsAbove, threshold), nil
+	case parser.LSS:
+		return queryExpr, newThresholdExpression(expr.ThresholdIsBelow, threshold), nil
+	default:
+		return queryExpr, newMathExpression(fmt.Sprintf("$%s %s %s", reduceRefID, op, strconv.FormatFloat(threshold, 'f', -1, 64))), nil
+	}
+}
+
+func (c *Converter) newQuery(e string, offset time.Duration) (models.AlertQuery, error) {
+	m := map[string]any{
+		"refId": queryRefID,
+		"expr":  e,
+		"datasource": map[string]string{
+			"type": c.cfg.DatasourceType,
+			"uid":  c.cfg.DatasourceUID,
+		},
+	}
+	var queryType string
+	if c.cfg.DatasourceType == datasources.DS_LOKI {
+		queryType = "instant"
+		m["queryType"] = queryType
+	} else {
+		m["instant"] = true
+		m["range"] = false
+	}
+	model, err := json.Marshal(m)
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:         queryRefID,
+		QueryType:     queryType,
+		DatasourceUID: c.cfg.DatasourceUID,
+		// The query offset of the group shifts the time the query is evaluated at.
+		RelativeTimeRange: models.RelativeTimeRange{
+			From: models.Duration(defaultQueryRange + offset),
+			To:   models.Duration(offset),
+		},
+		Model: model,
+	}, nil
+}
+
+func newReduceExpression() models.AlertQuery {
+	return newExpression(reduceRefID, map[string]any{
+		"type":       "reduce",
+		"expression": queryRefID,
+		"reducer":    "last",
+	})
+}
+
+func newThresholdExpression(thresholdFunc string, threshold float64) models.AlertQuery {
+	return newExpression(conditionRefID, map[string]any{
+		"type":       "threshold",
+		"expression": reduceRefID,
+		"conditions": []any{
+			map[string]any{
+				"evaluator": map[string]any{
+					"type":   thresholdFunc,
+					"params": []float64{threshold},
+				},
+			},
+		},
+	})
+}
+
+func newMathExpression(expression string) models.AlertQuery {
+	return newExpression(conditionRefID, map[string]any{
+		"type":       "math",
+		"expression": expression,
+	})
+}
+
+func newExpression(refID string, m map[string]any) models.AlertQuery {
+	m["refId"] = refID
+	m["datasource"] = map[string]string{
+		"type": expr.DatasourceType,
+		"uid":  expr.DatasourceUID,
+	}
+	// the model consists of strings, numbers and maps only and cannot fail to marshal
+	model, _ := json.Marshal(m)
+	return models.AlertQuery{
+		RefID:         refID,
+		QueryType:     expr.DatasourceType,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}
+}
+
+// numberLiteral returns the value of e if it is a number, optionally in parentheses or negated.
+func numberLiteral(e parser.Expr) (float64, bool) {
+	switch n := e.(type) {
+	case *parser.NumberLiteral:
+		return n.Val, true
+	case *parser.ParenExpr:
+		return numberLiteral(n.Expr)
+	case *parser.UnaryExpr:
+		v, ok := numberLiteral(n.Expr)
+		if n.Op == parser.SUB {
+			v = -v
+		}
+		return v, ok
+	}
+	return 0, false
+}
+
+// flipComparison returns the operator that gives the same result if the operands of the comparison are swapped.
+func flipComparison(op parser.ItemType) parser.ItemType {
+	switch op {
+	case parser.GTR:
+		return parser.LSS
+	case parser.LSS:
+		return parser.GTR
+	case parser.GTE:
+		return parser.LTE
+	case parser.LTE:
+		return parser.GTE
+	}
+	return op
+}
+
+// convertTemplates replaces $value in the templates of labels and annotations by the value of the reduce expression.
+// In Prometheus, $value is the value of the series that fired, while in Grafana it describes the values of all
+// queries and expressions.
+func convertTemplates(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = valueRegexp.ReplaceAllString(v, fmt.Sprintf("$$values.%s.Value", reduceRefID))
+	}
+	return result
+}
@@ -0,0 +1,241 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testRuleFile = `
+groups:
+  - name: example
+    interval: 30s
+    query_offset: 1m
+    rules:
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+        labels:
+          team: backend
+      - alert: HighRequestRate
+        expr: job:http_requests:rate5m > 100
+        for: 5m
+        keep_firing_for: 10m
+        labels:
+          severity: page
+        annotations:
+          summary: "{{ $labels.job }} serves {{ $value | humanize }} requests per second"
+  - name: other
+    rules:
+      - alert: HighRequestRate
+        expr: up == 0
+`
+
+func TestParseRuleFile(t *testing.T) {
+	file, err := ParseRuleFile([]byte(testRuleFile))
+	require.NoError(t, err)
+	require.Len(t, file.Groups, 2)
+	require.Equal(t, model.Duration(30*time.Second), file.Groups[0].Interval)
+	require.Equal(t, model.Duration(time.Minute), *file.Groups[0].QueryOffset)
+	require.Equal(t, model.Duration(5*time.Minute), file.Groups[0].Rules[1].For)
+	require.Equal(t, model.Duration(10*time.Minute), file.Groups[0].Rules[1].KeepFiringFor)
+
+	t.Run("accepts JSON", func(t *testing.T) {
+		file, err := ParseRuleFile([]byte(`{"groups": [{"name": "example", "interval": "1m", "rules": [{"alert": "Down", "expr": "up == 0"}]}]}`))
+		require.NoError(t, err)
+		require.Equal(t, "Down", file.Groups[0].Rules[0].Alert)
+	})
+
+	t.Run("fails on unknown fields", func(t *testing.T) {
+		_, err := ParseRuleFile([]byte("groups:\n  - name: example\n    rules:\n      - alert: Down\n        exp: up == 0\n"))
+		require.ErrorContains(t, err, "field exp not found")
+	})
+
+	t.Run("fails if there are no groups", func(t *testing.T) {
+		_, err := ParseRuleFile([]byte(""))
+		require.Error(t, err)
+		_, err = ParseRuleFile([]byte("groups: []"))
+		require.Error(t, err)
+	})
+}
+
+func TestConverter(t *testing.T) {
+	cfg := Config{
+		OrgID:           1,
+		NamespaceUID:    "folder",
+		DatasourceUID:   "prom",
+		DatasourceType:  datasources.DS_PROMETHEUS,
+		DefaultInterval: time.Minute,
+	}
+
+	t.Run("converts rule file", func(t *testing.T) {
+		c, err := NewConverter(cfg)
+		require.NoError(t, err)
+		file, err := ParseRuleFile([]byte(testRuleFile))
+		require.NoError(t, err)
+
+		groups, err := c.ConvertRuleFile(file)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+
+		group := groups[0]
+		require.Equal(t, "example", group.Title)
+		require.Equal(t, "folder", group.FolderUID)
+		require.EqualValues(t, 30, group.Interval)
+		require.Len(t, group.Rules, 2)
+
+		recording := group.Rules[0]
+		require.Equal(t, "job:http_requests:rate5m", recording.Title)
+		require.Equal(t, &models.Record{Metric: "job:http_requests:rate5m", From: "B"}, recording.Record)
+		require.Equal(t, map[string]string{"team": "backend"}, recording.Labels)
+		require.Equal(t, 1, recording.RuleGroupIndex)
+
+		alerting := group.Rules[1]
+		require.Equal(t, "HighRequestRate", alerting.Title)
+		require.Equal(t, "C", alerting.Condition)
+		require.Equal(t, 5*time.Minute, alerting.For)
+		require.Equal(t, 10*time.Minute, alerting.KeepFiringFor)
+		require.Equal(t, models.OK, alerting.NoDataState)
+		require.Equal(t, models.ErrorErrState, alerting.ExecErrState)
+		require.Equal(t, map[string]string{"severity": "page"}, alerting.Labels)
+		require.Equal(t, "{{ $labels.job }} serves {{ $values.B.Value | humanize }} requests per second", alerting.Annotations["summary"])
+		require.Equal(t, 2, alerting.RuleGroupIndex)
+		require.EqualValues(t, 30, alerting.IntervalSeconds)
+		require.Len(t, alerting.Data, 3)
+
+		query := alerting.Data[0]
+		require.Equal(t, "prom", query.DatasourceUID)
+		require.Equal(t, models.RelativeTimeRange{From: models.Duration(11 * time.Minute), To: models.Duration(time.Minute)}, query.RelativeTimeRange)
+		require.JSONEq(t, `{"refId": "A", "expr": "job:http_requests:rate5m", "instant": true, "range": false, "datasource": {"type": "prometheus", "uid": "prom"}}`, string(query.Model))
+
+		for _, rule := range group.Rules {
+			require.NoError(t, rule.ValidateAlertRule(setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second}))
+		}
+
+		other := groups[1]
+		require.EqualValues(t, 60, other.Interval)
+		require.Equal(t, "HighRequestRate (2)", other.Rules[0].Title)
+		require.Equal(t, models.RelativeTimeRange{From: models.Duration(10 * time.Minute)}, other.Rules[0].Data[0].RelativeTimeRange)
+	})
+
+	testCases := []struct {
+		name      string
+		expr      string
+		query     string
+		condition string
+	}{
+		{
+			name:      "greater than is a threshold",
+			expr:      `rate(errors_total[5m]) > 0.5`,
+			query:     `rate(errors_total[5m])`,
+			condition: `{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [0.5]}}]}`,
+		},
+		{
+			name:      "less than is a threshold",
+			expr:      `up < 1`,
+			query:     `up`,
+			condition: `{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "lt", "params": [1]}}]}`,
+		},
+		{
+			name:      "number on the left-hand side flips the comparison",
+			expr:      `(10) < sum by (job) (up)`,
+			query:     `sum by (job) (up)`,
+			condition: `{"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [10]}}]}`,
+		},
+		{
+			name:      "other comparisons are math",
+			expr:      `up == 0`,
+			query:     `up`,
+			condition: `{"type": "math", "expression": "$B == 0"}`,
+		},
+		{
+			name:      "negative numbers",
+			expr:      `temperature_celsius <= -10`,
+			query:     `temperature_celsius`,
+			condition: `{"type": "math", "expression": "$B <= -10"}`,
+		},
+		{
+			name:      "comparison of two queries fires for every series",
+			expr:      `errors_total > requests_total`,
+			query:     `errors_total > requests_total`,
+			condition: `{"type": "math", "expression": "is_number($B) || is_nan($B) || is_inf($B)"}`,
+		},
+		{
+			name:      "bool comparison fires for every series",
+			expr:      `up == bool 0`,
+			query:     `up == bool 0`,
+			condition: `{"type": "math", "expression": "is_number($B) || is_nan($B) || is_inf($B)"}`,
+		},
+		{
+			name:      "query without comparison fires for every series",
+			expr:      `absent(up{job="api"})`,
+			query:     `absent(up{job="api"})`,
+			condition: `{"type": "math", "expression": "is_number($B) || is_nan($B) || is_inf($B)"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewConverter(cfg)
+			require.NoError(t, err)
+			group, err := c.ConvertRuleGroup(RuleGroup{Name: "group", Rules: []Rule{{Alert: "alert", Expr: tc.expr}}})
+			require.NoError(t, err)
+			rule := group.Rules[0]
+
+			var query map[string]any
+			require.NoError(t, json.Unmarshal(rule.Data[0].Model, &query))
+			assert.Equal(t, tc.query, query["expr"])
+
+			var condition map[string]any
+			require.NoError(t, json.Unmarshal(rule.Data[2].Model, &condition))
+			delete(condition, "refId")
+			delete(condition, "datasource")
+			actual, err := json.Marshal(condition)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.condition, string(actual))
+		})
+	}
+
+	t.Run("loki queries are instant queries", func(t *testing.T) {
+		lokiCfg := cfg
+		lokiCfg.DatasourceType = datasources.DS_LOKI
+		c, err := NewConverter(lokiCfg)
+		require.NoError(t, err)
+		group, err := c.ConvertRuleGroup(RuleGroup{Name: "group", Rules: []Rule{{Alert: "alert", Expr: `sum(rate({app="api"} |= "error" [5m])) > 10`}}})
+		require.NoError(t, err)
+		query := group.Rules[0].Data[0]
+		require.Equal(t, "instant", query.QueryType)
+		require.JSONEq(t, `{"refId": "A", "expr": "sum(rate({app=\"api\"} |= \"error\" [5m])) > 10", "queryType": "instant", "datasource": {"type": "loki", "uid": "prom"}}`, string(query.Model))
+	})
+
+	t.Run("fails on invalid input", func(t *testing.T) {
+		_, err := NewConverter(Config{DatasourceUID: "uid", DatasourceType: "graphite", DefaultInterval: time.Minute})
+		require.ErrorContains(t, err, "not supported")
+
+		c, err := NewConverter(cfg)
+		require.NoError(t, err)
+		for _, group := range []RuleGroup{
+			{Rules: []Rule{{Alert: "alert", Expr: "up"}}},
+			{Name: "group", Limit: 10, Rules: []Rule{{Alert: "alert", Expr: "up"}}},
+			{Name: "group", Rules: []Rule{{Alert: "alert"}}},
+			{Name: "group", Rules: []Rule{{Alert: "alert", Record: "record", Expr: "up"}}},
+			{Name: "group", Rules: []Rule{{Record: "record", Expr: "up", For: model.Duration(time.Minute)}}},
+			{Name: "group", Rules: []Rule{{Alert: "alert", Expr: "up >"}}},
+		} {
+			_, err := c.ConvertRuleGroup(group)
+			require.Error(t, err)
+		}
+
+		_, err = c.ConvertRuleFile(RuleFile{Groups: []RuleGroup{
+			{Name: "group", Rules: []Rule{{Alert: "alert", Expr: "up"}}},
+			{Name: "group", Rules: []Rule{{Alert: "alert", Expr: "up"}}},
+		}})
+		require.ErrorContains(t, err, "defined more than once")
+	})
+}
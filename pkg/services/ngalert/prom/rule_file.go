@@ -0,0 +1,54 @@
+package prom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleFile is a file of rule groups in the format used by Prometheus, Mimir and Loki.
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups" json:"groups"`
+}
+
+// RuleGroup is a group of Prometheus alerting and recording rules.
+type RuleGroup struct {
+	Name        string          `yaml:"name" json:"name"`
+	Interval    model.Duration  `yaml:"interval,omitempty" json:"interval,omitempty"`
+	QueryOffset *model.Duration `yaml:"query_offset,omitempty" json:"query_offset,omitempty"`
+	Limit       int             `yaml:"limit,omitempty" json:"limit,omitempty"`
+	Rules       []Rule          `yaml:"rules" json:"rules"`
+}
+
+// Rule is a Prometheus alerting rule if Alert is set, or a recording rule if Record is set.
+type Rule struct {
+	Record        string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Alert         string            `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Expr          string            `yaml:"expr" json:"expr"`
+	For           model.Duration    `yaml:"for,omitempty" json:"for,omitempty"`
+	KeepFiringFor model.Duration    `yaml:"keep_firing_for,omitempty" json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+}
+
+// ParseRuleFile parses a rule file in YAML or JSON format. Like Prometheus, it fails on unknown fields so that
+// typos are not silently ignored.
+func ParseRuleFile(content []byte) (RuleFile, error) {
+	var file RuleFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return file, errors.New("rule file is empty")
+		}
+		return file, fmt.Errorf("failed to parse rule file: %w", err)
+	}
+	if len(file.Groups) == 0 {
+		return file, errors.New("rule file does not contain any rule groups")
+	}
+	return file, nil
+}
@@ -23,6 +23,7 @@ type AlertRuleService struct {
 	quotas                 QuotaChecker
 	xact                   TransactionManager
 	log                    log.Logger
+	nsValidatorProvider    NotificationSettingsValidatorProvider
 }
 
 func NewAlertRuleService(ruleStore RuleStore,
@@ -32,7 +33,8 @@ func NewAlertRuleService(ruleStore RuleStore,
 	xact TransactionManager,
 	defaultIntervalSeconds int64,
 	baseIntervalSeconds int64,
-	log log.Logger) *AlertRuleService {
+	log log.Logger,
+	ns NotificationSettingsValidatorProvider) *AlertRuleService {
 	return &AlertRuleService{
 		defaultIntervalSeconds: defaultIntervalSeconds,
 		baseIntervalSeconds:    baseIntervalSeconds,
@@ -42,6 +44,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		quotas:                 quotas,
 		xact:                   xact,
 		log:                    log,
+		nsValidatorProvider:    ns,
 	}
 }
 
@@ -127,12 +130,22 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 		interval = service.defaultIntervalSeconds
 	} else if err != nil {
 		return models.AlertRule{}, err
+	} else {
+		// the rule is added to an existing group and is evaluated in the same way as the other rules of the group.
+		group, err := service.GetRuleGroup(ctx, rule.OrgID, rule.NamespaceUID, rule.RuleGroup)
+		if err != nil {
+			return models.AlertRule{}, err
+		}
+		rule.SequentialEvaluation = group.SequentialEvaluation
 	}
 	rule.IntervalSeconds = interval
 	err = rule.SetDashboardAndPanelFromAnnotations()
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := service.validateNotificationSettings(ctx, rule.OrgID, &rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	rule.Updated = time.Now()
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
@@ -179,10 +192,11 @@ func (service *AlertRuleService) GetRuleGroup(ctx context.Context, orgID int64,
 		return models.AlertRuleGroup{}, store.ErrAlertRuleGroupNotFound
 	}
 	res := models.AlertRuleGroup{
-		Title:     ruleList[0].RuleGroup,
-		FolderUID: ruleList[0].NamespaceUID,
-		Interval:  ruleList[0].IntervalSeconds,
-		Rules:     []models.AlertRule{},
+		Title:                ruleList[0].RuleGroup,
+		FolderUID:            ruleList[0].NamespaceUID,
+		Interval:             ruleList[0].IntervalSeconds,
+		SequentialEvaluation: ruleList[0].SequentialEvaluation,
+		Rules:                []models.AlertRule{},
 	}
 	for _, r := range ruleList {
 		if r != nil {
@@ -192,8 +206,8 @@ func (service *AlertRuleService) GetRuleGroup(ctx context.Context, orgID int64,
 	return res, nil
 }
 
-// UpdateRuleGroup will update the interval for all rules in the group.
-func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string, intervalSeconds int64) error {
+// UpdateRuleGroup will update the interval and whether the rules are evaluated sequentially for all rules in the group.
+func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string, intervalSeconds int64, sequentialEvaluation bool) error {
 	if err := models.ValidateRuleGroupInterval(intervalSeconds, service.baseIntervalSeconds); err != nil {
 		return err
 	}
@@ -207,18 +221,25 @@ func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int6
 		if err != nil {
 			return fmt.Errorf("failed to list alert rules: %w", err)
 		}
+		ruleList.SortByGroupIndex()
 		updateRules := make([]models.UpdateRule, 0, len(ruleList))
+		newRules := make([]*models.AlertRule, 0, len(ruleList))
 		for _, rule := range ruleList {
-			if rule.IntervalSeconds == intervalSeconds {
-				continue
-			}
 			newRule := *rule
 			newRule.IntervalSeconds = intervalSeconds
+			newRule.SequentialEvaluation = sequentialEvaluation
+			newRules = append(newRules, &newRule)
+			if rule.IntervalSeconds == intervalSeconds && rule.SequentialEvaluation == sequentialEvaluation {
+				continue
+			}
 			updateRules = append(updateRules, models.UpdateRule{
 				Existing: rule,
 				New:      newRule,
 			})
 		}
+		if err := models.ValidateRuleResultQueries(newRules); err != nil {
+			return err
+		}
 		return service.ruleStore.UpdateAlertRules(ctx, updateRules)
 	})
 }
@@ -255,11 +276,16 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 	}
 	rules := make([]*models.AlertRuleWithOptionals, len(group.Rules))
 	group = *syncGroupRuleFields(&group, orgID)
+	groupRules := make([]*models.AlertRule, 0, len(group.Rules))
 	for i := range group.Rules {
 		if err := group.Rules[i].SetDashboardAndPanelFromAnnotations(); err != nil {
 			return err
 		}
 		rules = append(rules, &models.AlertRuleWithOptionals{AlertRule: group.Rules[i], HasPause: true})
+		groupRules = append(groupRules, &group.Rules[i])
+	}
+	if err := models.ValidateRuleResultQueries(groupRules); err != nil {
+		return err
 	}
 	delta, err := store.CalculateChanges(ctx, service.ruleStore, key, rules)
 	if err != nil {
@@ -273,6 +299,15 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 		return nil
 	}
 
+	changed := make([]*models.AlertRule, 0, len(delta.New)+len(delta.Update))
+	changed = append(changed, delta.New...)
+	for _, update := range delta.Update {
+		changed = append(changed, update.New)
+	}
+	if err := service.validateNotificationSettings(ctx, orgID, changed...); err != nil {
+		return err
+	}
+
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		// Delete first as this could prevent future unique constraint violations.
 		if len(delta.Delete) > 0 {
@@ -349,10 +384,14 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	rule.Updated = time.Now()
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
+	rule.SequentialEvaluation = storedRule.SequentialEvaluation
 	err = rule.SetDashboardAndPanelFromAnnotations()
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := service.validateNotificationSettings(ctx, rule.OrgID, &rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
@@ -390,6 +429,27 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, orgID int6
 }
 
 // checkLimitsTransactionCtx checks whether the current transaction (as identified by the ctx) breaches configured alert rule limits.
+// validateNotificationSettings checks that the receivers and mute timings referenced by the notification settings of the rules exist.
+func (service *AlertRuleService) validateNotificationSettings(ctx context.Context, orgID int64, rules ...*models.AlertRule) error {
+	var validator models.NotificationSettingsValidator
+	for _, rule := range rules {
+		if rule == nil || rule.NotificationSettings == nil {
+			continue
+		}
+		if validator == nil {
+			v, err := service.nsValidatorProvider.Validator(ctx, orgID)
+			if err != nil {
+				return err
+			}
+			validator = v
+		}
+		if err := validator.Validate(*rule.NotificationSettings); err != nil {
+			return errors.Join(models.ErrAlertRuleFailedValidation, err)
+		}
+	}
+	return nil
+}
+
 func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context, orgID, userID int64) error {
 	limitReached, err := service.quotas.CheckQuotaReached(ctx, models.QuotaTargetSrv, &quota.ScopeParameters{
 		OrgID:  orgID,
@@ -517,6 +577,7 @@ func (service *AlertRuleService) GetAlertGroupsWithFolderTitle(ctx context.Conte
 func syncGroupRuleFields(group *models.AlertRuleGroup, orgID int64) *models.AlertRuleGroup {
 	for i := range group.Rules {
 		group.Rules[i].IntervalSeconds = group.Interval
+		group.Rules[i].SequentialEvaluation = group.SequentialEvaluation
 		group.Rules[i].RuleGroup = group.Title
 		group.Rules[i].NamespaceUID = group.FolderUID
 		group.Rules[i].OrgID = orgID
@@ -47,7 +47,7 @@ func TestAlertRuleService(t *testing.T) {
 		require.Equal(t, int64(60), rule.IntervalSeconds)
 
 		var interval int64 = 120
-		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120)
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120, false)
 		require.NoError(t, err)
 
 		rule, _, err = ruleService.GetAlertRule(context.Background(), orgID, rule.UID)
@@ -90,7 +90,7 @@ func TestAlertRuleService(t *testing.T) {
 		require.NoError(t, err)
 
 		var interval int64 = 120
-		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120)
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, rule.NamespaceUID, rule.RuleGroup, 120, false)
 		require.NoError(t, err)
 
 		rule = dummyRule("test#4-1", orgID)
@@ -100,6 +100,40 @@ func TestAlertRuleService(t *testing.T) {
 		require.Equal(t, interval, rule.IntervalSeconds)
 	})
 
+	t.Run("sequential evaluation should be set for all rules of the group", func(t *testing.T) {
+		group := createDummyGroup("group-test-sequential", orgID)
+		group.SequentialEvaluation = true
+		group.Rules = append(group.Rules, dummyRule("group-test-sequential-rule-2", orgID))
+		err := ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		group, err = ruleService.GetRuleGroup(context.Background(), orgID, "my-namespace", "group-test-sequential")
+		require.NoError(t, err)
+		group.Rules[1].Data = append(group.Rules[1].Data, models.CreateRuleResultQuery("B", group.Rules[0].UID, models.RuleResultState, ""))
+		err = ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		readGroup, err := ruleService.GetRuleGroup(context.Background(), orgID, "my-namespace", "group-test-sequential")
+		require.NoError(t, err)
+		require.True(t, readGroup.SequentialEvaluation)
+		for _, rule := range readGroup.Rules {
+			require.True(t, rule.SequentialEvaluation)
+		}
+
+		rule := dummyRule("test#sequential", orgID)
+		rule.RuleGroup = "group-test-sequential"
+		rule, err = ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceNone, 0)
+		require.NoError(t, err)
+		require.True(t, rule.SequentialEvaluation)
+
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, "my-namespace", "group-test-sequential", readGroup.Interval, false)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		group.SequentialEvaluation = false
+		err = ruleService.ReplaceRuleGroup(context.Background(), orgID, group, 0, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
 	t.Run("updating a rule group's top level fields should bump the version number", func(t *testing.T) {
 		const (
 			orgID              = 123
@@ -120,7 +154,7 @@ func TestAlertRuleService(t *testing.T) {
 		require.Equal(t, int64(1), rule.Version)
 		require.Equal(t, int64(60), rule.IntervalSeconds)
 
-		err = ruleService.UpdateRuleGroup(context.Background(), orgID, namespaceUID, ruleGroup, newInterval)
+		err = ruleService.UpdateRuleGroup(context.Background(), orgID, namespaceUID, ruleGroup, newInterval, false)
 		require.NoError(t, err)
 
 		rule, _, err = ruleService.GetAlertRule(context.Background(), orgID, ruleUID)
@@ -578,6 +612,7 @@ func createAlertRuleService(t *testing.T) AlertRuleService {
 		log:                    log.New("testing"),
 		baseIntervalSeconds:    10,
 		defaultIntervalSeconds: 60,
+		nsValidatorProvider:    &NotificationSettingsValidatorProviderFake{},
 	}
 }
 
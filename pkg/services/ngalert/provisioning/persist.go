@@ -51,6 +51,11 @@ type QuotaChecker interface {
 	CheckQuotaReached(ctx context.Context, target quota.TargetSrv, scopeParams *quota.ScopeParameters) (bool, error)
 }
 
+// NotificationSettingsValidatorProvider provides validators for the notification settings of alert rules.
+type NotificationSettingsValidatorProvider interface {
+	Validator(ctx context.Context, orgID int64) (models.NotificationSettingsValidator, error)
+}
+
 // PersistConfig validates to config before eventually persisting it if no error occurs
 func PersistConfig(ctx context.Context, store AMConfigStore, cmd *models.SaveAlertmanagerConfigurationCmd) error {
 	cfg := &definitions.PostableUserConfig{}
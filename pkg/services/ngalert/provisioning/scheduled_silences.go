@@ -0,0 +1,179 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ScheduledSilenceStore represents the ability to persist and query scheduled silences.
+type ScheduledSilenceStore interface {
+	GetScheduledSilences(ctx context.Context, orgID int64) ([]models.ScheduledSilence, error)
+	GetScheduledSilence(ctx context.Context, orgID int64, uid string) (models.ScheduledSilence, error)
+	InsertScheduledSilence(ctx context.Context, s *models.ScheduledSilence) error
+	UpdateScheduledSilence(ctx context.Context, s models.ScheduledSilence) error
+	DeleteScheduledSilence(ctx context.Context, orgID int64, uid string) error
+}
+
+// ScheduledSilenceExpirer expires the silence that was created for the current window of a scheduled silence.
+type ScheduledSilenceExpirer interface {
+	ExpireScheduledSilence(ctx context.Context, s models.ScheduledSilence, user string) error
+}
+
+type ScheduledSilenceService struct {
+	store   ScheduledSilenceStore
+	prov    ProvisioningStore
+	xact    TransactionManager
+	expirer ScheduledSilenceExpirer
+	log     log.Logger
+}
+
+func NewScheduledSilenceService(store ScheduledSilenceStore, prov ProvisioningStore, xact TransactionManager, expirer ScheduledSilenceExpirer, log log.Logger) *ScheduledSilenceService {
+	return &ScheduledSilenceService{
+		store:   store,
+		prov:    prov,
+		xact:    xact,
+		expirer: expirer,
+		log:     log,
+	}
+}
+
+// GetScheduledSilences returns all scheduled silences of the org and their provenances by UID.
+func (svc *ScheduledSilenceService) GetScheduledSilences(ctx context.Context, orgID int64) ([]models.ScheduledSilence, map[string]models.Provenance, error) {
+	silences, err := svc.store.GetScheduledSilences(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances, err := svc.prov.GetProvenances(ctx, orgID, (&models.ScheduledSilence{}).ResourceType())
+	if err != nil {
+		return nil, nil, err
+	}
+	return silences, provenances, nil
+}
+
+// GetScheduledSilence returns the scheduled silence with the given UID and its provenance.
+func (svc *ScheduledSilenceService) GetScheduledSilence(ctx context.Context, orgID int64, uid string) (models.ScheduledSilence, models.Provenance, error) {
+	s, err := svc.store.GetScheduledSilence(ctx, orgID, uid)
+	if err != nil {
+		return models.ScheduledSilence{}, models.ProvenanceNone, err
+	}
+	provenance, err := svc.prov.GetProvenance(ctx, &s, orgID)
+	if err != nil {
+		return models.ScheduledSilence{}, models.ProvenanceNone, err
+	}
+	return s, provenance, nil
+}
+
+// CreateScheduledSilence creates a new scheduled silence. The silences of its windows are created later by the
+// background job.
+func (svc *ScheduledSilenceService) CreateScheduledSilence(ctx context.Context, s models.ScheduledSilence, provenance models.Provenance) (models.ScheduledSilence, error) {
+	if s.UID == "" {
+		s.UID = util.GenerateShortUID()
+	} else if err := util.ValidateUID(s.UID); err != nil {
+		return models.ScheduledSilence{}, fmt.Errorf("%w: cannot create scheduled silence with UID '%s': %s", ErrValidation, s.UID, err.Error())
+	}
+	if err := s.Validate(); err != nil {
+		return models.ScheduledSilence{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	_, err := svc.store.GetScheduledSilence(ctx, s.OrgID, s.UID)
+	if err == nil {
+		return models.ScheduledSilence{}, fmt.Errorf("%w: a scheduled silence with this UID already exists", ErrValidation)
+	}
+	if !errors.Is(err, models.ErrScheduledSilenceNotFound) {
+		return models.ScheduledSilence{}, err
+	}
+	s.ID = 0
+	s.SilenceID = ""
+	s.WindowStart = 0
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertScheduledSilence(ctx, &s); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &s, s.OrgID, provenance)
+	})
+	if err != nil {
+		return models.ScheduledSilence{}, err
+	}
+	return s, nil
+}
+
+// UpdateScheduledSilence replaces the schedule and the matchers of an existing scheduled silence. The silence created
+// for the current window is expired, so that the background job creates a silence that matches the new definition.
+func (svc *ScheduledSilenceService) UpdateScheduledSilence(ctx context.Context, s models.ScheduledSilence, provenance models.Provenance, user string) (models.ScheduledSilence, error) {
+	if err := s.Validate(); err != nil {
+		return models.ScheduledSilence{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	existing, err := svc.store.GetScheduledSilence(ctx, s.OrgID, s.UID)
+	if err != nil {
+		return models.ScheduledSilence{}, err
+	}
+	if err := svc.checkProvenance(ctx, &existing, provenance); err != nil {
+		return models.ScheduledSilence{}, err
+	}
+	s.ID = existing.ID
+	s.SilenceID = ""
+	s.WindowStart = 0
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateScheduledSilence(ctx, s); err != nil {
+			return err
+		}
+		return svc.prov.SetProvenance(ctx, &s, s.OrgID, provenance)
+	})
+	if err != nil {
+		return models.ScheduledSilence{}, err
+	}
+	svc.expire(ctx, existing, user)
+	return s, nil
+}
+
+// DeleteScheduledSilence deletes the scheduled silence and expires the silence created for its current window. If the
+// scheduled silence does not exist, no error is returned.
+func (svc *ScheduledSilenceService) DeleteScheduledSilence(ctx context.Context, orgID int64, uid string, provenance models.Provenance, user string) error {
+	existing, err := svc.store.GetScheduledSilence(ctx, orgID, uid)
+	if errors.Is(err, models.ErrScheduledSilenceNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := svc.checkProvenance(ctx, &existing, provenance); err != nil {
+		return err
+	}
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteScheduledSilence(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.prov.DeleteProvenance(ctx, &existing, orgID)
+	})
+	if err != nil {
+		return err
+	}
+	svc.expire(ctx, existing, user)
+	return nil
+}
+
+func (svc *ScheduledSilenceService) checkProvenance(ctx context.Context, s *models.ScheduledSilence, provenance models.Provenance) error {
+	stored, err := svc.prov.GetProvenance(ctx, s, s.OrgID)
+	if err != nil {
+		return err
+	}
+	if stored != provenance && stored != models.ProvenanceNone {
+		return fmt.Errorf("cannot change scheduled silence with provided provenance '%s', needs '%s'", provenance, stored)
+	}
+	return nil
+}
+
+// expire expires the silence of the current window. The change of the scheduled silence is already persisted at this
+// point, so a failure is only logged and the silence ends with its window.
+func (svc *ScheduledSilenceService) expire(ctx context.Context, s models.ScheduledSilence, user string) {
+	if s.SilenceID == "" || svc.expirer == nil {
+		return
+	}
+	if err := svc.expirer.ExpireScheduledSilence(ctx, s, user); err != nil {
+		svc.log.Error("Failed to expire the silence of the scheduled silence", "org", s.OrgID, "uid", s.UID, "silenceID", s.SilenceID, "error", err)
+	}
+}
@@ -0,0 +1,188 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestScheduledSilenceService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create scheduled silence with provenance", func(t *testing.T) {
+		sut, store, _ := createScheduledSilenceSvcSut()
+
+		created, err := sut.CreateScheduledSilence(ctx, createScheduledSilence(), models.ProvenanceFile)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, created.UID)
+		require.Contains(t, store.silences, created.UID)
+
+		_, provenance, err := sut.GetScheduledSilence(ctx, 1, created.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceFile, provenance)
+	})
+
+	t.Run("should reject invalid scheduled silence", func(t *testing.T) {
+		sut, _, _ := createScheduledSilenceSvcSut()
+		s := createScheduledSilence()
+		s.EndsAt = s.StartsAt
+
+		_, err := sut.CreateScheduledSilence(ctx, s, models.ProvenanceNone)
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("should reject duplicate UID", func(t *testing.T) {
+		sut, _, _ := createScheduledSilenceSvcSut()
+		s := createScheduledSilence()
+		s.UID = "maintenance"
+		_, err := sut.CreateScheduledSilence(ctx, s, models.ProvenanceNone)
+		require.NoError(t, err)
+
+		_, err = sut.CreateScheduledSilence(ctx, s, models.ProvenanceNone)
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("should expire current silence on update", func(t *testing.T) {
+		sut, store, expirer := createScheduledSilenceSvcSut()
+		created, err := sut.CreateScheduledSilence(ctx, createScheduledSilence(), models.ProvenanceNone)
+		require.NoError(t, err)
+		materialized := store.silences[created.UID]
+		materialized.SilenceID = "silence-1"
+		materialized.WindowStart = materialized.StartsAt.Unix()
+		store.silences[created.UID] = materialized
+
+		created.Comment = "changed"
+		updated, err := sut.UpdateScheduledSilence(ctx, created, models.ProvenanceNone, "editor")
+
+		require.NoError(t, err)
+		require.Equal(t, "changed", store.silences[created.UID].Comment)
+		require.Empty(t, store.silences[created.UID].SilenceID)
+		require.Zero(t, store.silences[created.UID].WindowStart)
+		require.Equal(t, created.ID, updated.ID)
+		require.Equal(t, []string{"silence-1"}, expirer.expired)
+		require.Equal(t, []string{"editor"}, expirer.users)
+	})
+
+	t.Run("should not update scheduled silence with different provenance", func(t *testing.T) {
+		sut, _, _ := createScheduledSilenceSvcSut()
+		created, err := sut.CreateScheduledSilence(ctx, createScheduledSilence(), models.ProvenanceFile)
+		require.NoError(t, err)
+
+		_, err = sut.UpdateScheduledSilence(ctx, created, models.ProvenanceAPI, "editor")
+
+		require.Error(t, err)
+	})
+
+	t.Run("should return not found when updating unknown scheduled silence", func(t *testing.T) {
+		sut, _, _ := createScheduledSilenceSvcSut()
+		s := createScheduledSilence()
+		s.UID = "unknown"
+
+		_, err := sut.UpdateScheduledSilence(ctx, s, models.ProvenanceNone, "editor")
+
+		require.ErrorIs(t, err, models.ErrScheduledSilenceNotFound)
+	})
+
+	t.Run("should delete scheduled silence and expire current silence", func(t *testing.T) {
+		sut, store, expirer := createScheduledSilenceSvcSut()
+		created, err := sut.CreateScheduledSilence(ctx, createScheduledSilence(), models.ProvenanceNone)
+		require.NoError(t, err)
+		materialized := store.silences[created.UID]
+		materialized.SilenceID = "silence-1"
+		store.silences[created.UID] = materialized
+
+		require.NoError(t, sut.DeleteScheduledSilence(ctx, 1, created.UID, models.ProvenanceNone, "editor"))
+
+		require.NotContains(t, store.silences, created.UID)
+		require.Equal(t, []string{"silence-1"}, expirer.expired)
+		require.NoError(t, sut.DeleteScheduledSilence(ctx, 1, created.UID, models.ProvenanceNone, "editor"))
+	})
+}
+
+func createScheduledSilenceSvcSut() (*ScheduledSilenceService, *fakeScheduledSilenceStore, *fakeScheduledSilenceExpirer) {
+	store := &fakeScheduledSilenceStore{silences: map[string]models.ScheduledSilence{}}
+	expirer := &fakeScheduledSilenceExpirer{}
+	return &ScheduledSilenceService{
+		store:   store,
+		prov:    NewFakeProvisioningStore(),
+		xact:    newNopTransactionManager(),
+		expirer: expirer,
+		log:     log.NewNopLogger(),
+	}, store, expirer
+}
+
+func createScheduledSilence() models.ScheduledSilence {
+	start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+	return models.ScheduledSilence{
+		OrgID:     1,
+		Matchers:  []models.SilenceMatcher{{Name: "env", Value: "prod", IsEqual: true}},
+		Comment:   "weekly maintenance",
+		CreatedBy: "admin",
+		StartsAt:  start,
+		EndsAt:    start.Add(2 * time.Hour),
+		Frequency: models.ScheduledSilenceWeekly,
+		TimeZone:  "UTC",
+	}
+}
+
+type fakeScheduledSilenceStore struct {
+	silences map[string]models.ScheduledSilence
+	lastID   int64
+}
+
+func (f *fakeScheduledSilenceStore) GetScheduledSilences(_ context.Context, orgID int64) ([]models.ScheduledSilence, error) {
+	var result []models.ScheduledSilence
+	for _, s := range f.silences {
+		if s.OrgID == orgID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeScheduledSilenceStore) GetScheduledSilence(_ context.Context, orgID int64, uid string) (models.ScheduledSilence, error) {
+	s, ok := f.silences[uid]
+	if !ok || s.OrgID != orgID {
+		return models.ScheduledSilence{}, models.ErrScheduledSilenceNotFound
+	}
+	return s, nil
+}
+
+func (f *fakeScheduledSilenceStore) InsertScheduledSilence(_ context.Context, s *models.ScheduledSilence) error {
+	f.lastID++
+	s.ID = f.lastID
+	f.silences[s.UID] = *s
+	return nil
+}
+
+func (f *fakeScheduledSilenceStore) UpdateScheduledSilence(_ context.Context, s models.ScheduledSilence) error {
+	if _, ok := f.silences[s.UID]; !ok {
+		return models.ErrScheduledSilenceNotFound
+	}
+	f.silences[s.UID] = s
+	return nil
+}
+
+func (f *fakeScheduledSilenceStore) DeleteScheduledSilence(_ context.Context, _ int64, uid string) error {
+	delete(f.silences, uid)
+	return nil
+}
+
+type fakeScheduledSilenceExpirer struct {
+	expired []string
+	users   []string
+}
+
+func (f *fakeScheduledSilenceExpirer) ExpireScheduledSilence(_ context.Context, s models.ScheduledSilence, user string) error {
+	f.expired = append(f.expired, s.SilenceID)
+	f.users = append(f.users, user)
+	return nil
+}
@@ -185,3 +185,16 @@ func (m *MockQuotaChecker_Expecter) LimitExceeded() *MockQuotaChecker_Expecter {
 	m.CheckQuotaReached(mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
 	return m
 }
+
+// NotificationSettingsValidatorProviderFake provides validators that accept all notification settings.
+type NotificationSettingsValidatorProviderFake struct{}
+
+func (n *NotificationSettingsValidatorProviderFake) Validator(ctx context.Context, orgID int64) (models.NotificationSettingsValidator, error) {
+	return notificationSettingsValidatorFake{}, nil
+}
+
+type notificationSettingsValidatorFake struct{}
+
+func (n notificationSettingsValidatorFake) Validate(s models.NotificationSettings) error {
+	return nil
+}
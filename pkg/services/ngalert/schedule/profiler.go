@@ -0,0 +1,265 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleEvaluationProfile contains the evaluation statistics of a version of an alert rule.
+type RuleEvaluationProfile struct {
+	RuleKey         ngmodels.AlertRuleKey
+	Version         int64
+	IntervalSeconds int64
+	Evaluations     int64
+	Failures        int64
+	LastEvaluation  time.Time
+	LastDuration    time.Duration
+	MaxDuration     time.Duration
+	TotalDuration   time.Duration
+	// TotalQueryDuration and TotalExpressionDuration are the time spent executing the queries and expressions.
+	TotalQueryDuration      time.Duration
+	TotalExpressionDuration time.Duration
+	// LastSeries is the number of series returned by the queries in the last evaluation.
+	LastSeries int
+	// LastResults is the number of results of the last evaluation, which is the number of alert instances.
+	LastResults int
+	// Nodes contains the statistics of each query and expression in the order of execution.
+	Nodes []NodeEvaluationProfile
+}
+
+// NodeEvaluationProfile contains the execution statistics of a query or expression of an alert rule.
+type NodeEvaluationProfile struct {
+	RefID         string
+	IsQuery       bool
+	Executions    int64
+	LastDuration  time.Duration
+	MaxDuration   time.Duration
+	TotalDuration time.Duration
+	LastSeries    int
+}
+
+// AverageDuration returns the average duration of an evaluation of the rule.
+func (p RuleEvaluationProfile) AverageDuration() time.Duration {
+	if p.Evaluations == 0 {
+		return 0
+	}
+	return p.TotalDuration / time.Duration(p.Evaluations)
+}
+
+// AverageQueryDuration returns the average time spent executing the queries of the rule in an evaluation.
+func (p RuleEvaluationProfile) AverageQueryDuration() time.Duration {
+	if p.Evaluations == 0 {
+		return 0
+	}
+	return p.TotalQueryDuration / time.Duration(p.Evaluations)
+}
+
+// AverageExpressionDuration returns the average time spent executing the expressions of the rule in an evaluation.
+func (p RuleEvaluationProfile) AverageExpressionDuration() time.Duration {
+	if p.Evaluations == 0 {
+		return 0
+	}
+	return p.TotalExpressionDuration / time.Duration(p.Evaluations)
+}
+
+// Load returns the fraction of time the rule is being evaluated, which is the cost of the rule: a rule that takes
+// 1s to evaluate every 10s costs as much as a rule that takes 6s every minute.
+func (p RuleEvaluationProfile) Load() float64 {
+	if p.IntervalSeconds <= 0 {
+		return 0
+	}
+	return p.AverageDuration().Seconds() / float64(p.IntervalSeconds)
+}
+
+// AverageDuration returns the average duration of an execution of the node.
+func (p NodeEvaluationProfile) AverageDuration() time.Duration {
+	if p.Executions == 0 {
+		return 0
+	}
+	return p.TotalDuration / time.Duration(p.Executions)
+}
+
+// EvaluationProfiler keeps the evaluation statistics of the alert rules evaluated by the scheduler. The statistics of
+// a rule are reset when the rule is updated, and removed when the rule is no longer evaluated by this instance.
+type EvaluationProfiler struct {
+	mtx      sync.RWMutex
+	profiles map[ngmodels.AlertRuleKey]*RuleEvaluationProfile
+}
+
+func NewEvaluationProfiler() *EvaluationProfiler {
+	return &EvaluationProfiler{
+		profiles: make(map[ngmodels.AlertRuleKey]*RuleEvaluationProfile),
+	}
+}
+
+// Get returns the evaluation statistics of the rule, or false if the rule has not been evaluated.
+func (p *EvaluationProfiler) Get(key ngmodels.AlertRuleKey) (RuleEvaluationProfile, bool) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	profile, ok := p.profiles[key]
+	if !ok {
+		return RuleEvaluationProfile{}, false
+	}
+	return profile.copy(), true
+}
+
+// All returns the evaluation statistics of all rules.
+func (p *EvaluationProfiler) All() []RuleEvaluationProfile {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	result := make([]RuleEvaluationProfile, 0, len(p.profiles))
+	for _, profile := range p.profiles {
+		result = append(result, profile.copy())
+	}
+	return result
+}
+
+// withStats returns a context in which the evaluator records the statistics of the evaluation. It returns nil
+// statistics if the profiler is nil.
+func (p *EvaluationProfiler) withStats(ctx context.Context) (context.Context, *eval.EvaluationStats) {
+	if p == nil {
+		return ctx, nil
+	}
+	stats := &eval.EvaluationStats{}
+	return eval.WithEvaluationStats(ctx, stats), stats
+}
+
+// observe adds an evaluation of the rule to its statistics.
+func (p *EvaluationProfiler) observe(rule *ngmodels.AlertRule, evaluatedAt time.Time, duration time.Duration, stats *eval.EvaluationStats, failed bool) {
+	if p == nil || stats == nil {
+		return
+	}
+	key := rule.GetKey()
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	profile, ok := p.profiles[key]
+	if !ok || profile.Version != rule.Version {
+		profile = &RuleEvaluationProfile{RuleKey: key, Version: rule.Version}
+		p.profiles[key] = profile
+	}
+	profile.IntervalSeconds = rule.IntervalSeconds
+	profile.Evaluations++
+	if failed {
+		profile.Failures++
+	}
+	profile.LastEvaluation = evaluatedAt
+	profile.LastDuration = duration
+	if duration > profile.MaxDuration {
+		profile.MaxDuration = duration
+	}
+	profile.TotalDuration += duration
+	profile.TotalQueryDuration += stats.QueryDuration()
+	profile.TotalExpressionDuration += stats.ExpressionDuration()
+	profile.LastSeries = stats.Series()
+	profile.LastResults = stats.Results
+
+	previous := make(map[string]NodeEvaluationProfile, len(profile.Nodes))
+	for _, n := range profile.Nodes {
+		previous[n.RefID] = n
+	}
+	nodes := make([]NodeEvaluationProfile, 0, len(stats.Nodes))
+	for _, s := range stats.Nodes {
+		n := previous[s.RefID]
+		n.RefID = s.RefID
+		n.IsQuery = s.IsQuery
+		n.Executions++
+		n.LastDuration = s.Duration
+		if s.Duration > n.MaxDuration {
+			n.MaxDuration = s.Duration
+		}
+		n.TotalDuration += s.Duration
+		n.LastSeries = s.Series
+		nodes = append(nodes, n)
+		delete(previous, s.RefID)
+	}
+	// Keep the nodes that were not executed in this evaluation, for example because a query they depend on failed.
+	for _, n := range profile.Nodes {
+		if _, ok := previous[n.RefID]; ok {
+			nodes = append(nodes, n)
+		}
+	}
+	profile.Nodes = nodes
+}
+
+// delete removes the statistics of the rule.
+func (p *EvaluationProfiler) delete(key ngmodels.AlertRuleKey) {
+	if p == nil {
+		return
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	delete(p.profiles, key)
+}
+
+func (p *RuleEvaluationProfile) copy() RuleEvaluationProfile {
+	result := *p
+	result.Nodes = make([]NodeEvaluationProfile, len(p.Nodes))
+	copy(result.Nodes, p.Nodes)
+	return result
+}
+
+// profileCollector exports the evaluation statistics of the most expensive rules as metrics. Only the top rules are
+// exported, so that the number of series does not grow with the number of rules.
+type profileCollector struct {
+	profiler *EvaluationProfiler
+	top      int
+
+	load     *prometheus.Desc
+	duration *prometheus.Desc
+	series   *prometheus.Desc
+}
+
+func newProfileCollector(profiler *EvaluationProfiler, top int) *profileCollector {
+	return &profileCollector{
+		profiler: profiler,
+		top:      top,
+		load: prometheus.NewDesc(
+			prometheus.BuildFQName(metrics.Namespace, metrics.Subsystem, "rule_evaluation_load"),
+			"The fraction of time the rule is being evaluated, for the most expensive rules.",
+			[]string{"org", "rule_uid"}, nil,
+		),
+		duration: prometheus.NewDesc(
+			prometheus.BuildFQName(metrics.Namespace, metrics.Subsystem, "rule_evaluation_average_duration_seconds"),
+			"The average time to evaluate the rule, the queries of the rule and the expressions of the rule, for the most expensive rules.",
+			[]string{"org", "rule_uid", "kind"}, nil,
+		),
+		series: prometheus.NewDesc(
+			prometheus.BuildFQName(metrics.Namespace, metrics.Subsystem, "rule_evaluation_series"),
+			"The number of series returned by the queries of the rule in the last evaluation, for the most expensive rules.",
+			[]string{"org", "rule_uid"}, nil,
+		),
+	}
+}
+
+func (c *profileCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.load
+	ch <- c.duration
+	ch <- c.series
+}
+
+func (c *profileCollector) Collect(ch chan<- prometheus.Metric) {
+	profiles := c.profiler.All()
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Load() > profiles[j].Load()
+	})
+	if len(profiles) > c.top {
+		profiles = profiles[:c.top]
+	}
+	for _, p := range profiles {
+		org := fmt.Sprint(p.RuleKey.OrgID)
+		ch <- prometheus.MustNewConstMetric(c.load, prometheus.GaugeValue, p.Load(), org, p.RuleKey.UID)
+		ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, p.AverageDuration().Seconds(), org, p.RuleKey.UID, "total")
+		ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, p.AverageQueryDuration().Seconds(), org, p.RuleKey.UID, "query")
+		ch <- prometheus.MustNewConstMetric(c.duration, prometheus.GaugeValue, p.AverageExpressionDuration().Seconds(), org, p.RuleKey.UID, "expression")
+		ch <- prometheus.MustNewConstMetric(c.series, prometheus.GaugeValue, float64(p.LastSeries), org, p.RuleKey.UID)
+	}
+}
@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluationProfiler(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rule := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(10*time.Second))()
+	stats := func(a, b time.Duration, series, results int) *eval.EvaluationStats {
+		return &eval.EvaluationStats{
+			Nodes: []eval.NodeStats{
+				{RefID: "A", IsQuery: true, Duration: a, Series: series},
+				{RefID: "B", Duration: b, Series: series},
+			},
+			Results: results,
+		}
+	}
+
+	t.Run("should aggregate the evaluations of a rule", func(t *testing.T) {
+		p := NewEvaluationProfiler()
+		p.observe(rule, now, 3*time.Second, stats(2*time.Second, 500*time.Millisecond, 10, 10), false)
+		p.observe(rule, now.Add(10*time.Second), time.Second, stats(800*time.Millisecond, 100*time.Millisecond, 5, 4), true)
+
+		profile, ok := p.Get(rule.GetKey())
+		require.True(t, ok)
+		require.Equal(t, rule.Version, profile.Version)
+		require.Equal(t, int64(2), profile.Evaluations)
+		require.Equal(t, int64(1), profile.Failures)
+		require.Equal(t, now.Add(10*time.Second), profile.LastEvaluation)
+		require.Equal(t, time.Second, profile.LastDuration)
+		require.Equal(t, 3*time.Second, profile.MaxDuration)
+		require.Equal(t, 2*time.Second, profile.AverageDuration())
+		require.Equal(t, 1400*time.Millisecond, profile.AverageQueryDuration())
+		require.Equal(t, 300*time.Millisecond, profile.AverageExpressionDuration())
+		require.Equal(t, 5, profile.LastSeries)
+		require.Equal(t, 4, profile.LastResults)
+		require.InDelta(t, 0.2, profile.Load(), 0.0001)
+
+		require.Len(t, profile.Nodes, 2)
+		require.Equal(t, "A", profile.Nodes[0].RefID)
+		require.True(t, profile.Nodes[0].IsQuery)
+		require.Equal(t, int64(2), profile.Nodes[0].Executions)
+		require.Equal(t, 1400*time.Millisecond, profile.Nodes[0].AverageDuration())
+		require.Equal(t, 2*time.Second, profile.Nodes[0].MaxDuration)
+		require.Equal(t, "B", profile.Nodes[1].RefID)
+		require.False(t, profile.Nodes[1].IsQuery)
+	})
+
+	t.Run("should reset the statistics when the rule is updated", func(t *testing.T) {
+		p := NewEvaluationProfiler()
+		p.observe(rule, now, 3*time.Second, stats(2*time.Second, 500*time.Millisecond, 10, 10), false)
+
+		updated := models.CopyRule(rule)
+		updated.Version++
+		p.observe(updated, now.Add(10*time.Second), time.Second, stats(800*time.Millisecond, 100*time.Millisecond, 5, 4), false)
+
+		profile, ok := p.Get(rule.GetKey())
+		require.True(t, ok)
+		require.Equal(t, updated.Version, profile.Version)
+		require.Equal(t, int64(1), profile.Evaluations)
+		require.Equal(t, time.Second, profile.MaxDuration)
+	})
+
+	t.Run("should not keep statistics without evaluation statistics", func(t *testing.T) {
+		p := NewEvaluationProfiler()
+		p.observe(rule, now, time.Second, nil, false)
+		_, ok := p.Get(rule.GetKey())
+		require.False(t, ok)
+
+		var nilProfiler *EvaluationProfiler
+		ctx, s := nilProfiler.withStats(context.Background())
+		require.Nil(t, s)
+		nilProfiler.observe(rule, now, time.Second, s, false)
+		nilProfiler.delete(rule.GetKey())
+		require.Equal(t, context.Background(), ctx)
+	})
+
+	t.Run("should delete the statistics of a rule", func(t *testing.T) {
+		p := NewEvaluationProfiler()
+		p.observe(rule, now, time.Second, stats(time.Second, 0, 1, 1), false)
+		p.delete(rule.GetKey())
+		require.Empty(t, p.All())
+	})
+
+	t.Run("should export the statistics of the most expensive rules", func(t *testing.T) {
+		p := NewEvaluationProfiler()
+		cheap := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Minute))()
+		cheap.UID = "cheap"
+		expensive := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(10*time.Second))()
+		expensive.UID = "expensive"
+		p.observe(cheap, now, 6*time.Second, stats(5*time.Second, time.Second, 2, 1), false)
+		p.observe(expensive, now, 2*time.Second, stats(time.Second, time.Second, 100, 1), false)
+
+		reg := prometheus.NewPedanticRegistry()
+		reg.MustRegister(newProfileCollector(p, 1))
+		expected := `
+# HELP grafana_alerting_rule_evaluation_average_duration_seconds The average time to evaluate the rule, the queries of the rule and the expressions of the rule, for the most expensive rules.
+# TYPE grafana_alerting_rule_evaluation_average_duration_seconds gauge
+grafana_alerting_rule_evaluation_average_duration_seconds{kind="expression",org="1",rule_uid="expensive"} 1
+grafana_alerting_rule_evaluation_average_duration_seconds{kind="query",org="1",rule_uid="expensive"} 1
+grafana_alerting_rule_evaluation_average_duration_seconds{kind="total",org="1",rule_uid="expensive"} 2
+# HELP grafana_alerting_rule_evaluation_load The fraction of time the rule is being evaluated, for the most expensive rules.
+# TYPE grafana_alerting_rule_evaluation_load gauge
+grafana_alerting_rule_evaluation_load{org="1",rule_uid="expensive"} 0.2
+# HELP grafana_alerting_rule_evaluation_series The number of series returned by the queries of the rule in the last evaluation, for the most expensive rules.
+# TYPE grafana_alerting_rule_evaluation_series gauge
+grafana_alerting_rule_evaluation_series{org="1",rule_uid="expensive"} 100
+`
+		require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected)))
+	})
+}
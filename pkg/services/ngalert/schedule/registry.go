@@ -130,6 +130,16 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called when the evaluation is done or is not going to happen. It is set for the rules of groups
+	// that are evaluated sequentially to dispatch the next rule of the group.
+	afterEval func()
+}
+
+// done calls afterEval, if it is set, without blocking the caller.
+func (e *evaluation) done() {
+	if e.afterEval != nil {
+		go e.afterEval()
+	}
 }
 
 type alertRulesRegistry struct {
@@ -335,6 +345,13 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	} else {
 		writeInt(0)
 	}
+	if rule.Record != nil {
+		writeString(rule.Record.Metric)
+		writeString(rule.Record.From)
+	}
+	if rule.NotificationSettings != nil {
+		writeString(rule.NotificationSettings.Fingerprint())
+	}
 
 	// fields that do not affect the state.
 	// TODO consider removing fields below from the fingerprint
@@ -342,6 +359,12 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(rule.OrgID)
 	writeInt(rule.IntervalSeconds)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
+	if rule.SequentialEvaluation {
+		writeInt(1)
+	} else {
+		writeInt(0)
+	}
 	writeLabels(rule.Annotations)
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
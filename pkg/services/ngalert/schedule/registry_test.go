@@ -408,13 +408,17 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NoDataState:     "test-nodata",
 			ExecErrState:    "test-err",
 			For:             12,
+			KeepFiringFor:   13,
 			Annotations: map[string]string{
 				"key-annotation": "value-annotation",
 			},
 			Labels: map[string]string{
 				"key-label": "value-label",
 			},
-			IsPaused: false,
+			IsPaused:             false,
+			Record:               &models.Record{Metric: "test_metric", From: "1"},
+			NotificationSettings: &models.NotificationSettings{Receiver: "test-receiver"},
+			SequentialEvaluation: false,
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -443,18 +447,23 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			NoDataState:     "test-nodata2",
 			ExecErrState:    "test-err2",
 			For:             1141,
+			KeepFiringFor:   1142,
 			Annotations: map[string]string{
 				"key-annotation2": "value-annotation",
 			},
 			Labels: map[string]string{
 				"key-label": "value-label23",
 			},
-			IsPaused: true,
+			IsPaused:             true,
+			Record:               &models.Record{Metric: "test_metric_2", From: "2"},
+			NotificationSettings: &models.NotificationSettings{Receiver: "test-receiver-2"},
+			SequentialEvaluation: true,
 		}
 
 		excludedFields := map[string]struct{}{
-			"Version": {},
-			"Updated": {},
+			"Version":   {},
+			"Updated":   {},
+			"UpdatedBy": {},
 		}
 
 		tp := reflect.TypeOf(rule).Elem()
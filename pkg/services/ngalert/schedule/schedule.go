@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -41,6 +43,11 @@ type AlertsSender interface {
 	Send(ctx context.Context, key ngmodels.AlertRuleKey, alerts definitions.PostableAlerts)
 }
 
+// RecordingWriter writes the results of recording rules.
+type RecordingWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
 // RulesStore is a store that provides alert rules for scheduling
 type RulesStore interface {
 	GetAlertRulesKeysForScheduling(ctx context.Context) ([]ngmodels.AlertRuleKeyWithVersion, error)
@@ -91,6 +98,15 @@ type schedule struct {
 	schedulableAlertRules alertRulesRegistry
 
 	tracer tracing.Tracer
+
+	// recordingWriter is nil if recording rules are disabled.
+	recordingWriter RecordingWriter
+
+	// sharder is nil if the evaluation of alert rules is not sharded across the instances.
+	sharder *ruleSharder
+
+	// profiler is nil if the evaluation statistics of alert rules are not collected.
+	profiler *EvaluationProfiler
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -107,6 +123,15 @@ type SchedulerCfg struct {
 	AlertSender          AlertsSender
 	Tracer               tracing.Tracer
 	Log                  log.Logger
+	// RecordingWriter writes the results of recording rules. Recording rules are not evaluated if it is nil.
+	RecordingWriter RecordingWriter
+	// Sharding enables the sharded evaluation of alert rules across the instances. If it is nil, all rules are evaluated.
+	Sharding *ShardingCfg
+	// Profiler collects the evaluation statistics of alert rules. The statistics are not collected if it is nil.
+	Profiler *EvaluationProfiler
+	// ProfileMetricsTopRules is the number of the most expensive rules whose evaluation statistics are exported as
+	// metrics. It is only used if Profiler is set.
+	ProfileMetricsTopRules int
 }
 
 // NewScheduler returns a new schedule.
@@ -127,6 +152,14 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
+		recordingWriter:       cfg.RecordingWriter,
+		profiler:              cfg.Profiler,
+	}
+	if cfg.Sharding != nil {
+		sch.sharder = newRuleSharder(*cfg.Sharding, cfg.Log)
+	}
+	if cfg.Profiler != nil && cfg.ProfileMetricsTopRules > 0 && cfg.Metrics.Registerer != nil {
+		cfg.Metrics.Registerer.MustRegister(newProfileCollector(cfg.Profiler, cfg.ProfileMetricsTopRules))
 	}
 
 	return &sch
@@ -140,9 +173,26 @@ func (sch *schedule) Run(ctx context.Context) error {
 	if err := sch.schedulePeriodic(ctx, t); err != nil {
 		sch.log.Error("Failure while running the rule evaluation loop", "error", err)
 	}
+	if sch.sharder != nil {
+		leaveCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		sch.sharder.leave(leaveCtx)
+	}
 	return nil
 }
 
+// releaseAlertRule stops evaluation of the rule that is handed over to another instance. Unlike deleteAlertRule, the
+// rule is kept in the active rules and its state is kept in the database, so the new owner continues from it.
+func (sch *schedule) releaseAlertRule(keys ...ngmodels.AlertRuleKey) {
+	for _, key := range keys {
+		ruleInfo, ok := sch.registry.del(key)
+		if !ok {
+			continue
+		}
+		ruleInfo.stop(errRuleNotOwned)
+	}
+}
+
 // deleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
 func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	for _, key := range keys {
@@ -242,11 +292,24 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	if sch.sharder != nil {
+		sch.sharder.sync(ctx, tick)
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
+	// notOwned contains the rules that are evaluated by other instances. Their routines are stopped but the rules are not deleted.
+	notOwned := make([]ngmodels.AlertRuleKey, 0)
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if sch.sharder != nil && !sch.sharder.owns(shardingKey(item)) {
+			if _, ok := registeredDefinitions[key]; ok {
+				notOwned = append(notOwned, key)
+				delete(registeredDefinitions, key)
+			}
+			continue
+		}
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -313,26 +376,19 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
+	// the rules of groups that are evaluated sequentially are not dispatched on their own but by the rule before them.
+	toDispatch := sch.chainSequentialGroups(readyToRun)
+
 	var step int64 = 0
-	if len(readyToRun) > 0 {
-		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+	if len(toDispatch) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(toDispatch))
 	}
 
-	for i := range readyToRun {
-		item := readyToRun[i]
+	for i := range toDispatch {
+		item := toDispatch[i]
 
 		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleInfo.eval(&item.evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
-				return
-			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
-			}
+			sch.dispatch(item)
 		})
 	}
 
@@ -342,9 +398,61 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		toDelete = append(toDelete, key)
 	}
 	sch.deleteAlertRule(toDelete...)
+	sch.releaseAlertRule(notOwned...)
 	return readyToRun, registeredDefinitions, updatedRules
 }
 
+// dispatch sends the evaluation to the routine of the rule.
+func (sch *schedule) dispatch(item readyToRunItem) {
+	key := item.rule.GetKey()
+	success, dropped := item.ruleInfo.eval(&item.evaluation)
+	if dropped != nil {
+		sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", dropped.scheduledAt)...)
+		orgID := fmt.Sprint(key.OrgID)
+		sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+		dropped.done()
+	}
+	if !success {
+		sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", item.scheduledAt)...)
+		item.evaluation.done()
+	}
+}
+
+// chainSequentialGroups returns the items that have to be dispatched by the scheduler. The rules of a group that is
+// evaluated sequentially are chained in the order of the group: only the first rule is returned, and the evaluation
+// of each rule dispatches the next rule when it is done.
+func (sch *schedule) chainSequentialGroups(items []readyToRunItem) []readyToRunItem {
+	result := make([]readyToRunItem, 0, len(items))
+	groups := make(map[ngmodels.AlertRuleGroupKey][]readyToRunItem)
+	var groupKeys []ngmodels.AlertRuleGroupKey
+	for _, item := range items {
+		if !item.rule.SequentialEvaluation {
+			result = append(result, item)
+			continue
+		}
+		groupKey := item.rule.GetGroupKey()
+		if _, ok := groups[groupKey]; !ok {
+			groupKeys = append(groupKeys, groupKey)
+		}
+		groups[groupKey] = append(groups[groupKey], item)
+	}
+	for _, groupKey := range groupKeys {
+		group := groups[groupKey]
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].rule.RuleGroupIndex < group[j].rule.RuleGroupIndex
+		})
+		// link the rules from the last one so that every item carries the rest of the chain.
+		for i := len(group) - 2; i >= 0; i-- {
+			next := group[i+1]
+			group[i].afterEval = func() {
+				sch.dispatch(next)
+			}
+		}
+		result = append(result, group[0])
+	}
+	return result
+}
+
 func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertRuleKey, evalCh <-chan *evaluation, updateCh <-chan ruleVersionAndPauseStatus) error {
 	grafanaCtx = ngmodels.WithRuleKey(grafanaCtx, key)
 	logger := sch.log.FromContext(grafanaCtx)
@@ -356,6 +464,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	evalTotalFailures := sch.metrics.EvalFailures.WithLabelValues(orgID)
 	processDuration := sch.metrics.ProcessDuration.WithLabelValues(orgID)
 	sendDuration := sch.metrics.SendDuration.WithLabelValues(orgID)
+	recordingWrites := sch.metrics.RecordingWrites.WithLabelValues(orgID)
+	recordingWriteFailures := sch.metrics.RecordingWriteFailures.WithLabelValues(orgID)
+	recordingWriteDuration := sch.metrics.RecordingWriteDuration.WithLabelValues(orgID)
 
 	notify := func(states []state.StateTransition) {
 		expiredAlerts := state.FromAlertsStateToStoppedAlert(states, sch.appURL, sch.clock)
@@ -374,11 +485,53 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		notify(states)
 	}
 
+	evaluateRecording := func(ctx context.Context, logger log.Logger, e *evaluation, span trace.Span) {
+		if sch.recordingWriter == nil {
+			logger.Debug("Skip evaluation of the recording rule because recording rules are disabled")
+			return
+		}
+		start := sch.clock.Now()
+		statsCtx, stats := sch.profiler.withStats(ctx)
+		frames, err := sch.evaluateRecordingRule(statsCtx, e)
+		dur := sch.clock.Now().Sub(start)
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+		sch.profiler.observe(e.rule, e.scheduledAt, dur, stats, err != nil)
+		if err != nil {
+			evalTotalFailures.Inc()
+			logger.Error("Failed to evaluate recording rule", "error", err, "duration", dur)
+		} else if ctx.Err() == nil {
+			start = sch.clock.Now()
+			err = sch.recordingWriter.Write(ctx, e.rule.Record.Metric, e.scheduledAt, frames, e.rule.GetLabels())
+			recordingWriteDuration.Observe(sch.clock.Now().Sub(start).Seconds())
+			if err != nil {
+				recordingWriteFailures.Inc()
+				logger.Error("Failed to write recording rule result", "error", err)
+			} else {
+				recordingWrites.Inc()
+			}
+		}
+		if err != nil {
+			span.SetStatus(codes.Error, "recording rule evaluation failed")
+			span.RecordError(err)
+		}
+		if ctx.Err() != nil {
+			logger.Debug("Skip updating the status because the context has been cancelled")
+			return
+		}
+		sch.stateManager.ProcessRecordingResult(ctx, e.scheduledAt, e.rule, dur, err)
+	}
+
 	evaluate := func(ctx context.Context, f fingerprint, attempt int64, e *evaluation, span trace.Span) {
 		logger := logger.New("version", e.rule.Version, "fingerprint", f, "attempt", attempt, "now", e.scheduledAt).FromContext(ctx)
+		if e.rule.Type() == ngmodels.RuleTypeRecording {
+			evaluateRecording(ctx, logger, e, span)
+			return
+		}
 		start := sch.clock.Now()
 
 		evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+		evalCtx.RuleResults = sch.stateManager
 		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
 		var results eval.Results
 		var dur time.Duration
@@ -386,11 +539,13 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			dur = sch.clock.Now().Sub(start)
 			logger.Error("Failed to build rule evaluator", "error", err)
 		} else {
-			results, err = ruleEval.Evaluate(ctx, e.scheduledAt)
+			statsCtx, stats := sch.profiler.withStats(ctx)
+			results, err = ruleEval.Evaluate(statsCtx, e.scheduledAt)
 			dur = sch.clock.Now().Sub(start)
 			if err != nil {
 				logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
 			}
+			sch.profiler.observe(e.rule, e.scheduledAt, dur, stats, err != nil || results.HasErrors())
 		}
 
 		evalTotal.Inc()
@@ -455,6 +610,9 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	}
 
 	evalRunning := false
+	// stateLoaded is false if the state of the rule has to be loaded from the database before the first evaluation
+	// because the rule could have been evaluated by another instance.
+	stateLoaded := sch.sharder == nil
 	var currentFingerprint fingerprint
 	defer sch.stopApplied(key)
 	for {
@@ -477,6 +635,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				return nil
 			}
 			if evalRunning {
+				ctx.done()
 				continue
 			}
 
@@ -485,8 +644,14 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				defer func() {
 					evalRunning = false
 					sch.evalApplied(key, ctx.scheduledAt)
+					ctx.done()
 				}()
 
+				if !stateLoaded {
+					sch.stateManager.WarmRule(grafanaCtx, ctx.rule)
+					stateLoaded = true
+				}
+
 				err := retryIfError(func(attempt int64) error {
 					isPaused := ctx.rule.IsPaused
 					f := ruleWithFolder{ctx.rule, ctx.folderTitle}.Fingerprint()
@@ -538,12 +703,41 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				states := sch.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key, ngmodels.StateReasonRuleDeleted)
 				notify(states)
 			}
+			// the rule is evaluated by another instance from now on, which loads the state from the database.
+			if errors.Is(grafanaCtx.Err(), errRuleNotOwned) {
+				logger.Info("Alert rule is handed over to another instance")
+				sch.stateManager.ForgetStateByRuleUID(key)
+			}
+			sch.profiler.delete(key)
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
 	}
 }
 
+// evaluateRecordingRule evaluates the queries and expressions of a recording rule and returns the frames
+// of the node whose result is recorded.
+func (sch *schedule) evaluateRecordingRule(ctx context.Context, e *evaluation) (data.Frames, error) {
+	evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+	evalCtx.RuleResults = sch.stateManager
+	ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+	resp, err := ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := resp.Responses[e.rule.Record.From]
+	if !ok {
+		return nil, fmt.Errorf("no result for query or expression %s", e.rule.Record.From)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return result.Frames, nil
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -357,6 +359,70 @@ func TestProcessTicks(t *testing.T) {
 	})
 }
 
+func TestProcessTickSequentialGroup(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+
+	evalAppliedCh := make(chan evalAppliedInfo, 10)
+	sch.evalAppliedFunc = func(key models.AlertRuleKey, now time.Time) {
+		evalAppliedCh <- evalAppliedInfo{alertDefKey: key, now: now}
+	}
+
+	groupKey := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: "meta"}
+	gen := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), withQueryForState(t, eval.Alerting))
+	// each rule fires if the rule before it is firing, which is only the case in the first tick if they are evaluated in order.
+	usePrevious := func(prev *models.AlertRule) models.AlertRuleMutator {
+		return func(rule *models.AlertRule) {
+			rule.Condition = "B"
+			rule.Data = []models.AlertQuery{
+				models.CreateRuleResultQuery("A", prev.UID, models.RuleResultState, ""),
+				{
+					RefID:         "B",
+					DatasourceUID: expr.DatasourceUID,
+					Model:         json.RawMessage(`{"datasourceUid": "__expr__", "type": "math", "expression": "$A == 1"}`),
+				},
+			}
+		}
+	}
+	rule1 := gen()
+	rule2 := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), models.WithFor(0), usePrevious(rule1))()
+	rule3 := models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), models.WithFor(0), usePrevious(rule2))()
+	for idx, rule := range []*models.AlertRule{rule1, rule2, rule3} {
+		rule.RuleGroupIndex = idx + 1
+		rule.SequentialEvaluation = true
+		rule.IsPaused = false
+	}
+	ruleStore.PutRule(ctx, rule3, rule2, rule1)
+	other := models.AlertRuleGen(models.WithInterval(time.Second), withQueryForState(t, eval.Normal))()
+	other.IsPaused = false
+	ruleStore.PutRule(ctx, other)
+
+	tick := time.Unix(100, 0)
+	scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, 4)
+
+	var groupOrder []models.AlertRuleKey
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < len(scheduled); i++ {
+		select {
+		case info := <-evalAppliedCh:
+			require.Equal(t, tick, info.now)
+			if info.alertDefKey != other.GetKey() {
+				groupOrder = append(groupOrder, info.alertDefKey)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the evaluation of the rules")
+		}
+	}
+	require.Equal(t, []models.AlertRuleKey{rule1.GetKey(), rule2.GetKey(), rule3.GetKey()}, groupOrder)
+
+	states := sch.stateManager.GetStatesForRuleUID(rule3.OrgID, rule3.UID)
+	require.Len(t, states, 1)
+	require.Equal(t, eval.Alerting, states[0].State)
+}
+
 func TestSchedule_ruleRoutine(t *testing.T) {
 	createSchedule := func(
 		evalAppliedChan chan time.Time,
@@ -566,6 +632,30 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 
 			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
 		})
+		t.Run("and forget the state without resolving alerts if the rule is handed over", func(t *testing.T) {
+			stoppedChan := make(chan error)
+			sender := AlertsSenderMock{}
+			sch, _, instanceStore, _ := createSchedule(make(chan time.Time), &sender)
+
+			rule := models.AlertRuleGen()()
+			_ = sch.stateManager.ProcessEvalResults(context.Background(), sch.clock.Now(), rule, eval.GenerateResults(rand.Intn(5)+1, eval.ResultGen(eval.WithEvaluatedAt(sch.clock.Now()))), nil)
+			require.NotEmpty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			savedOps := len(instanceStore.RecordedOps)
+
+			ctx, cancel := util.WithCancelCause(context.Background())
+			go func() {
+				err := sch.ruleRoutine(ctx, rule.GetKey(), make(chan *evaluation), make(chan ruleVersionAndPauseStatus))
+				stoppedChan <- err
+			}()
+
+			cancel(errRuleNotOwned)
+			err := waitForErrChannel(t, stoppedChan)
+			require.NoError(t, err)
+
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			require.Len(t, instanceStore.RecordedOps, savedOps)
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+		})
 	})
 
 	t.Run("when a message is sent to update channel", func(t *testing.T) {
@@ -821,6 +911,162 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 	})
 }
 
+func TestSchedule_recordingRule(t *testing.T) {
+	withRecord := func(from string) models.AlertRuleMutator {
+		return func(rule *models.AlertRule) {
+			rule.Data = []models.AlertQuery{
+				{
+					DatasourceUID: expr.DatasourceUID,
+					Model: json.RawMessage(`{
+						"datasourceUid": "__expr__",
+						"type":"math",
+						"expression":"2 + 2"
+					}`),
+					RelativeTimeRange: models.RelativeTimeRange{
+						From: models.Duration(5 * time.Hour),
+						To:   models.Duration(3 * time.Hour),
+					},
+					RefID: "A",
+				},
+			}
+			rule.Condition = ""
+			rule.Record = &models.Record{Metric: "my_metric", From: from}
+		}
+	}
+
+	evaluate := func(t *testing.T, writer RecordingWriter, rule *models.AlertRule, times ...time.Time) (*schedule, prometheus.Gatherer) {
+		t.Helper()
+		ruleStore := newFakeRulesStore()
+		registry := prometheus.NewPedanticRegistry()
+		sender := &AlertsSenderMock{}
+		sch := setupScheduler(t, ruleStore, nil, registry, sender, nil)
+		sch.recordingWriter = writer
+		evalAppliedChan := make(chan time.Time)
+		sch.evalAppliedFunc = func(key models.AlertRuleKey, t time.Time) {
+			evalAppliedChan <- t
+		}
+
+		ruleStore.PutRule(context.Background(), rule)
+		evalChan := make(chan *evaluation)
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		for _, at := range times {
+			evalChan <- &evaluation{
+				scheduledAt: at,
+				rule:        rule,
+			}
+			waitForTimeChannel(t, evalAppliedChan)
+		}
+
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+		require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		return sch, registry
+	}
+
+	t.Run("should write the frames of the recorded query and report it healthy", func(t *testing.T) {
+		rule := models.AlertRuleGen(withRecord("A"))()
+		writer := &fakeRecordingWriter{}
+		at := time.UnixMicro(rand.Int63())
+
+		sch, registry := evaluate(t, writer, rule, at)
+
+		require.Len(t, writer.writes, 1)
+		write := writer.writes[0]
+		require.Equal(t, "my_metric", write.name)
+		require.Equal(t, at, write.t)
+		require.Equal(t, rule.GetLabels(), write.labels)
+		require.Len(t, write.frames, 1)
+		value, err := write.frames[0].FloatAt(0, 0)
+		require.NoError(t, err)
+		require.Equal(t, 4.0, value)
+
+		status, ok := sch.stateManager.GetRecordingRuleStatus(rule.OrgID, rule.UID)
+		require.True(t, ok)
+		require.Equal(t, state.RecordingRuleHealthOK, status.Health)
+		require.NoError(t, status.LastError)
+		require.Equal(t, at, status.LastEvaluationTime)
+		require.Equal(t, at, status.LastWriteTime)
+		require.Zero(t, status.ConsecutiveFailures)
+
+		expectedMetrics := fmt.Sprintf(`
+# HELP grafana_alerting_recording_rule_write_failures_total The total number of recording rule results that failed to be written to the remote write endpoint.
+# TYPE grafana_alerting_recording_rule_write_failures_total counter
+grafana_alerting_recording_rule_write_failures_total{org="%[1]d"} 0
+# HELP grafana_alerting_recording_rule_writes_total The total number of recording rule results written to the remote write endpoint.
+# TYPE grafana_alerting_recording_rule_writes_total counter
+grafana_alerting_recording_rule_writes_total{org="%[1]d"} 1
+`, rule.OrgID)
+		require.NoError(t, testutil.GatherAndCompare(registry, bytes.NewBufferString(expectedMetrics),
+			"grafana_alerting_recording_rule_writes_total", "grafana_alerting_recording_rule_write_failures_total"))
+	})
+
+	t.Run("should report the error of the write", func(t *testing.T) {
+		rule := models.AlertRuleGen(withRecord("A"))()
+		writer := &fakeRecordingWriter{err: errors.New("remote write failed")}
+		first := time.UnixMicro(rand.Int63())
+		second := first.Add(time.Minute)
+
+		sch, _ := evaluate(t, writer, rule, first, second)
+
+		require.Len(t, writer.writes, 2)
+		status, ok := sch.stateManager.GetRecordingRuleStatus(rule.OrgID, rule.UID)
+		require.True(t, ok)
+		require.Equal(t, state.RecordingRuleHealthError, status.Health)
+		require.EqualError(t, status.LastError, "remote write failed")
+		require.Equal(t, second, status.LastEvaluationTime)
+		require.True(t, status.LastWriteTime.IsZero())
+		require.Equal(t, int64(2), status.ConsecutiveFailures)
+	})
+
+	t.Run("should report the error of the evaluation and not write", func(t *testing.T) {
+		rule := models.AlertRuleGen(withRecord("B"))()
+		writer := &fakeRecordingWriter{}
+		at := time.UnixMicro(rand.Int63())
+
+		sch, _ := evaluate(t, writer, rule, at)
+
+		require.Empty(t, writer.writes)
+		status, ok := sch.stateManager.GetRecordingRuleStatus(rule.OrgID, rule.UID)
+		require.True(t, ok)
+		require.Equal(t, state.RecordingRuleHealthError, status.Health)
+		require.Error(t, status.LastError)
+		require.Equal(t, int64(1), status.ConsecutiveFailures)
+	})
+
+	t.Run("should not evaluate when recording rules are disabled", func(t *testing.T) {
+		rule := models.AlertRuleGen(withRecord("A"))()
+
+		sch, _ := evaluate(t, nil, rule, time.UnixMicro(rand.Int63()))
+
+		_, ok := sch.stateManager.GetRecordingRuleStatus(rule.OrgID, rule.UID)
+		require.False(t, ok)
+	})
+}
+
+type recordingWrite struct {
+	name   string
+	t      time.Time
+	frames data.Frames
+	labels map[string]string
+}
+
+type fakeRecordingWriter struct {
+	mtx    sync.Mutex
+	writes []recordingWrite
+	err    error
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.writes = append(w.writes, recordingWrite{name: name, t: t, frames: frames, labels: extraLabels})
+	return w.err
+}
+
 func TestSchedule_deleteAlertRule(t *testing.T) {
 	t.Run("when rule exists", func(t *testing.T) {
 		t.Run("it should stop evaluation loop and remove the controller from registry", func(t *testing.T) {
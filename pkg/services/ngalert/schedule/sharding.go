@@ -0,0 +1,150 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errRuleNotOwned is the reason for stopping the evaluation of a rule that is handed over to another instance.
+var errRuleNotOwned = errors.New("rule is owned by another instance")
+
+// PeerStore keeps track of the instances of the scheduler that share the evaluation of alert rules.
+type PeerStore interface {
+	HeartbeatSchedulerPeer(ctx context.Context, instanceID string, at time.Time) error
+	GetSchedulerPeers(ctx context.Context, activeSince time.Time) ([]string, error)
+	DeleteSchedulerPeer(ctx context.Context, instanceID string) error
+	DeleteStaleSchedulerPeers(ctx context.Context, lastSeenBefore time.Time) (int64, error)
+}
+
+// ShardingCfg is the configuration of the sharded evaluation of alert rules.
+type ShardingCfg struct {
+	// InstanceID identifies this instance of the scheduler. It must be unique across the instances.
+	InstanceID string
+	PeerStore  PeerStore
+	// PeerTimeout is the time after which an instance that stopped sending heartbeats is considered gone.
+	PeerTimeout time.Duration
+}
+
+// ruleSharder assigns every alert rule to exactly one of the instances of the scheduler. The instances discover
+// each other by sending heartbeats to the database on every tick, and the rules are assigned using rendezvous
+// hashing, so that a change of membership moves only the rules of the instances that joined or left.
+type ruleSharder struct {
+	instanceID  string
+	store       PeerStore
+	peerTimeout time.Duration
+	log         log.Logger
+
+	mtx   sync.RWMutex
+	peers []string
+}
+
+func newRuleSharder(cfg ShardingCfg, logger log.Logger) *ruleSharder {
+	return &ruleSharder{
+		instanceID:  cfg.InstanceID,
+		store:       cfg.PeerStore,
+		peerTimeout: cfg.PeerTimeout,
+		log:         logger,
+		peers:       []string{cfg.InstanceID},
+	}
+}
+
+// sync sends the heartbeat of this instance and refreshes the list of peers. If the database cannot be reached, the
+// last known peers are kept. When the membership changes, the peers that stopped sending heartbeats, for example,
+// because they crashed, are deleted from the database.
+func (s *ruleSharder) sync(ctx context.Context, now time.Time) {
+	if err := s.store.HeartbeatSchedulerPeer(ctx, s.instanceID, now); err != nil {
+		s.log.Error("Failed to send the heartbeat of the scheduler", "instance", s.instanceID, "error", err)
+	}
+	activeSince := now.Add(-s.peerTimeout)
+	peers, err := s.store.GetSchedulerPeers(ctx, activeSince)
+	if err != nil {
+		s.log.Error("Failed to fetch the peers of the scheduler, falling back to the last known peers", "error", err)
+		return
+	}
+	if !slices.Contains(peers, s.instanceID) {
+		peers = append(peers, s.instanceID)
+		slices.Sort(peers)
+	}
+
+	s.mtx.Lock()
+	changed := !slices.Equal(s.peers, peers)
+	if changed {
+		s.log.Info("Membership of the scheduler has changed, rebalancing alert rules", "previous", s.peers, "current", peers)
+		s.peers = peers
+	}
+	s.mtx.Unlock()
+	if !changed {
+		return
+	}
+
+	deleted, err := s.store.DeleteStaleSchedulerPeers(ctx, activeSince)
+	if err != nil {
+		s.log.Warn("Failed to delete the peers that stopped sending heartbeats", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Info("Deleted the peers that stopped sending heartbeats", "count", deleted)
+	}
+}
+
+// owns returns true if the rule is assigned to this instance.
+func (s *ruleSharder) owns(key ngmodels.AlertRuleKey) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return ownerOf(s.peers, key) == s.instanceID
+}
+
+// leave removes this instance from the peers, so the other instances take over its rules without waiting for the
+// peer timeout.
+func (s *ruleSharder) leave(ctx context.Context) {
+	if err := s.store.DeleteSchedulerPeer(ctx, s.instanceID); err != nil {
+		s.log.Error("Failed to remove the scheduler from the peers", "instance", s.instanceID, "error", err)
+	}
+}
+
+// shardingKey returns the key that is used to assign the rule to an instance. All rules of a group that is evaluated
+// sequentially are assigned to the same instance because they use the results of each other.
+func shardingKey(rule *ngmodels.AlertRule) ngmodels.AlertRuleKey {
+	if !rule.SequentialEvaluation {
+		return rule.GetKey()
+	}
+	return ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: rule.NamespaceUID + "/" + rule.RuleGroup}
+}
+
+// ownerOf returns the peer that has the highest score for the rule.
+func ownerOf(peers []string, key ngmodels.AlertRuleKey) string {
+	var owner string
+	var maxScore uint64
+	for _, peer := range peers {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(peer))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(strconv.FormatInt(key.OrgID, 10)))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(key.UID))
+		score := mix64(h.Sum64())
+		if owner == "" || score > maxScore || (score == maxScore && peer < owner) {
+			owner, maxScore = peer, score
+		}
+	}
+	return owner
+}
+
+// mix64 is the finalizer of SplitMix64. It spreads the bits of FNV hashes that differ only in a few bytes, so the
+// scores of the peers are independent of each other.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
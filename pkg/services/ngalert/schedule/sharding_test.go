@@ -0,0 +1,206 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestOwnerOf(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+
+	t.Run("should assign rules evenly", func(t *testing.T) {
+		peers := []string{"a", "b", "c"}
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[ownerOf(peers, key)]++
+		}
+		require.Len(t, counts, len(peers))
+		for peer, count := range counts {
+			require.Greaterf(t, count, len(keys)/5, "peer %s owns too few rules", peer)
+		}
+	})
+
+	t.Run("should move only the rules of the joining peer", func(t *testing.T) {
+		before := []string{"a", "b", "c"}
+		after := []string{"a", "b", "c", "d"}
+		moved := 0
+		for _, key := range keys {
+			prev, next := ownerOf(before, key), ownerOf(after, key)
+			if prev != next {
+				require.Equal(t, "d", next)
+				moved++
+			}
+		}
+		require.Greater(t, moved, 0)
+	})
+
+	t.Run("should not depend on the order of peers", func(t *testing.T) {
+		for _, key := range keys[:100] {
+			require.Equal(t, ownerOf([]string{"a", "b", "c"}, key), ownerOf([]string{"c", "a", "b"}, key))
+		}
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	t.Run("should send heartbeat and refresh peers", func(t *testing.T) {
+		store := &fakePeerStore{peers: []string{"a", "b"}}
+		s := newRuleSharder(ShardingCfg{InstanceID: "a", PeerStore: store, PeerTimeout: time.Minute}, log.NewNopLogger())
+
+		s.sync(context.Background(), now)
+		require.Equal(t, []string{"a", "b"}, s.peers)
+		require.Equal(t, []time.Time{now}, store.heartbeats)
+		require.Equal(t, now.Add(-time.Minute), store.activeSince)
+	})
+
+	t.Run("should always include itself", func(t *testing.T) {
+		store := &fakePeerStore{peers: []string{"b", "d"}}
+		s := newRuleSharder(ShardingCfg{InstanceID: "c", PeerStore: store, PeerTimeout: time.Minute}, log.NewNopLogger())
+
+		s.sync(context.Background(), now)
+		require.Equal(t, []string{"b", "c", "d"}, s.peers)
+	})
+
+	t.Run("should keep last known peers if database fails", func(t *testing.T) {
+		store := &fakePeerStore{peers: []string{"a", "b"}}
+		s := newRuleSharder(ShardingCfg{InstanceID: "a", PeerStore: store, PeerTimeout: time.Minute}, log.NewNopLogger())
+		s.sync(context.Background(), now)
+
+		store.err = errors.New("test")
+		s.sync(context.Background(), now)
+		require.Equal(t, []string{"a", "b"}, s.peers)
+	})
+
+	t.Run("should delete stale peers when membership changes", func(t *testing.T) {
+		store := &fakePeerStore{peers: []string{"a", "b"}}
+		s := newRuleSharder(ShardingCfg{InstanceID: "a", PeerStore: store, PeerTimeout: time.Minute}, log.NewNopLogger())
+
+		s.sync(context.Background(), now)
+		require.Equal(t, []time.Time{now.Add(-time.Minute)}, store.deletedBefore)
+
+		// the membership did not change
+		s.sync(context.Background(), now.Add(time.Second))
+		require.Len(t, store.deletedBefore, 1)
+
+		// b crashed and stopped sending heartbeats
+		store.peers = []string{"a"}
+		s.sync(context.Background(), now.Add(2*time.Minute))
+		require.Equal(t, []time.Time{now.Add(-time.Minute), now.Add(time.Minute)}, store.deletedBefore)
+	})
+}
+
+func TestProcessTickSharded(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	peerStore := &fakePeerStore{peers: []string{"a"}}
+	sch.sharder = newRuleSharder(ShardingCfg{InstanceID: "a", PeerStore: peerStore, PeerTimeout: time.Minute}, log.NewNopLogger())
+
+	rules := models.GenerateAlertRules(20, models.AlertRuleGen(models.WithInterval(time.Second), withQueryForState(t, eval.Normal)))
+	ruleStore.PutRule(ctx, rules...)
+
+	tick := time.Unix(100, 0)
+	scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, len(rules), "single instance should evaluate all rules")
+
+	peerStore.peers = []string{"a", "b"}
+	tick = tick.Add(time.Second)
+	scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Empty(t, stopped, "rules owned by another instance should not be deleted")
+
+	owned := map[models.AlertRuleKey]struct{}{}
+	for _, item := range scheduled {
+		require.Equal(t, "a", ownerOf(peerStore.peers, item.rule.GetKey()))
+		owned[item.rule.GetKey()] = struct{}{}
+	}
+	require.NotEmpty(t, owned)
+	require.Less(t, len(owned), len(rules))
+	for _, rule := range rules {
+		_, ok := owned[rule.GetKey()]
+		require.Equal(t, ok, sch.registry.exists(rule.GetKey()))
+		_, ok = sch.schedulableAlertRules.rules[rule.GetKey()]
+		require.True(t, ok, "rules owned by another instance should stay schedulable")
+	}
+
+	peerStore.peers = []string{"a"}
+	tick = tick.Add(time.Second)
+	scheduled, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, len(rules), "rules should be taken over when the other instance leaves")
+}
+
+func TestProcessTickShardedSequentialGroups(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	peerStore := &fakePeerStore{peers: []string{"a", "b", "c"}}
+	sch.sharder = newRuleSharder(ShardingCfg{InstanceID: "a", PeerStore: peerStore, PeerTimeout: time.Minute}, log.NewNopLogger())
+
+	groups := make(map[models.AlertRuleGroupKey][]*models.AlertRule)
+	for i := 0; i < 10; i++ {
+		groupKey := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: fmt.Sprintf("group-%d", i)}
+		rules := models.GenerateAlertRules(5, models.AlertRuleGen(models.WithGroupKey(groupKey), models.WithInterval(time.Second), withQueryForState(t, eval.Normal)))
+		for _, rule := range rules {
+			rule.SequentialEvaluation = true
+			rule.IsPaused = false
+		}
+		ruleStore.PutRule(ctx, rules...)
+		groups[groupKey] = rules
+	}
+
+	scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, time.Unix(100, 0))
+	require.NotEmpty(t, scheduled)
+	perGroup := make(map[models.AlertRuleGroupKey]int)
+	for _, item := range scheduled {
+		perGroup[item.rule.GetGroupKey()]++
+	}
+	for groupKey, count := range perGroup {
+		require.Equalf(t, len(groups[groupKey]), count, "all rules of group %s should be evaluated by the same instance", groupKey)
+	}
+	require.Less(t, len(perGroup), len(groups))
+}
+
+type fakePeerStore struct {
+	peers         []string
+	heartbeats    []time.Time
+	activeSince   time.Time
+	deletedBefore []time.Time
+	err           error
+}
+
+func (f *fakePeerStore) HeartbeatSchedulerPeer(_ context.Context, _ string, at time.Time) error {
+	f.heartbeats = append(f.heartbeats, at)
+	return f.err
+}
+
+func (f *fakePeerStore) GetSchedulerPeers(_ context.Context, activeSince time.Time) ([]string, error) {
+	f.activeSince = activeSince
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]string{}, f.peers...), nil
+}
+
+func (f *fakePeerStore) DeleteSchedulerPeer(_ context.Context, instanceID string) error {
+	return f.err
+}
+
+func (f *fakePeerStore) DeleteStaleSchedulerPeers(_ context.Context, lastSeenBefore time.Time) (int64, error) {
+	f.deletedBefore = append(f.deletedBefore, lastSeenBefore)
+	return 0, f.err
+}
@@ -111,8 +111,9 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 	q := annotations.ItemQuery{
 		AlertID:      rule.ID,
 		OrgID:        query.OrgID,
-		From:         query.From.Unix(),
-		To:           query.To.Unix(),
+		From:         query.From.UnixMilli(),
+		To:           query.To.UnixMilli(),
+		Limit:        int64(query.Limit),
 		SignedInUser: query.SignedInUser,
 	}
 	items, err := h.store.Find(ctx, &q)
@@ -154,7 +155,7 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 			logger.Error("Annotation service gave an annotation with unparseable data, skipping", "id", item.ID, "err", err)
 			continue
 		}
-		times = append(times, time.Unix(item.Time, 0))
+		times = append(times, time.UnixMilli(item.Time))
 		texts = append(texts, item.Text)
 		prevStates = append(prevStates, item.PrevState)
 		nextStates = append(nextStates, item.NewState)
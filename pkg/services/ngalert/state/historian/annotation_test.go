@@ -47,6 +47,32 @@ func TestAnnotationHistorian(t *testing.T) {
 		}
 	})
 
+	t.Run("alert annotations are queried in milliseconds", func(t *testing.T) {
+		at := time.UnixMilli(time.Now().UnixMilli())
+		store := &queryRecordingAnnotationStore{items: []*annotations.ItemDTO{{ID: 1, Time: at.UnixMilli(), Text: "MyAlert {a=b} - No data"}}}
+		rules := fakes.NewRuleStore(t)
+		rules.Rules[1] = []*models.AlertRule{
+			models.AlertRuleGen(withOrgID(1), withUID("my-rule"))(),
+		}
+		anns := NewAnnotationBackend(store, rules, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+
+		q := models.HistoryQuery{
+			RuleUID: "my-rule",
+			OrgID:   1,
+			From:    at.Add(-time.Minute),
+			To:      at.Add(time.Minute),
+			Limit:   10,
+		}
+		frame, err := anns.Query(context.Background(), q)
+		require.NoError(t, err)
+
+		require.Equal(t, q.From.UnixMilli(), store.query.From)
+		require.Equal(t, q.To.UnixMilli(), store.query.To)
+		require.EqualValues(t, q.Limit, store.query.Limit)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, at, frame.Fields[0].At(0))
+	})
+
 	t.Run("writing state transitions as annotations succeeds", func(t *testing.T) {
 		anns := createTestAnnotationBackendSut(t)
 		rule := createTestRule()
@@ -100,6 +126,20 @@ grafana_alerting_state_history_writes_total{backend="annotations",org="1"} 2
 	})
 }
 
+type queryRecordingAnnotationStore struct {
+	query *annotations.ItemQuery
+	items []*annotations.ItemDTO
+}
+
+func (s *queryRecordingAnnotationStore) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	s.query = query
+	return s.items, nil
+}
+
+func (s *queryRecordingAnnotationStore) Save(_ context.Context, _ *PanelKey, _ []annotations.Item, _ int64, _ log.Logger) error {
+	return nil
+}
+
 func createTestAnnotationBackendSut(t *testing.T) *AnnotationBackend {
 	return createTestAnnotationBackendSutWithMetrics(t, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
 }
@@ -15,6 +15,7 @@ func (bt BackendType) String() string {
 
 const (
 	BackendTypeAnnotations BackendType = "annotations"
+	BackendTypeDatabase    BackendType = "database"
 	BackendTypeLoki        BackendType = "loki"
 	BackendTypeMultiple    BackendType = "multiple"
 	BackendTypeNoop        BackendType = "noop"
@@ -25,6 +26,7 @@ func ParseBackendType(s string) (BackendType, error) {
 
 	types := map[BackendType]struct{}{
 		BackendTypeAnnotations: {},
+		BackendTypeDatabase:    {},
 		BackendTypeLoki:        {},
 		BackendTypeMultiple:    {},
 		BackendTypeNoop:        {},
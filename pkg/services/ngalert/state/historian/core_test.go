@@ -47,6 +47,7 @@ func TestShouldRecord(t *testing.T) {
 		models.StateReasonPaused,
 		models.StateReasonUpdated,
 		models.StateReasonRuleDeleted,
+		models.StateReasonKeepFiring,
 		eval.Error.String(),
 		eval.NoData.String(),
 	}
@@ -75,6 +76,7 @@ func TestShouldRecord(t *testing.T) {
 		transition(eval.Normal, models.StateReasonRuleDeleted, eval.Normal, models.StateReasonMissingSeries): {},
 		transition(eval.Normal, models.StateReasonPaused, eval.Normal, models.StateReasonMissingSeries):      {},
 		transition(eval.Normal, models.StateReasonUpdated, eval.Normal, models.StateReasonMissingSeries):     {},
+		transition(eval.Normal, models.StateReasonKeepFiring, eval.Normal, models.StateReasonMissingSeries):  {},
 	}
 	// add all transitions from reason X(Y) to X(Y) as negative.
 	for _, s := range allStates {
@@ -0,0 +1,233 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// DatabaseBackend is an implementation of state.Historian that records state history to the Grafana database.
+// Unlike the annotation backend it stores the full state transitions, and it supports the same queries as the Loki backend.
+type DatabaseBackend struct {
+	store   store.StateHistoryStore
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewDatabaseBackend(store store.StateHistoryStore, metrics *metrics.Historian) *DatabaseBackend {
+	return &DatabaseBackend{
+		store:   store,
+		clock:   clock.New(),
+		metrics: metrics,
+		log:     log.New("ngalert.state.historian", "backend", "database"),
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *DatabaseBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	// Build the entries before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	entries := statesToEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "database").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.store.InsertStateHistory(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "database").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the database. The result has the same format as the result of
+// RemoteLokiBackend.Query.
+func (h *DatabaseBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+
+	entries, err := h.store.QueryStateHistory(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	// We represent state history in the same format as the Loki backend:
+	//   1. `time` - timestamp - when the transition happened
+	//   2. `line` - JSON - the full data of the transition
+	//   3. `labels` - JSON - the labels of the rule the transition belongs to
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		line, err := entryToLine(e)
+		if err != nil {
+			h.log.Error("State history entry has an invalid format, skipping", "id", e.ID, "error", err)
+			continue
+		}
+		streamLbls, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize labels: %w", err)
+		}
+
+		times = append(times, time.UnixMilli(e.TransitionTime))
+		lines = append(lines, line)
+		labels = append(labels, streamLbls)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+
+	return frame, nil
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, s := range states {
+		if !shouldRecord(s) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(s.Labels)
+		lbls, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		var values []byte
+		if v := valuesAsDataBlob(s.State); v != nil {
+			values, err = v.MarshalJSON()
+			if err != nil {
+				logger.Error("Failed to serialize values of state, skipping", "error", err)
+				continue
+			}
+		}
+		entry := models.StateHistoryEntry{
+			OrgID:          rule.OrgID,
+			RuleUID:        rule.UID,
+			NamespaceUID:   rule.NamespaceUID,
+			RuleGroup:      rule.Group,
+			DashboardUID:   rule.DashboardUID,
+			PanelID:        rule.PanelID,
+			Labels:         string(lbls),
+			LabelsHash:     labelFingerprint(sanitizedLabels),
+			PreviousState:  s.PreviousState.String(),
+			PreviousReason: s.PreviousStateReason,
+			CurrentState:   s.State.State.String(),
+			CurrentReason:  s.State.StateReason,
+			Values:         string(values),
+			Condition:      rule.Condition,
+			TransitionTime: s.State.LastEvaluationTime.UnixMilli(),
+		}
+		if s.State.State == eval.Error && s.Error != nil {
+			entry.Error = s.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entryToLine converts a state history entry into a log line of the Loki backend.
+func entryToLine(e models.StateHistoryEntry) (json.RawMessage, error) {
+	var lbls map[string]string
+	if err := json.Unmarshal([]byte(e.Labels), &lbls); err != nil {
+		return nil, err
+	}
+	line := lokiEntry{
+		SchemaVersion:  1,
+		Previous:       formatStateAndReason(e.PreviousState, e.PreviousReason),
+		Current:        formatStateAndReason(e.CurrentState, e.CurrentReason),
+		Error:          e.Error,
+		Condition:      e.Condition,
+		DashboardUID:   e.DashboardUID,
+		PanelID:        e.PanelID,
+		Fingerprint:    e.LabelsHash,
+		RuleUID:        e.RuleUID,
+		InstanceLabels: lbls,
+	}
+	if e.Values != "" {
+		values, err := simplejson.NewJson([]byte(e.Values))
+		if err != nil {
+			return nil, err
+		}
+		line.Values = values
+	}
+	return json.Marshal(line)
+}
+
+// formatStateAndReason formats the state in the same way as state.FormatStateAndReason.
+func formatStateAndReason(s, reason string) string {
+	if reason == "" {
+		return s
+	}
+	return fmt.Sprintf("%s (%s)", s, reason)
+}
+
+// DeleteExpiredService is a service to delete state history that is older than the retention of the database backend.
+type DeleteExpiredService struct {
+	store interface {
+		DeleteExpiredStateHistory(context.Context) (int64, error)
+	}
+}
+
+func (s *DeleteExpiredService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.store.DeleteExpiredStateHistory(ctx)
+}
+
+func ProvideDeleteExpiredService(store *store.DBstore) *DeleteExpiredService {
+	return &DeleteExpiredService{store: store}
+}
@@ -0,0 +1,172 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestDatabaseBackend(t *testing.T) {
+	t.Run("Record", func(t *testing.T) {
+		t.Run("writes state transitions to the store", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			backend := createTestDatabaseBackend(store)
+			rule := createTestRule()
+			now := time.Now()
+			states := []state.StateTransition{
+				{
+					PreviousState: eval.Normal,
+					State: &state.State{
+						State:              eval.Alerting,
+						Labels:             data.Labels{"a": "b", "__private__": "c"},
+						LastEvaluationTime: now,
+					},
+				},
+				{
+					PreviousState: eval.Normal,
+					State:         &state.State{State: eval.Normal, Labels: data.Labels{"a": "c"}},
+				},
+				{
+					PreviousState:       eval.Alerting,
+					PreviousStateReason: models.StateReasonMissingSeries,
+					State:               &state.State{State: eval.Error, Error: errors.New("oh no"), Labels: data.Labels{"a": "d"}},
+				},
+			}
+
+			err := <-backend.Record(context.Background(), rule, states)
+
+			require.NoError(t, err)
+			require.Len(t, store.inserted, 2)
+			entry := store.inserted[0]
+			require.Equal(t, rule.OrgID, entry.OrgID)
+			require.Equal(t, rule.UID, entry.RuleUID)
+			require.Equal(t, rule.NamespaceUID, entry.NamespaceUID)
+			require.Equal(t, rule.Group, entry.RuleGroup)
+			require.Equal(t, `{"a":"b"}`, entry.Labels)
+			require.Equal(t, "Normal", entry.PreviousState)
+			require.Equal(t, "Alerting", entry.CurrentState)
+			require.Equal(t, now.UnixMilli(), entry.TransitionTime)
+			require.Equal(t, "oh no", store.inserted[1].Error)
+			require.Equal(t, models.StateReasonMissingSeries, store.inserted[1].PreviousReason)
+		})
+
+		t.Run("elides write if nothing to record", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			backend := createTestDatabaseBackend(store)
+
+			err := <-backend.Record(context.Background(), createTestRule(), []state.StateTransition{})
+
+			require.NoError(t, err)
+			require.Nil(t, store.inserted)
+		})
+
+		t.Run("returns error if the store fails", func(t *testing.T) {
+			store := &fakeStateHistoryStore{err: errors.New("failure")}
+			backend := createTestDatabaseBackend(store)
+			states := singleFromNormal(&state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}})
+
+			err := <-backend.Record(context.Background(), createTestRule(), states)
+
+			require.ErrorContains(t, err, "failure")
+		})
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		t.Run("defaults the time range", func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			backend := createTestDatabaseBackend(store)
+			clk := clock.NewMock()
+			backend.clock = clk
+
+			_, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1})
+
+			require.NoError(t, err)
+			require.Equal(t, clk.Now().UTC(), store.lastQuery.To)
+			require.Equal(t, clk.Now().UTC().Add(-defaultQueryRange), store.lastQuery.From)
+		})
+
+		t.Run("returns entries in the format of the loki backend", func(t *testing.T) {
+			at := time.UnixMilli(1700000000000)
+			store := &fakeStateHistoryStore{
+				entries: []models.StateHistoryEntry{
+					{
+						OrgID:          1,
+						RuleUID:        "rule-uid",
+						NamespaceUID:   "my-folder",
+						RuleGroup:      "my-group",
+						Labels:         `{"a":"b"}`,
+						LabelsHash:     "hash",
+						PreviousState:  "Normal",
+						CurrentState:   "Alerting",
+						CurrentReason:  "Paused",
+						Values:         `{"A":1}`,
+						TransitionTime: at.UnixMilli(),
+					},
+					{ID: 2, Labels: "invalid", TransitionTime: at.UnixMilli()},
+				},
+			}
+			backend := createTestDatabaseBackend(store)
+
+			frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1})
+
+			require.NoError(t, err)
+			require.Equal(t, 1, frame.Rows())
+			require.Equal(t, dfTime, frame.Fields[0].Name)
+			require.Equal(t, at, frame.Fields[0].At(0))
+
+			var entry lokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+			require.Equal(t, "Normal", entry.Previous)
+			require.Equal(t, "Alerting (Paused)", entry.Current)
+			require.Equal(t, "rule-uid", entry.RuleUID)
+			require.Equal(t, "hash", entry.Fingerprint)
+			require.Equal(t, map[string]string{"a": "b"}, entry.InstanceLabels)
+			require.Equal(t, float64(1), entry.Values.Get("A").MustFloat64())
+
+			var lbls map[string]string
+			require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+			require.Equal(t, map[string]string{
+				StateHistoryLabelKey: StateHistoryLabelValue,
+				OrgIDLabel:           "1",
+				GroupLabel:           "my-group",
+				FolderUIDLabel:       "my-folder",
+			}, lbls)
+		})
+	})
+}
+
+func createTestDatabaseBackend(store *fakeStateHistoryStore) *DatabaseBackend {
+	return NewDatabaseBackend(store, metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem))
+}
+
+type fakeStateHistoryStore struct {
+	inserted  []models.StateHistoryEntry
+	entries   []models.StateHistoryEntry
+	lastQuery models.HistoryQuery
+	err       error
+}
+
+func (f *fakeStateHistoryStore) InsertStateHistory(_ context.Context, entries []models.StateHistoryEntry) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.inserted = append(f.inserted, entries...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) QueryStateHistory(_ context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error) {
+	f.lastQuery = query
+	return f.entries, f.err
+}
@@ -27,6 +27,7 @@ var (
 type AlertInstanceManager interface {
 	GetAll(orgID int64) []*State
 	GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State
+	GetRecordingRuleStatus(orgID int64, ruleUID string) (RecordingRuleStatus, bool)
 }
 
 type Manager struct {
@@ -36,12 +37,15 @@ type Manager struct {
 
 	clock       clock.Clock
 	cache       *cache
+	recording   *recordingRuleStatuses
 	ResendDelay time.Duration
 
 	instanceStore InstanceStore
-	images        ImageCapturer
-	historian     Historian
-	externalURL   *url.URL
+	// ruleInstanceStore is set if the instance store saves all instances of a rule at once.
+	ruleInstanceStore RuleInstanceStore
+	images            ImageCapturer
+	historian         Historian
+	externalURL       *url.URL
 
 	doNotSaveNormalState           bool
 	maxStateSaveConcurrency        int
@@ -77,6 +81,7 @@ func NewManager(cfg ManagerCfg) *Manager {
 
 	m := &Manager{
 		cache:                          c,
+		recording:                      newRecordingRuleStatuses(),
 		ResendDelay:                    ResendDelay, // TODO: make this configurable
 		log:                            cfg.Log,
 		metrics:                        cfg.Metrics,
@@ -91,6 +96,10 @@ func NewManager(cfg ManagerCfg) *Manager {
 		tracer:                         cfg.Tracer,
 	}
 
+	if ruleStore, ok := cfg.InstanceStore.(RuleInstanceStore); ok {
+		m.ruleInstanceStore = ruleStore
+	}
+
 	if m.applyNoDataAndErrorToAllStates {
 		m.log.Info("Running in alternative execution of Error/NoData mode")
 	}
@@ -153,24 +162,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("Error getting cacheId for entry", "error", err)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry.Annotations)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -178,6 +171,51 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule replaces the cached states of the rule with the states saved in the instance store. It is used when the
+// rule is handed over from another instance of Grafana that evaluated the rule before.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.FromContext(ctx)
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch previous state of the rule", "error", err)
+		return
+	}
+	rs := &ruleStates{states: make(map[string]*State, len(alertInstances))}
+	for _, entry := range alertInstances {
+		s := st.stateFromInstance(entry, rule.Annotations)
+		rs.states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.OrgID, rule.UID, rs)
+	logger.Debug("State of the rule has been loaded", "states", len(rs.states))
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, annotations map[string]string) *State {
+	cacheID, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("Error getting cacheId for entry", "error", err)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		KeepFiringSince:      entry.KeepFiringSince,
+		Annotations:          annotations,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -190,6 +228,7 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 	logger.Debug("Resetting state of the rule")
 
 	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.deleteRecordingRuleStatus(ruleKey)
 
 	if len(states) == 0 {
 		return nil
@@ -228,6 +267,13 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 	return transitions
 }
 
+// ForgetStateByRuleUID removes the rule instances from cache but keeps them in the instanceStore. It is used when
+// the rule is handed over to another instance of Grafana, which continues the evaluation from the saved state.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey) {
+	st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.deleteRecordingRuleStatus(ruleKey)
+}
+
 // ResetStateByRuleUID removes the rule instances from cache and instanceStore and saves state history. If the state
 // history has to be saved, rule must not be nil.
 func (st *Manager) ResetStateByRuleUID(ctx context.Context, rule *ngModels.AlertRule, reason string) []StateTransition {
@@ -269,7 +315,13 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 	))
 
 	staleStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
-	st.deleteAlertStates(tracingCtx, logger, staleStates)
+	if st.ruleInstanceStore != nil {
+		// The stale states are already deleted from the cache, therefore, saving the states of the rule deletes them
+		// from the database too.
+		st.saveRuleStates(tracingCtx, logger, alertRule.GetKey())
+	} else {
+		st.deleteAlertStates(tracingCtx, logger, staleStates)
+	}
 
 	if len(staleStates) > 0 {
 		span.AddEvent("deleted stale states", trace.WithAttributes(
@@ -277,7 +329,9 @@ func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time
 		))
 	}
 
-	st.saveAlertStates(tracingCtx, logger, states...)
+	if st.ruleInstanceStore == nil {
+		st.saveAlertStates(tracingCtx, logger, states...)
+	}
 	span.AddEvent("updated database")
 
 	allChanges := append(states, staleStates...)
@@ -387,6 +441,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = result.State.String()
 	}
 
+	// The alert is still firing although the condition is no longer met because of the KeepFiringFor duration of the rule.
+	if currentState.State == eval.Alerting && result.State == eval.Normal {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
@@ -426,6 +485,33 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
 }
 
+// GetRuleResults returns the latest result of each alert instance of the rule. It is used by the rule result queries
+// of rules that are evaluated after the rule in the same group. The labels that Grafana adds to alert instances, such
+// as the name and folder of the rule, are removed so that the results can be combined with the results of other rules.
+func (st *Manager) GetRuleResults(orgID int64, alertRuleUID string) []eval.RuleResult {
+	states := st.cache.getStatesForRuleUID(orgID, alertRuleUID, false)
+	result := make([]eval.RuleResult, 0, len(states))
+	for _, s := range states {
+		labels := make(data.Labels, len(s.Labels))
+		for k, v := range s.Labels {
+			if isRuleExtraLabel(k) {
+				continue
+			}
+			labels[k] = v
+		}
+		values := make(map[string]float64, len(s.Values))
+		for k, v := range s.Values {
+			values[k] = v
+		}
+		result = append(result, eval.RuleResult{
+			Labels: labels,
+			State:  s.State,
+			Values: values,
+		})
+	}
+	return result
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
@@ -456,6 +542,7 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 			CurrentState:      ngModels.InstanceStateType(s.State.State.String()),
 			CurrentReason:     s.StateReason,
 			LastEvalTime:      s.LastEvaluationTime,
+			KeepFiringSince:   s.KeepFiringSince,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
 		}
@@ -474,6 +561,36 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 	logger.Debug("Saving alert states done", "count", len(states), "max_state_save_concurrency", st.maxStateSaveConcurrency, "duration", time.Since(start))
 }
 
+// saveRuleStates saves all current states of the rule with a single write to the rule instance store.
+func (st *Manager) saveRuleStates(ctx context.Context, logger log.Logger, key ngModels.AlertRuleKey) {
+	states := st.cache.getStatesForRuleUID(key.OrgID, key.UID, st.doNotSaveNormalState)
+	instances := make([]ngModels.AlertInstance, 0, len(states))
+	for _, s := range states {
+		instanceKey, err := s.GetAlertInstanceKey()
+		if err != nil {
+			logger.Error("Failed to create a key for alert state to save it to database. The state will be ignored ", "cacheID", s.CacheID, "error", err, "labels", s.Labels.String())
+			continue
+		}
+		instances = append(instances, ngModels.AlertInstance{
+			AlertInstanceKey:  instanceKey,
+			Labels:            ngModels.InstanceLabels(s.Labels),
+			CurrentState:      ngModels.InstanceStateType(s.State.String()),
+			CurrentReason:     s.StateReason,
+			LastEvalTime:      s.LastEvaluationTime,
+			KeepFiringSince:   s.KeepFiringSince,
+			CurrentStateSince: s.StartsAt,
+			CurrentStateEnd:   s.EndsAt,
+		})
+	}
+
+	start := time.Now()
+	if err := st.ruleInstanceStore.SaveAlertInstancesForRule(ctx, key, instances); err != nil {
+		logger.Error("Failed to save alert states", "count", len(instances), "error", err)
+		return
+	}
+	logger.Debug("Saving alert states done", "count", len(instances), "duration", time.Since(start))
+}
+
 func (st *Manager) deleteAlertStates(ctx context.Context, logger log.Logger, states []StateTransition) {
 	if st.instanceStore == nil || len(states) == 0 {
 		return
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/mock"
 
@@ -17,6 +18,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 )
 
 func BenchmarkProcessEvalResults(b *testing.B) {
@@ -47,6 +50,138 @@ func BenchmarkProcessEvalResults(b *testing.B) {
 	_ = fmt.Sprintf("%v", len(ans))
 }
 
+// BenchmarkWarm measures how long it takes to load the state of all rules on startup with the store that saves a row
+// per alert instance and with the store that saves a compact row per rule.
+func BenchmarkWarm(b *testing.B) {
+	const instancesPerRule = 100
+	for _, count := range []int{1_000, 10_000, 100_000} {
+		_, dbstore := tests.SetupTestEnv(b, 1)
+		compactStore := store.CompactInstanceStore{
+			SQLStore:       dbstore.SQLStore,
+			FeatureToggles: dbstore.FeatureToggles,
+			Logger:         log.New("ngalert.state.manager"),
+		}
+		rules := makeBenchRules(count / instancesPerRule)
+		for _, rule := range rules {
+			if err := compactStore.SaveAlertInstancesForRule(context.Background(), rule.GetKey(), makeBenchInstances(rule, instancesPerRule)); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.Run(fmt.Sprintf("compact/%d", count), func(b *testing.B) {
+			benchmarkWarm(b, compactStore, rules, count)
+		})
+
+		if _, err := compactStore.ExportAlertInstances(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("row/%d", count), func(b *testing.B) {
+			benchmarkWarm(b, dbstore, rules, count)
+		})
+	}
+}
+
+func benchmarkWarm(b *testing.B, instanceStore state.InstanceStore, rules models.RulesGroup, expected int) {
+	reader := &benchRuleReader{rules: rules}
+	for i := 0; i < b.N; i++ {
+		sut := state.NewManager(state.ManagerCfg{
+			InstanceStore: instanceStore,
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		})
+		sut.Warm(context.Background(), reader)
+
+		b.StopTimer()
+		actual := 0
+		for _, rule := range rules {
+			actual += len(sut.GetStatesForRuleUID(rule.OrgID, rule.UID))
+		}
+		if actual != expected {
+			b.Fatalf("expected %d states, got %d", expected, actual)
+		}
+		b.StartTimer()
+	}
+}
+
+// BenchmarkProcessEvalResultsWithStore measures the evaluation of a rule with many instances, including saving the
+// state with the store that saves a row per alert instance and with the store that saves a compact row per rule.
+func BenchmarkProcessEvalResultsWithStore(b *testing.B) {
+	_, dbstore := tests.SetupTestEnv(b, 1)
+	compactStore := store.CompactInstanceStore{
+		SQLStore:       dbstore.SQLStore,
+		FeatureToggles: dbstore.FeatureToggles,
+		Logger:         log.New("ngalert.state.manager"),
+	}
+	for _, tc := range []struct {
+		name          string
+		instanceStore state.InstanceStore
+	}{
+		{name: "compact", instanceStore: compactStore},
+		{name: "row", instanceStore: dbstore},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			sut := state.NewManager(state.ManagerCfg{
+				InstanceStore:           tc.instanceStore,
+				Clock:                   clock.New(),
+				MaxStateSaveConcurrency: 1,
+				Tracer:                  tracing.InitializeTracerForTest(),
+				Log:                     log.New("ngalert.state.manager"),
+			})
+			rule := makeBenchRule()
+			results := makeBenchResultsWithLabels(1_000)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sut.ProcessEvalResults(context.Background(), time.Now().UTC(), &rule, results, nil)
+			}
+		})
+	}
+}
+
+type benchRuleReader struct {
+	rules models.RulesGroup
+}
+
+func (r *benchRuleReader) ListAlertRules(_ context.Context, _ *models.ListAlertRulesQuery) (models.RulesGroup, error) {
+	return r.rules, nil
+}
+
+func makeBenchRules(count int) models.RulesGroup {
+	rules := make(models.RulesGroup, 0, count)
+	for i := 0; i < count; i++ {
+		rule := makeBenchRule()
+		rule.ID = int64(i + 1)
+		rule.UID = fmt.Sprintf("rule-%d", i)
+		rules = append(rules, &rule)
+	}
+	return rules
+}
+
+func makeBenchInstances(rule *models.AlertRule, count int) []models.AlertInstance {
+	now := time.Now().UTC()
+	instances := make([]models.AlertInstance, 0, count)
+	for i := 0; i < count; i++ {
+		labels := models.InstanceLabels{}
+		for k, v := range rule.Labels {
+			labels[k] = v
+		}
+		labels["instance"] = fmt.Sprintf("instance-%d", i)
+		_, hash, _ := labels.StringAndHash()
+		instances = append(instances, models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  rule.OrgID,
+				RuleUID:    rule.UID,
+				LabelsHash: hash,
+			},
+			Labels:            labels,
+			CurrentState:      models.InstanceStateFiring,
+			CurrentStateSince: now.Add(-time.Hour),
+			CurrentStateEnd:   now.Add(time.Hour),
+			LastEvalTime:      now,
+		})
+	}
+	return instances
+}
+
 func makeBenchRule() models.AlertRule {
 	dashUID := "my-dash"
 	panelID := int64(14)
@@ -114,3 +249,15 @@ func makeBenchResults(count int) eval.Results {
 	}
 	return results
 }
+
+// makeBenchResultsWithLabels returns results with distinct labels, so every result is a different alert instance.
+func makeBenchResultsWithLabels(count int) eval.Results {
+	results := makeBenchResults(count)
+	for i := range results {
+		results[i].Instance = map[string]string{
+			"cluster":  "prod-eu-west-123",
+			"instance": fmt.Sprintf("instance-%d", i),
+		}
+	}
+	return results
+}
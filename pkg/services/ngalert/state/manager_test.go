@@ -69,6 +69,7 @@ func TestWarmStateCache(t *testing.T) {
 			StartsAt:           evaluationTime.Add(-1 * time.Minute),
 			EndsAt:             evaluationTime.Add(1 * time.Minute),
 			LastEvaluationTime: evaluationTime,
+			KeepFiringSince:    evaluationTime.Add(-30 * time.Second),
 			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 		},
 		{
@@ -141,6 +142,7 @@ func TestWarmStateCache(t *testing.T) {
 		LastEvalTime:      evaluationTime,
 		CurrentStateSince: evaluationTime.Add(-1 * time.Minute),
 		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
+		KeepFiringSince:   evaluationTime.Add(-30 * time.Second),
 		Labels:            labels,
 	})
 
@@ -217,6 +219,30 @@ func TestWarmStateCache(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("WarmRule loads the states of a single rule", func(t *testing.T) {
+		cfg.Metrics = metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics()
+		st := state.NewManager(cfg)
+		st.WarmRule(ctx, rule)
+
+		require.Len(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID), len(expectedEntries))
+		for _, entry := range expectedEntries {
+			setCacheID(entry)
+			cacheEntry := st.Get(entry.OrgID, entry.AlertRuleUID, entry.CacheID)
+
+			if diff := cmp.Diff(entry, cacheEntry, cmpopts.IgnoreFields(state.State{}, "Results")); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+				t.FailNow()
+			}
+		}
+
+		st.ForgetStateByRuleUID(rule.GetKey())
+		require.Empty(t, st.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+		instances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, instances, len(expectedEntries))
+	})
 }
 
 func TestDashboardAnnotations(t *testing.T) {
@@ -1199,6 +1225,103 @@ func TestProcessEvalResults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:      "alerting -> alerting (KeepFiring) when KeepFiringFor is set",
+			alertRule: baseRuleWith(models.WithKeepFiringForNTimes(2)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 2,
+			expectedStates: []*state.State{
+				{
+					Labels:      labels["system + rule + labels1"],
+					State:       eval.Alerting,
+					StateReason: models.StateReasonKeepFiring,
+					Results: []state.Evaluation{
+						newEvaluation(t1, eval.Alerting),
+						newEvaluation(t2, eval.Normal),
+						newEvaluation(t3, eval.Normal),
+					},
+					KeepFiringSince:    t2,
+					StartsAt:           t1,
+					EndsAt:             t3.Add(state.ResendDelay * 4),
+					LastEvaluationTime: t3,
+				},
+			},
+		},
+		{
+			desc:      "alerting -> alerting (KeepFiring) -> normal when KeepFiringFor has passed",
+			alertRule: baseRuleWith(models.WithKeepFiringForNTimes(2)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				tn(4): {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 3,
+			expectedStates: []*state.State{
+				{
+					Labels: labels["system + rule + labels1"],
+					State:  eval.Normal,
+					Results: []state.Evaluation{
+						newEvaluation(t1, eval.Alerting),
+						newEvaluation(t2, eval.Normal),
+						newEvaluation(t3, eval.Normal),
+						newEvaluation(tn(4), eval.Normal),
+					},
+					Resolved:           true,
+					StartsAt:           tn(4),
+					EndsAt:             tn(4),
+					LastEvaluationTime: tn(4),
+				},
+			},
+		},
+		{
+			desc:      "alerting -> alerting (KeepFiring) -> alerting when the condition is met again",
+			alertRule: baseRuleWith(models.WithKeepFiringForNTimes(2)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 3,
+			expectedStates: []*state.State{
+				{
+					Labels: labels["system + rule + labels1"],
+					State:  eval.Alerting,
+					Results: []state.Evaluation{
+						newEvaluation(t1, eval.Alerting),
+						newEvaluation(t2, eval.Normal),
+						newEvaluation(t3, eval.Alerting),
+					},
+					StartsAt:           t1,
+					EndsAt:             t3.Add(state.ResendDelay * 4),
+					LastEvaluationTime: t3,
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1633,6 +1756,97 @@ func TestStaleResults(t *testing.T) {
 	})
 }
 
+func TestProcessEvalResultsSavesStatesOfRule(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	store := &state.FakeRuleInstanceStore{}
+
+	cfg := state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		ExternalURL:             nil,
+		InstanceStore:           store,
+		Images:                  &state.NoopImageService{},
+		Clock:                   clk,
+		Historian:               &state.FakeHistorian{},
+		MaxStateSaveConcurrency: 1,
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg)
+
+	rule := models.AlertRuleGen(models.WithFor(0))()
+
+	results := eval.Results{
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()))(),
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()))(),
+	}
+
+	lastOp := func(t *testing.T) state.FakeRuleInstanceStoreOp {
+		t.Helper()
+		require.NotEmpty(t, store.RecordedOps)
+		for _, op := range store.RecordedOps {
+			_, ok := op.(state.FakeRuleInstanceStoreOp)
+			require.Truef(t, ok, "Unexpected store operation %v", op)
+		}
+		return store.RecordedOps[len(store.RecordedOps)-1].(state.FakeRuleInstanceStoreOp)
+	}
+
+	t.Run("should save all states of the rule at once", func(t *testing.T) {
+		st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil)
+		require.Len(t, store.RecordedOps, 1)
+
+		op := lastOp(t)
+		require.Equal(t, rule.GetKey(), op.Key)
+		require.Len(t, op.Instances, 2)
+		for _, instance := range op.Instances {
+			require.Equal(t, models.InstanceStateFiring, instance.CurrentState)
+		}
+	})
+
+	t.Run("should not save stale states", func(t *testing.T) {
+		clk.Add(2 * time.Duration(rule.IntervalSeconds) * time.Second)
+		result := results[0]
+		result.EvaluatedAt = clk.Now()
+		st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil)
+		require.Len(t, store.RecordedOps, 2)
+
+		op := lastOp(t)
+		require.Len(t, op.Instances, 1)
+		expected, err := st.GetStatesForRuleUID(rule.OrgID, rule.UID)[0].GetAlertInstanceKey()
+		require.NoError(t, err)
+		require.Equal(t, expected, op.Instances[0].AlertInstanceKey)
+	})
+}
+
+func TestGetRuleResults(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	st := state.NewManager(state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           &state.FakeInstanceStore{},
+		Images:                  &state.NoopImageService{},
+		Clock:                   clk,
+		Historian:               &state.FakeHistorian{},
+		MaxStateSaveConcurrency: 1,
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+	})
+
+	rule := models.AlertRuleGen(models.WithFor(0), models.WithLabels(data.Labels{"team": "a"}), models.WithNotificationSettings(models.NotificationSettings{Receiver: "test"}))()
+	require.Empty(t, st.GetRuleResults(rule.OrgID, rule.UID))
+
+	firing := eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()), eval.WithLabels(data.Labels{"service": "api"}))()
+	firing.Values = map[string]eval.NumberValueCapture{"B": {Var: "B", Value: util.Pointer(3.0)}}
+	normal := eval.ResultGen(eval.WithState(eval.Normal), eval.WithEvaluatedAt(clk.Now()), eval.WithLabels(data.Labels{"service": "web"}))()
+	st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{firing, normal}, data.Labels{models.FolderTitleLabel: "folder"})
+
+	require.ElementsMatch(t, []eval.RuleResult{
+		{Labels: data.Labels{"service": "api", "team": "a"}, State: eval.Alerting, Values: map[string]float64{"B": 3}},
+		{Labels: data.Labels{"service": "web", "team": "a"}, State: eval.Normal, Values: map[string]float64{}},
+	}, st.GetRuleResults(rule.OrgID, rule.UID))
+}
+
 func TestDeleteStateByRuleUID(t *testing.T) {
 	interval := time.Minute
 	ctx := context.Background()
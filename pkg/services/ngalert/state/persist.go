@@ -16,6 +16,15 @@ type InstanceStore interface {
 	DeleteAlertInstancesByRule(ctx context.Context, key models.AlertRuleKey) error
 }
 
+// RuleInstanceStore is an InstanceStore that saves all instances of a rule at once. If the instance store of the
+// Manager implements it, the Manager saves the current states of a rule after every evaluation instead of saving the
+// changed states and deleting the stale ones.
+type RuleInstanceStore interface {
+	InstanceStore
+	// SaveAlertInstancesForRule replaces all saved instances of the rule with the given ones.
+	SaveAlertInstancesForRule(ctx context.Context, key models.AlertRuleKey, instances []models.AlertInstance) error
+}
+
 // RuleReader represents the ability to fetch alert rules.
 type RuleReader interface {
 	ListAlertRules(ctx context.Context, query *models.ListAlertRulesQuery) (models.RulesGroup, error)
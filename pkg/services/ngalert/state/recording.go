@@ -0,0 +1,84 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	RecordingRuleHealthOK    = "ok"
+	RecordingRuleHealthError = "error"
+)
+
+// RecordingRuleStatus describes the outcome of the last evaluation of a recording rule.
+// Recording rules do not produce alert instances, so their health is tracked separately.
+type RecordingRuleStatus struct {
+	Health             string
+	LastError          error
+	LastEvaluationTime time.Time
+	EvaluationDuration time.Duration
+	// LastWriteTime is the time of the last evaluation whose result was written successfully.
+	LastWriteTime time.Time
+	// ConsecutiveFailures is the number of evaluations that failed since the last successful write.
+	ConsecutiveFailures int64
+}
+
+type recordingRuleStatuses struct {
+	mtx      sync.RWMutex
+	statuses map[ngModels.AlertRuleKey]RecordingRuleStatus
+}
+
+func newRecordingRuleStatuses() *recordingRuleStatuses {
+	return &recordingRuleStatuses{statuses: make(map[ngModels.AlertRuleKey]RecordingRuleStatus)}
+}
+
+// ProcessRecordingResult updates the status of a recording rule with the outcome of an evaluation.
+// err is the error of either the evaluation or the write of the result, nil if the result was written.
+func (st *Manager) ProcessRecordingResult(ctx context.Context, evaluatedAt time.Time, rule *ngModels.AlertRule, duration time.Duration, err error) RecordingRuleStatus {
+	key := rule.GetKey()
+	st.recording.mtx.Lock()
+	defer st.recording.mtx.Unlock()
+
+	status := st.recording.statuses[key]
+	previousHealth := status.Health
+	status.LastEvaluationTime = evaluatedAt
+	status.EvaluationDuration = duration
+	if err != nil {
+		status.Health = RecordingRuleHealthError
+		status.LastError = err
+		status.ConsecutiveFailures++
+	} else {
+		status.Health = RecordingRuleHealthOK
+		status.LastError = nil
+		status.LastWriteTime = evaluatedAt
+		status.ConsecutiveFailures = 0
+	}
+	st.recording.statuses[key] = status
+
+	if previousHealth != status.Health {
+		logger := st.log.FromContext(ctx)
+		if err != nil {
+			logger.Warn("Recording rule failed to write its result", "error", err)
+		} else if previousHealth != "" {
+			logger.Info("Recording rule recovered")
+		}
+	}
+	return status
+}
+
+// GetRecordingRuleStatus returns the status of a recording rule and false if it was not evaluated yet.
+func (st *Manager) GetRecordingRuleStatus(orgID int64, ruleUID string) (RecordingRuleStatus, bool) {
+	st.recording.mtx.RLock()
+	defer st.recording.mtx.RUnlock()
+	status, ok := st.recording.statuses[ngModels.AlertRuleKey{OrgID: orgID, UID: ruleUID}]
+	return status, ok
+}
+
+func (st *Manager) deleteRecordingRuleStatus(key ngModels.AlertRuleKey) {
+	st.recording.mtx.Lock()
+	defer st.recording.mtx.Unlock()
+	delete(st.recording.statuses, key)
+}
@@ -0,0 +1,92 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// remoteStatesTTL is how long the states of the rules evaluated by other instances are reused before they are read
+// from the instance store again. It keeps the rules API from querying the database for every rule.
+const remoteStatesTTL = 5 * time.Second
+
+// ShardedStateReader reads the current alert instances when the evaluation of alert rules is sharded across the
+// instances of Grafana. Each instance caches only the states of the rules that it evaluates, so the states of the other
+// rules are read from the instance store, where their owners save them after every evaluation. This way every
+// instance returns the alert instances of all rules. The alert instances read from the instance store have no values
+// and no annotations, because these are not saved.
+type ShardedStateReader struct {
+	manager *Manager
+
+	mtx    sync.Mutex
+	remote map[int64]*remoteStates
+}
+
+// remoteStates are the states of the rules of an organization that were read from the instance store.
+type remoteStates struct {
+	fetchedAt time.Time
+	byRule    map[string][]*State
+}
+
+func NewShardedStateReader(manager *Manager) *ShardedStateReader {
+	return &ShardedStateReader{
+		manager: manager,
+		remote:  make(map[int64]*remoteStates),
+	}
+}
+
+func (r *ShardedStateReader) GetAll(orgID int64) []*State {
+	states := r.manager.GetAll(orgID)
+	for uid, ruleStates := range r.getRemoteStates(orgID) {
+		if r.manager.cache.hasRule(orgID, uid) {
+			continue
+		}
+		states = append(states, ruleStates...)
+	}
+	return states
+}
+
+func (r *ShardedStateReader) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
+	if r.manager.cache.hasRule(orgID, alertRuleUID) {
+		return r.manager.GetStatesForRuleUID(orgID, alertRuleUID)
+	}
+	return r.getRemoteStates(orgID)[alertRuleUID]
+}
+
+func (r *ShardedStateReader) GetRecordingRuleStatus(orgID int64, ruleUID string) (RecordingRuleStatus, bool) {
+	return r.manager.GetRecordingRuleStatus(orgID, ruleUID)
+}
+
+// getRemoteStates returns the states of the rules of the organization that are saved in the instance store. If the
+// instance store cannot be read, the states that were read last are returned.
+func (r *ShardedStateReader) getRemoteStates(orgID int64) map[string][]*State {
+	if r.manager.instanceStore == nil {
+		return nil
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	now := r.manager.clock.Now()
+	previous, ok := r.remote[orgID]
+	if ok && now.Sub(previous.fetchedAt) < remoteStatesTTL {
+		return previous.byRule
+	}
+
+	instances, err := r.manager.instanceStore.ListAlertInstances(context.Background(), &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+	})
+	if err != nil {
+		r.manager.log.Error("Unable to read the state of the rules evaluated by other instances", "org", orgID, "error", err)
+		if ok {
+			return previous.byRule
+		}
+		return nil
+	}
+	byRule := make(map[string][]*State)
+	for _, entry := range instances {
+		byRule[entry.RuleUID] = append(byRule[entry.RuleUID], r.manager.stateFromInstance(entry, nil))
+	}
+	r.remote[orgID] = &remoteStates{fetchedAt: now, byRule: byRule}
+	return byRule
+}
@@ -0,0 +1,129 @@
+package state_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// listInstanceStore is an instance store that returns the instances of the rules that it was given.
+type listInstanceStore struct {
+	state.FakeInstanceStore
+
+	mtx       sync.Mutex
+	instances []*models.AlertInstance
+	lists     int
+}
+
+func (s *listInstanceStore) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lists++
+	result := make([]*models.AlertInstance, 0, len(s.instances))
+	for _, instance := range s.instances {
+		if instance.RuleOrgID == q.RuleOrgID && (q.RuleUID == "" || instance.RuleUID == q.RuleUID) {
+			result = append(result, instance)
+		}
+	}
+	return result, nil
+}
+
+func (s *listInstanceStore) setInstanceState(ruleUID string, instanceState models.InstanceStateType) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, instance := range s.instances {
+		if instance.RuleUID == ruleUID {
+			instance.CurrentState = instanceState
+		}
+	}
+}
+
+func TestShardedStateReader(t *testing.T) {
+	const orgID int64 = 1
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewMock()
+	clk.Set(now)
+
+	instance := func(ruleUID string, instanceState models.InstanceStateType) *models.AlertInstance {
+		return &models.AlertInstance{
+			AlertInstanceKey:  models.AlertInstanceKey{RuleOrgID: orgID, RuleUID: ruleUID, LabelsHash: ruleUID},
+			Labels:            models.InstanceLabels{"rule": ruleUID},
+			CurrentState:      instanceState,
+			CurrentStateSince: now.Add(-time.Minute),
+			LastEvalTime:      now,
+		}
+	}
+	store := &listInstanceStore{instances: []*models.AlertInstance{
+		instance("owned", models.InstanceStateFiring),
+		instance("remote", models.InstanceStateFiring),
+	}}
+	st := state.NewManager(state.ManagerCfg{
+		InstanceStore: store,
+		Clock:         clk,
+		Log:           log.New("test"),
+	})
+	owned := &models.AlertRule{OrgID: orgID, UID: "owned", Annotations: map[string]string{"summary": "owned"}}
+	st.WarmRule(ctx, owned)
+	reader := state.NewShardedStateReader(st)
+
+	// the state of the owned rule changes only in the cache of this instance until the next evaluation is saved
+	store.setInstanceState("owned", models.InstanceStateNormal)
+
+	t.Run("should read the states of the owned rules from the cache", func(t *testing.T) {
+		states := reader.GetStatesForRuleUID(orgID, "owned")
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Equal(t, "owned", states[0].Annotations["summary"])
+	})
+
+	t.Run("should read the states of the rules evaluated by other instances from the instance store", func(t *testing.T) {
+		states := reader.GetStatesForRuleUID(orgID, "remote")
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Equal(t, data.Labels{"rule": "remote"}, states[0].Labels)
+		require.Equal(t, now.Add(-time.Minute), states[0].StartsAt)
+		require.Empty(t, reader.GetStatesForRuleUID(orgID, "unknown"))
+	})
+
+	t.Run("should return every state once", func(t *testing.T) {
+		states := reader.GetAll(orgID)
+		require.Len(t, states, 2)
+		byRule := map[string]*state.State{}
+		for _, s := range states {
+			byRule[s.AlertRuleUID] = s
+		}
+		require.Equal(t, "owned", byRule["owned"].Annotations["summary"])
+		require.Equal(t, eval.Alerting, byRule["remote"].State)
+	})
+
+	t.Run("should read the instance store again when the states expire", func(t *testing.T) {
+		lists := store.lists
+		store.setInstanceState("remote", models.InstanceStateNormal)
+		require.Equal(t, eval.Alerting, reader.GetStatesForRuleUID(orgID, "remote")[0].State)
+		require.Equal(t, lists, store.lists)
+
+		clk.Add(5 * time.Second)
+		require.Equal(t, eval.Normal, reader.GetStatesForRuleUID(orgID, "remote")[0].State)
+		require.Equal(t, lists+1, store.lists)
+	})
+
+	t.Run("should read the states of a rule from the instance store after it is handed over", func(t *testing.T) {
+		st.ForgetStateByRuleUID(owned.GetKey())
+		clk.Add(5 * time.Second)
+
+		states := reader.GetStatesForRuleUID(orgID, "owned")
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Normal, states[0].State)
+	})
+}
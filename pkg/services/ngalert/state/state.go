@@ -61,6 +61,10 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// KeepFiringSince is the time of the first evaluation of a firing alert that no longer met the condition.
+	// It is only set while the alert keeps firing because of the KeepFiringFor duration of the rule.
+	KeepFiringSince time.Time
+
 	StartsAt             time.Time
 	EndsAt               time.Time
 	LastSentAt           time.Time
@@ -92,6 +96,7 @@ func (a *State) SetAlerting(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // SetPending the state to Pending. It changes both the start and end time.
@@ -128,6 +133,7 @@ func (a *State) SetNormal(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = time.Time{}
 }
 
 // Resolve sets the State to Normal. It updates the StateReason, the end time, and sets Resolved to true.
@@ -187,9 +193,21 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]*float
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
+	} else if state.State == eval.Alerting && keepFiring(state, rule, result.EvaluatedAt) {
+		prevEndsAt := state.EndsAt
+		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		logger.Debug("Keeping state because the rule keeps firing",
+			"state",
+			state.State,
+			"keep_firing_since",
+			state.KeepFiringSince,
+			"previous_ends_at",
+			prevEndsAt,
+			"next_ends_at",
+			state.EndsAt)
 	} else {
 		nextEndsAt := result.EvaluatedAt
 		logger.Debug("Changing state",
@@ -206,11 +224,25 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 	}
 }
 
+// keepFiring returns true if a firing alert should keep firing although the condition is no longer met.
+// This is the case until the KeepFiringFor duration of the rule has passed since the condition was first no longer met.
+func keepFiring(state *State, rule *models.AlertRule, evaluatedAt time.Time) bool {
+	if rule.KeepFiringFor <= 0 {
+		return false
+	}
+	if state.KeepFiringSince.IsZero() {
+		state.KeepFiringSince = evaluatedAt
+	}
+	return evaluatedAt.Sub(state.KeepFiringSince) < rule.KeepFiringFor
+}
+
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
 	switch state.State {
 	case eval.Alerting:
 		prevEndsAt := state.EndsAt
 		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		// the condition is met again, so the keep firing period starts over the next time it is not.
+		state.KeepFiringSince = time.Time{}
 		logger.Debug("Keeping state",
 			"state",
 			state.State,
@@ -479,6 +511,11 @@ func FormatStateAndReason(state eval.State, reason string) string {
 	return s
 }
 
+// isRuleExtraLabel returns true if the label is added to alert instances by GetRuleExtraLabels.
+func isRuleExtraLabel(name string) bool {
+	return name == prometheusModel.AlertNameLabel || name == models.FolderTitleLabel || strings.HasPrefix(name, "__")
+}
+
 // GetRuleExtraLabels returns a map of built-in labels that should be added to an alert before it is sent to the Alertmanager or its state is cached.
 func GetRuleExtraLabels(rule *models.AlertRule, folderTitle string, includeFolder bool) map[string]string {
 	extraLabels := make(map[string]string, 4)
@@ -490,5 +527,11 @@ func GetRuleExtraLabels(rule *models.AlertRule, folderTitle string, includeFolde
 	if includeFolder {
 		extraLabels[models.FolderTitleLabel] = folderTitle
 	}
+
+	if rule.NotificationSettings != nil {
+		for k, v := range rule.NotificationSettings.ToLabels() {
+			extraLabels[k] = v
+		}
+	}
 	return extraLabels
 }
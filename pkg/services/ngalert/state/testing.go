@@ -53,6 +53,25 @@ func (f *FakeInstanceStore) DeleteAlertInstancesByRule(ctx context.Context, key
 	return nil
 }
 
+var _ RuleInstanceStore = &FakeRuleInstanceStore{}
+
+// FakeRuleInstanceStore is a FakeInstanceStore that saves all instances of a rule at once.
+type FakeRuleInstanceStore struct {
+	FakeInstanceStore
+}
+
+type FakeRuleInstanceStoreOp struct {
+	Key       models.AlertRuleKey
+	Instances []models.AlertInstance
+}
+
+func (f *FakeRuleInstanceStore) SaveAlertInstancesForRule(_ context.Context, key models.AlertRuleKey, instances []models.AlertInstance) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, FakeRuleInstanceStoreOp{Key: key, Instances: instances})
+	return nil
+}
+
 type FakeRuleReader struct{}
 
 func (f *FakeRuleReader) ListAlertRules(_ context.Context, q *models.ListAlertRulesQuery) (models.RulesGroup, error) {
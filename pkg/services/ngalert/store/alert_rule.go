@@ -66,6 +66,12 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("Deleted alert instances", "count", rows)
+
+		rows, err = sess.Table("alert_rule_state").Where("org_id = ?", orgID).In("rule_uid", ruleUID).Delete(ngmodels.AlertRule{})
+		if err != nil {
+			return err
+		}
+		logger.Debug("Deleted alert rule states", "count", rows)
 		return nil
 	})
 }
@@ -115,6 +121,24 @@ func (st DBstore) GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmode
 	return result, err
 }
 
+// GetAlertRuleVersions returns the versions of an alert rule by UID and organisation ID, the most recent first.
+// Returns ErrAlertRuleNotFound if there are no versions of the rule.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, query *ngmodels.GetAlertRuleVersionsQuery) (result []*ngmodels.AlertRuleVersion, err error) {
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var versions []*ngmodels.AlertRuleVersion
+		err := sess.Table(&ngmodels.AlertRuleVersion{}).Where("rule_org_id = ? AND rule_uid = ?", query.OrgID, query.UID).Desc("version").Find(&versions)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return ngmodels.ErrAlertRuleNotFound
+		}
+		result = versions
+		return nil
+	})
+	return result, err
+}
+
 // InsertAlertRules is a handler for creating/updating alert rules.
 // Returns the UID and ID of rules that were created in the same order as the input rules.
 func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRule) ([]ngmodels.AlertRuleKeyWithId, error) {
@@ -140,22 +164,29 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 			}
 			newRules = append(newRules, r)
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleUID:          r.UID,
-				RuleOrgID:        r.OrgID,
-				RuleNamespaceUID: r.NamespaceUID,
-				RuleGroup:        r.RuleGroup,
-				ParentVersion:    0,
-				Version:          r.Version,
-				Created:          r.Updated,
-				Condition:        r.Condition,
-				Title:            r.Title,
-				Data:             r.Data,
-				IntervalSeconds:  r.IntervalSeconds,
-				NoDataState:      r.NoDataState,
-				ExecErrState:     r.ExecErrState,
-				For:              r.For,
-				Annotations:      r.Annotations,
-				Labels:           r.Labels,
+				RuleUID:              r.UID,
+				RuleOrgID:            r.OrgID,
+				RuleNamespaceUID:     r.NamespaceUID,
+				RuleGroup:            r.RuleGroup,
+				RuleGroupIndex:       r.RuleGroupIndex,
+				ParentVersion:        0,
+				Version:              r.Version,
+				Created:              r.Updated,
+				CreatedBy:            r.UpdatedBy,
+				Condition:            r.Condition,
+				Title:                r.Title,
+				Data:                 r.Data,
+				IntervalSeconds:      r.IntervalSeconds,
+				NoDataState:          r.NoDataState,
+				ExecErrState:         r.ExecErrState,
+				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
+				Annotations:          r.Annotations,
+				Labels:               r.Labels,
+				IsPaused:             r.IsPaused,
+				Record:               r.Record,
+				NotificationSettings: r.NotificationSettings,
+				SequentialEvaluation: r.SequentialEvaluation,
 			})
 		}
 		if len(newRules) > 0 {
@@ -215,23 +246,30 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				RuleGroupIndex:   r.New.RuleGroupIndex,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version + 1,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:            r.New.OrgID,
+				RuleUID:              r.New.UID,
+				RuleNamespaceUID:     r.New.NamespaceUID,
+				RuleGroup:            r.New.RuleGroup,
+				RuleGroupIndex:       r.New.RuleGroupIndex,
+				ParentVersion:        parentVersion,
+				RestoredFrom:         r.RestoredFrom,
+				Version:              r.New.Version + 1,
+				Created:              r.New.Updated,
+				CreatedBy:            r.New.UpdatedBy,
+				Condition:            r.New.Condition,
+				Title:                r.New.Title,
+				Data:                 r.New.Data,
+				IntervalSeconds:      r.New.IntervalSeconds,
+				NoDataState:          r.New.NoDataState,
+				ExecErrState:         r.New.ExecErrState,
+				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
+				Annotations:          r.New.Annotations,
+				Labels:               r.New.Labels,
+				IsPaused:             r.New.IsPaused,
+				Record:               r.New.Record,
+				NotificationSettings: r.New.NotificationSettings,
+				SequentialEvaluation: r.New.SequentialEvaluation,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -409,6 +447,32 @@ func (st DBstore) Count(ctx context.Context, orgID int64) (int64, error) {
 	return r.Count, err
 }
 
+// ListNotificationSettings returns the notification settings of the alert rules of the organization that have them.
+func (st DBstore) ListNotificationSettings(ctx context.Context, orgID int64) (map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, error) {
+	type ruleSettings struct {
+		UID                  string                         `xorm:"uid"`
+		NotificationSettings *ngmodels.NotificationSettings `xorm:"notification_settings"`
+	}
+	var rules []ruleSettings
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule").
+			Select("uid, notification_settings").
+			Where("org_id = ? AND notification_settings IS NOT NULL", orgID).
+			Find(&rules)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, len(rules))
+	for _, r := range rules {
+		if r.NotificationSettings == nil || r.NotificationSettings.Receiver == "" {
+			continue
+		}
+		result[ngmodels.AlertRuleKey{OrgID: orgID, UID: r.UID}] = *r.NotificationSettings
+	}
+	return result, nil
+}
+
 func (st DBstore) GetRuleGroupInterval(ctx context.Context, orgID int64, namespaceUID string, ruleGroup string) (int64, error) {
 	var interval int64 = 0
 	return interval, st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
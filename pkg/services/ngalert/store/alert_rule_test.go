@@ -68,6 +68,32 @@ func TestIntegrationUpdateAlertRules(t *testing.T) {
 		require.Equal(t, rule.Version+1, dbrule.Version)
 	})
 
+	t.Run("should record versions of the rule", func(t *testing.T) {
+		rule := createRule(t, store, generator)
+		newRule := models.CopyRule(rule)
+		newRule.Title = util.GenerateShortUID()
+		newRule.UpdatedBy = 42
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing:     rule,
+			New:          *newRule,
+			RestoredFrom: 1,
+		},
+		})
+		require.NoError(t, err)
+
+		versions, err := store.GetAlertRuleVersions(context.Background(), &models.GetAlertRuleVersionsQuery{UID: rule.UID, OrgID: rule.OrgID})
+		require.NoError(t, err)
+		require.Len(t, versions, 1) // createRule does not create the first version
+		require.Equal(t, rule.Version+1, versions[0].Version)
+		require.Equal(t, rule.Version, versions[0].ParentVersion)
+		require.Equal(t, int64(1), versions[0].RestoredFrom)
+		require.Equal(t, int64(42), versions[0].CreatedBy)
+		require.Equal(t, newRule.Title, versions[0].Title)
+
+		_, err = store.GetAlertRuleVersions(context.Background(), &models.GetAlertRuleVersionsQuery{UID: "unknown", OrgID: rule.OrgID})
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+
 	t.Run("should fail due to optimistic locking if version does not match", func(t *testing.T) {
 		rule := createRule(t, store, generator)
 		rule.Version-- // simulate version discrepancy
@@ -558,6 +584,90 @@ func TestIntegrationInsertAlertRules(t *testing.T) {
 	}
 }
 
+func TestIntegrationAlertRuleRecord(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	gen := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))
+	alerting := gen()
+	alerting.Record = nil
+	recording := gen()
+	recording.Record = &models.Record{Metric: "test_metric", From: recording.Data[0].RefID}
+	recording.For = 0
+
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*alerting, *recording})
+	require.NoError(t, err)
+
+	t.Run("should read rule by UID", func(t *testing.T) {
+		dbRule, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: alerting.UID})
+		require.NoError(t, err)
+		require.Nil(t, dbRule.Record)
+
+		dbRule, err = store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: recording.UID})
+		require.NoError(t, err)
+		require.Equal(t, recording.Record, dbRule.Record)
+	})
+
+	t.Run("should list rules", func(t *testing.T) {
+		dbRules, err := store.ListAlertRules(context.Background(), &models.ListAlertRulesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, dbRules, 2)
+		for _, dbRule := range dbRules {
+			switch dbRule.UID {
+			case alerting.UID:
+				require.Nil(t, dbRule.Record)
+			case recording.UID:
+				require.Equal(t, recording.Record, dbRule.Record)
+			}
+		}
+	})
+}
+
+func TestIntegrationListNotificationSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	settings := models.NotificationSettings{Receiver: "receiver", GroupBy: []string{"alertname"}, MuteTimeIntervals: []string{"weekends"}}
+	withSettings := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval), models.WithNotificationSettings(settings))()
+	withoutSettings := models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval))()
+	otherOrg := models.AlertRuleGen(models.WithOrgID(2), withIntervalMatching(store.Cfg.BaseInterval), models.WithNotificationSettings(settings))()
+
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*withSettings, *withoutSettings, *otherOrg})
+	require.NoError(t, err)
+
+	result, err := store.ListNotificationSettings(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, map[models.AlertRuleKey]models.NotificationSettings{
+		withSettings.GetKey(): settings,
+	}, result)
+
+	dbRule, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: 1, UID: withoutSettings.UID})
+	require.NoError(t, err)
+	require.Nil(t, dbRule.NotificationSettings)
+}
+
 func createRule(t *testing.T, store *DBstore, generate func() *models.AlertRule) *models.AlertRule {
 	t.Helper()
 	if generate == nil {
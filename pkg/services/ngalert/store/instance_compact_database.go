@@ -0,0 +1,366 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// exportBatchSize is the number of alert instances that are inserted by a single statement when the rule states are
+// exported to the alert_instance table.
+const exportBatchSize = 100
+
+// CompactInstanceStore is an instance store that saves all instances of an alert rule in a single compressed row of
+// the alert_rule_state table, instead of a row per instance in the alert_instance table. It makes saving the state
+// of a rule a single write, and loading the state of all rules on startup a read of one row per rule.
+type CompactInstanceStore struct {
+	SQLStore       db.DB
+	FeatureToggles featuremgmt.FeatureToggles
+	Logger         log.Logger
+}
+
+// alertRuleState is a row of the alert_rule_state table.
+type alertRuleState struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	RuleUID string `xorm:"rule_uid"`
+	// Data holds the instances of the rule encoded with encodeInstances.
+	Data    []byte `xorm:"data"`
+	Updated int64  `xorm:"updated"`
+}
+
+func (alertRuleState) TableName() string {
+	return "alert_rule_state"
+}
+
+// compactInstance is the encoded form of a models.AlertInstance. The key of the rule is stored in the row.
+type compactInstance struct {
+	Labels            models.InstanceLabels    `json:"l"`
+	LabelsHash        string                   `json:"h"`
+	CurrentState      models.InstanceStateType `json:"s"`
+	CurrentReason     string                   `json:"r,omitempty"`
+	CurrentStateSince int64                    `json:"b"`
+	CurrentStateEnd   int64                    `json:"e"`
+	LastEvalTime      int64                    `json:"t"`
+	KeepFiringSince   int64                    `json:"k,omitempty"`
+}
+
+// encodeInstances encodes the instances as JSON compressed with snappy.
+func encodeInstances(instances []models.AlertInstance) ([]byte, error) {
+	compact := make([]compactInstance, 0, len(instances))
+	for _, instance := range instances {
+		c := compactInstance{
+			Labels:            instance.Labels,
+			LabelsHash:        instance.LabelsHash,
+			CurrentState:      instance.CurrentState,
+			CurrentReason:     instance.CurrentReason,
+			CurrentStateSince: instance.CurrentStateSince.Unix(),
+			CurrentStateEnd:   instance.CurrentStateEnd.Unix(),
+			LastEvalTime:      instance.LastEvalTime.Unix(),
+		}
+		if !instance.KeepFiringSince.IsZero() {
+			c.KeepFiringSince = instance.KeepFiringSince.Unix()
+		}
+		compact = append(compact, c)
+	}
+	b, err := json.Marshal(compact)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, b), nil
+}
+
+// decodeInstances decodes the instances of the row.
+func (r alertRuleState) decodeInstances() ([]*models.AlertInstance, error) {
+	b, err := snappy.Decode(nil, r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the state of rule %s: %w", r.RuleUID, err)
+	}
+	var compact []compactInstance
+	if err := json.Unmarshal(b, &compact); err != nil {
+		return nil, fmt.Errorf("failed to decode the state of rule %s: %w", r.RuleUID, err)
+	}
+	result := make([]*models.AlertInstance, 0, len(compact))
+	for _, c := range compact {
+		instance := &models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{
+				RuleOrgID:  r.OrgID,
+				RuleUID:    r.RuleUID,
+				LabelsHash: c.LabelsHash,
+			},
+			Labels:            c.Labels,
+			CurrentState:      c.CurrentState,
+			CurrentReason:     c.CurrentReason,
+			CurrentStateSince: time.Unix(c.CurrentStateSince, 0),
+			CurrentStateEnd:   time.Unix(c.CurrentStateEnd, 0),
+			LastEvalTime:      time.Unix(c.LastEvalTime, 0),
+		}
+		if c.KeepFiringSince != 0 {
+			instance.KeepFiringSince = time.Unix(c.KeepFiringSince, 0)
+		}
+		result = append(result, instance)
+	}
+	return result, nil
+}
+
+// ListAlertInstances returns the instances of the rules of an organization, or of a single rule.
+func (st CompactInstanceStore) ListAlertInstances(ctx context.Context, cmd *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	var rows []alertRuleState
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", cmd.RuleOrgID)
+		if cmd.RuleUID != "" {
+			q = q.And("rule_uid = ?", cmd.RuleUID)
+		}
+		return q.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	skipNormal := st.FeatureToggles.IsEnabled(ctx, featuremgmt.FlagAlertingNoNormalState)
+	result := make([]*models.AlertInstance, 0, len(rows))
+	for _, row := range rows {
+		instances, err := row.decodeInstances()
+		if err != nil {
+			// a corrupted row must not prevent loading the state of the other rules.
+			st.Logger.Error("Failed to decode the state of the alert rule", "org_id", row.OrgID, "rule_uid", row.RuleUID, "error", err)
+			continue
+		}
+		for _, instance := range instances {
+			if skipNormal && instance.CurrentState == models.InstanceStateNormal && instance.CurrentReason == "" {
+				continue
+			}
+			result = append(result, instance)
+		}
+	}
+	return result, nil
+}
+
+// SaveAlertInstancesForRule replaces all saved instances of the rule with the given ones.
+func (st CompactInstanceStore) SaveAlertInstancesForRule(ctx context.Context, key models.AlertRuleKey, instances []models.AlertInstance) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return st.saveRuleState(sess, key, instances)
+	})
+}
+
+func (st CompactInstanceStore) saveRuleState(sess *db.Session, key models.AlertRuleKey, instances []models.AlertInstance) error {
+	if len(instances) == 0 {
+		_, err := sess.Exec("DELETE FROM alert_rule_state WHERE org_id = ? AND rule_uid = ?", key.OrgID, key.UID)
+		return err
+	}
+	for _, instance := range instances {
+		if err := models.ValidateAlertInstance(instance); err != nil {
+			return err
+		}
+	}
+	data, err := encodeInstances(instances)
+	if err != nil {
+		return err
+	}
+	upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
+		"alert_rule_state",
+		[]string{"org_id", "rule_uid"},
+		[]string{"org_id", "rule_uid", "data", "updated"})
+	_, err = sess.SQL(upsertSQL, key.OrgID, key.UID, data, time.Now().Unix()).Query()
+	return err
+}
+
+// SaveAlertInstance saves a single instance. It rewrites the row of the rule, therefore,
+// SaveAlertInstancesForRule should be used to save the instances of a rule.
+func (st CompactInstanceStore) SaveAlertInstance(ctx context.Context, instance models.AlertInstance) error {
+	if err := models.ValidateAlertInstance(instance); err != nil {
+		return err
+	}
+	key := models.AlertRuleKey{OrgID: instance.RuleOrgID, UID: instance.RuleUID}
+	return st.updateRuleState(ctx, key, func(instances []models.AlertInstance) []models.AlertInstance {
+		for i := range instances {
+			if instances[i].LabelsHash == instance.LabelsHash {
+				instances[i] = instance
+				return instances
+			}
+		}
+		return append(instances, instance)
+	})
+}
+
+// DeleteAlertInstances deletes the instances with the provided keys. It rewrites the row of every affected rule.
+func (st CompactInstanceStore) DeleteAlertInstances(ctx context.Context, keys ...models.AlertInstanceKey) error {
+	byRule := make(map[models.AlertRuleKey]map[string]struct{})
+	for _, k := range keys {
+		ruleKey := models.AlertRuleKey{OrgID: k.RuleOrgID, UID: k.RuleUID}
+		if _, ok := byRule[ruleKey]; !ok {
+			byRule[ruleKey] = make(map[string]struct{})
+		}
+		byRule[ruleKey][k.LabelsHash] = struct{}{}
+	}
+	for ruleKey, hashes := range byRule {
+		err := st.updateRuleState(ctx, ruleKey, func(instances []models.AlertInstance) []models.AlertInstance {
+			result := instances[:0]
+			for _, instance := range instances {
+				if _, ok := hashes[instance.LabelsHash]; !ok {
+					result = append(result, instance)
+				}
+			}
+			return result
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateRuleState reads the instances of the rule, applies update to them and saves the result in a transaction.
+func (st CompactInstanceStore) updateRuleState(ctx context.Context, key models.AlertRuleKey, update func([]models.AlertInstance) []models.AlertInstance) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var row alertRuleState
+		found, err := sess.Where("org_id = ? AND rule_uid = ?", key.OrgID, key.UID).Get(&row)
+		if err != nil {
+			return err
+		}
+		var instances []models.AlertInstance
+		if found {
+			decoded, err := row.decodeInstances()
+			if err != nil {
+				return err
+			}
+			instances = make([]models.AlertInstance, 0, len(decoded))
+			for _, instance := range decoded {
+				instances = append(instances, *instance)
+			}
+		}
+		return st.saveRuleState(sess, key, update(instances))
+	})
+}
+
+func (st CompactInstanceStore) FetchOrgIds(ctx context.Context) ([]int64, error) {
+	orgIds := []int64{}
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT DISTINCT org_id FROM alert_rule_state").Find(&orgIds)
+	})
+	return orgIds, err
+}
+
+func (st CompactInstanceStore) DeleteAlertInstancesByRule(ctx context.Context, key models.AlertRuleKey) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM alert_rule_state WHERE org_id = ? AND rule_uid = ?", key.OrgID, key.UID)
+		return err
+	})
+}
+
+// ImportAlertInstances moves the instances saved in the alert_instance table to the alert_rule_state table. It is
+// used when the compact state persistence is enabled, so the state saved before is not lost. If the state of a
+// rule is in both tables, the instances of the alert_instance table replace those of the alert_rule_state table.
+// It returns the number of imported instances.
+func (st CompactInstanceStore) ImportAlertInstances(ctx context.Context) (int, error) {
+	count := 0
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var instances []*models.AlertInstance
+		if err := sess.SQL("SELECT * FROM alert_instance").Find(&instances); err != nil {
+			return err
+		}
+		if len(instances) == 0 {
+			return nil
+		}
+
+		byRule := make(map[models.AlertRuleKey][]models.AlertInstance)
+		for _, instance := range instances {
+			key := models.AlertRuleKey{OrgID: instance.RuleOrgID, UID: instance.RuleUID}
+			byRule[key] = append(byRule[key], *instance)
+		}
+		for key, ruleInstances := range byRule {
+			if err := st.saveRuleState(sess, key, ruleInstances); err != nil {
+				return err
+			}
+		}
+		if _, err := sess.Exec("DELETE FROM alert_instance"); err != nil {
+			return err
+		}
+		count = len(instances)
+		return nil
+	})
+	return count, err
+}
+
+// ExportAlertInstances moves the instances saved in the alert_rule_state table to the alert_instance table. It is
+// used when the compact state persistence is disabled, so the state saved before is not lost. It returns the number
+// of exported instances.
+func (st CompactInstanceStore) ExportAlertInstances(ctx context.Context) (int, error) {
+	count := 0
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var rows []alertRuleState
+		if err := sess.Find(&rows); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		var instances []*models.AlertInstance
+		for _, row := range rows {
+			decoded, err := row.decodeInstances()
+			if err != nil {
+				st.Logger.Error("Failed to decode the state of the alert rule, the state is dropped", "org_id", row.OrgID, "rule_uid", row.RuleUID, "error", err)
+				continue
+			}
+			instances = append(instances, decoded...)
+		}
+		// sort the instances to make the order of the writes deterministic.
+		sort.Slice(instances, func(i, j int) bool {
+			a, b := instances[i], instances[j]
+			if a.RuleOrgID != b.RuleOrgID {
+				return a.RuleOrgID < b.RuleOrgID
+			}
+			if a.RuleUID != b.RuleUID {
+				return a.RuleUID < b.RuleUID
+			}
+			return a.LabelsHash < b.LabelsHash
+		})
+
+		for start := 0; start < len(instances); start += exportBatchSize {
+			end := start + exportBatchSize
+			if end > len(instances) {
+				end = len(instances)
+			}
+			if err := st.insertAlertInstances(sess, instances[start:end]); err != nil {
+				return err
+			}
+		}
+		if _, err := sess.Exec("DELETE FROM alert_rule_state"); err != nil {
+			return err
+		}
+		count = len(instances)
+		return nil
+	})
+	return count, err
+}
+
+func (st CompactInstanceStore) insertAlertInstances(sess *db.Session, instances []*models.AlertInstance) error {
+	upsertSQL, err := st.SQLStore.GetDialect().UpsertMultipleSQL(
+		"alert_instance",
+		[]string{"rule_org_id", "rule_uid", "labels_hash"},
+		[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "keep_firing_since"},
+		len(instances))
+	if err != nil {
+		return err
+	}
+	params := make([]any, 0, len(instances)*10)
+	for _, instance := range instances {
+		labelTupleJSON, err := instance.Labels.StringKey()
+		if err != nil {
+			return err
+		}
+		params = append(params, instance.RuleOrgID, instance.RuleUID, labelTupleJSON, instance.LabelsHash, instance.CurrentState, instance.CurrentReason, instance.CurrentStateSince.Unix(), instance.CurrentStateEnd.Unix(), instance.LastEvalTime.Unix(), instance.KeepFiringSinceUnix())
+	}
+	_, err = sess.SQL(upsertSQL, params...).Query()
+	return err
+}
@@ -0,0 +1,171 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationCompactInstanceStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	compactStore := store.CompactInstanceStore{
+		SQLStore:       dbstore.SQLStore,
+		FeatureToggles: featuremgmt.WithFeatures(),
+		Logger:         log.New("test"),
+	}
+
+	newInstances := func(rule *models.AlertRule, count int, state models.InstanceStateType) []models.AlertInstance {
+		now := time.Now().UTC().Truncate(time.Second)
+		result := make([]models.AlertInstance, 0, count)
+		for i := 0; i < count; i++ {
+			labels := models.InstanceLabels{"test": fmt.Sprint(i)}
+			_, hash, _ := labels.StringAndHash()
+			instance := models.AlertInstance{
+				AlertInstanceKey: models.AlertInstanceKey{
+					RuleOrgID:  rule.OrgID,
+					RuleUID:    rule.UID,
+					LabelsHash: hash,
+				},
+				Labels:            labels,
+				CurrentState:      state,
+				CurrentReason:     string(models.InstanceStateError),
+				CurrentStateSince: now.Add(-time.Minute),
+				CurrentStateEnd:   now.Add(time.Minute),
+				LastEvalTime:      now,
+			}
+			// only some of the instances keep firing
+			if i%2 == 0 {
+				instance.KeepFiringSince = now.Add(-30 * time.Second)
+			}
+			result = append(result, instance)
+		}
+		return result
+	}
+
+	listRule := func(t *testing.T, s interface {
+		ListAlertInstances(context.Context, *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error)
+	}, rule *models.AlertRule) []models.AlertInstance {
+		t.Helper()
+		instances, err := s.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		result := make([]models.AlertInstance, 0, len(instances))
+		for _, instance := range instances {
+			// the stores return the times in different locations
+			instance.CurrentStateSince = instance.CurrentStateSince.UTC()
+			instance.CurrentStateEnd = instance.CurrentStateEnd.UTC()
+			instance.LastEvalTime = instance.LastEvalTime.UTC()
+			if !instance.KeepFiringSince.IsZero() {
+				instance.KeepFiringSince = instance.KeepFiringSince.UTC()
+			}
+			result = append(result, *instance)
+		}
+		return result
+	}
+
+	t.Run("can save and read the instances of a rule", func(t *testing.T) {
+		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		instances := newInstances(rule, 3, models.InstanceStateFiring)
+
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule.GetKey(), instances))
+		require.ElementsMatch(t, instances, listRule(t, compactStore, rule))
+
+		// saving again replaces the instances of the rule
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule.GetKey(), instances[:1]))
+		require.ElementsMatch(t, instances[:1], listRule(t, compactStore, rule))
+
+		// saving no instances deletes the state of the rule
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule.GetKey(), nil))
+		require.Empty(t, listRule(t, compactStore, rule))
+	})
+
+	t.Run("can save and delete single instances", func(t *testing.T) {
+		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		instances := newInstances(rule, 3, models.InstanceStateFiring)
+		for _, instance := range instances {
+			require.NoError(t, compactStore.SaveAlertInstance(ctx, instance))
+		}
+		require.ElementsMatch(t, instances, listRule(t, compactStore, rule))
+
+		updated := instances[0]
+		updated.CurrentState = models.InstanceStateNormal
+		require.NoError(t, compactStore.SaveAlertInstance(ctx, updated))
+		require.ElementsMatch(t, []models.AlertInstance{updated, instances[1], instances[2]}, listRule(t, compactStore, rule))
+
+		require.NoError(t, compactStore.DeleteAlertInstances(ctx, instances[0].AlertInstanceKey, instances[2].AlertInstanceKey))
+		require.ElementsMatch(t, instances[1:2], listRule(t, compactStore, rule))
+
+		require.NoError(t, compactStore.DeleteAlertInstancesByRule(ctx, rule.GetKey()))
+		require.Empty(t, listRule(t, compactStore, rule))
+	})
+
+	t.Run("should ignore Normal state with no reason if feature flag is enabled", func(t *testing.T) {
+		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		instances := newInstances(rule, 2, models.InstanceStateNormal)
+		instances[1].CurrentReason = ""
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule.GetKey(), instances))
+
+		s := compactStore
+		s.FeatureToggles = featuremgmt.WithFeatures(featuremgmt.FlagAlertingNoNormalState)
+		require.ElementsMatch(t, instances[:1], listRule(t, s, rule))
+		require.ElementsMatch(t, instances, listRule(t, compactStore, rule))
+
+		require.NoError(t, compactStore.DeleteAlertInstancesByRule(ctx, rule.GetKey()))
+	})
+
+	t.Run("deleting rules deletes their state", func(t *testing.T) {
+		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule.GetKey(), newInstances(rule, 2, models.InstanceStateFiring)))
+
+		require.NoError(t, dbstore.DeleteAlertRulesByUID(ctx, rule.OrgID, rule.UID))
+		require.Empty(t, listRule(t, compactStore, rule))
+	})
+
+	t.Run("can export and import the state", func(t *testing.T) {
+		rule1 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		rule2 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+		instances1 := newInstances(rule1, 150, models.InstanceStateFiring)
+		instances2 := newInstances(rule2, 2, models.InstanceStatePending)
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule1.GetKey(), instances1))
+		require.NoError(t, compactStore.SaveAlertInstancesForRule(ctx, rule2.GetKey(), instances2))
+
+		orgIDs, err := compactStore.FetchOrgIds(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []int64{mainOrgID}, orgIDs)
+
+		exported, err := compactStore.ExportAlertInstances(ctx)
+		require.NoError(t, err)
+		require.Equal(t, len(instances1)+len(instances2), exported)
+		require.Empty(t, listRule(t, compactStore, rule1))
+		require.ElementsMatch(t, instances1, listRule(t, dbstore, rule1))
+		require.ElementsMatch(t, instances2, listRule(t, dbstore, rule2))
+
+		imported, err := compactStore.ImportAlertInstances(ctx)
+		require.NoError(t, err)
+		require.Equal(t, exported, imported)
+		require.Empty(t, listRule(t, dbstore, rule1))
+		require.ElementsMatch(t, instances1, listRule(t, compactStore, rule1))
+		require.ElementsMatch(t, instances2, listRule(t, compactStore, rule2))
+
+		// nothing to move the second time
+		imported, err = compactStore.ImportAlertInstances(ctx)
+		require.NoError(t, err)
+		require.Zero(t, imported)
+		require.ElementsMatch(t, instances1, listRule(t, compactStore, rule1))
+	})
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// notificationDeliveryInsertBatchSize is the maximum number of delivery attempts inserted by a single statement.
+	notificationDeliveryInsertBatchSize = 100
+	// notificationDeliveryDeleteBatchSize is the maximum number of delivery attempts deleted by a single statement.
+	notificationDeliveryDeleteBatchSize = 1000
+)
+
+// InsertNotificationDeliveries records the delivery attempts in the notification delivery log.
+func (st DBstore) InsertNotificationDeliveries(ctx context.Context, deliveries []models.NotificationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(deliveries); start += notificationDeliveryInsertBatchSize {
+			end := start + notificationDeliveryInsertBatchSize
+			if end > len(deliveries) {
+				end = len(deliveries)
+			}
+			batch := deliveries[start:end]
+			if _, err := sess.Table(&models.NotificationDelivery{}).InsertMulti(&batch); err != nil {
+				return fmt.Errorf("failed to insert notification deliveries: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListNotificationDeliveries returns the delivery attempts that match the query, the most recent attempts first.
+func (st DBstore) ListNotificationDeliveries(ctx context.Context, query models.ListNotificationDeliveriesQuery) ([]models.NotificationDelivery, error) {
+	var result []models.NotificationDelivery
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.Receiver != "" {
+			q = q.And("receiver = ?", query.Receiver)
+		}
+		if query.Integration != "" {
+			q = q.And("integration = ?", query.Integration)
+		}
+		if query.IntegrationUID != "" {
+			q = q.And("integration_uid = ?", query.IntegrationUID)
+		}
+		if query.AlertFingerprint != "" {
+			q = q.And("alert_fingerprints LIKE ?", "%"+query.AlertFingerprint+"%")
+		}
+		if query.Success != nil {
+			q = q.And("success = ?", *query.Success)
+		}
+		if !query.From.IsZero() {
+			q = q.And("attempted_at >= ?", query.From)
+		}
+		if !query.To.IsZero() {
+			q = q.And("attempted_at <= ?", query.To)
+		}
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Desc("attempted_at", "id").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteExpiredNotificationDeliveries deletes the delivery attempts that are older than the retention of the
+// notification delivery log. It returns the number of deleted attempts or an error.
+func (st DBstore) DeleteExpiredNotificationDeliveries(ctx context.Context) (int64, error) {
+	retention := st.Cfg.NotificationLog.Retention
+	if retention <= 0 {
+		return 0, nil
+	}
+	before := TimeNow().Add(-retention)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var deleted int64
+		err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			var ids []int64
+			if err := sess.Table(&models.NotificationDelivery{}).Where("attempted_at < ?", before).Limit(notificationDeliveryDeleteBatchSize).Cols("id").Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			n, err := sess.In("id", ids).Delete(&models.NotificationDelivery{})
+			deleted = n
+			return err
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired notification deliveries: %w", err)
+		}
+		total += deleted
+		if deleted < notificationDeliveryDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
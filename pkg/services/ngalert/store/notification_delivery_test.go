@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationNotificationDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Second)
+	delivery := func(orgID int64, receiver, integration, fingerprints string, success bool, at time.Time) models.NotificationDelivery {
+		d := models.NotificationDelivery{
+			OrgID:             orgID,
+			Receiver:          receiver,
+			Integration:       integration,
+			IntegrationUID:    receiver + "-" + integration,
+			GroupKey:          "{}:{}",
+			AlertFingerprints: fingerprints,
+			Duration:          10,
+			Success:           success,
+			AttemptedAt:       at,
+		}
+		if !success {
+			d.StatusCode = 500
+			d.Error = "webhook response status 500 Internal Server Error"
+		}
+		return d
+	}
+	require.NoError(t, dbstore.InsertNotificationDeliveries(ctx, []models.NotificationDelivery{
+		delivery(1, "team-a", "slack", "aaa,bbb", false, now.Add(-3*time.Hour)),
+		delivery(1, "team-a", "slack", "aaa,bbb", true, now.Add(-2*time.Hour)),
+		delivery(1, "team-b", "webhook", "ccc", true, now.Add(-time.Hour)),
+		delivery(1, "team-a", "email", "bbb", true, now),
+		delivery(2, "team-a", "slack", "aaa", true, now),
+	}))
+
+	receivers := func(result []models.NotificationDelivery) []string {
+		names := make([]string, 0, len(result))
+		for _, d := range result {
+			names = append(names, d.Receiver+"/"+d.Integration)
+		}
+		return names
+	}
+
+	t.Run("should return attempts of the organization, the most recent first", func(t *testing.T) {
+		result, err := dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a/email", "team-b/webhook", "team-a/slack", "team-a/slack"}, receivers(result))
+		require.Equal(t, 500, result[3].StatusCode)
+		require.Equal(t, []string{"aaa", "bbb"}, result[3].Fingerprints())
+		require.True(t, now.Add(-3*time.Hour).Equal(result[3].AttemptedAt))
+	})
+
+	t.Run("should filter attempts", func(t *testing.T) {
+		result, err := dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1, Receiver: "team-a", Integration: "slack"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		result, err = dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1, AlertFingerprint: "bbb"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a/email", "team-a/slack", "team-a/slack"}, receivers(result))
+
+		result, err = dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1, Success: util.Pointer(false)})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "webhook response status 500 Internal Server Error", result[0].Error)
+
+		result, err = dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now.Add(-time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-b/webhook", "team-a/slack"}, receivers(result))
+
+		result, err = dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a/email"}, receivers(result))
+	})
+
+	t.Run("should delete attempts older than the retention", func(t *testing.T) {
+		dbstore.Cfg.NotificationLog.Retention = 90 * time.Minute
+		deleted, err := dbstore.DeleteExpiredNotificationDeliveries(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		result, err := dbstore.ListNotificationDeliveries(ctx, models.ListNotificationDeliveriesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"team-a/email", "team-b/webhook"}, receivers(result))
+	})
+
+	t.Run("should not delete attempts if retention is disabled", func(t *testing.T) {
+		dbstore.Cfg.NotificationLog.Retention = 0
+		deleted, err := dbstore.DeleteExpiredNotificationDeliveries(ctx)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// GetScheduledSilences returns the scheduled silences of the organization.
+func (st DBstore) GetScheduledSilences(ctx context.Context, orgID int64) ([]models.ScheduledSilence, error) {
+	var result []models.ScheduledSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+// GetAllScheduledSilences returns the scheduled silences of all organizations.
+func (st DBstore) GetAllScheduledSilences(ctx context.Context) ([]models.ScheduledSilence, error) {
+	var result []models.ScheduledSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("org_id", "id").Find(&result)
+	})
+	return result, err
+}
+
+// GetScheduledSilence returns the scheduled silence with the given UID or models.ErrScheduledSilenceNotFound.
+func (st DBstore) GetScheduledSilence(ctx context.Context, orgID int64, uid string) (models.ScheduledSilence, error) {
+	var result models.ScheduledSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrScheduledSilenceNotFound
+		}
+		return nil
+	})
+	return result, err
+}
+
+// InsertScheduledSilence inserts the scheduled silence and sets its ID.
+func (st DBstore) InsertScheduledSilence(ctx context.Context, s *models.ScheduledSilence) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		s.Updated = time.Now()
+		_, err := sess.Insert(s)
+		return err
+	})
+}
+
+// UpdateScheduledSilence updates the scheduled silence with the same UID.
+func (st DBstore) UpdateScheduledSilence(ctx context.Context, s models.ScheduledSilence) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		s.Updated = time.Now()
+		affected, err := sess.Where("org_id = ? AND uid = ?", s.OrgID, s.UID).
+			Cols("matchers", "comment", "created_by", "starts_at", "ends_at", "frequency", "repeat_interval", "repeat_until", "time_zone", "silence_id", "window_start", "updated").
+			Update(&s)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrScheduledSilenceNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteScheduledSilence deletes the scheduled silence. It does not fail if the silence does not exist.
+func (st DBstore) DeleteScheduledSilence(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.ScheduledSilence{})
+		return err
+	})
+}
+
+// ClaimScheduledSilenceWindow claims the window that starts at windowStart, if the last claimed window is still
+// prevWindowStart. Returns false if another instance has already claimed the window.
+func (st DBstore) ClaimScheduledSilenceWindow(ctx context.Context, orgID int64, uid string, prevWindowStart, windowStart int64) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE alert_scheduled_silence SET window_start = ? WHERE org_id = ? AND uid = ? AND window_start = ?",
+			windowStart, orgID, uid, prevWindowStart)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected > 0
+		return err
+	})
+	return claimed, err
+}
+
+// ReleaseScheduledSilenceWindow gives up the claim of the window that starts at windowStart, so that its silence is
+// created again at the next sync.
+func (st DBstore) ReleaseScheduledSilenceWindow(ctx context.Context, orgID int64, uid string, windowStart, prevWindowStart int64) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE alert_scheduled_silence SET window_start = ? WHERE org_id = ? AND uid = ? AND window_start = ?",
+			prevWindowStart, orgID, uid, windowStart)
+		return err
+	})
+}
+
+// SetScheduledSilenceWindowSilence records the silence created for the claimed window that starts at windowStart.
+func (st DBstore) SetScheduledSilenceWindowSilence(ctx context.Context, orgID int64, uid string, windowStart int64, silenceID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE alert_scheduled_silence SET silence_id = ? WHERE org_id = ? AND uid = ? AND window_start = ?",
+			silenceID, orgID, uid, windowStart)
+		return err
+	})
+}
+
+// InsertSilenceAuditEntry records a change of a silence in the audit trail.
+func (st DBstore) InsertSilenceAuditEntry(ctx context.Context, entry models.SilenceAuditEntry) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if entry.Created.IsZero() {
+			entry.Created = time.Now()
+		}
+		_, err := sess.Insert(&entry)
+		return err
+	})
+}
+
+// ListSilenceAuditEntries returns the audit trail of silences, the most recent entries first.
+func (st DBstore) ListSilenceAuditEntries(ctx context.Context, query models.ListSilenceAuditEntriesQuery) ([]models.SilenceAuditEntry, error) {
+	var result []models.SilenceAuditEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.SilenceID != "" {
+			q = q.And("silence_id = ?", query.SilenceID)
+		}
+		if query.ScheduledSilenceUID != "" {
+			q = q.And("scheduled_silence_uid = ?", query.ScheduledSilenceUID)
+		}
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Desc("created", "id").Find(&result)
+	})
+	return result, err
+}
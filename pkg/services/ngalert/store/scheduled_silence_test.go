@@ -0,0 +1,167 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationScheduledSilences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	start := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+	until := start.AddDate(1, 0, 0)
+	silence := models.ScheduledSilence{
+		UID:         "maintenance",
+		OrgID:       1,
+		Matchers:    []models.SilenceMatcher{{Name: "env", Value: "prod", IsEqual: true}},
+		Comment:     "weekly maintenance",
+		CreatedBy:   "admin",
+		StartsAt:    start,
+		EndsAt:      start.Add(2 * time.Hour),
+		Frequency:   models.ScheduledSilenceWeekly,
+		Interval:    1,
+		RepeatUntil: &until,
+		TimeZone:    "UTC",
+	}
+	require.NoError(t, dbstore.InsertScheduledSilence(ctx, &silence))
+	require.NotZero(t, silence.ID)
+
+	other := silence
+	other.ID = 0
+	other.OrgID = 2
+	require.NoError(t, dbstore.InsertScheduledSilence(ctx, &other))
+
+	t.Run("should get scheduled silence by UID", func(t *testing.T) {
+		result, err := dbstore.GetScheduledSilence(ctx, 1, "maintenance")
+		require.NoError(t, err)
+		require.Equal(t, silence.Matchers, result.Matchers)
+		require.Equal(t, models.ScheduledSilenceWeekly, result.Frequency)
+		require.True(t, start.Equal(result.StartsAt))
+		require.NotNil(t, result.RepeatUntil)
+		require.True(t, until.Equal(*result.RepeatUntil))
+
+		_, err = dbstore.GetScheduledSilence(ctx, 1, "unknown")
+		require.ErrorIs(t, err, models.ErrScheduledSilenceNotFound)
+	})
+
+	t.Run("should list scheduled silences", func(t *testing.T) {
+		result, err := dbstore.GetScheduledSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		result, err = dbstore.GetAllScheduledSilences(ctx)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+	})
+
+	t.Run("should claim a window only once", func(t *testing.T) {
+		claimed, err := dbstore.ClaimScheduledSilenceWindow(ctx, 1, "maintenance", 0, start.Unix())
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = dbstore.ClaimScheduledSilenceWindow(ctx, 1, "maintenance", 0, start.Unix())
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		require.NoError(t, dbstore.SetScheduledSilenceWindowSilence(ctx, 1, "maintenance", start.Unix(), "silence-1"))
+		result, err := dbstore.GetScheduledSilence(ctx, 1, "maintenance")
+		require.NoError(t, err)
+		require.Equal(t, "silence-1", result.SilenceID)
+		require.Equal(t, start.Unix(), result.WindowStart)
+	})
+
+	t.Run("should claim a released window again", func(t *testing.T) {
+		next := start.AddDate(0, 0, 7).Unix()
+		claimed, err := dbstore.ClaimScheduledSilenceWindow(ctx, 1, "maintenance", start.Unix(), next)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		require.NoError(t, dbstore.ReleaseScheduledSilenceWindow(ctx, 1, "maintenance", next, start.Unix()))
+		result, err := dbstore.GetScheduledSilence(ctx, 1, "maintenance")
+		require.NoError(t, err)
+		require.Equal(t, start.Unix(), result.WindowStart)
+		require.Equal(t, "silence-1", result.SilenceID)
+
+		claimed, err = dbstore.ClaimScheduledSilenceWindow(ctx, 1, "maintenance", start.Unix(), next)
+		require.NoError(t, err)
+		require.True(t, claimed)
+	})
+
+	t.Run("should update scheduled silence", func(t *testing.T) {
+		updated := silence
+		updated.Comment = "changed"
+		updated.RepeatUntil = nil
+		require.NoError(t, dbstore.UpdateScheduledSilence(ctx, updated))
+
+		result, err := dbstore.GetScheduledSilence(ctx, 1, "maintenance")
+		require.NoError(t, err)
+		require.Equal(t, "changed", result.Comment)
+		require.Nil(t, result.RepeatUntil)
+
+		updated.UID = "unknown"
+		require.ErrorIs(t, dbstore.UpdateScheduledSilence(ctx, updated), models.ErrScheduledSilenceNotFound)
+	})
+
+	t.Run("should delete scheduled silence", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteScheduledSilence(ctx, 1, "maintenance"))
+		_, err := dbstore.GetScheduledSilence(ctx, 1, "maintenance")
+		require.ErrorIs(t, err, models.ErrScheduledSilenceNotFound)
+
+		_, err = dbstore.GetScheduledSilence(ctx, 2, "maintenance")
+		require.NoError(t, err)
+	})
+}
+
+func TestIntegrationSilenceAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	entries := []models.SilenceAuditEntry{
+		{OrgID: 1, SilenceID: "a", Action: models.SilenceAuditActionCreate, User: "admin", Created: now.Add(-2 * time.Minute)},
+		{OrgID: 1, SilenceID: "b", Action: models.SilenceAuditActionCreate, User: "admin", ScheduledSilenceUID: "maintenance", Created: now.Add(-time.Minute)},
+		{OrgID: 1, SilenceID: "a", Action: models.SilenceAuditActionExpire, User: "editor", Created: now},
+		{OrgID: 2, SilenceID: "c", Action: models.SilenceAuditActionCreate, User: "admin", Created: now},
+	}
+	for _, e := range entries {
+		require.NoError(t, dbstore.InsertSilenceAuditEntry(ctx, e))
+	}
+
+	t.Run("should return entries of the organization, most recent first", func(t *testing.T) {
+		result, err := dbstore.ListSilenceAuditEntries(ctx, models.ListSilenceAuditEntriesQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, models.SilenceAuditActionExpire, result[0].Action)
+		require.Equal(t, "editor", result[0].User)
+		require.Equal(t, "maintenance", result[1].ScheduledSilenceUID)
+	})
+
+	t.Run("should filter entries", func(t *testing.T) {
+		result, err := dbstore.ListSilenceAuditEntries(ctx, models.ListSilenceAuditEntriesQuery{OrgID: 1, SilenceID: "a"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		result, err = dbstore.ListSilenceAuditEntries(ctx, models.ListSilenceAuditEntriesQuery{OrgID: 1, ScheduledSilenceUID: "maintenance"})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		result, err = dbstore.ListSilenceAuditEntries(ctx, models.ListSilenceAuditEntriesQuery{OrgID: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+	})
+}
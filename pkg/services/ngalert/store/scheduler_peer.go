@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// HeartbeatSchedulerPeer records that the instance of the scheduler is alive at the given time.
+func (st DBstore) HeartbeatSchedulerPeer(ctx context.Context, instanceID string, at time.Time) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
+			"alert_scheduler_peer",
+			[]string{"instance_id"},
+			[]string{"instance_id", "last_seen"})
+		_, err := sess.SQL(upsertSQL, instanceID, at.Unix()).Query()
+		return err
+	})
+}
+
+// GetSchedulerPeers returns the sorted IDs of the scheduler instances that sent a heartbeat since the given time.
+func (st DBstore) GetSchedulerPeers(ctx context.Context, activeSince time.Time) ([]string, error) {
+	var peers []models.SchedulerPeer
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("last_seen >= ?", activeSince.Unix()).Find(&peers)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(peers))
+	for _, p := range peers {
+		result = append(result, p.InstanceID)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// DeleteSchedulerPeer removes the scheduler instance, for example, when it shuts down.
+func (st DBstore) DeleteSchedulerPeer(ctx context.Context, instanceID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("instance_id = ?", instanceID).Delete(&models.SchedulerPeer{})
+		return err
+	})
+}
+
+// DeleteStaleSchedulerPeers removes the scheduler instances that have not sent a heartbeat since the given time, for
+// example, because they crashed. It returns the number of deleted instances.
+func (st DBstore) DeleteStaleSchedulerPeers(ctx context.Context, lastSeenBefore time.Time) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		n, err := sess.Where("last_seen < ?", lastSeenBefore.Unix()).Delete(&models.SchedulerPeer{})
+		deleted = n
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationSchedulerPeers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	require.NoError(t, dbstore.HeartbeatSchedulerPeer(ctx, "b", now.Add(-time.Minute)))
+	require.NoError(t, dbstore.HeartbeatSchedulerPeer(ctx, "c", now.Add(-time.Hour)))
+	require.NoError(t, dbstore.HeartbeatSchedulerPeer(ctx, "a", now))
+
+	peers, err := dbstore.GetSchedulerPeers(ctx, now.Add(-2*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, peers)
+
+	t.Run("heartbeat should update the peer", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerPeer(ctx, "c", now))
+
+		peers, err := dbstore.GetSchedulerPeers(ctx, now.Add(-2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, peers)
+	})
+
+	t.Run("deleted peer should not be returned", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSchedulerPeer(ctx, "b"))
+
+		peers, err := dbstore.GetSchedulerPeers(ctx, now.Add(-2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "c"}, peers)
+	})
+
+	t.Run("stale peers should be deleted", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerPeer(ctx, "d", now.Add(-time.Hour)))
+
+		deleted, err := dbstore.DeleteStaleSchedulerPeers(ctx, now.Add(-2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		peers, err := dbstore.GetSchedulerPeers(ctx, time.Unix(0, 0))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "c"}, peers)
+	})
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// stateHistoryInsertBatchSize is the maximum number of state history entries inserted by a single statement.
+	stateHistoryInsertBatchSize = 100
+	// stateHistoryDeleteBatchSize is the maximum number of state history entries deleted by a single statement.
+	stateHistoryDeleteBatchSize = 1000
+	// stateHistoryQueryPageSize is the maximum number of state history entries read by a single statement when the
+	// entries are filtered by labels.
+	stateHistoryQueryPageSize = 1000
+)
+
+// StateHistoryStore is the database interface of the database state history backend.
+type StateHistoryStore interface {
+	InsertStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	// QueryStateHistory returns the entries that match the query ordered by transition time. If the query has a limit,
+	// the most recent entries are returned.
+	QueryStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error)
+}
+
+func (st DBstore) InsertStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(entries); start += stateHistoryInsertBatchSize {
+			end := start + stateHistoryInsertBatchSize
+			if end > len(entries) {
+				end = len(entries)
+			}
+			batch := entries[start:end]
+			if _, err := sess.Table(&models.StateHistoryEntry{}).InsertMulti(&batch); err != nil {
+				return fmt.Errorf("failed to insert state history: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) QueryStateHistory(ctx context.Context, query models.HistoryQuery) ([]models.StateHistoryEntry, error) {
+	var result []models.StateHistoryEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		newQuery := func() *xorm.Session {
+			q := sess.Table(&models.StateHistoryEntry{}).Where("org_id = ?", query.OrgID)
+			if query.RuleUID != "" {
+				q = q.And("rule_uid = ?", query.RuleUID)
+			}
+			if query.DashboardUID != "" {
+				q = q.And("dashboard_uid = ?", query.DashboardUID)
+			}
+			if query.PanelID != 0 {
+				q = q.And("panel_id = ?", query.PanelID)
+			}
+			if !query.From.IsZero() {
+				q = q.And("transition_time >= ?", query.From.UnixMilli())
+			}
+			if !query.To.IsZero() {
+				q = q.And("transition_time <= ?", query.To.UnixMilli())
+			}
+			return q.Desc("transition_time", "id")
+		}
+
+		if len(query.Labels) == 0 {
+			q := newQuery()
+			if query.Limit > 0 {
+				q = q.Limit(query.Limit)
+			}
+			return q.Find(&result)
+		}
+
+		// Labels are stored as JSON and matched after the query, so the entries are read in pages until the limit
+		// is reached. Each page starts after the last entry of the previous page.
+		var last *models.StateHistoryEntry
+		for {
+			q := newQuery()
+			if last != nil {
+				q = q.And("(transition_time < ? OR (transition_time = ? AND id < ?))", last.TransitionTime, last.TransitionTime, last.ID)
+			}
+			var page []models.StateHistoryEntry
+			if err := q.Limit(stateHistoryQueryPageSize).Find(&page); err != nil {
+				return err
+			}
+			for _, entry := range page {
+				matches, err := stateHistoryLabelsMatch(entry.Labels, query.Labels)
+				if err != nil {
+					st.Logger.Warn("Skipping state history entry with invalid labels", "id", entry.ID, "error", err)
+					continue
+				}
+				if !matches {
+					continue
+				}
+				result = append(result, entry)
+				if query.Limit > 0 && len(result) >= query.Limit {
+					return nil
+				}
+			}
+			if len(page) < stateHistoryQueryPageSize {
+				return nil
+			}
+			last = &page[len(page)-1]
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+
+	// Entries are fetched newest first, so the limit keeps the most recent ones.
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
+
+// DeleteExpiredStateHistory deletes the state history entries that are older than the retention of the database
+// state history backend. It returns the number of deleted entries or an error.
+func (st DBstore) DeleteExpiredStateHistory(ctx context.Context) (int64, error) {
+	retention := st.Cfg.StateHistory.DatabaseRetention
+	if retention <= 0 {
+		return 0, nil
+	}
+	before := TimeNow().Add(-retention).UnixMilli()
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var deleted int64
+		err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			var ids []int64
+			if err := sess.Table(&models.StateHistoryEntry{}).Where("transition_time < ?", before).Limit(stateHistoryDeleteBatchSize).Cols("id").Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			n, err := sess.In("id", ids).Delete(&models.StateHistoryEntry{})
+			deleted = n
+			return err
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to delete expired state history: %w", err)
+		}
+		total += deleted
+		if deleted < stateHistoryDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func stateHistoryLabelsMatch(encoded string, matchers map[string]string) (bool, error) {
+	var lbls map[string]string
+	if err := json.Unmarshal([]byte(encoded), &lbls); err != nil {
+		return false, err
+	}
+	for k, v := range matchers {
+		if lbls[k] != v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
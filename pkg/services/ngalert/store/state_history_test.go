@@ -0,0 +1,134 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Millisecond)
+	entry := func(orgID int64, ruleUID string, labels string, at time.Time) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:          orgID,
+			RuleUID:        ruleUID,
+			NamespaceUID:   "folder",
+			RuleGroup:      "group",
+			Labels:         labels,
+			LabelsHash:     "hash",
+			PreviousState:  "Normal",
+			CurrentState:   "Alerting",
+			TransitionTime: at.UnixMilli(),
+		}
+	}
+	entries := []models.StateHistoryEntry{
+		entry(1, "rule-1", `{"instance":"a"}`, now.Add(-3*time.Hour)),
+		entry(1, "rule-1", `{"instance":"b"}`, now.Add(-2*time.Hour)),
+		entry(1, "rule-2", `{"instance":"a"}`, now.Add(-time.Hour)),
+		entry(1, "rule-1", `{"instance":"a"}`, now),
+		entry(2, "rule-1", `{"instance":"a"}`, now),
+	}
+	require.NoError(t, dbstore.InsertStateHistory(ctx, entries))
+
+	ruleUIDs := func(result []models.StateHistoryEntry) []string {
+		uids := make([]string, 0, len(result))
+		for _, e := range result {
+			uids = append(uids, e.RuleUID)
+		}
+		return uids
+	}
+
+	t.Run("should return entries of the organization ordered by time", func(t *testing.T) {
+		result, err := dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 4)
+		require.Equal(t, []string{"rule-1", "rule-1", "rule-2", "rule-1"}, ruleUIDs(result))
+		require.Equal(t, now.Add(-3*time.Hour).UnixMilli(), result[0].TransitionTime)
+		require.Equal(t, entries[0].Labels, result[0].Labels)
+	})
+
+	t.Run("should filter by rule UID, labels and time range", func(t *testing.T) {
+		result, err := dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-1"})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+
+		result, err = dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 1, Labels: map[string]string{"instance": "a"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"rule-1", "rule-2", "rule-1"}, ruleUIDs(result))
+
+		result, err = dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now.Add(-time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, `{"instance":"b"}`, result[0].Labels)
+		require.Equal(t, "rule-2", result[1].RuleUID)
+	})
+
+	t.Run("should return the most recent entries if there is a limit", func(t *testing.T) {
+		result, err := dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 1, RuleUID: "rule-1", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, now.Add(-2*time.Hour).UnixMilli(), result[0].TransitionTime)
+		require.Equal(t, now.UnixMilli(), result[1].TransitionTime)
+	})
+
+	t.Run("should read entries in pages if there are label matchers", func(t *testing.T) {
+		// more entries than fit in a single page, the matching entries are the oldest ones
+		many := make([]models.StateHistoryEntry, 0, 2500)
+		for i := 0; i < 2500; i++ {
+			labels := `{"instance":"other"}`
+			if i < 3 {
+				labels = `{"instance":"paged"}`
+			}
+			many = append(many, entry(3, "rule-3", labels, now.Add(-time.Hour+time.Duration(i)*time.Millisecond)))
+		}
+		require.NoError(t, dbstore.InsertStateHistory(ctx, many))
+
+		result, err := dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 3, RuleUID: "rule-3", Labels: map[string]string{"instance": "paged"}})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		for i, e := range result {
+			require.Equal(t, many[i].TransitionTime, e.TransitionTime)
+		}
+
+		result, err = dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 3, RuleUID: "rule-3", Labels: map[string]string{"instance": "paged"}, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, many[1].TransitionTime, result[0].TransitionTime)
+		require.Equal(t, many[2].TransitionTime, result[1].TransitionTime)
+
+		result, err = dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 3, RuleUID: "rule-3", Labels: map[string]string{"instance": "other"}, Limit: 1500})
+		require.NoError(t, err)
+		require.Len(t, result, 1500)
+		require.Equal(t, many[len(many)-1].TransitionTime, result[len(result)-1].TransitionTime)
+	})
+
+	t.Run("should delete entries older than the retention", func(t *testing.T) {
+		dbstore.Cfg.StateHistory.DatabaseRetention = 90 * time.Minute
+		deleted, err := dbstore.DeleteExpiredStateHistory(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		result, err := dbstore.QueryStateHistory(ctx, models.HistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"rule-2", "rule-1"}, ruleUIDs(result))
+	})
+
+	t.Run("should not delete entries if retention is disabled", func(t *testing.T) {
+		dbstore.Cfg.StateHistory.DatabaseRetention = 0
+		deleted, err := dbstore.DeleteExpiredStateHistory(ctx)
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+}
@@ -0,0 +1,164 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ErrUnsupportedFrame is returned when a recording rule result cannot be converted to samples.
+var ErrUnsupportedFrame = errors.New("recording rule result must be numbers or series with a single point, use a Reduce expression to convert series")
+
+type PrometheusWriterConfig struct {
+	URL               *url.URL
+	BasicAuthUser     string
+	BasicAuthPassword string
+	Timeout           time.Duration
+	CustomHeaders     map[string]string
+}
+
+func NewPrometheusWriterConfig(cfg setting.RecordingRuleSettings) (PrometheusWriterConfig, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return PrometheusWriterConfig{}, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+	return PrometheusWriterConfig{
+		URL:               u,
+		BasicAuthUser:     cfg.BasicAuthUsername,
+		BasicAuthPassword: cfg.BasicAuthPassword,
+		Timeout:           cfg.Timeout,
+		CustomHeaders:     cfg.CustomHeaders,
+	}, nil
+}
+
+// PrometheusWriter writes recording rule results to a Prometheus remote write endpoint.
+type PrometheusWriter struct {
+	client *http.Client
+	cfg    PrometheusWriterConfig
+	log    log.Logger
+}
+
+func NewPrometheusWriter(cfg PrometheusWriterConfig, logger log.Logger) *PrometheusWriter {
+	return &PrometheusWriter{
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		log:    logger,
+	}
+}
+
+func (w *PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	series, err := TimeSeriesFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(series) == 0 {
+		w.log.FromContext(ctx).Debug("No series to write", "metric", name)
+		return nil
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to encode series: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range w.cfg.CustomHeaders {
+		req.Header.Set(k, v)
+	}
+	if w.cfg.BasicAuthUser != "" || w.cfg.BasicAuthPassword != "" {
+		req.SetBasicAuth(w.cfg.BasicAuthUser, w.cfg.BasicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write endpoint returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	w.log.FromContext(ctx).Debug("Wrote recording rule result", "metric", name, "series", len(series))
+	return nil
+}
+
+// TimeSeriesFromFrames converts the numeric fields of the frames to one sample per series at time t.
+// Only frames with a single row, such as numbers or instant series, are accepted.
+func TimeSeriesFromFrames(name string, t time.Time, frames data.Frames, extraLabels map[string]string) ([]prompb.TimeSeries, error) {
+	ts := t.UnixMilli()
+	result := make([]prompb.TimeSeries, 0, len(frames))
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		rows, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		if rows == 0 {
+			continue
+		}
+		if rows > 1 {
+			return nil, ErrUnsupportedFrame
+		}
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			v, err := field.NullableFloatAt(0)
+			if err != nil || v == nil {
+				continue
+			}
+			result = append(result, prompb.TimeSeries{
+				Labels:  seriesLabels(name, field.Labels, extraLabels),
+				Samples: []prompb.Sample{{Value: *v, Timestamp: ts}},
+			})
+		}
+	}
+	return result, nil
+}
+
+func seriesLabels(name string, fieldLabels data.Labels, extraLabels map[string]string) []prompb.Label {
+	merged := make(map[string]string, len(fieldLabels)+len(extraLabels)+1)
+	for k, v := range fieldLabels {
+		merged[k] = v
+	}
+	for k, v := range extraLabels {
+		merged[k] = v
+	}
+	merged["__name__"] = name
+
+	labels := make([]prompb.Label, 0, len(merged))
+	for k, v := range merged {
+		labels = append(labels, prompb.Label{Name: k, Value: v})
+	}
+	// Remote write requires labels to be sorted by name.
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
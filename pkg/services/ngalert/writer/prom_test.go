@@ -0,0 +1,106 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestTimeSeriesFromFrames(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("numbers are converted to samples with merged labels", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("A", data.Labels{"instance": "a", "job": "x"}, []*float64{ptr(1)})),
+			data.NewFrame("", data.NewField("A", data.Labels{"instance": "b"}, []float64{2})),
+		}
+
+		series, err := TimeSeriesFromFrames("metric", now, frames, map[string]string{"job": "recorded"})
+		require.NoError(t, err)
+		require.Len(t, series, 2)
+		require.Equal(t, []prompb.Label{
+			{Name: "__name__", Value: "metric"},
+			{Name: "instance", Value: "a"},
+			{Name: "job", Value: "recorded"},
+		}, series[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 1, Timestamp: now.UnixMilli()}}, series[0].Samples)
+	})
+
+	t.Run("null values are skipped", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("", data.NewField("A", nil, []*float64{nil}))}
+		series, err := TimeSeriesFromFrames("metric", now, frames, nil)
+		require.NoError(t, err)
+		require.Empty(t, series)
+	})
+
+	t.Run("series with several points are rejected", func(t *testing.T) {
+		frames := data.Frames{data.NewFrame("",
+			data.NewField("time", nil, []time.Time{now, now.Add(time.Second)}),
+			data.NewField("A", nil, []float64{1, 2}),
+		)}
+		_, err := TimeSeriesFromFrames("metric", now, frames, nil)
+		require.ErrorIs(t, err, ErrUnsupportedFrame)
+	})
+}
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	var received prompb.WriteRequest
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, b)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(decoded, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	w := NewPrometheusWriter(PrometheusWriterConfig{
+		URL:           u,
+		BasicAuthUser: "user",
+		Timeout:       time.Second,
+		CustomHeaders: map[string]string{"X-Scope-OrgID": "tenant"},
+	}, log.NewNopLogger())
+
+	frames := data.Frames{data.NewFrame("", data.NewField("A", nil, []float64{42}))}
+	require.NoError(t, w.Write(context.Background(), "metric", time.Unix(1, 0), frames, nil))
+
+	require.Len(t, received.Timeseries, 1)
+	require.Equal(t, 42.0, received.Timeseries[0].Samples[0].Value)
+	require.Equal(t, "tenant", headers.Get("X-Scope-OrgID"))
+	user, _, ok := (&http.Request{Header: headers}).BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "user", user)
+
+	t.Run("non-2xx responses are returned as errors", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "out of order sample", http.StatusBadRequest)
+		}))
+		t.Cleanup(failing.Close)
+		u, err := url.Parse(failing.URL)
+		require.NoError(t, err)
+		w := NewPrometheusWriter(PrometheusWriterConfig{URL: u}, log.NewNopLogger())
+		err = w.Write(context.Background(), "metric", time.Unix(1, 0), frames, nil)
+		require.ErrorContains(t, err, "out of order sample")
+	})
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
@@ -57,7 +57,7 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 					return err
 				}
 			}
-			err = prov.ruleService.UpdateRuleGroup(ctx, group.OrgID, folderUID, group.Title, group.Interval)
+			err = prov.ruleService.UpdateRuleGroup(ctx, group.OrgID, folderUID, group.Title, group.Interval, group.SequentialEvaluation)
 			if err != nil {
 				return err
 			}
@@ -24,11 +24,12 @@ type RuleDeleteV1 struct {
 }
 
 type AlertRuleGroupV1 struct {
-	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
-	Name     values.StringValue `json:"name" yaml:"name"`
-	Folder   values.StringValue `json:"folder" yaml:"folder"`
-	Interval values.StringValue `json:"interval" yaml:"interval"`
-	Rules    []AlertRuleV1      `json:"rules" yaml:"rules"`
+	OrgID                values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name                 values.StringValue `json:"name" yaml:"name"`
+	Folder               values.StringValue `json:"folder" yaml:"folder"`
+	Interval             values.StringValue `json:"interval" yaml:"interval"`
+	SequentialEvaluation values.BoolValue   `json:"sequentialEvaluation" yaml:"sequentialEvaluation"`
+	Rules                []AlertRuleV1      `json:"rules" yaml:"rules"`
 }
 
 func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (models.AlertRuleGroupWithFolderTitle, error) {
@@ -46,6 +47,7 @@ func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (models.AlertRuleGroupWithFold
 		return models.AlertRuleGroupWithFolderTitle{}, err
 	}
 	ruleGroup.Interval = int64(time.Duration(interval).Seconds())
+	ruleGroup.SequentialEvaluation = ruleGroupV1.SequentialEvaluation.Value()
 	ruleGroup.FolderTitle = ruleGroupV1.Folder.Value()
 	if strings.TrimSpace(ruleGroup.FolderTitle) == "" {
 		return models.AlertRuleGroupWithFolderTitle{}, errors.New("rule group has no folder set")
@@ -55,24 +57,76 @@ func (ruleGroupV1 *AlertRuleGroupV1) MapToModel() (models.AlertRuleGroupWithFold
 		if err != nil {
 			return models.AlertRuleGroupWithFolderTitle{}, err
 		}
+		rule.SequentialEvaluation = ruleGroup.SequentialEvaluation
 		ruleGroup.Rules = append(ruleGroup.Rules, rule)
 	}
 	return ruleGroup, nil
 }
 
 type AlertRuleV1 struct {
-	UID          values.StringValue    `json:"uid" yaml:"uid"`
-	Title        values.StringValue    `json:"title" yaml:"title"`
-	Condition    values.StringValue    `json:"condition" yaml:"condition"`
-	Data         []QueryV1             `json:"data" yaml:"data"`
-	DashboardUID values.StringValue    `json:"dasboardUid" yaml:"dashboardUid"`
-	PanelID      values.Int64Value     `json:"panelId" yaml:"panelId"`
-	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
-	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
-	For          values.StringValue    `json:"for" yaml:"for"`
-	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
-	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
-	IsPaused     values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+	UID           values.StringValue    `json:"uid" yaml:"uid"`
+	Title         values.StringValue    `json:"title" yaml:"title"`
+	Condition     values.StringValue    `json:"condition" yaml:"condition"`
+	Data          []QueryV1             `json:"data" yaml:"data"`
+	DashboardUID  values.StringValue    `json:"dasboardUid" yaml:"dashboardUid"`
+	PanelID       values.Int64Value     `json:"panelId" yaml:"panelId"`
+	NoDataState   values.StringValue    `json:"noDataState" yaml:"noDataState"`
+	ExecErrState  values.StringValue    `json:"execErrState" yaml:"execErrState"`
+	For           values.StringValue    `json:"for" yaml:"for"`
+	KeepFiringFor values.StringValue    `json:"keepFiringFor" yaml:"keepFiringFor"`
+	Annotations   values.StringMapValue `json:"annotations" yaml:"annotations"`
+	Labels        values.StringMapValue `json:"labels" yaml:"labels"`
+	IsPaused      values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+	Record        *RecordV1             `json:"record" yaml:"record"`
+
+	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
+}
+
+type RecordV1 struct {
+	Metric values.StringValue `json:"metric" yaml:"metric"`
+	From   values.StringValue `json:"from" yaml:"from"`
+}
+
+type NotificationSettingsV1 struct {
+	Receiver          values.StringValue   `json:"receiver" yaml:"receiver"`
+	GroupBy           []values.StringValue `json:"group_by" yaml:"group_by"`
+	GroupWait         values.StringValue   `json:"group_wait" yaml:"group_wait"`
+	GroupInterval     values.StringValue   `json:"group_interval" yaml:"group_interval"`
+	RepeatInterval    values.StringValue   `json:"repeat_interval" yaml:"repeat_interval"`
+	MuteTimeIntervals []values.StringValue `json:"mute_time_intervals" yaml:"mute_time_intervals"`
+}
+
+func (nsV1 *NotificationSettingsV1) mapToModel() (models.NotificationSettings, error) {
+	parseDuration := func(v values.StringValue) (*model.Duration, error) {
+		if v.Value() == "" {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(v.Value())
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	var err error
+	ns := models.NotificationSettings{
+		Receiver: nsV1.Receiver.Value(),
+	}
+	if ns.GroupWait, err = parseDuration(nsV1.GroupWait); err != nil {
+		return models.NotificationSettings{}, fmt.Errorf("failed to parse group_wait: %w", err)
+	}
+	if ns.GroupInterval, err = parseDuration(nsV1.GroupInterval); err != nil {
+		return models.NotificationSettings{}, fmt.Errorf("failed to parse group_interval: %w", err)
+	}
+	if ns.RepeatInterval, err = parseDuration(nsV1.RepeatInterval); err != nil {
+		return models.NotificationSettings{}, fmt.Errorf("failed to parse repeat_interval: %w", err)
+	}
+	for _, l := range nsV1.GroupBy {
+		ns.GroupBy = append(ns.GroupBy, l.Value())
+	}
+	for _, mt := range nsV1.MuteTimeIntervals {
+		ns.MuteTimeIntervals = append(ns.MuteTimeIntervals, mt.Value())
+	}
+	return ns, nil
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -91,6 +145,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
 	}
 	alertRule.For = time.Duration(duration)
+	if keepFiringFor := rule.KeepFiringFor.Value(); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -114,9 +175,22 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.NoDataState = noDataState
 	alertRule.Condition = rule.Condition.Value()
-	if alertRule.Condition == "" {
+	if rule.Record != nil {
+		alertRule.Record = &models.Record{
+			Metric: rule.Record.Metric.Value(),
+			From:   rule.Record.From.Value(),
+		}
+	}
+	if alertRule.Condition == "" && alertRule.Record == nil {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
+	if rule.NotificationSettings != nil {
+		ns, err := rule.NotificationSettings.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.NotificationSettings = &ns
+	}
 	alertRule.Annotations = rule.Annotations.Raw
 	alertRule.Labels = rule.Labels.Value()
 	for _, queryV1 := range rule.Data {
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -80,6 +81,18 @@ func TestRuleGroup(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(1), rgMapped.OrgID)
 	})
+	t.Run("a sequential rule group should make all rules sequential", func(t *testing.T) {
+		rg := validRuleGroupV1(t)
+		rg.Rules = []AlertRuleV1{validRuleV1(t), validRuleV1(t)}
+		err := yaml.Unmarshal([]byte("true"), &rg.SequentialEvaluation)
+		require.NoError(t, err)
+		rgMapped, err := rg.MapToModel()
+		require.NoError(t, err)
+		require.True(t, rgMapped.SequentialEvaluation)
+		for _, rule := range rgMapped.Rules {
+			require.True(t, rule.SequentialEvaluation)
+		}
+	})
 }
 
 func TestRules(t *testing.T) {
@@ -125,12 +138,73 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with out a keep firing for duration should default to 0", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(0), ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with a keep firing for duration should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("5m"), &keepFiringFor)
+		require.NoError(t, err)
+		rule.KeepFiringFor = keepFiringFor
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with an invalid keep firing for duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("10x"), &keepFiringFor)
+		require.NoError(t, err)
+		rule.KeepFiringFor = keepFiringFor
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
 		_, err := rule.mapToModel(1)
 		require.Error(t, err)
 	})
+	t.Run("a recording rule with out a condition should map the record", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		var metric, from values.StringValue
+		require.NoError(t, yaml.Unmarshal([]byte("my_metric"), &metric))
+		require.NoError(t, yaml.Unmarshal([]byte("A"), &from))
+		rule.Record = &RecordV1{Metric: metric, From: from}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "my_metric", From: "A"}, ruleMapped.Record)
+	})
+	t.Run("a rule with notification settings should map them", func(t *testing.T) {
+		rule := validRuleV1(t)
+		settings := NotificationSettingsV1{}
+		err := yaml.Unmarshal([]byte("receiver: team-a\ngroup_by: [alertname]\ngroup_wait: 30s\nmute_time_intervals: [weekends]"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		groupWait := model.Duration(30 * time.Second)
+		require.Equal(t, &models.NotificationSettings{
+			Receiver:          "team-a",
+			GroupBy:           []string{"alertname"},
+			GroupWait:         &groupWait,
+			MuteTimeIntervals: []string{"weekends"},
+		}, ruleMapped.NotificationSettings)
+	})
+	t.Run("a rule with invalid notification settings durations should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		settings := NotificationSettingsV1{}
+		err := yaml.Unmarshal([]byte("receiver: team-a\nrepeat_interval: 10x"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		_, err = rule.mapToModel(1)
+		require.ErrorContains(t, err, "repeat_interval")
+	})
 	t.Run("a rule with out data should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Data = []QueryV1{}
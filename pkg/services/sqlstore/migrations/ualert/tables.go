@@ -24,6 +24,59 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("add last_applied column to alert_configuration_history", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_configuration_history"}, &migrator.Column{
 		Name: "last_applied", Type: migrator.DB_Int, Nullable: false, Default: "0",
 	}))
+	mg.AddMigration("add record column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name: "record", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add record column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name: "record", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add keep_firing_for column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name: "keep_firing_for", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add notification_settings column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name: "notification_settings", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add notification_settings column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name: "notification_settings", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	addAlertStateHistoryMigrations(mg)
+
+	mg.AddMigration("add updated_by column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name: "updated_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add created_by column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name: "created_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	addAlertSchedulerPeerMigrations(mg)
+
+	addScheduledSilenceMigrations(mg)
+
+	addAlertRuleStateMigrations(mg)
+
+	mg.AddMigration("add sequential_evaluation column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name: "sequential_evaluation", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add sequential_evaluation column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name: "sequential_evaluation", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	addNotificationDeliveryMigrations(mg)
+
+	mg.AddMigration("add keep_firing_since column to alert_instance", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name: "keep_firing_since", Type: migrator.DB_BigInt, Nullable: true,
+	}))
 	// End of migration log, add new migrations above this line.
 }
 
@@ -491,6 +544,41 @@ func addAlertImageMigrations(mg *migrator.Migrator) {
 		Mysql("ALTER TABLE alert_image MODIFY url VARCHAR(2048) NOT NULL;"))
 }
 
+func addAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "previous_reason", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "current_reason", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "result_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "condition", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "transition_time", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "transition_time"}},
+			{Cols: []string{"org_id", "transition_time"}},
+			{Cols: []string{"transition_time"}},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index on org_id, rule_uid and transition_time to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index on org_id and transition_time to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index on transition_time to alert_state_history table", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+}
+
 func extractAlertmanagerConfigurationHistoryMigration(mg *migrator.Migrator) {
 	// Since it's not always consistent as to what state the org ID indexes are in, just drop them all and rebuild from scratch.
 	// This is not expensive since this table is guaranteed to have a small number of rows.
@@ -565,3 +653,113 @@ func (c extractAlertmanagerConfigurationHistory) Exec(sess *xorm.Session, migrat
 	}
 	return nil
 }
+
+func addAlertSchedulerPeerMigrations(mg *migrator.Migrator) {
+	schedulerPeer := migrator.Table{
+		Name: "alert_scheduler_peer",
+		Columns: []*migrator.Column{
+			{Name: "instance_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, IsPrimaryKey: true},
+			{Name: "last_seen", Type: migrator.DB_BigInt, Nullable: false},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduler_peer table", migrator.NewAddTableMigration(schedulerPeer))
+}
+
+func addScheduledSilenceMigrations(mg *migrator.Migrator) {
+	scheduledSilence := migrator.Table{
+		Name: "alert_scheduled_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "starts_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "frequency", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "repeat_interval", Type: migrator.DB_Int, Nullable: false},
+			{Name: "repeat_until", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "time_zone", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "window_start", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_scheduled_silence table", migrator.NewAddTableMigration(scheduledSilence))
+	mg.AddMigration("add unique index on org_id and uid to alert_scheduled_silence table", migrator.NewAddIndexMigration(scheduledSilence, scheduledSilence.Indices[0]))
+
+	silenceAudit := migrator.Table{
+		Name: "alert_silence_audit",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "action", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "user_login", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "scheduled_silence_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "created"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_silence_audit table", migrator.NewAddTableMigration(silenceAudit))
+	mg.AddMigration("add index on org_id and created to alert_silence_audit table", migrator.NewAddIndexMigration(silenceAudit, silenceAudit.Indices[0]))
+}
+
+func addAlertRuleStateMigrations(mg *migrator.Migrator) {
+	ruleState := migrator.Table{
+		Name: "alert_rule_state",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "data", Type: migrator.DB_LongBlob, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_state table", migrator.NewAddTableMigration(ruleState))
+	mg.AddMigration("add unique index on org_id and rule_uid to alert_rule_state table", migrator.NewAddIndexMigration(ruleState, ruleState.Indices[0]))
+}
+
+func addNotificationDeliveryMigrations(mg *migrator.Migrator) {
+	delivery := migrator.Table{
+		Name: "alert_notification_delivery",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alert_fingerprints", Type: migrator.DB_Text, Nullable: false},
+			{Name: "payload_hash", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: false},
+			{Name: "retry", Type: migrator.DB_Int, Nullable: false},
+			{Name: "success", Type: migrator.DB_Bool, Nullable: false},
+			{Name: "attempted_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "attempted_at"}, Type: migrator.IndexType},
+			{Cols: []string{"attempted_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table", migrator.NewAddTableMigration(delivery))
+	mg.AddMigration("add index on org_id and attempted_at to alert_notification_delivery table", migrator.NewAddIndexMigration(delivery, delivery.Indices[0]))
+	mg.AddMigration("add index on attempted_at to alert_notification_delivery table", migrator.NewAddIndexMigration(delivery, delivery.Indices[1]))
+}
@@ -528,6 +528,8 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	Caching CachingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -1211,6 +1213,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.Caching = readCachingSettings(iniFile)
 
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
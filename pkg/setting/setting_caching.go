@@ -0,0 +1,32 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type CachingSettings struct {
+	// Enabled turns on the built-in query and resource cache.
+	Enabled bool
+	// TTL is the default time-to-live for cached query responses.
+	TTL time.Duration
+	// ResourcesTTL is the default time-to-live for cached resource responses.
+	ResourcesTTL time.Duration
+	// MaxTTL caps any per data source TTL override.
+	MaxTTL time.Duration
+	// MaxValueSize is the largest encoded response, in bytes, that is stored in the cache.
+	MaxValueSize int
+}
+
+func readCachingSettings(iniFile *ini.File) CachingSettings {
+	s := CachingSettings{}
+
+	cachingSection := iniFile.Section("caching")
+	s.Enabled = cachingSection.Key("enabled").MustBool(false)
+	s.TTL = cachingSection.Key("ttl").MustDuration(time.Minute)
+	s.ResourcesTTL = cachingSection.Key("resources_ttl").MustDuration(5 * time.Minute)
+	s.MaxTTL = cachingSection.Key("max_ttl").MustDuration(time.Hour)
+	s.MaxValueSize = cachingSection.Key("max_value_mb").MustInt(1) * 1024 * 1024
+	return s
+}
@@ -21,6 +21,7 @@ const (
 	alertmanagerDefaultPushPullInterval   = cluster.DefaultPushPullInterval
 	alertmanagerDefaultConfigPollInterval = time.Minute
 	alertmanagerRedisDefaultMaxConns      = 5
+	shardedEvaluationDefaultPeerTimeout   = 30 * time.Second
 	// To start, the alertmanager needs at least one route defined.
 	// TODO: we should move this to Grafana settings and define this as the default.
 	alertmanagerDefaultConfiguration = `{
@@ -61,6 +62,11 @@ const (
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
+	// stateHistoryDefaultDatabaseRetention is the default retention of the database state history backend.
+	stateHistoryDefaultDatabaseRetention = "30d"
+	// notificationLogDefaultRetention is the default retention of the notification delivery log.
+	notificationLogDefaultRetention = "7d"
+	recordingRulesDefaultTimeout    = 10 * time.Second
 )
 
 type UnifiedAlertingSettings struct {
@@ -80,6 +86,10 @@ type UnifiedAlertingSettings struct {
 	HARedisPassword                string
 	HARedisDB                      int
 	HARedisMaxConns                int
+	// HAShardedEvaluation enables sharding of alert rule evaluation across the instances of Grafana that share the database.
+	HAShardedEvaluation bool
+	// HAShardedEvaluationPeerTimeout is the time after which an instance that stopped sending heartbeats is considered gone.
+	HAShardedEvaluationPeerTimeout time.Duration
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -95,10 +105,15 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	NotificationLog               UnifiedAlertingNotificationLogSettings
+	EvaluationProfiling           UnifiedAlertingEvaluationProfilingSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	Upgrade                       UnifiedAlertingUpgradeSettings
+	RecordingRules                RecordingRuleSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency int
+	// CompactStatePersistence enables saving the state of all alert instances of a rule in a single row.
+	CompactStatePersistence bool
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -135,6 +150,35 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// DatabaseRetention is how long the state history is kept by the database backend.
+	// Entries are not deleted if it is zero.
+	DatabaseRetention time.Duration
+}
+
+// UnifiedAlertingNotificationLogSettings configures the log of the delivery attempts of contact points.
+type UnifiedAlertingNotificationLogSettings struct {
+	Enabled bool
+	// Retention is how long delivery attempts are kept. They are not deleted if it is zero.
+	Retention time.Duration
+}
+
+// UnifiedAlertingEvaluationProfilingSettings configures the collection of evaluation statistics of alert rules.
+type UnifiedAlertingEvaluationProfilingSettings struct {
+	Enabled bool
+	// MetricsTopRules is the number of the most expensive rules whose statistics are exported as metrics.
+	// No metrics are exported if it is zero.
+	MetricsTopRules int
+}
+
+// RecordingRuleSettings configures the evaluation of recording rules and the
+// Prometheus remote write endpoint their results are written to.
+type RecordingRuleSettings struct {
+	Enabled           bool
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+	CustomHeaders     map[string]string
 }
 
 type UnifiedAlertingUpgradeSettings struct {
@@ -259,6 +303,11 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisPassword = ua.Key("ha_redis_password").MustString("")
 	uaCfg.HARedisDB = ua.Key("ha_redis_db").MustInt(0)
 	uaCfg.HARedisMaxConns = ua.Key("ha_redis_max_conns").MustInt(alertmanagerRedisDefaultMaxConns)
+	uaCfg.HAShardedEvaluation = ua.Key("ha_sharded_evaluation").MustBool(false)
+	uaCfg.HAShardedEvaluationPeerTimeout, err = gtime.ParseDuration(valueAsString(ua, "ha_sharded_evaluation_peer_timeout", (shardedEvaluationDefaultPeerTimeout).String()))
+	if err != nil {
+		return err
+	}
 	peers := ua.Key("ha_peers").MustString("")
 	uaCfg.HAPeers = make([]string, 0)
 	if peers != "" {
@@ -401,9 +450,54 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	uaCfgStateHistory.DatabaseRetention, err = gtime.ParseDuration(valueAsString(stateHistory, "database_retention", stateHistoryDefaultDatabaseRetention))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'database_retention' as duration: %w", err)
+	}
+	if uaCfgStateHistory.DatabaseRetention < 0 {
+		return errors.New("value of setting 'database_retention' cannot be negative")
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	notificationLog := iniFile.Section("unified_alerting.notification_log")
+	uaCfgNotificationLog := UnifiedAlertingNotificationLogSettings{
+		Enabled: notificationLog.Key("enabled").MustBool(false),
+	}
+	uaCfgNotificationLog.Retention, err = gtime.ParseDuration(valueAsString(notificationLog, "retention", notificationLogDefaultRetention))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'retention' of [unified_alerting.notification_log] as duration: %w", err)
+	}
+	if uaCfgNotificationLog.Retention < 0 {
+		return errors.New("value of setting 'retention' of [unified_alerting.notification_log] cannot be negative")
+	}
+	uaCfg.NotificationLog = uaCfgNotificationLog
+
+	evaluationProfiling := iniFile.Section("unified_alerting.evaluation_profiling")
+	uaCfg.EvaluationProfiling = UnifiedAlertingEvaluationProfilingSettings{
+		Enabled:         evaluationProfiling.Key("enabled").MustBool(true),
+		MetricsTopRules: evaluationProfiling.Key("metrics_top_rules").MustInt(0),
+	}
+	if uaCfg.EvaluationProfiling.MetricsTopRules < 0 {
+		return errors.New("value of setting 'metrics_top_rules' of [unified_alerting.evaluation_profiling] cannot be negative")
+	}
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
+	uaCfg.CompactStatePersistence = ua.Key("compact_state_persistence").MustBool(false)
+
+	recordingRules := iniFile.Section("recording_rules")
+	recordingRulesHeaders := iniFile.Section("recording_rules.custom_headers")
+	uaCfgRecordingRules := RecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		Timeout:           recordingRules.Key("timeout").MustDuration(recordingRulesDefaultTimeout),
+		CustomHeaders:     recordingRulesHeaders.KeysHash(),
+	}
+	if uaCfgRecordingRules.Enabled && uaCfgRecordingRules.URL == "" {
+		return errors.New("a remote write URL is required in [recording_rules] when recording rules are enabled")
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
 
 	upgrade := iniFile.Section("unified_alerting.upgrade")
 	uaCfgUpgrade := UnifiedAlertingUpgradeSettings{
@@ -52,6 +52,8 @@ type Client interface {
 	GetConfiguredFields() ConfiguredFields
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	OpenPointInTime(keepAlive string) (string, error)
+	ExecuteEsql(r *EsqlRequest) (*EsqlResponse, error)
 }
 
 // NewClient creates a new elasticsearch client
@@ -243,6 +245,13 @@ func (c *baseClientImpl) createMultiSearchRequests(searchRequests []*SearchReque
 			body:     searchReq,
 			interval: searchReq.Interval,
 		}
+		// A search with a point in time searches the indices of the point in time, and fails if the indices or
+		// the options of the indices are given.
+		if searchReq.PointInTime != nil {
+			mr.header = map[string]any{
+				"search_type": "query_then_fetch",
+			}
+		}
 
 		multiRequests = append(multiRequests, &mr)
 	}
@@ -266,6 +275,101 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 	return strings.Join(qs, "&")
 }
 
+// OpenPointInTime opens a point in time on the indices of the client, which keeps the state of the indices for
+// keepAlive so that the pages of a search are consistent, and returns its ID.
+func (c *baseClientImpl) OpenPointInTime(keepAlive string) (string, error) {
+	var err error
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.openPointInTime", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	start := time.Now()
+	uriQuery := url.Values{
+		"keep_alive":         []string{keepAlive},
+		"ignore_unavailable": []string{"true"},
+	}
+	res, err := c.executeRequest(http.MethodPost, strings.Join(c.indices, ",")+"/_pit", uriQuery.Encode(), nil)
+	if err != nil {
+		c.logger.Error("Failed to open point in time", "error", err, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return "", err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	var pit PointInTimeResponse
+	if err = json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		c.logger.Error("Failed to decode point in time response from Elasticsearch", "error", err, "statusCode", res.StatusCode)
+		return "", err
+	}
+	if res.StatusCode/100 != 2 || pit.ID == "" {
+		err = fmt.Errorf("failed to open point in time, status: %d", res.StatusCode)
+		if reason, ok := pit.Error["reason"].(string); ok {
+			err = fmt.Errorf("failed to open point in time: %s", reason)
+		}
+		return "", err
+	}
+
+	c.logger.Debug("Opened point in time", "duration", time.Since(start), "stage", StageDatabaseRequest)
+	return pit.ID, nil
+}
+
+// ExecuteEsql executes an ES|QL query. The response of a query that Elasticsearch rejects contains the error.
+func (c *baseClientImpl) ExecuteEsql(r *EsqlRequest) (*EsqlResponse, error) {
+	var err error
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.executeEsql", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, "_query", "", body)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		c.logger.Error("Error received from Elasticsearch", "error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "status", "ok", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	var esqlRes EsqlResponse
+	if err = json.NewDecoder(res.Body).Decode(&esqlRes); err != nil {
+		c.logger.Error("Failed to decode ES|QL response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	esqlRes.Status = res.StatusCode
+
+	return &esqlRes, nil
+}
+
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder()
 }
@@ -217,6 +217,68 @@ func TestClient_Index(t *testing.T) {
 	}
 }
 
+func TestClient_PointInTime(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r)
+		bodies = append(bodies, buf)
+
+		switch r.URL.Path {
+		case "/logs-2018.05.15/_pit":
+			_, err = rw.Write([]byte(`{"id": "pit-id"}`))
+		case "/_msearch":
+			_, err = rw.Write([]byte(`{"responses": [{"pit_id": "next-pit-id", "hits": {"hits": []}, "status": 200}]}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:        ts.URL,
+		HTTPClient: ts.Client(),
+		Database:   "[logs-]YYYY.MM.DD",
+		Interval:   "Daily",
+	}
+	timeRange := backend.TimeRange{
+		From: time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC),
+		To:   time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC),
+	}
+	c, err := NewClient(context.Background(), &ds, timeRange, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+
+	id, err := c.OpenPointInTime("5m")
+	require.NoError(t, err)
+	require.Equal(t, "pit-id", id)
+	require.Equal(t, http.MethodPost, requests[0].Method)
+	require.Equal(t, "5m", requests[0].URL.Query().Get("keep_alive"))
+
+	msb := c.MultiSearch()
+	msb.Search(15*time.Second).PointInTime(id, "5m").AddSearchAfter(1)
+	ms, err := msb.Build()
+	require.NoError(t, err)
+	res, err := c.ExecuteMultisearch(ms)
+	require.NoError(t, err)
+	require.Equal(t, "next-pit-id", res.Responses[0].PitID)
+
+	requestBody := bytes.NewBuffer(bodies[1])
+	headerBytes, err := requestBody.ReadBytes('\n')
+	require.NoError(t, err)
+	jHeader, err := simplejson.NewJson(headerBytes)
+	require.NoError(t, err)
+	jBody, err := simplejson.NewJson(requestBody.Bytes())
+	require.NoError(t, err)
+
+	// The indices of a point in time search are the indices of the point in time.
+	assert.Equal(t, map[string]any{"search_type": "query_then_fetch"}, jHeader.MustMap())
+	assert.Equal(t, "pit-id", jBody.GetPath("pit", "id").MustString())
+	assert.Equal(t, "5m", jBody.GetPath("pit", "keep_alive").MustString())
+}
+
 func createMultisearchForTest(t *testing.T, c Client) (*MultiSearchRequest, error) {
 	t.Helper()
 
@@ -14,6 +14,19 @@ type SearchRequest struct {
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
+	PointInTime *PointInTime
+}
+
+// PointInTime represents the point in time of a search request
+type PointInTime struct {
+	ID        string `json:"id"`
+	KeepAlive string `json:"keep_alive"`
+}
+
+// PointInTimeResponse represents the response to the opening of a point in time
+type PointInTimeResponse struct {
+	ID    string                 `json:"id"`
+	Error map[string]interface{} `json:"error"`
 }
 
 // MarshalJSON returns the JSON encoding of the request.
@@ -29,6 +42,10 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 		root[key] = value
 	}
 
+	if r.PointInTime != nil {
+		root["pit"] = r.PointInTime
+	}
+
 	root["query"] = r.Query
 
 	if len(r.Aggs) > 0 {
@@ -48,6 +65,8 @@ type SearchResponse struct {
 	Error        map[string]interface{} `json:"error"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 	Hits         *SearchResponseHits    `json:"hits"`
+	// PitID is the ID of the point in time of the search, which can change between the pages of a search.
+	PitID string `json:"pit_id"`
 }
 
 // MultiSearchRequest represents a multi search request
@@ -61,6 +80,25 @@ type MultiSearchResponse struct {
 	Responses []*SearchResponse `json:"responses"`
 }
 
+// EsqlRequest represents an ES|QL query request
+type EsqlRequest struct {
+	Query string `json:"query"`
+}
+
+// EsqlColumn represents a column of an ES|QL query response
+type EsqlColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EsqlResponse represents an ES|QL query response, which contains the columns and the rows of the result
+type EsqlResponse struct {
+	Status  int                    `json:"status,omitempty"`
+	Error   map[string]interface{} `json:"error"`
+	Columns []EsqlColumn           `json:"columns"`
+	Values  [][]interface{}        `json:"values"`
+}
+
 // Query represents a query
 type Query struct {
 	Bool *BoolQuery `json:"bool"`
@@ -22,6 +22,7 @@ type SearchRequestBuilder struct {
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]any
+	pointInTime  *PointInTime
 }
 
 // NewSearchRequestBuilder create a new search request builder
@@ -43,6 +44,7 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Size:        b.size,
 		Sort:        b.sort,
 		CustomProps: b.customProps,
+		PointInTime: b.pointInTime,
 	}
 
 	if b.queryBuilder != nil {
@@ -138,6 +140,12 @@ func (b *SearchRequestBuilder) AddSearchAfter(value any) *SearchRequestBuilder {
 	return b
 }
 
+// PointInTime makes the search request search the given point in time, and keep it alive for keepAlive
+func (b *SearchRequestBuilder) PointInTime(id string, keepAlive string) *SearchRequestBuilder {
+	b.pointInTime = &PointInTime{ID: id, KeepAlive: keepAlive}
+	return b
+}
+
 // Query creates and return a query builder
 func (b *SearchRequestBuilder) Query() *QueryBuilder {
 	if b.queryBuilder == nil {
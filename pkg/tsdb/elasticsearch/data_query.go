@@ -20,6 +20,8 @@ import (
 
 const (
 	defaultSize = 500
+	// pointInTimeKeepAlive is how long a point in time is kept after each page of a paginated query.
+	pointInTimeKeepAlive = "5m"
 )
 
 type elasticsearchDataQuery struct {
@@ -51,6 +53,36 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	// ES|QL queries are not part of the multisearch request, each of them is sent to the ES|QL endpoint.
+	dslQueries := make([]*Query, 0, len(queries))
+	for _, q := range queries {
+		if isEsqlQuery(q) {
+			response.Responses[q.RefID] = e.executeEsqlQuery(q, e.dataQueries[0].TimeRange.From, e.dataQueries[0].TimeRange.To)
+		} else {
+			dslQueries = append(dslQueries, q)
+		}
+	}
+	if len(dslQueries) == 0 {
+		return response, nil
+	}
+	queries = dslQueries
+
+	for _, q := range queries {
+		if !isPaginatedQuery(q) {
+			continue
+		}
+		q.PointInTimeID = q.Metrics[0].Settings.Get("pitId").MustString()
+		if q.PointInTimeID != "" {
+			continue
+		}
+		// The first page of a paginated query opens the point in time that the next pages search.
+		q.PointInTimeID, err = e.client.OpenPointInTime(pointInTimeKeepAlive)
+		if err != nil {
+			e.logger.Error("Failed to open point in time", "error", err, "duration", time.Since(start), "stage", es.StagePrepareRequest)
+			return errorsource.AddErrorToResponse(q.RefID, response, err), nil
+		}
+	}
+
 	ms := e.client.MultiSearch()
 
 	from := e.dataQueries[0].TimeRange.From.UnixNano() / int64(time.Millisecond)
@@ -77,7 +109,14 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	for refID, res := range response.Responses {
+		result.Responses[refID] = res
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -103,6 +142,10 @@ func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchReques
 		processTimeSeriesQuery(q, b, from, to, defaultTimeField)
 	}
 
+	if isPaginatedQuery(q) {
+		b.PointInTime(q.PointInTimeID, pointInTimeKeepAlive)
+	}
+
 	return nil
 }
 
@@ -332,6 +375,15 @@ func isLogsQuery(query *Query) bool {
 	return query.Metrics[0].Type == logsType
 }
 
+// isPaginatedQuery returns whether the query is a logs or raw data query that pages through the documents using
+// search_after on a point in time, instead of returning the first documents only.
+func isPaginatedQuery(query *Query) bool {
+	if len(query.Metrics) == 0 || !(isLogsQuery(query) || isRawDataQuery(query)) {
+		return false
+	}
+	return query.Metrics[0].Settings.Get("pointInTime").MustBool(false)
+}
+
 func isDocumentQuery(query *Query) bool {
 	return isRawDataQuery(query) || isRawDocumentQuery(query)
 }
@@ -360,8 +412,8 @@ func processLogsQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defa
 	b.Size(stringToIntWithDefaultValue(metric.Settings.Get("limit").MustString(), defaultSize))
 	b.AddHighlight()
 
-	// This is currently used only for log context query to get
-	// log lines before and after the selected log line
+	// This is used for log context query to get log lines before and
+	// after the selected log line, and to get the next page of a paginated query
 	searchAfter := metric.Settings.Get("searchAfter").MustArray()
 	for _, value := range searchAfter {
 		b.AddSearchAfter(value)
@@ -393,6 +445,12 @@ func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, from, to int64,
 		// For raw_data queries we need to add timeField as field with standardized time format to not receive
 		// invalid formats that elasticsearch can parse, but our frontend can't (e.g. yyyy_MM_dd_HH_mm_ss)
 		b.AddTimeFieldWithStandardizedFormat(defaultTimeField)
+
+		// This is used to get the next page of a paginated query
+		searchAfter := metric.Settings.Get("searchAfter").MustArray()
+		for _, value := range searchAfter {
+			b.AddSearchAfter(value)
+		}
 	}
 	b.Size(stringToIntWithDefaultValue(metric.Settings.Get("size").MustString(), defaultSize))
 }
@@ -1426,6 +1426,40 @@ func TestExecuteElasticsearchDataQuery(t *testing.T) {
 			require.Equal(t, secondSearchAfter, "2")
 		})
 
+		t.Run("With paginated logs query should open a point in time", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "logs", "id": "1", "settings": { "limit": "1000", "pointInTime": true }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Equal(t, []string{pointInTimeKeepAlive}, c.pointInTimes)
+			sr := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, &es.PointInTime{ID: "pit-id", KeepAlive: pointInTimeKeepAlive}, sr.PointInTime)
+			require.Nil(t, sr.CustomProps["search_after"])
+		})
+
+		t.Run("With next page of paginated raw data query should search the point in time after the last document", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "raw_data", "id": "1", "settings": { "size": "1000", "pointInTime": true, "pitId": "previous-pit-id", "searchAfter": [1, "2"] }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Empty(t, c.pointInTimes)
+			sr := c.multisearchRequests[0].Requests[0]
+			require.Equal(t, &es.PointInTime{ID: "previous-pit-id", KeepAlive: pointInTimeKeepAlive}, sr.PointInTime)
+			require.Len(t, sr.CustomProps["search_after"], 2)
+		})
+
+		t.Run("With raw data query should not search a point in time", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeElasticsearchDataQuery(c, `{
+			"metrics": [{ "type": "raw_data", "id": "1", "settings": { "size": "1000" }}]
+		}`, from, to)
+			require.NoError(t, err)
+			require.Empty(t, c.pointInTimes)
+			require.Nil(t, c.multisearchRequests[0].Requests[0].PointInTime)
+		})
+
 		t.Run("With invalid query should return error", (func(t *testing.T) {
 			c := newFakeClient()
 			res, err := executeElasticsearchDataQuery(c, `{
@@ -1806,6 +1840,9 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	pointInTimes        []string
+	esqlRequests        []*es.EsqlRequest
+	esqlResponse        *es.EsqlResponse
 }
 
 func newFakeClient() *fakeClient {
@@ -1819,6 +1856,7 @@ func newFakeClient() *fakeClient {
 		configuredFields:    configuredFields,
 		multisearchRequests: make([]*es.MultiSearchRequest, 0),
 		multiSearchResponse: &es.MultiSearchResponse{},
+		esqlResponse:        &es.EsqlResponse{},
 	}
 }
 
@@ -1831,6 +1869,16 @@ func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSear
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) OpenPointInTime(keepAlive string) (string, error) {
+	c.pointInTimes = append(c.pointInTimes, keepAlive)
+	return "pit-id", nil
+}
+
+func (c *fakeClient) ExecuteEsql(r *es.EsqlRequest) (*es.EsqlResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.esqlResponse, nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
@@ -0,0 +1,344 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	esqlQueryType = "esql"
+
+	// esqlFormatAuto returns a time series frame if the result of the ES|QL query is a time series, a logs frame if
+	// the result contains the log message field, and a table otherwise.
+	esqlFormatAuto       = "auto"
+	esqlFormatTable      = "table"
+	esqlFormatTimeSeries = "time_series"
+	esqlFormatLogs       = "logs"
+
+	esqlTimeFormat = "2006-01-02T15:04:05.000Z"
+)
+
+var esqlMacroRegexp = regexp.MustCompile(`\$__(\w+)(?:\(([^)]*)\))?`)
+
+func isEsqlQuery(query *Query) bool {
+	return query.QueryType == esqlQueryType
+}
+
+// executeEsqlQuery executes the ES|QL query and converts its result to a data frame.
+func (e *elasticsearchDataQuery) executeEsqlQuery(q *Query, from, to time.Time) backend.DataResponse {
+	start := time.Now()
+	configuredFields := e.client.GetConfiguredFields()
+	query, err := interpolateEsqlMacros(q, configuredFields.TimeField, from, to)
+	if err != nil {
+		return errorsource.Response(errorsource.DownstreamError(err, false))
+	}
+
+	res, err := e.client.ExecuteEsql(&es.EsqlRequest{Query: query})
+	if err != nil {
+		// We are returning error containing the source that was added trough errorsource.Middleware
+		return errorsource.Response(err)
+	}
+	if res.Error != nil {
+		me, _ := json.Marshal(res.Error)
+		e.logger.Error("Processing error response from Elasticsearch", "error", string(me), "query", query)
+		errResult := getErrorFromElasticResponse(&es.SearchResponse{Error: res.Error})
+		return errorsource.Response(errorsource.DownstreamError(errors.New(errResult), false))
+	}
+
+	frame, err := esqlResponseToFrame(res, q.EsqlFormat, configuredFields)
+	if err != nil {
+		e.logger.Error("Failed to process ES|QL response", "error", err, "query", query, "stage", es.StageParseResponse)
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.ExecutedQueryString = query
+
+	e.logger.Info("Finished processing of ES|QL response", "duration", time.Since(start), "stage", es.StageParseResponse)
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// interpolateEsqlMacros replaces the macros of the ES|QL query:
+//   - $__timeFilter and $__timeFilter(field) with a condition on the time field or the given field
+//   - $__timeFrom and $__timeTo with the start and end of the time range
+//   - $__interval and $__interval_ms with the interval of the query, as a time span and in milliseconds
+func interpolateEsqlMacros(q *Query, timeField string, from, to time.Time) (string, error) {
+	interval := q.Interval
+	if interval <= 0 {
+		interval = time.Duration(q.IntervalMs) * time.Millisecond
+	}
+	if interval < time.Millisecond {
+		interval = time.Second
+	}
+	fromValue := fmt.Sprintf("TO_DATETIME(%q)", from.UTC().Format(esqlTimeFormat))
+	toValue := fmt.Sprintf("TO_DATETIME(%q)", to.UTC().Format(esqlTimeFormat))
+
+	var err error
+	query := esqlMacroRegexp.ReplaceAllStringFunc(q.RawQuery, func(match string) string {
+		groups := esqlMacroRegexp.FindStringSubmatch(match)
+		name, arg := groups[1], strings.TrimSpace(groups[2])
+		switch name {
+		case "timeFilter":
+			field := timeField
+			if arg != "" {
+				field = arg
+			}
+			field = quoteEsqlIdentifier(field)
+			return fmt.Sprintf("%s >= %s AND %s <= %s", field, fromValue, field, toValue)
+		case "timeFrom":
+			return fromValue
+		case "timeTo":
+			return toValue
+		case "interval":
+			return fmt.Sprintf("%d milliseconds", interval.Milliseconds())
+		case "interval_ms":
+			return strconv.FormatInt(interval.Milliseconds(), 10)
+		}
+		if groups[2] != "" || strings.HasSuffix(match, ")") {
+			err = fmt.Errorf("unknown macro %q", match)
+		}
+		return match
+	})
+	if err != nil {
+		return "", err
+	}
+	return query, nil
+}
+
+// quoteEsqlIdentifier quotes the name of a field so that it can contain any character.
+func quoteEsqlIdentifier(name string) string {
+	name = strings.Trim(name, "`")
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// esqlResponseToFrame converts the columns and rows of the ES|QL response to a frame of the given format.
+func esqlResponseToFrame(res *es.EsqlResponse, format string, configuredFields es.ConfiguredFields) (*data.Frame, error) {
+	timeIndex := -1
+	hasNumber, hasOther, hasLogMessage := false, false, false
+	for i, c := range res.Columns {
+		switch {
+		case isEsqlTimeType(c.Type):
+			if timeIndex == -1 {
+				timeIndex = i
+			} else {
+				hasOther = true
+			}
+		case isEsqlNumberType(c.Type):
+			hasNumber = true
+		case c.Type == "keyword" || c.Type == "text":
+			if configuredFields.LogMessageField != "" && c.Name == configuredFields.LogMessageField {
+				hasLogMessage = true
+			}
+		default:
+			hasOther = true
+		}
+	}
+
+	if format == esqlFormatAuto || format == "" {
+		switch {
+		case hasLogMessage && timeIndex >= 0:
+			format = esqlFormatLogs
+		case timeIndex >= 0 && hasNumber && !hasOther:
+			format = esqlFormatTimeSeries
+		default:
+			format = esqlFormatTable
+		}
+	}
+
+	switch format {
+	case esqlFormatTimeSeries:
+		if timeIndex < 0 || !hasNumber {
+			return nil, errors.New("the result of the query is not a time series, it must have a time column and a numeric column")
+		}
+		return esqlTimeSeriesFrame(res, timeIndex)
+	case esqlFormatLogs:
+		frame := esqlTableFrame(res, -1)
+		frame.Fields = esqlLogsFields(frame.Fields, timeIndex, configuredFields)
+		setPreferredVisType(frame, data.VisTypeLogs)
+		return frame, nil
+	case esqlFormatTable:
+		frame := esqlTableFrame(res, -1)
+		setPreferredVisType(frame, data.VisTypeTable)
+		return frame, nil
+	default:
+		return nil, fmt.Errorf("unknown ES|QL format %q", format)
+	}
+}
+
+// esqlTimeSeriesFrame returns a time series frame sorted by time. The rows are the values of the series if the
+// other columns are numeric, and the result is converted to a wide frame with the string columns as labels
+// otherwise.
+func esqlTimeSeriesFrame(res *es.EsqlResponse, timeIndex int) (*data.Frame, error) {
+	frame := esqlTableFrame(res, timeIndex)
+	if frame.Rows() == 0 {
+		frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesWide}
+		return frame, nil
+	}
+	if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+		wide, err := data.LongToWide(frame, nil)
+		if err != nil {
+			return nil, err
+		}
+		frame = wide
+	}
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Type = data.FrameTypeTimeSeriesWide
+	return frame, nil
+}
+
+// esqlTableFrame returns a frame with a field for each column. If sortTimeIndex is a column, the rows without a
+// time are dropped and the rows are sorted by time, and the field of the column is not nullable.
+func esqlTableFrame(res *es.EsqlResponse, sortTimeIndex int) *data.Frame {
+	rows := res.Values
+	if sortTimeIndex >= 0 {
+		type timedRow struct {
+			t   time.Time
+			row []any
+		}
+		timed := make([]timedRow, 0, len(rows))
+		for _, row := range rows {
+			if t := esqlTimeValue(esqlCell(row, sortTimeIndex)); t != nil {
+				timed = append(timed, timedRow{t: *t, row: row})
+			}
+		}
+		sort.SliceStable(timed, func(i, j int) bool {
+			return timed[i].t.Before(timed[j].t)
+		})
+		rows = make([][]any, len(timed))
+		for i, r := range timed {
+			rows[i] = r.row
+		}
+	}
+
+	fields := make([]*data.Field, 0, len(res.Columns))
+	for i, c := range res.Columns {
+		var field *data.Field
+		switch {
+		case i == sortTimeIndex:
+			values := make([]time.Time, len(rows))
+			for j, row := range rows {
+				values[j] = *esqlTimeValue(esqlCell(row, i))
+			}
+			field = data.NewField(c.Name, nil, values)
+		case isEsqlTimeType(c.Type):
+			values := make([]*time.Time, len(rows))
+			for j, row := range rows {
+				values[j] = esqlTimeValue(esqlCell(row, i))
+			}
+			field = data.NewField(c.Name, nil, values)
+		case isEsqlNumberType(c.Type):
+			values := make([]*float64, len(rows))
+			for j, row := range rows {
+				if v, ok := esqlCell(row, i).(float64); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(c.Name, nil, values)
+		case c.Type == "boolean":
+			values := make([]*bool, len(rows))
+			for j, row := range rows {
+				if v, ok := esqlCell(row, i).(bool); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(c.Name, nil, values)
+		default:
+			values := make([]*string, len(rows))
+			for j, row := range rows {
+				values[j] = esqlStringValue(esqlCell(row, i))
+			}
+			field = data.NewField(c.Name, nil, values)
+		}
+		fields = append(fields, field)
+	}
+	return data.NewFrame("", fields...)
+}
+
+// esqlLogsFields orders the fields of a logs frame like the fields of the frames of logs queries: the time field,
+// then the log message field, then the other fields. The log level field is renamed to level.
+func esqlLogsFields(fields []*data.Field, timeIndex int, configuredFields es.ConfiguredFields) []*data.Field {
+	ordered := make([]*data.Field, 0, len(fields))
+	var rest []*data.Field
+	if timeIndex >= 0 {
+		ordered = append(ordered, fields[timeIndex])
+	}
+	for i, f := range fields {
+		switch {
+		case i == timeIndex:
+		case configuredFields.LogMessageField != "" && f.Name == configuredFields.LogMessageField:
+			ordered = append(ordered, f)
+		default:
+			if configuredFields.LogLevelField != "" && f.Name == configuredFields.LogLevelField {
+				f.Name = "level"
+			}
+			rest = append(rest, f)
+		}
+	}
+	return append(ordered, rest...)
+}
+
+func esqlCell(row []any, i int) any {
+	if i >= len(row) {
+		return nil
+	}
+	return row[i]
+}
+
+func esqlTimeValue(v any) *time.Time {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// esqlStringValue returns the value of a column, or the JSON encoding of the value if it is not a string, for
+// example if the field has multiple values.
+func esqlStringValue(v any) *string {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return &value
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		s := string(b)
+		return &s
+	}
+}
+
+func isEsqlTimeType(t string) bool {
+	return t == "date" || t == "date_nanos" || t == "datetime"
+}
+
+func isEsqlNumberType(t string) bool {
+	switch t {
+	case "long", "integer", "short", "byte", "double", "float", "half_float", "scaled_float", "unsigned_long",
+		"counter_long", "counter_integer", "counter_double":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestInterpolateEsqlMacros(t *testing.T) {
+	from := time.Date(2022, 11, 14, 10, 40, 0, 0, time.UTC)
+	to := time.Date(2022, 11, 14, 11, 40, 0, 0, time.UTC)
+	interpolate := func(query string) (string, error) {
+		return interpolateEsqlMacros(&Query{RawQuery: query, Interval: 30 * time.Second}, "@timestamp", from, to)
+	}
+
+	t.Run("should filter the time field", func(t *testing.T) {
+		query, err := interpolate("FROM logs | WHERE $__timeFilter")
+		require.NoError(t, err)
+		require.Equal(t, "FROM logs | WHERE `@timestamp` >= TO_DATETIME(\"2022-11-14T10:40:00.000Z\") AND `@timestamp` <= TO_DATETIME(\"2022-11-14T11:40:00.000Z\")", query)
+	})
+
+	t.Run("should filter the given field", func(t *testing.T) {
+		query, err := interpolate("FROM logs | WHERE $__timeFilter(event.created)")
+		require.NoError(t, err)
+		require.Equal(t, "FROM logs | WHERE `event.created` >= TO_DATETIME(\"2022-11-14T10:40:00.000Z\") AND `event.created` <= TO_DATETIME(\"2022-11-14T11:40:00.000Z\")", query)
+	})
+
+	t.Run("should replace the time range and the interval", func(t *testing.T) {
+		query, err := interpolate("FROM logs | WHERE @timestamp > $__timeFrom AND @timestamp < $__timeTo | STATS c = COUNT(*) BY b = BUCKET(@timestamp, $__interval) | EVAL ms = $__interval_ms")
+		require.NoError(t, err)
+		require.Equal(t, "FROM logs | WHERE @timestamp > TO_DATETIME(\"2022-11-14T10:40:00.000Z\") AND @timestamp < TO_DATETIME(\"2022-11-14T11:40:00.000Z\") | STATS c = COUNT(*) BY b = BUCKET(@timestamp, 30000 milliseconds) | EVAL ms = 30000", query)
+	})
+
+	t.Run("should keep unknown variables and reject unknown macros", func(t *testing.T) {
+		query, err := interpolate("FROM logs | WHERE host == \"$__unknown\"")
+		require.NoError(t, err)
+		require.Equal(t, "FROM logs | WHERE host == \"$__unknown\"", query)
+
+		_, err = interpolate("FROM logs | WHERE $__unknown(host)")
+		require.EqualError(t, err, `unknown macro "$__unknown(host)"`)
+	})
+}
+
+func TestEsqlQuery(t *testing.T) {
+	t.Run("should send the query to the ES|QL endpoint", func(t *testing.T) {
+		var request *http.Request
+		var body []byte
+		dsInfo := newFlowTestDsInfo([]byte(`{"columns": [], "values": []}`), http.StatusOK, func(req *http.Request) error {
+			request = req
+			var err error
+			body, err = io.ReadAll(req.Body)
+			return err
+		})
+		queries, err := newFlowTestQueries([]byte(`[{"refId": "A", "queryType": "esql", "query": "FROM logs | LIMIT 10"}]`))
+		require.NoError(t, err)
+
+		result, err := queryData(context.Background(), queries, dsInfo, log.New("test.logger"), tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.NoError(t, result.Responses["A"].Error)
+		require.Equal(t, http.MethodPost, request.Method)
+		require.Equal(t, "/_query", request.URL.Path)
+		require.JSONEq(t, `{"query": "FROM logs | LIMIT 10"}`, string(body))
+	})
+
+	t.Run("should return the error of the query", func(t *testing.T) {
+		response := `{
+			"error": {
+				"root_cause": [{ "type": "verification_exception", "reason": "Found 1 problem\nline 1:6: Unknown index [unknown]" }],
+				"type": "verification_exception",
+				"reason": "Found 1 problem\nline 1:6: Unknown index [unknown]"
+			},
+			"status": 400
+		}`
+		result, err := queryDataTestWithResponseCode([]byte(`[{"refId": "A", "queryType": "esql", "query": "FROM unknown"}]`), http.StatusBadRequest, []byte(response))
+		require.NoError(t, err)
+		require.EqualError(t, result.response.Responses["A"].Error, "Found 1 problem\nline 1:6: Unknown index [unknown]")
+		require.Equal(t, backend.ErrorSourceDownstream, result.response.Responses["A"].ErrorSource)
+	})
+
+	t.Run("should send the other queries in a multisearch request", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.EsqlResponse{
+			Columns: []es.EsqlColumn{{Name: "count", Type: "long"}},
+			Values:  [][]any{{float64(3)}},
+		}
+		c.multiSearchResponse = &es.MultiSearchResponse{
+			Responses: []*es.SearchResponse{{Hits: &es.SearchResponseHits{}}},
+		}
+		from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+		to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+		queries := []backend.DataQuery{
+			{RefID: "A", TimeRange: backend.TimeRange{From: from, To: to}, JSON: json.RawMessage(`{"queryType": "esql", "query": "FROM logs | STATS count = COUNT(*)"}`)},
+			{RefID: "B", TimeRange: backend.TimeRange{From: from, To: to}, JSON: json.RawMessage(`{"metrics": [{ "type": "raw_data", "id": "1" }]}`)},
+		}
+
+		result, err := newElasticsearchDataQuery(context.Background(), c, queries, log.New("test.logger"), tracing.InitializeTracerForTest()).execute()
+		require.NoError(t, err)
+		require.Len(t, c.esqlRequests, 1)
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		require.Len(t, result.Responses, 2)
+		require.Equal(t, "count", result.Responses["A"].Frames[0].Fields[0].Name)
+		require.NoError(t, result.Responses["B"].Error)
+	})
+}
+
+func TestEsqlResponseToFrame(t *testing.T) {
+	configuredFields := es.ConfiguredFields{TimeField: "@timestamp", LogMessageField: "message", LogLevelField: "level"}
+	res := &es.EsqlResponse{
+		Columns: []es.EsqlColumn{{Name: "@timestamp", Type: "date"}, {Name: "value", Type: "double"}},
+		Values: [][]any{
+			{"2022-11-14T10:41:00.000Z", float64(2)},
+			{"2022-11-14T10:40:00.000Z", float64(1)},
+		},
+	}
+
+	t.Run("should return a table if requested", func(t *testing.T) {
+		frame, err := esqlResponseToFrame(res, esqlFormatTable, configuredFields)
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, float64(2), *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("should return a wide time series sorted by time", func(t *testing.T) {
+		frame, err := esqlResponseToFrame(res, esqlFormatAuto, configuredFields)
+		require.NoError(t, err)
+		require.Equal(t, "timeseries-wide", string(frame.Meta.Type))
+		require.Equal(t, float64(1), *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("should fail if the result is not a time series", func(t *testing.T) {
+		_, err := esqlResponseToFrame(&es.EsqlResponse{
+			Columns: []es.EsqlColumn{{Name: "host", Type: "keyword"}},
+		}, esqlFormatTimeSeries, configuredFields)
+		require.Error(t, err)
+	})
+}
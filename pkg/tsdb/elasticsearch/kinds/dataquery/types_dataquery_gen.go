@@ -19,6 +19,19 @@ const (
 	BucketAggregationTypeTerms         BucketAggregationType = "terms"
 )
 
+// Defines values for ElasticsearchQueryType.
+const (
+	ElasticsearchQueryTypeEsql ElasticsearchQueryType = "esql"
+)
+
+// Defines values for EsqlFormat.
+const (
+	EsqlFormatAuto       EsqlFormat = "auto"
+	EsqlFormatLogs       EsqlFormat = "logs"
+	EsqlFormatTable      EsqlFormat = "table"
+	EsqlFormatTimeSeries EsqlFormat = "time_series"
+)
+
 // Defines values for ExtendedStatMetaType.
 const (
 	ExtendedStatMetaTypeAvg                     ExtendedStatMetaType = "avg"
@@ -228,6 +241,9 @@ type ElasticsearchDataQuery struct {
 	// List of bucket aggregations
 	BucketAggs []any `json:"bucketAggs,omitempty"`
 
+	// Format of the frame of an ES|QL query, whose queryType is #ElasticsearchQueryType
+	EsqlFormat *EsqlFormat `json:"esqlFormat,omitempty"`
+
 	// List of metric aggregations
 	Metrics []any `json:"metrics,omitempty"`
 
@@ -238,6 +254,14 @@ type ElasticsearchDataQuery struct {
 	TimeField *string `json:"timeField,omitempty"`
 }
 
+// Defines the supported queryTypes. The query of an ES|QL query is run by the backend, and its metrics and
+// bucket aggregations are ignored. The queries built from the metrics and bucket aggregations have no queryType.
+type ElasticsearchQueryType string
+
+// auto returns a time series if the result of the ES|QL query is a time series, logs if the result contains the
+// log message field, and a table otherwise.
+type EsqlFormat string
+
 // ExtendedStat defines model for ExtendedStat.
 type ExtendedStat struct {
 	Label string               `json:"label"`
@@ -513,7 +537,15 @@ type RawData struct {
 	Hide     *bool  `json:"hide,omitempty"`
 	Id       string `json:"id"`
 	Settings *struct {
-		Size *string `json:"size,omitempty"`
+		// Point in time of the pages, returned in the custom metadata of the frame of the first page
+		PitId *string `json:"pitId,omitempty"`
+
+		// Page through the documents with search_after on a point in time
+		PointInTime *bool `json:"pointInTime,omitempty"`
+
+		// Sort values of the last document of the previous page, returned in the custom metadata of its frame
+		SearchAfter []any   `json:"searchAfter,omitempty"`
+		Size        *string `json:"size,omitempty"`
 	} `json:"settings,omitempty"`
 	Type MetricAggregationType `json:"type"`
 }
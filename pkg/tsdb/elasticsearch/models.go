@@ -16,6 +16,14 @@ type Query struct {
 	IntervalMs    int64
 	RefID         string
 	MaxDataPoints int64
+	// QueryType is esqlQueryType for ES|QL queries, whose RawQuery is the ES|QL query, and empty for the queries
+	// built from the metrics and bucket aggregations.
+	QueryType string
+	// EsqlFormat is the format of the frame of an ES|QL query, one of the esqlFormat constants.
+	EsqlFormat string
+	// PointInTimeID is the ID of the point in time searched by a logs or raw data query that pages through the
+	// documents, see isPaginatedQuery.
+	PointInTimeID string
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -19,6 +19,19 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 		// please do not create a new field with that name, to avoid potential problems with old, persisted queries.
 
 		rawQuery := model.Get("query").MustString()
+		queryType := model.Get("queryType").MustString()
+		if queryType == esqlQueryType {
+			queries = append(queries, &Query{
+				RawQuery:      rawQuery,
+				QueryType:     queryType,
+				EsqlFormat:    model.Get("esqlFormat").MustString(esqlFormatAuto),
+				Interval:      q.Interval,
+				IntervalMs:    model.Get("intervalMs").MustInt64(0),
+				RefID:         q.RefID,
+				MaxDataPoints: q.MaxDataPoints,
+			})
+			continue
+		}
 		bucketAggs, err := parseBucketAggs(model)
 		if err != nil {
 			logger.Error("Failed to parse bucket aggs in query", "error", err, "model", string(q.JSON))
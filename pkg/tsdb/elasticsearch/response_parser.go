@@ -86,6 +86,7 @@ func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets
 				// TODO: This error never happens so we should remove it
 				return &backend.QueryDataResponse{}, err
 			}
+			setPaginationCustomMeta(&queryRes, res, target)
 			result.Responses[target.RefID] = queryRes
 		} else if isRawDocumentQuery(target) {
 			err := processRawDocumentResponse(res, target, &queryRes, logger)
@@ -100,6 +101,7 @@ func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets
 				// TODO: This error never happens so we should remove it
 				return &backend.QueryDataResponse{}, err
 			}
+			setPaginationCustomMeta(&queryRes, res, target)
 			result.Responses[target.RefID] = queryRes
 		} else {
 			// Process as metric query result
@@ -1202,6 +1204,42 @@ func setLogsCustomMeta(frame *data.Frame, searchWords map[string]bool, limit int
 	}
 }
 
+// setPaginationCustomMeta adds the point in time and the sort values of the last document of a paginated query to
+// the custom metadata of its frame, which are the pitId and searchAfter settings of the query of the next page.
+func setPaginationCustomMeta(queryRes *backend.DataResponse, res *es.SearchResponse, target *Query) {
+	if !isPaginatedQuery(target) || len(queryRes.Frames) == 0 {
+		return
+	}
+	frame := queryRes.Frames[0]
+
+	pitID := res.PitID
+	if pitID == "" {
+		pitID = target.PointInTimeID
+	}
+	var searchAfter interface{}
+	if hits := res.Hits.Hits; len(hits) > 0 {
+		searchAfter = hits[len(hits)-1]["sort"]
+	}
+	sizeSetting := "size"
+	if isLogsQuery(target) {
+		sizeSetting = "limit"
+	}
+	size := stringToIntWithDefaultValue(target.Metrics[0].Settings.Get(sizeSetting).MustString(), defaultSize)
+
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	custom, ok := frame.Meta.Custom.(map[string]interface{})
+	if !ok {
+		custom = map[string]interface{}{}
+	}
+	custom["pitId"] = pitID
+	custom["searchAfter"] = searchAfter
+	// A full page means that there may be more documents, the next page is empty if there are not.
+	custom["hasMore"] = searchAfter != nil && len(res.Hits.Hits) >= size
+	frame.Meta.Custom = custom
+}
+
 func createFields(frames data.Frames, propKeys []string) []*data.Field {
 	var fields []*data.Field
 	// Otherwise use the fields from frames
@@ -687,6 +687,46 @@ func TestProcessRawDataResponse(t *testing.T) {
 		require.Equal(t, &t0, logsFieldMap["testtime"].At(0))
 		require.Equal(t, &t1, logsFieldMap["testtime"].At(1))
 	})
+
+	t.Run("Paginated raw data query", func(t *testing.T) {
+		response := `{
+			"responses": [
+				{
+					"pit_id": "next-pit-id",
+					"hits": {
+						"hits": [
+							{ "_id": "1", "_index": "logs", "_source": { "@timestamp": "2023-02-08T15:10:55.830Z" }, "sort": [1675869055830, 4] },
+							{ "_id": "2", "_index": "logs", "_source": { "@timestamp": "2023-02-08T15:10:54.835Z" }, "sort": [1675869054835, 7] }
+						]
+					}
+				}
+			]
+		}`
+
+		t.Run("should return the cursor of the next page", func(t *testing.T) {
+			result, err := parseTestResponse(map[string]string{
+				"A": `{ "metrics": [{ "type": "raw_data", "settings": { "size": "2", "pointInTime": true } }] }`,
+			}, response)
+			require.NoError(t, err)
+			frame := result.Responses["A"].Frames[0]
+			require.Equal(t, map[string]any{
+				"pitId":       "next-pit-id",
+				"searchAfter": []any{float64(1675869054835), float64(7)},
+				"hasMore":     true,
+			}, frame.Meta.Custom)
+		})
+
+		t.Run("should report the last page", func(t *testing.T) {
+			result, err := parseTestResponse(map[string]string{
+				"A": `{ "metrics": [{ "type": "logs", "settings": { "limit": "10", "pointInTime": true } }] }`,
+			}, response)
+			require.NoError(t, err)
+			custom := result.Responses["A"].Frames[0].Meta.Custom.(map[string]any)
+			require.Equal(t, "next-pit-id", custom["pitId"])
+			require.Equal(t, false, custom["hasMore"])
+			require.Equal(t, 10, custom["limit"])
+		})
+	})
 }
 
 func TestProcessRawDocumentResponse(t *testing.T) {
@@ -141,6 +141,9 @@ func TestResponseSnapshots(t *testing.T) {
 		{name: "metric extended_stats test", path: "metric_extended_stats"},
 		{name: "raw data test", path: "raw_data"},
 		{name: "logs test", path: "logs"},
+		{name: "ES|QL time series test", path: "esql_time_series"},
+		{name: "ES|QL logs test", path: "esql_logs"},
+		{name: "ES|QL table test", path: "esql_table"},
 	}
 
 	snapshotCount := findResponseSnapshotCounts(t, "testdata_response")
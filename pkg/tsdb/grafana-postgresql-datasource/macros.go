@@ -3,17 +3,14 @@ package postgres
 import (
 	"fmt"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
-	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
-const rsIdentifier = `([_a-zA-Z0-9]+)`
-const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+var timeGroupCompatibilityRegExp = regexp.MustCompile(`\$__timeGroup\(([^\)]*)\),`)
 
 type postgresMacroEngine struct {
 	*sqleng.SQLMacroEngineBase
@@ -28,133 +25,50 @@ func newPostgresMacroEngine(timescaledb bool) sqleng.SQLMacroEngine {
 }
 
 func (m *postgresMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
-	// TODO: Handle error
-	rExp, _ := regexp.Compile(sExpr)
-	var macroError error
-
-	sql = m.ReplaceAllStringSubmatchFunc(rExp, sql, func(groups []string) string {
-		// detect if $__timeGroup is supposed to add AS time for pre 5.3 compatibility
-		// if there is a ',' directly after the macro call $__timeGroup is probably used
-		// in the old way. Inside window function ORDER BY $__timeGroup will be followed
-		// by ')'
-		if groups[1] == "__timeGroup" {
-			if index := strings.Index(sql, groups[0]); index >= 0 {
-				index += len(groups[0])
-				// check for character after macro expression
-				if len(sql) > index && sql[index] == ',' {
-					groups[1] = "__timeGroupAlias"
-				}
-			}
-		}
-
-		args := strings.Split(groups[2], ",")
-		for i, arg := range args {
-			args[i] = strings.Trim(arg, " ")
-		}
-		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
-		if err != nil && macroError == nil {
-			macroError = err
-			return "macro_error()"
-		}
-		return res
-	})
-
-	if macroError != nil {
-		return "", macroError
-	}
+	// detect if $__timeGroup is supposed to add AS time for pre 5.3 compatibility
+	// if there is a ',' directly after the macro call $__timeGroup is probably used
+	// in the old way. Inside window function ORDER BY $__timeGroup will be followed
+	// by ')'
+	sql = timeGroupCompatibilityRegExp.ReplaceAllString(sql, "$$__timeGroupAlias($1),")
+	return sqleng.InterpolateMacros(m, query, timeRange, sql)
+}
+
+func (m *postgresMacroEngine) Time(column string) string {
+	return fmt.Sprintf("%s AS \"time\"", column)
+}
+
+func (m *postgresMacroEngine) TimeEpoch(column string) string {
+	return fmt.Sprintf("extract(epoch from %s) as \"time\"", column)
+}
+
+func (m *postgresMacroEngine) TimeFilter(column string, timeRange backend.TimeRange) string {
+	return fmt.Sprintf("%s BETWEEN %s AND %s", column, m.TimeLiteral(timeRange.From), m.TimeLiteral(timeRange.To))
+}
 
-	return sql, nil
+func (m *postgresMacroEngine) TimeLiteral(t time.Time) string {
+	return fmt.Sprintf("'%s'", t.UTC().Format(time.RFC3339Nano))
 }
 
-//nolint:gocyclo
-func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
-	switch name {
-	case "__time":
-		if len(args) == 0 {
-			return "", fmt.Errorf("missing time column argument for macro %v", name)
-		}
-		return fmt.Sprintf("%s AS \"time\"", args[0]), nil
-	case "__timeEpoch":
-		if len(args) == 0 {
-			return "", fmt.Errorf("missing time column argument for macro %v", name)
-		}
-		return fmt.Sprintf("extract(epoch from %s) as \"time\"", args[0]), nil
-	case "__timeFilter":
-		if len(args) == 0 {
-			return "", fmt.Errorf("missing time column argument for macro %v", name)
-		}
-
-		return fmt.Sprintf("%s BETWEEN '%s' AND '%s'", args[0], timeRange.From.UTC().Format(time.RFC3339Nano), timeRange.To.UTC().Format(time.RFC3339Nano)), nil
-	case "__timeFrom":
-		return fmt.Sprintf("'%s'", timeRange.From.UTC().Format(time.RFC3339Nano)), nil
-	case "__timeTo":
-		return fmt.Sprintf("'%s'", timeRange.To.UTC().Format(time.RFC3339Nano)), nil
-	case "__timeGroup":
-		if len(args) < 2 {
-			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
-		}
-		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'`))
-		if err != nil {
-			return "", fmt.Errorf("error parsing interval %v", args[1])
-		}
-		if len(args) == 3 {
-			err := sqleng.SetupFillmode(query, interval, args[2])
-			if err != nil {
-				return "", err
-			}
-		}
-
-		if m.timescaledb {
-			return fmt.Sprintf("time_bucket('%.3fs',%s)", interval.Seconds(), args[0]), nil
-		}
-
-		return fmt.Sprintf(
-			"floor(extract(epoch from %s)/%v)*%v", args[0],
-			interval.Seconds(),
-			interval.Seconds(),
-		), nil
-	case "__timeGroupAlias":
-		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
-		if err == nil {
-			return tg + " AS \"time\"", nil
-		}
-		return "", err
-	case "__unixEpochFilter":
-		if len(args) == 0 {
-			return "", fmt.Errorf("missing time column argument for macro %v", name)
-		}
-		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().Unix(), args[0], timeRange.To.UTC().Unix()), nil
-	case "__unixEpochNanoFilter":
-		if len(args) == 0 {
-			return "", fmt.Errorf("missing time column argument for macro %v", name)
-		}
-		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().UnixNano(), args[0], timeRange.To.UTC().UnixNano()), nil
-	case "__unixEpochNanoFrom":
-		return fmt.Sprintf("%d", timeRange.From.UTC().UnixNano()), nil
-	case "__unixEpochNanoTo":
-		return fmt.Sprintf("%d", timeRange.To.UTC().UnixNano()), nil
-	case "__unixEpochGroup":
-		if len(args) < 2 {
-			return "", fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
-		}
-		interval, err := gtime.ParseInterval(strings.Trim(args[1], `'`))
-		if err != nil {
-			return "", fmt.Errorf("error parsing interval %v", args[1])
-		}
-		if len(args) == 3 {
-			err := sqleng.SetupFillmode(query, interval, args[2])
-			if err != nil {
-				return "", err
-			}
-		}
-		return fmt.Sprintf("floor((%s)/%v)*%v", args[0], interval.Seconds(), interval.Seconds()), nil
-	case "__unixEpochGroupAlias":
-		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
-		if err == nil {
-			return tg + " AS \"time\"", nil
-		}
-		return "", err
-	default:
-		return "", fmt.Errorf("unknown macro %q", name)
+func (m *postgresMacroEngine) TimeGroup(column string, interval time.Duration) string {
+	if m.timescaledb {
+		return fmt.Sprintf("time_bucket('%.3fs',%s)", interval.Seconds(), column)
 	}
+
+	return fmt.Sprintf(
+		"floor(extract(epoch from %s)/%v)*%v", column,
+		interval.Seconds(),
+		interval.Seconds(),
+	)
+}
+
+func (m *postgresMacroEngine) UnixEpochGroup(column string, interval time.Duration) string {
+	return fmt.Sprintf("floor((%s)/%v)*%v", column, interval.Seconds(), interval.Seconds())
+}
+
+func (m *postgresMacroEngine) Epoch(column string) string {
+	return fmt.Sprintf("extract(epoch from %s)", column)
+}
+
+func (m *postgresMacroEngine) Alias(expression string) string {
+	return expression + " AS \"time\""
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng/sqlengtest"
 )
 
 func TestMacroEngine(t *testing.T) {
@@ -247,3 +249,17 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineCompatibility(t *testing.T) {
+	sqlengtest.RunMacroCompatibilityTests(t, newPostgresMacroEngine(false), sqlengtest.MacroExpectations{
+		Time:           `time_column AS "time"`,
+		TimeEpoch:      `extract(epoch from time_column) as "time"`,
+		TimeFilter:     "time_column BETWEEN '2018-04-12T18:00:00Z' AND '2018-04-12T18:05:00Z'",
+		TimeFrom:       "'2018-04-12T18:00:00Z'",
+		TimeTo:         "'2018-04-12T18:05:00Z'",
+		TimeGroup:      "floor(extract(epoch from time_column)/300)*300",
+		UnixEpochGroup: "floor((time_column)/300)*300",
+		Epoch:          "extract(epoch from time_column)",
+		Alias:          ` AS "time"`,
+	})
+}
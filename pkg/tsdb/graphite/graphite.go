@@ -48,10 +48,22 @@ func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Trace
 	}
 }
 
+var (
+	_ backend.QueryDataHandler    = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+	_ backend.CallResourceHandler = (*Service)(nil)
+)
+
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+	// GraphiteVersion is the version of Graphite configured in the data source, for example 1.1.
+	GraphiteVersion string
+}
+
+type jsonData struct {
+	GraphiteVersion string `json:"graphiteVersion"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -66,10 +78,19 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		// The settings are only used by the health check, so invalid settings do not prevent querying.
+		var jd jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				logger.Warn("Failed to parse data source settings", "error", err)
+			}
+		}
+
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:      client,
+			URL:             settings.URL,
+			Id:              settings.ID,
+			GraphiteVersion: jd.GraphiteVersion,
 		}
 
 		return model, nil
@@ -0,0 +1,141 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const healthCheckTarget = "constantLine(1)"
+
+// CheckHealth checks that Graphite can render a series and find metrics, and detects the version of Graphite.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return getHealthCheckMessage(logger, "", fmt.Errorf("failed to get datasource information: %w", err))
+	}
+
+	if err := checkRender(ctx, logger, dsInfo); err != nil {
+		return getHealthCheckMessage(logger, "", fmt.Errorf("failed to render a series: %w", err))
+	}
+	if err := checkMetricsFind(ctx, logger, dsInfo); err != nil {
+		return getHealthCheckMessage(logger, "", fmt.Errorf("failed to find metrics: %w", err))
+	}
+
+	version, err := getVersion(ctx, logger, dsInfo)
+	if err != nil {
+		// Graphite older than 1.1 has no version endpoint.
+		logger.Debug("Failed to detect Graphite version", "error", err)
+		return getHealthCheckMessage(logger, "Data source is working", nil)
+	}
+	message := fmt.Sprintf("Data source is working. Graphite version %s", version)
+	if dsInfo.GraphiteVersion != "" && !strings.HasPrefix(version, dsInfo.GraphiteVersion) {
+		message += fmt.Sprintf(", but the data source is configured for version %s", dsInfo.GraphiteVersion)
+	}
+	return getHealthCheckMessage(logger, message, nil)
+}
+
+func checkRender(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo) error {
+	body, err := doGraphiteRequest(ctx, logger, dsInfo, http.MethodGet, "render", url.Values{
+		"target": []string{healthCheckTarget},
+		"from":   []string{"-1min"},
+		"until":  []string{"now"},
+		"format": []string{"json"},
+	})
+	if err != nil {
+		return err
+	}
+	var series []TargetResponseDTO
+	if err := json.Unmarshal(body, &series); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if len(series) == 0 {
+		return errors.New("no series returned")
+	}
+	return nil
+}
+
+func checkMetricsFind(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo) error {
+	body, err := doGraphiteRequest(ctx, logger, dsInfo, http.MethodGet, "metrics/find", url.Values{
+		"query": []string{"*"},
+	})
+	if err != nil {
+		return err
+	}
+	var metrics []json.RawMessage
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// getVersion returns the version reported by the version endpoint of Graphite 1.1 and later.
+func getVersion(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo) (string, error) {
+	body, err := doGraphiteRequest(ctx, logger, dsInfo, http.MethodGet, "version", nil)
+	if err != nil {
+		return "", err
+	}
+	version := strings.Trim(strings.TrimSpace(string(body)), `"`)
+	if version == "" {
+		return "", errors.New("empty version")
+	}
+	return version, nil
+}
+
+// doGraphiteRequest sends a request to the given path of the Graphite API and returns the body of the response.
+func doGraphiteRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, method string, p string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, p)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+	return body, nil
+}
+
+func getHealthCheckMessage(logger log.Logger, message string, err error) (*backend.CheckHealthResult, error) {
+	if err == nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusOk,
+			Message: message,
+		}, nil
+	}
+
+	logger.Warn("Graphite health check failed", "error", err)
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusError,
+		Message: fmt.Sprintf("Graphite health check failed: %s", err.Error()),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type serverInstanceManager struct {
+	dsInfo datasourceInfo
+}
+
+func (m serverInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m serverInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+// newTestService returns a service whose data source is the given Graphite API.
+func newTestService(t *testing.T, handler http.HandlerFunc, graphiteVersion string) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Service{
+		im:     serverInstanceManager{dsInfo: datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, GraphiteVersion: graphiteVersion}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	graphite := func(version string, renderStatus int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/render":
+				if renderStatus != http.StatusOK {
+					w.WriteHeader(renderStatus)
+					return
+				}
+				require.Equal(t, healthCheckTarget, r.URL.Query().Get("target"))
+				_, _ = w.Write([]byte(`[{"target":"1","datapoints":[[1,1700000000]]}]`))
+			case "/metrics/find":
+				require.Equal(t, "*", r.URL.Query().Get("query"))
+				_, _ = w.Write([]byte(`[{"text":"carbon","id":"carbon","expandable":1,"leaf":0}]`))
+			case "/version":
+				if version == "" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(version + "\n"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+	}
+
+	t.Run("should report the version of Graphite", func(t *testing.T) {
+		s := newTestService(t, graphite("1.1.10", http.StatusOK), "1.1")
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working. Graphite version 1.1.10", res.Message)
+	})
+
+	t.Run("should report a different configured version", func(t *testing.T) {
+		s := newTestService(t, graphite("1.1.10", http.StatusOK), "1.0")
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working. Graphite version 1.1.10, but the data source is configured for version 1.0", res.Message)
+	})
+
+	t.Run("should succeed without version endpoint", func(t *testing.T) {
+		s := newTestService(t, graphite("", http.StatusOK), "1.0")
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working", res.Message)
+	})
+
+	t.Run("should fail if render fails", func(t *testing.T) {
+		s := newTestService(t, graphite("1.1.10", http.StatusInternalServerError), "1.1")
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Contains(t, res.Message, "failed to render a series")
+	})
+}
@@ -0,0 +1,125 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// resourcePaths are the paths of the Graphite API that can be called as resources of the data source.
+var resourcePaths = map[string]struct{}{
+	"metrics/find":             {},
+	"metrics/expand":           {},
+	"tags/autoComplete/tags":   {},
+	"tags/autoComplete/values": {},
+	"functions":                {},
+	"version":                  {},
+}
+
+// CallResource forwards the requests for metric find and expand, tag and value autocompletion, function listing
+// and version to the Graphite API.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	if _, ok := resourcePaths[req.Path]; !ok {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		logger.Error("Invalid HTTP method", "method", req.Method)
+		return fmt.Errorf("invalid HTTP method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	graphiteURL, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		logger.Error("Failed to parse data source URL", "error", err, "url", dsInfo.URL)
+		return err
+	}
+	resourceURL, err := url.Parse(req.URL)
+	if err != nil {
+		logger.Error("Failed to parse resource URL", "error", err, "url", req.URL)
+		return err
+	}
+	graphiteURL.Path = path.Join(graphiteURL.Path, req.Path)
+	graphiteURL.RawQuery = resourceURL.RawQuery
+
+	ctx, span := s.tracer.Start(ctx, "datasource.graphite.CallResource", trace.WithAttributes(
+		attribute.String("path", req.Path),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	))
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, req.Method, graphiteURL.String(), bytes.NewReader(req.Body))
+	if err != nil {
+		logger.Error("Failed to create request", "error", err, "url", graphiteURL.String())
+		return err
+	}
+	if contentType := firstHeader(req.Headers, "Content-Type"); contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	s.tracer.Inject(ctx, request.Header, span)
+
+	start := time.Now()
+	response, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		logger.Error("Error received from Graphite", "error", err, "status", status, "duration", time.Since(start), "resourcePath", req.Path)
+		return err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	span.SetAttributes(attribute.Int("graphite.response.code", response.StatusCode))
+	logger.Debug("Response received from Graphite", "statusCode", response.StatusCode, "duration", time.Since(start), "resourcePath", req.Path)
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		logger.Error("Error reading response body bytes", "error", err)
+		return err
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status: response.StatusCode,
+		Headers: map[string][]string{
+			"content-type": {contentType},
+		},
+		Body: body,
+	})
+}
+
+func firstHeader(headers map[string][]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (sender *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	sender.resp = resp
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics/find":
+			if r.Method == http.MethodPost {
+				body, _ := io.ReadAll(r.Body)
+				require.Equal(t, "query=apps.*", string(body))
+				require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+			} else {
+				require.Equal(t, "apps.*", r.URL.Query().Get("query"))
+			}
+			_, _ = w.Write([]byte(`[{"text":"backend","id":"apps.backend","expandable":1,"leaf":0}]`))
+		case "/metrics/expand":
+			require.Equal(t, "apps.*", r.URL.Query().Get("query"))
+			_, _ = w.Write([]byte(`{"results":["apps.backend","apps.frontend"]}`))
+		case "/tags/autoComplete/values":
+			require.Equal(t, "region", r.URL.Query().Get("tag"))
+			require.Equal(t, "eu", r.URL.Query().Get("valuePrefix"))
+			_, _ = w.Write([]byte(`["eu-west","eu-central"]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}, "")
+
+	call := func(method, path, url string, body string, headers map[string][]string) (*backend.CallResourceResponse, error) {
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method:  method,
+			Path:    path,
+			URL:     url,
+			Body:    []byte(body),
+			Headers: headers,
+		}, sender)
+		return sender.resp, err
+	}
+
+	t.Run("should find metrics", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "metrics/find", "metrics/find?query=apps.*", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `[{"text":"backend","id":"apps.backend","expandable":1,"leaf":0}]`, string(resp.Body))
+
+		resp, err = call(http.MethodPost, "metrics/find", "metrics/find", "query=apps.*", map[string][]string{"content-type": {"application/x-www-form-urlencoded"}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+	})
+
+	t.Run("should expand metrics", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "metrics/expand", "metrics/expand?query=apps.*", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"results":["apps.backend","apps.frontend"]}`, string(resp.Body))
+	})
+
+	t.Run("should autocomplete tag values", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "tags/autoComplete/values", "tags/autoComplete/values?tag=region&valuePrefix=eu", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["eu-west","eu-central"]`, string(resp.Body))
+	})
+
+	t.Run("should forward the status of Graphite", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "functions", "functions", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.Status)
+	})
+
+	t.Run("should reject other paths and methods", func(t *testing.T) {
+		_, err := call(http.MethodGet, "render", "render?target=foo", "", nil)
+		require.ErrorContains(t, err, "invalid resource URL")
+		_, err = call(http.MethodDelete, "metrics/find", "metrics/find", "", nil)
+		require.True(t, err != nil && strings.Contains(err.Error(), "invalid HTTP method"))
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng/sqlengtest"
 )
 
 func TestMacroEngine(t *testing.T) {
@@ -256,3 +258,17 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineCompatibility(t *testing.T) {
+	sqlengtest.RunMacroCompatibilityTests(t, newMssqlMacroEngine(), sqlengtest.MacroExpectations{
+		Time:           "time_column AS time",
+		TimeEpoch:      "DATEDIFF(second, '1970-01-01', time_column) AS time",
+		TimeFilter:     "time_column BETWEEN '2018-04-12T18:00:00Z' AND '2018-04-12T18:05:00Z'",
+		TimeFrom:       "'2018-04-12T18:00:00Z'",
+		TimeTo:         "'2018-04-12T18:05:00Z'",
+		TimeGroup:      "FLOOR(DATEDIFF(second, '1970-01-01', time_column)/300)*300",
+		UnixEpochGroup: "FLOOR(time_column/300)*300",
+		Epoch:          "DATEDIFF(second, '1970-01-01', time_column)",
+		Alias:          " AS [time]",
+	})
+}
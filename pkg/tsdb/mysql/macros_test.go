@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng/sqlengtest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -223,3 +224,18 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineCompatibility(t *testing.T) {
+	engine := newMysqlMacroEngine(log.New("test"), setting.NewCfg())
+	sqlengtest.RunMacroCompatibilityTests(t, engine, sqlengtest.MacroExpectations{
+		Time:           "UNIX_TIMESTAMP(time_column) as time_sec",
+		TimeEpoch:      "UNIX_TIMESTAMP(time_column) as time_sec",
+		TimeFilter:     "time_column BETWEEN FROM_UNIXTIME(1523556000) AND FROM_UNIXTIME(1523556300)",
+		TimeFrom:       "FROM_UNIXTIME(1523556000)",
+		TimeTo:         "FROM_UNIXTIME(1523556300)",
+		TimeGroup:      "UNIX_TIMESTAMP(time_column) DIV 300 * 300",
+		UnixEpochGroup: "time_column DIV 300 * 300",
+		Epoch:          "UNIX_TIMESTAMP(time_column)",
+		Alias:          ` AS "time"`,
+	})
+}
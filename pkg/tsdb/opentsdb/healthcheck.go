@@ -0,0 +1,122 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// CheckHealth checks that the OpenTSDB API can be reached with the credentials of the data source and detects the
+// version of OpenTSDB.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return getHealthCheckMessage(logger, "", fmt.Errorf("failed to get datasource information: %w", err))
+	}
+
+	body, err := doOpenTSDBRequest(ctx, logger, dsInfo, "api/version", nil)
+	if err != nil {
+		return getHealthCheckMessage(logger, "", err)
+	}
+	var version OpenTsdbVersion
+	if err := json.Unmarshal(body, &version); err != nil || version.Version == "" {
+		return getHealthCheckMessage(logger, "", errors.New("invalid response from the version endpoint, check that the URL is the URL of the OpenTSDB API"))
+	}
+
+	message := fmt.Sprintf("Data source is working. OpenTSDB version %s", version.Version)
+	if dsInfo.TSDBVersion > 0 && !versionMatches(dsInfo.TSDBVersion, version.Version) {
+		message += fmt.Sprintf(", but the data source is configured for version %s", configuredVersion(dsInfo.TSDBVersion))
+	}
+	return getHealthCheckMessage(logger, message, nil)
+}
+
+// configuredVersion returns the version of OpenTSDB of the tsdbVersion setting of the data source.
+func configuredVersion(tsdbVersion int) string {
+	if tsdbVersion == 1 {
+		return "2.1 or earlier"
+	}
+	return fmt.Sprintf("2.%d", tsdbVersion)
+}
+
+// versionMatches returns whether the version reported by OpenTSDB matches the tsdbVersion setting of the data
+// source. Versions that cannot be parsed are assumed to match.
+func versionMatches(tsdbVersion int, version string) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return true
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return true
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return true
+	}
+	if tsdbVersion == 1 {
+		return major < 2 || (major == 2 && minor <= 1)
+	}
+	return major == 2 && minor == tsdbVersion
+}
+
+// doOpenTSDBRequest sends a GET request to the given path of the OpenTSDB API and returns the body of the response.
+func doOpenTSDBRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, p string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, p)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("authentication failed, status: %s", res.Status)
+	case res.StatusCode/100 != 2:
+		return nil, errors.New(errorMessage(res, body))
+	}
+	return body, nil
+}
+
+func getHealthCheckMessage(logger log.Logger, message string, err error) (*backend.CheckHealthResult, error) {
+	if err == nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusOk,
+			Message: message,
+		}, nil
+	}
+
+	logger.Warn("OpenTSDB health check failed", "error", err)
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusError,
+		Message: fmt.Sprintf("OpenTSDB health check failed: %s", err.Error()),
+	}, nil
+}
@@ -0,0 +1,88 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+)
+
+type serverInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (m serverInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m serverInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+// newTestService returns a service whose data source is the given OpenTSDB API.
+func newTestService(t *testing.T, handler http.HandlerFunc, tsdbVersion int) *Service {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Service{
+		im: serverInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, TSDBVersion: tsdbVersion}},
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	opentsdb := func(status int, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/version" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}
+	}
+
+	t.Run("should report the version of OpenTSDB", func(t *testing.T) {
+		s := newTestService(t, opentsdb(http.StatusOK, `{"version":"2.4.1","short_revision":"abc"}`), 4)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working. OpenTSDB version 2.4.1", res.Message)
+	})
+
+	t.Run("should report a different configured version", func(t *testing.T) {
+		s := newTestService(t, opentsdb(http.StatusOK, `{"version":"2.4.1"}`), 1)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working. OpenTSDB version 2.4.1, but the data source is configured for version 2.1 or earlier", res.Message)
+	})
+
+	t.Run("should fail if the credentials are rejected", func(t *testing.T) {
+		s := newTestService(t, opentsdb(http.StatusUnauthorized, ``), 4)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "OpenTSDB health check failed: authentication failed, status: 401 Unauthorized", res.Message)
+	})
+
+	t.Run("should fail if the response is not a version", func(t *testing.T) {
+		s := newTestService(t, opentsdb(http.StatusOK, `<html></html>`), 4)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Contains(t, res.Message, "invalid response from the version endpoint")
+	})
+}
+
+func TestVersionMatches(t *testing.T) {
+	require.True(t, versionMatches(1, "2.1.0"))
+	require.True(t, versionMatches(1, "1.1.0"))
+	require.False(t, versionMatches(1, "2.2.0"))
+	require.True(t, versionMatches(3, "2.3.0-RC1"))
+	require.False(t, versionMatches(3, "2.4.0"))
+	require.True(t, versionMatches(4, "unknown"))
+}
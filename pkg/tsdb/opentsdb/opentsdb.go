@@ -3,6 +3,7 @@ package opentsdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -35,9 +36,22 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 	}
 }
 
+var (
+	_ backend.QueryDataHandler    = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+	_ backend.CallResourceHandler = (*Service)(nil)
+)
+
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// TSDBVersion is the version of OpenTSDB configured in the data source: 1 for 2.1 and earlier, 2 for 2.2, 3 for
+	// 2.3 and 4 for 2.4.
+	TSDBVersion int
+}
+
+type jsonData struct {
+	TSDBVersion int `json:"tsdbVersion"`
 }
 
 type DsAccess string
@@ -54,9 +68,18 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		// The settings are only used by the health check, so invalid settings do not prevent querying.
+		var jd jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				logger.Warn("Failed to parse data source settings", "error", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:  client,
+			URL:         settings.URL,
+			TSDBVersion: jd.TSDBVersion,
 		}
 
 		return model, nil
@@ -64,55 +87,120 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	myRefID := q.RefID
+	result := backend.NewQueryDataResponse()
+	for _, b := range s.buildBatches(dsInfo, req.Queries, result) {
+		for refID, res := range s.executeBatch(ctx, logger, dsInfo, b) {
+			result.Responses[refID] = res
+		}
+	}
+	return result, nil
+}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+// batch contains the queries of a request that have the same time range, which are sent to OpenTSDB in a single
+// request.
+type batch struct {
+	query  OpenTsdbQuery
+	refIDs []string
+}
 
-	for _, query := range req.Queries {
+// buildBatches groups the queries by time range. The queries that cannot be built get an error response in result.
+// OpenTSDB 2.1 and earlier do not return the index of the sub query of a series, so each query gets its own batch.
+func (s *Service) buildBatches(dsInfo *datasourceInfo, queries []backend.DataQuery, result *backend.QueryDataResponse) []*batch {
+	var batches []*batch
+	byTimeRange := make(map[backend.TimeRange]*batch)
+	for _, query := range queries {
 		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+		if metric == nil {
+			result.Responses[query.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, "failed to parse query")
+			continue
+		}
+		b, ok := byTimeRange[query.TimeRange]
+		if !ok || dsInfo.TSDBVersion <= 1 {
+			b = &batch{query: OpenTsdbQuery{
+				Start:     query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+				End:       query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+				ShowQuery: true,
+			}}
+			byTimeRange[query.TimeRange] = b
+			batches = append(batches, b)
+		}
+		b.query.Queries = append(b.query.Queries, metric)
+		b.refIDs = append(b.refIDs, query.RefID)
 	}
+	return batches
+}
 
+// executeBatch sends the queries of the batch in a single request and returns the response of each query. OpenTSDB
+// fails the whole request if one of the queries is invalid, for example if its metric does not exist, in which case
+// the queries are sent one by one so that the error is reported for the invalid queries only. The queries are also
+// sent one by one if a series of the response cannot be matched to its query.
+func (s *Service) executeBatch(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, b *batch) map[string]backend.DataResponse {
 	// TODO: Don't use global variable
 	if setting.Env == setting.Dev {
-		logger.Debug("OpenTsdb request", "params", tsdbQuery)
+		logger.Debug("OpenTsdb request", "params", b.query)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
+	responses, err := s.doQuery(ctx, logger, dsInfo, b)
+	if err == nil {
+		return responses
 	}
 
-	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
+	var qErr *queryError
+	invalidQuery := errors.As(err, &qErr) && qErr.status == http.StatusBadRequest
+	if len(b.refIDs) > 1 && (invalidQuery || errors.Is(err, errUnmatchedSeries)) {
+		logger.Debug("Batch of queries failed, retrying the queries one by one", "error", err)
+		responses := make(map[string]backend.DataResponse, len(b.refIDs))
+		for i, refID := range b.refIDs {
+			single := &batch{
+				query: OpenTsdbQuery{
+					Start:     b.query.Start,
+					End:       b.query.End,
+					Queries:   b.query.Queries[i : i+1],
+					ShowQuery: true,
+				},
+				refIDs: []string{refID},
+			}
+			for refID, res := range s.executeBatch(ctx, logger, dsInfo, single) {
+				responses[refID] = res
+			}
+		}
+		return responses
+	}
+
+	status := backend.StatusInternal
+	if errors.As(err, &qErr) {
+		status = backend.Status(qErr.status)
+	}
+	responses = make(map[string]backend.DataResponse, len(b.refIDs))
+	for _, refID := range b.refIDs {
+		responses[refID] = backend.ErrDataResponse(status, err.Error())
+	}
+	return responses
+}
+
+func (s *Service) doQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, b *batch) (map[string]backend.DataResponse, error) {
+	request, err := s.createRequest(ctx, logger, dsInfo, b.query)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
-	defer func() {
-		err := res.Body.Close()
-		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
-		}
-	}()
-
-	result, err := s.parseResponse(logger, res, myRefID)
+	result, err := s.parseBatchResponse(logger, res, b.refIDs)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
-
-	return result, nil
+	return result.Responses, nil
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -139,6 +227,25 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 }
 
 func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+	return s.parseBatchResponse(logger, res, []string{myRefID})
+}
+
+// queryError is returned when OpenTSDB rejects a query.
+type queryError struct {
+	status  int
+	message string
+}
+
+func (e *queryError) Error() string {
+	return e.message
+}
+
+// errUnmatchedSeries is returned when a series of the response to a batch has no index of its sub query.
+var errUnmatchedSeries = errors.New("failed to match series to a query")
+
+// parseBatchResponse parses the response to a request with a query for each of the refIDs. The series are assigned
+// to the query whose index is returned by OpenTSDB with the series.
+func (s *Service) parseBatchResponse(logger log.Logger, res *http.Response, refIDs []string) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -153,7 +260,7 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		return nil, &queryError{status: res.StatusCode, message: errorMessage(res, body)}
 	}
 
 	var responseData []OpenTsdbResponse
@@ -163,7 +270,7 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		return nil, err
 	}
 
-	frames := data.Frames{}
+	framesByRefID := make(map[string]data.Frames, len(refIDs))
 	for _, val := range responseData {
 		timeVector := make([]time.Time, 0, len(val.DataPoints))
 		values := make([]float64, 0, len(val.DataPoints))
@@ -179,16 +286,38 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
 			values = append(values, value)
 		}
-		frames = append(frames, data.NewFrame(name,
+
+		refID := refIDs[0]
+		if len(refIDs) > 1 {
+			if val.Query == nil || val.Query.Index < 0 || val.Query.Index >= len(refIDs) {
+				logger.Debug("Failed to match series to a query", "metric", val.Metric)
+				return nil, errUnmatchedSeries
+			}
+			refID = refIDs[val.Query.Index]
+		}
+		framesByRefID[refID] = append(framesByRefID[refID], data.NewFrame(name,
 			data.NewField("time", nil, timeVector),
 			data.NewField("value", tags, values)))
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
+	for _, refID := range refIDs {
+		frames := framesByRefID[refID]
+		if frames == nil {
+			frames = data.Frames{}
+		}
+		resp.Responses[refID] = backend.DataResponse{Frames: frames}
+	}
 	return resp, nil
 }
 
+// errorMessage returns the message of the error returned by OpenTSDB, or the status of the response.
+func errorMessage(res *http.Response, body []byte) string {
+	var errResponse OpenTsdbErrorResponse
+	if err := json.Unmarshal(body, &errResponse); err == nil && errResponse.Error.Message != "" {
+		return errResponse.Error.Message
+	}
+	return fmt.Sprintf("request failed, status: %s", res.Status)
+}
+
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	metric := make(map[string]any)
 
@@ -2,6 +2,8 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -277,3 +279,125 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
 }
+
+func TestQueryData(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(1405544100, 0), To: time.Unix(1405544200, 0)}
+	query := func(refID, metric string, tr backend.TimeRange) backend.DataQuery {
+		return backend.DataQuery{
+			RefID:     refID,
+			TimeRange: tr,
+			JSON:      []byte(`{"metric": "` + metric + `", "aggregator": "avg", "disableDownsampling": true}`),
+		}
+	}
+
+	var requests []OpenTsdbQuery
+	// withIndex makes the server return the index of the sub query of each series, as OpenTSDB 2.2 and later do
+	withIndex := true
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/query", r.URL.Path)
+		var q OpenTsdbQuery
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+		requests = append(requests, q)
+		require.True(t, q.ShowQuery)
+
+		var series []string
+		for i, sub := range q.Queries {
+			if sub["metric"] == "unknown" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"code":400,"message":"No such name for 'metrics': 'unknown'"}}`))
+				return
+			}
+			subQuery := ""
+			if withIndex {
+				subQuery = fmt.Sprintf(`,"query":{"index":%d}`, i)
+			}
+			series = append(series, fmt.Sprintf(`{"metric":"%s","tags":{},"dps":{"1405544146":%d}%s}`, sub["metric"], i, subQuery))
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(series, ",") + "]"))
+	}
+	s := newTestService(t, handler, 4)
+
+	t.Run("should send the queries with the same time range in a single request", func(t *testing.T) {
+		requests = nil
+		other := backend.TimeRange{From: timeRange.From.Add(-time.Hour), To: timeRange.To}
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				query("A", "cpu", timeRange),
+				query("B", "mem", timeRange),
+				query("C", "disk", other),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		require.Len(t, requests[0].Queries, 2)
+		require.Len(t, requests[1].Queries, 1)
+		require.Equal(t, other.From.UnixMilli(), requests[1].Start)
+
+		for refID, metric := range map[string]string{"A": "cpu", "B": "mem", "C": "disk"} {
+			require.NoError(t, resp.Responses[refID].Error)
+			require.Len(t, resp.Responses[refID].Frames, 1)
+			require.Equal(t, metric, resp.Responses[refID].Frames[0].Name)
+		}
+	})
+
+	t.Run("should report the error of an invalid query on its response only", func(t *testing.T) {
+		requests = nil
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				query("A", "cpu", timeRange),
+				query("B", "unknown", timeRange),
+				{RefID: "C", TimeRange: timeRange, JSON: []byte(`{ invalid }`)},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 3)
+
+		require.NoError(t, resp.Responses["A"].Error)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.EqualError(t, resp.Responses["B"].Error, "No such name for 'metrics': 'unknown'")
+		require.Equal(t, backend.StatusBadRequest, resp.Responses["B"].Status)
+		require.Error(t, resp.Responses["C"].Error)
+	})
+
+	t.Run("should send the queries one by one if the series have no index", func(t *testing.T) {
+		requests = nil
+		withIndex = false
+		t.Cleanup(func() { withIndex = true })
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				query("A", "cpu", timeRange),
+				query("B", "mem", timeRange),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 3)
+		require.Len(t, requests[0].Queries, 2)
+
+		for refID, metric := range map[string]string{"A": "cpu", "B": "mem"} {
+			require.NoError(t, resp.Responses[refID].Error)
+			require.Len(t, resp.Responses[refID].Frames, 1)
+			require.Equal(t, metric, resp.Responses[refID].Frames[0].Name)
+		}
+	})
+
+	t.Run("should not batch the queries to OpenTSDB 2.1 and earlier", func(t *testing.T) {
+		requests = nil
+		withIndex = false
+		t.Cleanup(func() { withIndex = true })
+		resp, err := newTestService(t, handler, 1).QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				query("A", "cpu", timeRange),
+				query("B", "mem", timeRange),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		for i, refID := range []string{"A", "B"} {
+			require.Len(t, requests[i].Queries, 1)
+			require.NoError(t, resp.Responses[refID].Error)
+			require.Len(t, resp.Responses[refID].Frames, 1)
+		}
+		require.Equal(t, "cpu", resp.Responses["A"].Frames[0].Name)
+		require.Equal(t, "mem", resp.Responses["B"].Frames[0].Name)
+	})
+}
@@ -0,0 +1,201 @@
+package opentsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	tagKeysPath   = "tag-keys"
+	tagValuesPath = "tag-values"
+
+	// lookupLimit is the maximum number of series returned by a lookup for the discovery of tag keys and values.
+	lookupLimit = "1000"
+)
+
+// resourcePaths are the paths of the OpenTSDB API that can be called as resources of the data source.
+var resourcePaths = map[string]struct{}{
+	"api/suggest":       {},
+	"api/search/lookup": {},
+}
+
+// CallResource forwards the requests for suggestions and lookups to the OpenTSDB API, and discovers the tag keys of
+// a metric and the tag values of a tag key of a metric.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	switch req.Path {
+	case tagKeysPath, tagValuesPath:
+		if req.Method != http.MethodGet {
+			logger.Error("Invalid HTTP method", "method", req.Method)
+			return fmt.Errorf("invalid HTTP method: %s", req.Method)
+		}
+		return s.handleTagDiscovery(ctx, req, sender)
+	}
+	if _, ok := resourcePaths[req.Path]; !ok {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		logger.Error("Invalid HTTP method", "method", req.Method)
+		return fmt.Errorf("invalid HTTP method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	tsdbURL, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		logger.Error("Failed to parse data source URL", "error", err, "url", dsInfo.URL)
+		return err
+	}
+	resourceURL, err := url.Parse(req.URL)
+	if err != nil {
+		logger.Error("Failed to parse resource URL", "error", err, "url", req.URL)
+		return err
+	}
+	tsdbURL.Path = path.Join(tsdbURL.Path, req.Path)
+	tsdbURL.RawQuery = resourceURL.RawQuery
+
+	request, err := http.NewRequestWithContext(ctx, req.Method, tsdbURL.String(), bytes.NewReader(req.Body))
+	if err != nil {
+		logger.Error("Failed to create request", "error", err, "url", tsdbURL.String())
+		return err
+	}
+	if contentType := firstHeader(req.Headers, "Content-Type"); contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	start := time.Now()
+	response, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		logger.Error("Error received from OpenTSDB", "error", err, "status", status, "duration", time.Since(start), "resourcePath", req.Path)
+		return err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	logger.Debug("Response received from OpenTSDB", "statusCode", response.StatusCode, "duration", time.Since(start), "resourcePath", req.Path)
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		logger.Error("Error reading response body bytes", "error", err)
+		return err
+	}
+
+	contentType := response.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status: response.StatusCode,
+		Headers: map[string][]string{
+			"content-type": {contentType},
+		},
+		Body: body,
+	})
+}
+
+// handleTagDiscovery looks up the series of the metric and returns the sorted tag keys of the series, or the sorted
+// values of the tag key of the series.
+func (s *Service) handleTagDiscovery(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	resourceURL, err := url.Parse(req.URL)
+	if err != nil {
+		logger.Error("Failed to parse resource URL", "error", err, "url", req.URL)
+		return err
+	}
+	params := resourceURL.Query()
+	metric := params.Get("metric")
+	key := params.Get("key")
+	if metric == "" {
+		return sendJSON(sender, http.StatusBadRequest, map[string]string{"message": "missing metric"})
+	}
+	if req.Path == tagValuesPath && key == "" {
+		return sendJSON(sender, http.StatusBadRequest, map[string]string{"message": "missing key"})
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	m := metric
+	if req.Path == tagValuesPath {
+		m = fmt.Sprintf("%s{%s=*}", metric, key)
+	}
+	body, err := doOpenTSDBRequest(ctx, logger, dsInfo, "api/search/lookup", url.Values{
+		"m":     []string{m},
+		"limit": []string{lookupLimit},
+	})
+	if err != nil {
+		logger.Warn("Failed to look up series", "error", err, "metric", metric)
+		return sendJSON(sender, http.StatusBadGateway, map[string]string{"message": err.Error()})
+	}
+	var lookup OpenTsdbLookupResponse
+	if err := json.Unmarshal(body, &lookup); err != nil {
+		logger.Warn("Failed to unmarshal lookup response", "error", err)
+		return sendJSON(sender, http.StatusBadGateway, map[string]string{"message": "invalid lookup response"})
+	}
+
+	found := make(map[string]struct{})
+	for _, r := range lookup.Results {
+		if req.Path == tagKeysPath {
+			for k := range r.Tags {
+				found[k] = struct{}{}
+			}
+		} else if v, ok := r.Tags[key]; ok {
+			found[v] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(found))
+	for v := range found {
+		result = append(result, v)
+	}
+	sort.Strings(result)
+	return sendJSON(sender, http.StatusOK, result)
+}
+
+func sendJSON(sender backend.CallResourceResponseSender, status int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status: status,
+		Headers: map[string][]string{
+			"content-type": {"application/json"},
+		},
+		Body: body,
+	})
+}
+
+func firstHeader(headers map[string][]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (sender *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	sender.resp = resp
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/suggest":
+			require.Equal(t, "metrics", r.URL.Query().Get("type"))
+			require.Equal(t, "sys", r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(`["sys.cpu.user","sys.cpu.system"]`))
+		case "/api/search/lookup":
+			require.Equal(t, lookupLimit, r.URL.Query().Get("limit"))
+			switch r.URL.Query().Get("m") {
+			case "sys.cpu.user":
+				_, _ = w.Write([]byte(`{"results":[{"metric":"sys.cpu.user","tags":{"host":"b","dc":"eu"}},{"metric":"sys.cpu.user","tags":{"host":"a"}}]}`))
+			case "sys.cpu.user{host=*}":
+				_, _ = w.Write([]byte(`{"results":[{"metric":"sys.cpu.user","tags":{"host":"b","dc":"eu"}},{"metric":"sys.cpu.user","tags":{"host":"a"}},{"metric":"sys.cpu.user","tags":{"host":"a","dc":"us"}}]}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"code":400,"message":"No such name for 'metrics'"}}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}, 4)
+
+	call := func(method, path, url string) (*backend.CallResourceResponse, error) {
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: method,
+			Path:   path,
+			URL:    url,
+		}, sender)
+		return sender.resp, err
+	}
+
+	t.Run("should suggest metrics", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "api/suggest", "api/suggest?type=metrics&q=sys")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["sys.cpu.user","sys.cpu.system"]`, string(resp.Body))
+	})
+
+	t.Run("should return the tag keys of a metric", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "tag-keys", "tag-keys?metric=sys.cpu.user")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["dc","host"]`, string(resp.Body))
+	})
+
+	t.Run("should return the tag values of a tag key of a metric", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "tag-values", "tag-values?metric=sys.cpu.user&key=host")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["a","b"]`, string(resp.Body))
+	})
+
+	t.Run("should return the error of the lookup", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "tag-keys", "tag-keys?metric=unknown")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadGateway, resp.Status)
+		require.JSONEq(t, `{"message":"No such name for 'metrics'"}`, string(resp.Body))
+	})
+
+	t.Run("should require the tag key", func(t *testing.T) {
+		resp, err := call(http.MethodGet, "tag-values", "tag-values?metric=sys.cpu.user")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("should reject other paths", func(t *testing.T) {
+		_, err := call(http.MethodGet, "api/put", "api/put")
+		require.Error(t, err)
+	})
+
+	t.Run("should reject other methods", func(t *testing.T) {
+		_, err := call(http.MethodDelete, "api/suggest", "api/suggest")
+		require.Error(t, err)
+	})
+}
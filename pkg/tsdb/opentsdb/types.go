@@ -4,10 +4,36 @@ type OpenTsdbQuery struct {
 	Start   int64            `json:"start"`
 	End     int64            `json:"end"`
 	Queries []map[string]any `json:"queries"`
+	// ShowQuery makes OpenTSDB return the sub query of each series, so that the series can be matched to the query.
+	ShowQuery bool `json:"showQuery,omitempty"`
 }
 
 type OpenTsdbResponse struct {
 	Metric     string             `json:"metric"`
 	Tags       map[string]string  `json:"tags"`
 	DataPoints map[string]float64 `json:"dps"`
+	Query      *OpenTsdbSubQuery  `json:"query,omitempty"`
+}
+
+// OpenTsdbSubQuery is the sub query of a series, returned when the request has ShowQuery set.
+type OpenTsdbSubQuery struct {
+	Index int `json:"index"`
+}
+
+type OpenTsdbErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type OpenTsdbVersion struct {
+	Version string `json:"version"`
+}
+
+type OpenTsdbLookupResponse struct {
+	Results []struct {
+		Metric string            `json:"metric"`
+		Tags   map[string]string `json:"tags"`
+	} `json:"results"`
 }
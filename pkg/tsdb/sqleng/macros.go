@@ -0,0 +1,238 @@
+package sqleng
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// AllValue is the value of a template variable for which the conditional blocks $__ifAll and $__ifNotAll
+// consider that all values are selected. It must be set as the custom all value of the variable.
+const AllValue = "$__all"
+
+var (
+	macroRegExp = regexp.MustCompile(`\$([_a-zA-Z0-9]+)\(([^\)]*)\)`)
+	// conditionalRegExp matches $__ifAll(value) ... [$__else ...] $__endIf blocks, which cannot be nested.
+	conditionalRegExp = regexp.MustCompile(`(?s)\$__if(Not)?All\(([^\)]*)\)(.*?)(?:\$__else(?:\(\))?(.*?))?\$__endIf(?:\(\))?`)
+)
+
+// SQLMacroDialect translates the macros interpolated by InterpolateMacros to the SQL dialect of a data source.
+type SQLMacroDialect interface {
+	// Time returns the expression of $__time(column).
+	Time(column string) string
+	// TimeEpoch returns the expression of $__timeEpoch(column).
+	TimeEpoch(column string) string
+	// TimeFilter returns the condition of $__timeFilter(column).
+	TimeFilter(column string, timeRange backend.TimeRange) string
+	// TimeLiteral returns the literal of a time, used by $__timeFrom() and $__timeTo().
+	TimeLiteral(t time.Time) string
+	// TimeGroup returns the expression of $__timeGroup(column, interval).
+	TimeGroup(column string, interval time.Duration) string
+	// UnixEpochGroup returns the expression of $__unixEpochGroup(column, interval).
+	UnixEpochGroup(column string, interval time.Duration) string
+	// Epoch returns the time column converted to seconds since epoch, used by $__timeBucket(column, interval).
+	Epoch(column string) string
+	// Alias returns the expression aliased as the time column, used by the macros with the Alias suffix.
+	Alias(expression string) string
+}
+
+// InterpolateMacros interpolates the conditional blocks and the macros of the query with the given dialect. The
+// macros are:
+//   - $__time(column), $__timeEpoch(column) and $__timeFilter(column)
+//   - $__timeFrom() and $__timeTo()
+//   - $__timeGroup(column, interval[, fill]) and $__timeGroupAlias(column, interval[, fill])
+//   - $__timeBucket(column, interval[, alignment]) and $__timeBucketAlias(column, interval[, alignment]), where
+//     alignment is from to align the buckets to the start of the time range, or a duration to shift the buckets by
+//   - $__unixEpochFilter(column), $__unixEpochNanoFilter(column), $__unixEpochNanoFrom() and $__unixEpochNanoTo()
+//   - $__unixEpochGroup(column, interval[, fill]) and $__unixEpochGroupAlias(column, interval[, fill])
+//
+// The conditional blocks $__ifAll(value) ... [$__else ...] $__endIf and $__ifNotAll(value) ... $__endIf keep their
+// content depending on whether the value, usually a template variable, is AllValue.
+func InterpolateMacros(dialect SQLMacroDialect, query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	sql = interpolateConditionals(sql)
+
+	var macroError error
+	sql = (&SQLMacroEngineBase{}).ReplaceAllStringSubmatchFunc(macroRegExp, sql, func(groups []string) string {
+		args := strings.Split(groups[2], ",")
+		for i, arg := range args {
+			args[i] = strings.Trim(arg, " ")
+		}
+		res, err := evaluateMacro(dialect, timeRange, query, groups[1], args)
+		if err != nil && macroError == nil {
+			macroError = err
+			return "macro_error()"
+		}
+		return res
+	})
+
+	if macroError != nil {
+		return "", macroError
+	}
+
+	return sql, nil
+}
+
+func interpolateConditionals(sql string) string {
+	return conditionalRegExp.ReplaceAllStringFunc(sql, func(block string) string {
+		groups := conditionalRegExp.FindStringSubmatch(block)
+		if isAllValue(groups[2]) == (groups[1] == "") {
+			return groups[3]
+		}
+		return groups[4]
+	})
+}
+
+func isAllValue(value string) bool {
+	return strings.Trim(strings.TrimSpace(value), `'"`) == AllValue
+}
+
+//nolint:gocyclo
+func evaluateMacro(dialect SQLMacroDialect, timeRange backend.TimeRange, query *backend.DataQuery, name string, args []string) (string, error) {
+	switch name {
+	case "__time":
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return dialect.Time(args[0]), nil
+	case "__timeEpoch":
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return dialect.TimeEpoch(args[0]), nil
+	case "__timeFilter":
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return dialect.TimeFilter(args[0], timeRange), nil
+	case "__timeFrom":
+		return dialect.TimeLiteral(timeRange.From), nil
+	case "__timeTo":
+		return dialect.TimeLiteral(timeRange.To), nil
+	case "__timeGroup":
+		interval, err := groupInterval(query, name, args)
+		if err != nil {
+			return "", err
+		}
+		return dialect.TimeGroup(args[0], interval), nil
+	case "__timeGroupAlias":
+		tg, err := evaluateMacro(dialect, timeRange, query, "__timeGroup", args)
+		if err != nil {
+			return "", err
+		}
+		return dialect.Alias(tg), nil
+	case "__timeBucket":
+		return timeBucket(dialect, timeRange, name, args)
+	case "__timeBucketAlias":
+		tb, err := evaluateMacro(dialect, timeRange, query, "__timeBucket", args)
+		if err != nil {
+			return "", err
+		}
+		return dialect.Alias(tb), nil
+	case "__unixEpochFilter":
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().Unix(), args[0], timeRange.To.UTC().Unix()), nil
+	case "__unixEpochNanoFilter":
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.From.UTC().UnixNano(), args[0], timeRange.To.UTC().UnixNano()), nil
+	case "__unixEpochNanoFrom":
+		return fmt.Sprintf("%d", timeRange.From.UTC().UnixNano()), nil
+	case "__unixEpochNanoTo":
+		return fmt.Sprintf("%d", timeRange.To.UTC().UnixNano()), nil
+	case "__unixEpochGroup":
+		interval, err := groupInterval(query, name, args)
+		if err != nil {
+			return "", err
+		}
+		return dialect.UnixEpochGroup(args[0], interval), nil
+	case "__unixEpochGroupAlias":
+		tg, err := evaluateMacro(dialect, timeRange, query, "__unixEpochGroup", args)
+		if err != nil {
+			return "", err
+		}
+		return dialect.Alias(tg), nil
+	case "__ifAll", "__ifNotAll":
+		return "", fmt.Errorf("missing $__endIf for macro %v", name)
+	default:
+		return "", fmt.Errorf("unknown macro %q", name)
+	}
+}
+
+// groupInterval returns the interval of a group macro and sets up the fill mode of the query if it is given.
+func groupInterval(query *backend.DataQuery, name string, args []string) (time.Duration, error) {
+	if len(args) < 2 {
+		return 0, fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+	}
+	interval, err := parseMacroInterval(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("error parsing interval %v", args[1])
+	}
+	if len(args) == 3 {
+		err := SetupFillmode(query, interval, args[2])
+		if err != nil {
+			return 0, err
+		}
+	}
+	return interval, nil
+}
+
+// timeBucket returns the start of the bucket of the time column in seconds since epoch. The buckets are aligned to
+// the epoch, to the start of the time range, or shifted by a duration.
+func timeBucket(dialect SQLMacroDialect, timeRange backend.TimeRange, name string, args []string) (string, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return "", fmt.Errorf("macro %v needs time column and interval and optional alignment", name)
+	}
+	interval, err := parseMacroInterval(args[1])
+	if err != nil || interval <= 0 {
+		return "", fmt.Errorf("error parsing interval %v", args[1])
+	}
+
+	var offset time.Duration
+	if len(args) == 3 {
+		alignment := strings.Trim(args[2], `'"`)
+		switch {
+		case strings.EqualFold(alignment, "from"):
+			offset = time.Duration(timeRange.From.UnixNano())
+		case strings.HasPrefix(alignment, "-"):
+			d, err := parseMacroInterval(alignment[1:])
+			if err != nil {
+				return "", fmt.Errorf("error parsing alignment %v", args[2])
+			}
+			offset = -d
+		default:
+			d, err := parseMacroInterval(alignment)
+			if err != nil {
+				return "", fmt.Errorf("error parsing alignment %v", args[2])
+			}
+			offset = d
+		}
+		offset %= interval
+		if offset < 0 {
+			offset += interval
+		}
+	}
+
+	epoch := dialect.Epoch(args[0])
+	seconds := formatSeconds(interval)
+	if offset == 0 {
+		return fmt.Sprintf("FLOOR(%s/%s)*%s", epoch, seconds, seconds), nil
+	}
+	o := formatSeconds(offset)
+	return fmt.Sprintf("FLOOR((%s-%s)/%s)*%s+%s", epoch, o, seconds, seconds, o), nil
+}
+
+func parseMacroInterval(value string) (time.Duration, error) {
+	return gtime.ParseInterval(strings.Trim(value, `'"`))
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"regexp"
 	"strconv"
@@ -48,6 +49,8 @@ type SqlQueryResultTransformer interface {
 
 var sqlIntervalCalculator = intervalv2.NewCalculator()
 
+var intervalArithmeticRegExp = regexp.MustCompile(`\$__intervalMs\(\s*([*/+-])\s*([0-9]+(?:\.[0-9]+)?)\s*\)`)
+
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
 //
 //nolint:gocritic
@@ -409,6 +412,10 @@ var Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, tim
 	}
 	interval := sqlIntervalCalculator.Calculate(timeRange, minInterval, query.MaxDataPoints)
 
+	sql, err = interpolateIntervalArithmetic(sql, interval.Value)
+	if err != nil {
+		return "", err
+	}
 	sql = strings.ReplaceAll(sql, "$__interval_ms", strconv.FormatInt(interval.Milliseconds(), 10))
 	sql = strings.ReplaceAll(sql, "$__interval", interval.Text)
 	sql = strings.ReplaceAll(sql, "$__unixEpochFrom()", fmt.Sprintf("%d", timeRange.From.UTC().Unix()))
@@ -417,6 +424,42 @@ var Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, tim
 	return sql, nil
 }
 
+// interpolateIntervalArithmetic replaces $__intervalMs(<operator> <number>) with the interval in milliseconds
+// multiplied, divided, increased or decreased by the number, for example $__intervalMs(* 2). The macro is not named
+// after $__interval_ms, because dashboards replace $__interval_ms before the query is sent.
+func interpolateIntervalArithmetic(sql string, interval time.Duration) (string, error) {
+	var arithmeticError error
+	sql = intervalArithmeticRegExp.ReplaceAllStringFunc(sql, func(match string) string {
+		groups := intervalArithmeticRegExp.FindStringSubmatch(match)
+		operand, _ := strconv.ParseFloat(groups[2], 64)
+		ms := float64(interval.Milliseconds())
+		switch groups[1] {
+		case "*":
+			ms *= operand
+		case "/":
+			if operand == 0 {
+				if arithmeticError == nil {
+					arithmeticError = fmt.Errorf("division by zero in %v", match)
+				}
+				return match
+			}
+			ms /= operand
+		case "+":
+			ms += operand
+		case "-":
+			ms -= operand
+		}
+		return strconv.FormatInt(int64(math.Round(ms)), 10)
+	})
+	if arithmeticError != nil {
+		return "", arithmeticError
+	}
+	if strings.Contains(sql, "$__intervalMs") {
+		return "", fmt.Errorf("invalid $__intervalMs macro, expected $__intervalMs(<operator> <number>)")
+	}
+	return sql, nil
+}
+
 func (e *DataSourceHandler) newProcessCfg(query backend.DataQuery, queryContext context.Context,
 	rows *core.Rows, interpolatedQuery string) (*dataQueryModel, error) {
 	columnNames, err := rows.Columns()
@@ -43,6 +43,22 @@ func TestSQLEngine(t *testing.T) {
 			require.Equal(t, "select 60000 ", sql)
 		})
 
+		t.Run("interpolate $__intervalMs arithmetic", func(t *testing.T) {
+			sql, err := Interpolate(query, timeRange, "", "select $__intervalMs(*2), $__intervalMs( / 4 ), $__intervalMs(+500), $__intervalMs(- 1000), $__interval_ms")
+			require.NoError(t, err)
+			require.Equal(t, "select 120000, 15000, 60500, 59000, 60000", sql)
+		})
+
+		t.Run("interpolate $__intervalMs division by zero", func(t *testing.T) {
+			_, err := Interpolate(query, timeRange, "", "select $__intervalMs(/0)")
+			require.EqualError(t, err, "division by zero in $__intervalMs(/0)")
+		})
+
+		t.Run("interpolate $__intervalMs without arithmetic", func(t *testing.T) {
+			_, err := Interpolate(query, timeRange, "", "select $__intervalMs, $__intervalMs(%2)")
+			require.EqualError(t, err, "invalid $__intervalMs macro, expected $__intervalMs(<operator> <number>)")
+		})
+
 		t.Run("interpolate __unixEpochFrom function", func(t *testing.T) {
 			sql, err := Interpolate(query, timeRange, "", "select $__unixEpochFrom()")
 			require.NoError(t, err)
@@ -0,0 +1,145 @@
+package sqlengtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// MacroExpectations are the interpolations of the macros of a dialect for the column time_column, the interval 5m
+// and the time range between 2018-04-12 18:00 and 2018-04-12 18:05.
+type MacroExpectations struct {
+	Time           string
+	TimeEpoch      string
+	TimeFilter     string
+	TimeFrom       string
+	TimeTo         string
+	TimeGroup      string
+	UnixEpochGroup string
+	// Epoch is time_column converted to seconds since epoch.
+	Epoch string
+	// Alias is appended to the expressions of the macros with the Alias suffix.
+	Alias string
+}
+
+// RunMacroCompatibilityTests checks that the macro engine of a data source interpolates the macros shared by all
+// SQL data sources like the other data sources.
+func RunMacroCompatibilityTests(t *testing.T, engine sqleng.SQLMacroEngine, expected MacroExpectations) {
+	t.Helper()
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	interpolate := func(t *testing.T, sql string) string {
+		t.Helper()
+		res, err := engine.Interpolate(&backend.DataQuery{JSON: []byte("{}")}, timeRange, sql)
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("should interpolate the time macros", func(t *testing.T) {
+		require.Equal(t, "SELECT "+expected.Time, interpolate(t, "SELECT $__time(time_column)"))
+		require.Equal(t, "SELECT "+expected.TimeEpoch, interpolate(t, "SELECT $__timeEpoch(time_column)"))
+		require.Equal(t, "WHERE "+expected.TimeFilter, interpolate(t, "WHERE $__timeFilter(time_column)"))
+		require.Equal(t, "SELECT "+expected.TimeFrom, interpolate(t, "SELECT $__timeFrom()"))
+		require.Equal(t, "SELECT "+expected.TimeTo, interpolate(t, "SELECT $__timeTo()"))
+	})
+
+	t.Run("should interpolate the group macros and their aliases", func(t *testing.T) {
+		require.Equal(t, "GROUP BY "+expected.TimeGroup, interpolate(t, "GROUP BY $__timeGroup(time_column,'5m')"))
+		require.Equal(t, "SELECT "+expected.TimeGroup+expected.Alias, interpolate(t, "SELECT $__timeGroupAlias(time_column,'5m')"))
+		require.Equal(t, "GROUP BY "+expected.UnixEpochGroup, interpolate(t, "GROUP BY $__unixEpochGroup(time_column,'5m')"))
+		require.Equal(t, "SELECT "+expected.UnixEpochGroup+expected.Alias, interpolate(t, "SELECT $__unixEpochGroupAlias(time_column,'5m')"))
+	})
+
+	t.Run("should accept quoted and unquoted intervals and spaces around arguments", func(t *testing.T) {
+		for _, sql := range []string{
+			"GROUP BY $__timeGroup(time_column,5m)",
+			`GROUP BY $__timeGroup(time_column,"5m")`,
+			"GROUP BY $__timeGroup( time_column , '5m' )",
+		} {
+			require.Equal(t, "GROUP BY "+expected.TimeGroup, interpolate(t, sql), sql)
+		}
+	})
+
+	t.Run("should interpolate the time bucket macros", func(t *testing.T) {
+		require.Equal(t, fmt.Sprintf("GROUP BY FLOOR(%s/300)*300", expected.Epoch), interpolate(t, "GROUP BY $__timeBucket(time_column,'5m')"))
+		require.Equal(t, fmt.Sprintf("SELECT FLOOR(%s/300)*300%s", expected.Epoch, expected.Alias), interpolate(t, "SELECT $__timeBucketAlias(time_column,'5m')"))
+		require.Equal(t, fmt.Sprintf("GROUP BY FLOOR(%s/0.5)*0.5", expected.Epoch), interpolate(t, "GROUP BY $__timeBucket(time_column,'500ms')"))
+	})
+
+	t.Run("should align the time buckets", func(t *testing.T) {
+		require.Equal(t, fmt.Sprintf("GROUP BY FLOOR((%s-60)/300)*300+60", expected.Epoch), interpolate(t, "GROUP BY $__timeBucket(time_column,'5m','1m')"))
+		require.Equal(t, fmt.Sprintf("GROUP BY FLOOR((%s-240)/300)*300+240", expected.Epoch), interpolate(t, "GROUP BY $__timeBucket(time_column,'5m','-1m')"))
+		require.Equal(t, fmt.Sprintf("GROUP BY FLOOR(%s/300)*300", expected.Epoch), interpolate(t, "GROUP BY $__timeBucket(time_column,'5m','1h')"))
+
+		offset := from.Unix() % 420
+		require.Equal(t, fmt.Sprintf("GROUP BY FLOOR((%s-%d)/420)*420+%d", expected.Epoch, offset, offset), interpolate(t, "GROUP BY $__timeBucket(time_column,'7m',from)"))
+	})
+
+	t.Run("should interpolate the unix epoch macros", func(t *testing.T) {
+		require.Equal(t, fmt.Sprintf("WHERE time_column >= %d AND time_column <= %d", from.Unix(), to.Unix()), interpolate(t, "WHERE $__unixEpochFilter(time_column)"))
+		require.Equal(t, fmt.Sprintf("WHERE time_column >= %d AND time_column <= %d", from.UnixNano(), to.UnixNano()), interpolate(t, "WHERE $__unixEpochNanoFilter(time_column)"))
+		require.Equal(t, fmt.Sprintf("SELECT %d, %d", from.UnixNano(), to.UnixNano()), interpolate(t, "SELECT $__unixEpochNanoFrom(), $__unixEpochNanoTo()"))
+	})
+
+	t.Run("should set up the fill mode of the query", func(t *testing.T) {
+		tcs := map[string]map[string]any{
+			"NULL":     {"fill": true, "fillInterval": 300.0, "fillMode": "null"},
+			"previous": {"fill": true, "fillInterval": 300.0, "fillMode": "previous"},
+			"1.5":      {"fill": true, "fillInterval": 300.0, "fillMode": "value", "fillValue": 1.5},
+		}
+		for fill, props := range tcs {
+			for _, macro := range []string{"__timeGroup", "__unixEpochGroup"} {
+				query := &backend.DataQuery{JSON: []byte("{}")}
+				_, err := engine.Interpolate(query, timeRange, fmt.Sprintf("GROUP BY $%s(time_column,'5m',%s)", macro, fill))
+				require.NoError(t, err)
+
+				var model map[string]any
+				require.NoError(t, json.Unmarshal(query.JSON, &model))
+				require.Equal(t, props, model, macro)
+			}
+		}
+	})
+
+	t.Run("should keep the blocks depending on whether all values are selected", func(t *testing.T) {
+		sql := "WHERE $__timeFilter(time_column) $__ifNotAll(%s)AND host IN (%s)$__endIf"
+		require.Equal(t, "WHERE "+expected.TimeFilter+" ", interpolate(t, fmt.Sprintf(sql, "'$__all'", "'$__all'")))
+		require.Equal(t, "WHERE "+expected.TimeFilter+" AND host IN ('a','b')", interpolate(t, fmt.Sprintf(sql, "'a','b'", "'a','b'")))
+
+		sql = "SELECT $__ifAll(%s)'all'$__else'some'$__endIf AS hosts"
+		require.Equal(t, "SELECT 'all' AS hosts", interpolate(t, fmt.Sprintf(sql, "$__all")))
+		require.Equal(t, "SELECT 'some' AS hosts", interpolate(t, fmt.Sprintf(sql, "'a'")))
+	})
+
+	t.Run("should return unmodified sql if there are no macros present", func(t *testing.T) {
+		sql := "select * from table where col1 > 1 and col2 < 'abc'"
+		require.Equal(t, sql, interpolate(t, sql))
+	})
+
+	t.Run("should return the same errors", func(t *testing.T) {
+		tcs := map[string]string{
+			"SELECT $__time()":                                   "missing time column argument for macro __time",
+			"WHERE $__timeFilter()":                              "missing time column argument for macro __timeFilter",
+			"GROUP BY $__timeGroup(time_column)":                 "macro __timeGroup needs time column and interval and optional fill value",
+			"GROUP BY $__unixEpochGroup(time_column,'5x')":       "error parsing interval '5x'",
+			"GROUP BY $__timeGroup(time_column,'5m',abc)":        "error parsing fill value abc",
+			"GROUP BY $__timeBucket(time_column)":                "macro __timeBucket needs time column and interval and optional alignment",
+			"GROUP BY $__timeBucket(time_column,'5m','later')":   "error parsing alignment 'later'",
+			"WHERE $__ifAll($__all) host = 'a'":                  "missing $__endIf for macro __ifAll",
+			"SELECT $__unknown(time_column)":                     `unknown macro "__unknown"`,
+			"SELECT $__timeGroupAlias(time_column) FROM metrics": "macro __timeGroup needs time column and interval and optional fill value",
+		}
+		for sql, expectedError := range tcs {
+			_, err := engine.Interpolate(&backend.DataQuery{JSON: []byte("{}")}, timeRange, sql)
+			require.EqualError(t, err, expectedError, sql)
+		}
+	})
+}
@@ -14,6 +14,7 @@ import {
   downsamplingTypes,
   ExpressionQuery,
   ExpressionQueryType,
+  getReducerLabel,
   reducerModes,
   ReducerMode,
   reducerTypes,
@@ -298,7 +299,6 @@ function ReduceConditionViewer({ model }: { model: ExpressionQuery }) {
   const styles = useStyles2(getReduceConditionViewerStyles);
 
   const { reducer, expression, settings } = model;
-  const reducerType = reducerTypes.find((rt) => rt.value === reducer);
 
   const reducerMode = settings?.mode ?? ReducerMode.Strict;
   const modeName = reducerModes.find((rm) => rm.value === reducerMode);
@@ -306,7 +306,7 @@ function ReduceConditionViewer({ model }: { model: ExpressionQuery }) {
   return (
     <div className={styles.container}>
       <div className={styles.label}>Function</div>
-      <div className={styles.value}>{reducerType?.label}</div>
+      <div className={styles.value}>{getReducerLabel(reducerTypes, reducer)}</div>
 
       <div className={styles.label}>Input</div>
       <div className={styles.value}>{expression}</div>
@@ -336,7 +336,6 @@ function ResampleExpressionViewer({ model }: { model: ExpressionQuery }) {
   const styles = useStyles2(getResampleExpressionViewerStyles);
 
   const { expression, window, downsampler, upsampler } = model;
-  const downsamplerType = downsamplingTypes.find((dt) => dt.value === downsampler);
   const upsamplerType = upsamplingTypes.find((ut) => ut.value === upsampler);
 
   return (
@@ -348,7 +347,7 @@ function ResampleExpressionViewer({ model }: { model: ExpressionQuery }) {
       <div className={styles.value}>{window}</div>
 
       <div className={styles.label}>Downsample</div>
-      <div className={styles.value}>{downsamplerType?.label}</div>
+      <div className={styles.value}>{getReducerLabel(downsamplingTypes, downsampler)}</div>
 
       <div className={styles.label}>Upsample</div>
       <div className={styles.value}>{upsamplerType?.label}</div>
@@ -11,6 +11,7 @@ const LokiStateHistory = lazy(() => import('../components/rules/state-history/Lo
 
 enum StateHistoryImplementation {
   Loki = 'loki',
+  Database = 'database',
   Annotations = 'annotations',
 }
 
@@ -20,14 +21,16 @@ function useStateHistoryModal() {
 
   const styles = useStyles2(getStyles);
 
-  // can be "loki", "multiple" or "annotations"
+  // can be "loki", "database", "multiple" or "annotations"
   const stateHistoryBackend = config.unifiedAlerting.alertStateHistoryBackend;
-  // can be "loki" or "annotations"
+  // can be "loki", "database" or "annotations"
   const stateHistoryPrimary = config.unifiedAlerting.alertStateHistoryPrimary;
 
-  // if "loki" is either the backend or the primary, show the new state history implementation
+  // if "loki" or "database" is either the backend or the primary, show the new state history implementation
+  // the database backend returns state history in the same format as Loki
   const usingNewAlertStateHistory = [stateHistoryBackend, stateHistoryPrimary].some(
-    (implementation) => implementation === StateHistoryImplementation.Loki
+    (implementation) =>
+      implementation === StateHistoryImplementation.Loki || implementation === StateHistoryImplementation.Database
   );
   const implementation = usingNewAlertStateHistory
     ? StateHistoryImplementation.Loki
@@ -0,0 +1,37 @@
+import React from 'react';
+
+import { InlineField, Input } from '@grafana/ui';
+
+import { DEFAULT_PERCENTILE, getPercentile, isPercentileReducer, toPercentileReducer } from '../types';
+
+interface Props {
+  labelWidth?: number | 'auto';
+  reducer?: string;
+  onChange: (reducer: string) => void;
+}
+
+// Edits the percentile of a percentile reducer, e.g. the 95 of `percentile(95)`.
+export const PercentileField = ({ labelWidth = 'auto', reducer, onChange }: Props) => {
+  if (!isPercentileReducer(reducer)) {
+    return null;
+  }
+
+  const onPercentileChange = (e: React.FocusEvent<HTMLInputElement>) => {
+    const value = e.currentTarget.valueAsNumber;
+    onChange(toPercentileReducer(isNaN(value) ? DEFAULT_PERCENTILE : value));
+  };
+
+  return (
+    <InlineField label="Percentile" labelWidth={labelWidth} tooltip="A number between 0 and 100">
+      <Input
+        type="number"
+        min={0}
+        max={100}
+        width={10}
+        aria-label="Percentile"
+        onBlur={onPercentileChange}
+        defaultValue={getPercentile(reducer)}
+      />
+    </InlineField>
+  );
+};
@@ -3,7 +3,17 @@ import React from 'react';
 import { SelectableValue } from '@grafana/data';
 import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
 
-import { ExpressionQuery, ExpressionQuerySettings, ReducerMode, reducerModes, reducerTypes } from '../types';
+import {
+  ExpressionQuery,
+  ExpressionQuerySettings,
+  findReducerType,
+  ReducerMode,
+  reducerModes,
+  reducerTypes,
+  selectReducer,
+} from '../types';
+
+import { PercentileField } from './PercentileField';
 
 interface Props {
   labelWidth?: number | 'auto';
@@ -13,14 +23,18 @@ interface Props {
 }
 
 export const Reduce = ({ labelWidth = 'auto', onChange, refIds, query }: Props) => {
-  const reducer = reducerTypes.find((o) => o.value === query.reducer);
+  const reducer = findReducerType(reducerTypes, query.reducer);
 
   const onRefIdChange = (value: SelectableValue<string>) => {
     onChange({ ...query, expression: value.value });
   };
 
   const onSelectReducer = (value: SelectableValue<string>) => {
-    onChange({ ...query, reducer: value.value });
+    onChange({ ...query, reducer: selectReducer(value.value, query.reducer) });
+  };
+
+  const onPercentileChange = (reducer: string) => {
+    onChange({ ...query, reducer });
   };
 
   const onSettingsChanged = (settings: ExpressionQuerySettings) => {
@@ -77,6 +91,7 @@ export const Reduce = ({ labelWidth = 'auto', onChange, refIds, query }: Props)
         <InlineField label="Function" labelWidth={labelWidth}>
           <Select options={reducerTypes} value={reducer} onChange={onSelectReducer} width={20} />
         </InlineField>
+        <PercentileField labelWidth={labelWidth} reducer={query.reducer} onChange={onPercentileChange} />
         <InlineField label="Mode" labelWidth={labelWidth}>
           <Select onChange={onModeChanged} options={reducerModes} value={mode} width={25} />
         </InlineField>
@@ -3,7 +3,9 @@ import React, { ChangeEvent } from 'react';
 import { SelectableValue } from '@grafana/data';
 import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
 
-import { downsamplingTypes, ExpressionQuery, upsamplingTypes } from '../types';
+import { downsamplingTypes, ExpressionQuery, findReducerType, selectReducer, upsamplingTypes } from '../types';
+
+import { PercentileField } from './PercentileField';
 
 interface Props {
   refIds: Array<SelectableValue<string>>;
@@ -13,7 +15,7 @@ interface Props {
 }
 
 export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props) => {
-  const downsampler = downsamplingTypes.find((o) => o.value === query.downsampler);
+  const downsampler = findReducerType(downsamplingTypes, query.downsampler);
   const upsampler = upsamplingTypes.find((o) => o.value === query.upsampler);
 
   const onWindowChange = (event: ChangeEvent<HTMLInputElement>) => {
@@ -25,7 +27,11 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
   };
 
   const onSelectDownsampler = (value: SelectableValue<string>) => {
-    onChange({ ...query, downsampler: value.value });
+    onChange({ ...query, downsampler: selectReducer(value.value, query.downsampler) });
+  };
+
+  const onPercentileChange = (downsampler: string) => {
+    onChange({ ...query, downsampler });
   };
 
   const onSelectUpsampler = (value: SelectableValue<string>) => {
@@ -46,6 +52,7 @@ export const Resample = ({ labelWidth = 'auto', onChange, refIds, query }: Props
         <InlineField label="Downsample">
           <Select options={downsamplingTypes} value={downsampler} onChange={onSelectDownsampler} width={25} />
         </InlineField>
+        <PercentileField reducer={query.downsampler} onChange={onPercentileChange} />
         <InlineField label="Upsample">
           <Select options={upsamplingTypes} value={upsampler} onChange={onSelectUpsampler} width={25} />
         </InlineField>
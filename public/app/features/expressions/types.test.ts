@@ -0,0 +1,22 @@
+import { downsamplingTypes, getPercentile, getReducerLabel, reducerTypes, selectReducer } from './types';
+
+describe('percentile reducer', () => {
+  it('should read the percentile of the reducer', () => {
+    expect(getPercentile('percentile(95)')).toBe(95);
+    expect(getPercentile('percentile(99.9)')).toBe(99.9);
+    expect(getPercentile('percentile')).toBeUndefined();
+    expect(getPercentile('mean')).toBeUndefined();
+  });
+
+  it('should select the percentile option with the default or the current percentile', () => {
+    expect(selectReducer('percentile', 'mean')).toBe('percentile(95)');
+    expect(selectReducer('percentile', 'percentile(50)')).toBe('percentile(50)');
+    expect(selectReducer('median', 'percentile(50)')).toBe('median');
+  });
+
+  it('should show the percentile in the label', () => {
+    expect(getReducerLabel(reducerTypes, 'percentile(90)')).toBe('Percentile (90)');
+    expect(getReducerLabel(downsamplingTypes, 'rate')).toBe('Rate');
+    expect(getReducerLabel(reducerTypes, 'unknown')).toBeUndefined();
+  });
+});
@@ -61,6 +61,41 @@ export const expressionTypes: Array<SelectableValue<ExpressionQueryType>> = [
   },
 ];
 
+/**
+ * The percentile reducer takes the percentile as an argument, e.g. `percentile(95)`. Its option is selected for any
+ * percentile.
+ */
+export const PERCENTILE_REDUCER = 'percentile';
+export const DEFAULT_PERCENTILE = 95;
+
+export const isPercentileReducer = (reducer?: string): boolean => !!reducer?.startsWith(`${PERCENTILE_REDUCER}(`);
+
+export const getPercentile = (reducer?: string): number | undefined => {
+  if (!reducer || !isPercentileReducer(reducer)) {
+    return undefined;
+  }
+  const percentile = parseFloat(reducer.slice(PERCENTILE_REDUCER.length + 1, -1));
+  return isNaN(percentile) ? undefined : percentile;
+};
+
+export const toPercentileReducer = (percentile: number): string => `${PERCENTILE_REDUCER}(${percentile})`;
+
+export const findReducerType = (types: Array<SelectableValue<string>>, reducer?: string) =>
+  types.find((o) => o.value === (isPercentileReducer(reducer) ? PERCENTILE_REDUCER : reducer));
+
+// Returns the label of the reducer, including the percentile of a percentile reducer, e.g. `Percentile (95)`.
+export const getReducerLabel = (types: Array<SelectableValue<string>>, reducer?: string): string | undefined => {
+  const label = findReducerType(types, reducer)?.label;
+  const percentile = getPercentile(reducer);
+  return label && percentile !== undefined ? `${label} (${percentile})` : label;
+};
+
+/**
+ * Returns the reducer of the selected option, the percentile option keeps the percentile of the current reducer.
+ */
+export const selectReducer = (value: string | undefined, current?: string): string | undefined =>
+  value === PERCENTILE_REDUCER ? toPercentileReducer(getPercentile(current) ?? DEFAULT_PERCENTILE) : value;
+
 export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.min, label: 'Min', description: 'Get the minimum value' },
   { value: ReducerID.max, label: 'Max', description: 'Get the maximum value' },
@@ -68,6 +103,12 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: PERCENTILE_REDUCER, label: 'Percentile', description: 'Get the value at the given percentile' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the population standard deviation' },
+  { value: 'variance', label: 'Variance', description: 'Get the population variance' },
+  { value: 'increase', label: 'Increase', description: 'Get the increase of a counter, accounting for resets' },
+  { value: 'rate', label: 'Rate', description: 'Get the per-second increase of a counter' },
 ];
 
 export enum ReducerMode {
@@ -100,6 +141,12 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  { value: 'median', label: 'Median', description: 'Fill with the median value' },
+  { value: PERCENTILE_REDUCER, label: 'Percentile', description: 'Fill with the value at the given percentile' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Fill with the population standard deviation' },
+  { value: 'variance', label: 'Variance', description: 'Fill with the population variance' },
+  { value: 'increase', label: 'Increase', description: 'Fill with the increase of a counter, accounting for resets' },
+  { value: 'rate', label: 'Rate', description: 'Fill with the per-second increase of a counter' },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
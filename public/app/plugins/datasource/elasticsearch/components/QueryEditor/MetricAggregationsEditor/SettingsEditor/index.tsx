@@ -1,4 +1,4 @@
-import { uniqueId } from 'lodash';
+import { omit, uniqueId } from 'lodash';
 import React, { ComponentProps, useId, useRef, useState } from 'react';
 
 import { InlineField, Input, InlineSwitch, Select } from '@grafana/ui';
@@ -8,7 +8,7 @@ import { extendedStats } from '../../../../queryDef';
 import { MetricAggregation, ExtendedStat } from '../../../../types';
 import { SettingsEditorContainer } from '../../SettingsEditorContainer';
 import { isMetricAggregationWithInlineScript, isMetricAggregationWithMissingSupport } from '../aggregations';
-import { changeMetricMeta, changeMetricSetting } from '../state/actions';
+import { changeMetricAttribute, changeMetricMeta, changeMetricSetting } from '../state/actions';
 import { metricAggregationConfig } from '../utils';
 
 import { BucketScriptSettingsEditor } from './BucketScriptSettingsEditor';
@@ -34,6 +34,7 @@ export const SettingsEditor = ({ metric, previousMetrics }: Props) => {
   const description = useDescription(metric);
 
   const sizeFieldId = useId();
+  const pointInTimeFieldId = useId();
   const unitFieldId = useId();
   const modeFieldId = useId();
 
@@ -87,6 +88,30 @@ export const SettingsEditor = ({ metric, previousMetrics }: Props) => {
         </InlineField>
       )}
 
+      {metric.type === 'raw_data' && (
+        <InlineField
+          label="Point in time"
+          {...inlineFieldProps}
+          htmlFor={pointInTimeFieldId}
+          tooltip="Page through the documents on a point in time, so that documents indexed in the meantime do not shift the pages"
+        >
+          <InlineSwitch
+            id={pointInTimeFieldId}
+            value={!!metric.settings?.pointInTime}
+            onChange={(e: React.ChangeEvent<HTMLInputElement>) =>
+              dispatch(
+                changeMetricAttribute({
+                  metric,
+                  attribute: 'settings',
+                  // the pages of a previous point in time cannot be searched anymore
+                  newValue: { ...omit(metric.settings, 'pitId', 'searchAfter'), pointInTime: e.target.checked },
+                })
+              )
+            }
+          />
+        </InlineField>
+      )}
+
       {metric.type === 'logs' && <SettingField label="Limit" metric={metric} settingName="limit" placeholder="500" />}
 
       {metric.type === 'cardinality' && (
@@ -0,0 +1,43 @@
+import React from 'react';
+
+import { PanelData } from '@grafana/data';
+import { Button, InlineLabel, Stack } from '@grafana/ui';
+
+import { firstPageQuery, getPaginationMeta, isFirstPage, isPaginatedQuery, nextPageQuery } from '../../pagination';
+import { ElasticsearchQuery } from '../../types';
+
+interface Props {
+  query: ElasticsearchQuery;
+  data?: PanelData;
+  onChange: (query: ElasticsearchQuery) => void;
+  onRunQuery: () => void;
+}
+
+// Pages through the documents of a raw data query that has the point in time setting enabled.
+export const RawDataPagination = ({ query, data, onChange, onRunQuery }: Props) => {
+  if (!isPaginatedQuery(query)) {
+    return null;
+  }
+
+  const meta = getPaginationMeta(data, query.refId);
+  const changePage = (newQuery: ElasticsearchQuery) => {
+    onChange(newQuery);
+    onRunQuery();
+  };
+
+  return (
+    <Stack gap={0.5}>
+      <InlineLabel width={17}>Pagination</InlineLabel>
+      <Button variant="secondary" disabled={isFirstPage(query)} onClick={() => changePage(firstPageQuery(query))}>
+        First page
+      </Button>
+      <Button
+        variant="secondary"
+        disabled={!meta?.hasMore}
+        onClick={() => meta && changePage(nextPageQuery(query, meta))}
+      >
+        Next page
+      </Button>
+    </Stack>
+  );
+};
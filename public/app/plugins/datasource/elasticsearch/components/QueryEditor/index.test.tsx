@@ -1,6 +1,8 @@
 import { fireEvent, render, screen } from '@testing-library/react';
 import React from 'react';
 
+import { getDefaultTimeRange, LoadingState, PanelData, toDataFrame } from '@grafana/data';
+
 import { ElasticDatasource } from '../../datasource';
 import { ElasticsearchQuery } from '../../types';
 
@@ -129,4 +131,52 @@ describe('QueryEditor', () => {
 
     expect(screen.getByText('Group By')).toBeInTheDocument();
   });
+
+  it('Should request the next page of a raw data query with point in time', () => {
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      query: '',
+      metrics: [{ id: '1', type: 'raw_data', settings: { size: '500', pointInTime: true } }],
+      bucketAggs: [],
+    };
+    const data: PanelData = {
+      state: LoadingState.Done,
+      timeRange: getDefaultTimeRange(),
+      series: [
+        {
+          ...toDataFrame({ fields: [] }),
+          refId: 'A',
+          meta: { custom: { pitId: 'pit-1', searchAfter: [42], hasMore: true } },
+        },
+      ],
+    };
+    const onChange = jest.fn<void, [ElasticsearchQuery]>();
+    const onRunQuery = jest.fn();
+
+    render(
+      <QueryEditor query={query} datasource={datasourceMock} onChange={onChange} onRunQuery={onRunQuery} data={data} />
+    );
+
+    expect(screen.getByRole('button', { name: 'First page' })).toBeDisabled();
+    fireEvent.click(screen.getByRole('button', { name: 'Next page' }));
+
+    expect(onChange).toHaveBeenCalledWith({
+      ...query,
+      metrics: [
+        { id: '1', type: 'raw_data', settings: { size: '500', pointInTime: true, pitId: 'pit-1', searchAfter: [42] } },
+      ],
+    });
+    expect(onRunQuery).toHaveBeenCalled();
+  });
+
+  it('Should not change ES|QL queries', () => {
+    const query: ElasticsearchQuery = { refId: 'A', queryType: 'esql', query: 'FROM logs | LIMIT 10' };
+    const onChange = jest.fn();
+
+    render(<QueryEditor query={query} datasource={datasourceMock} onChange={onChange} onRunQuery={noop} />);
+
+    expect(screen.getByText('ES|QL query')).toBeInTheDocument();
+    expect(screen.queryByText('Lucene Query')).not.toBeInTheDocument();
+    expect(onChange).not.toHaveBeenCalled();
+  });
 });
@@ -16,6 +16,7 @@ import { ElasticsearchProvider } from './ElasticsearchQueryContext';
 import { MetricAggregationsEditor } from './MetricAggregationsEditor';
 import { metricAggregationConfig } from './MetricAggregationsEditor/utils';
 import { QueryTypeSelector } from './QueryTypeSelector';
+import { RawDataPagination } from './RawDataPagination';
 import { changeAliasPattern, changeQuery } from './state';
 
 export type ElasticQueryEditorProps = QueryEditorProps<ElasticDatasource, ElasticsearchQuery, ElasticsearchOptions>;
@@ -46,9 +47,19 @@ function useElasticVersion(datasource: ElasticDatasource): SemVer | null {
   return version;
 }
 
-export const QueryEditor = ({ query, onChange, onRunQuery, datasource, range }: ElasticQueryEditorProps) => {
+export const QueryEditor = ({ query, onChange, onRunQuery, datasource, range, data }: ElasticQueryEditorProps) => {
   const elasticVersion = useElasticVersion(datasource);
   const showUnsupportedMessage = elasticVersion != null && !isSupportedVersion(elasticVersion);
+
+  // ES|QL queries are only run by the backend and have no editor, the provider would add aggregations to them
+  if (query.queryType === 'esql') {
+    return (
+      <Alert title="ES|QL query" severity="info">
+        This query is an ES|QL query, which can only be edited in the query JSON, e.g. in the query inspector.
+      </Alert>
+    );
+  }
+
   return (
     <ElasticsearchProvider
       datasource={datasource}
@@ -59,6 +70,7 @@ export const QueryEditor = ({ query, onChange, onRunQuery, datasource, range }:
     >
       {showUnsupportedMessage && <Alert title={unsupportedVersionMessage} />}
       <QueryEditorForm value={query} />
+      <RawDataPagination query={query} data={data} onChange={onChange} onRunQuery={onRunQuery} />
     </ElasticsearchProvider>
   );
 };
@@ -37,6 +37,8 @@ composableKinds: DataQuery: {
 				bucketAggs?: [...#BucketAggregation]
 				// List of metric aggregations
 				metrics?: [...#MetricAggregation]
+				// Format of the frame of an ES|QL query, whose queryType is #ElasticsearchQueryType
+				esqlFormat?: #EsqlFormat
 
 				#BucketAggregation: #DateHistogram | #Histogram | #Terms | #Filters | #GeoHashGrid | #Nested @cuetsy(kind="type")
 				#MetricAggregation: #Count | #PipelineMetricAggregation | #MetricAggregationWithSettings     @cuetsy(kind="type")
@@ -251,7 +253,15 @@ composableKinds: DataQuery: {
 				#RawData: {
 					#BaseMetricAggregation
 					type: #MetricAggregationType & "raw_data"
-					settings?: size?: string
+					settings?: {
+						size?: string
+						// Page through the documents with search_after on a point in time
+						pointInTime?: bool
+						// Point in time of the pages, returned in the custom metadata of the frame of the first page
+						pitId?: string
+						// Sort values of the last document of the previous page, returned in the custom metadata of its frame
+						searchAfter?: [...(string | number)]
+					}
 				} @cuetsy(kind="interface")
 
 				#Logs: {
@@ -398,6 +408,14 @@ composableKinds: DataQuery: {
 
 				#PipelineMetricAggregation:     #MovingAverage | #Derivative | #CumulativeSum | #BucketScript                                                                                                                                                                        @cuetsy(kind="type")
 				#MetricAggregationWithSettings: #BucketScript | #CumulativeSum | #Derivative | #SerialDiff | #RawData | #RawDocument | #UniqueCount | #Percentiles | #ExtendedStats | #Min | #Max | #Sum | #Average | #MovingAverage | #MovingFunction | #Logs | #Rate | #TopMetrics @cuetsy(kind="type")
+
+				// Defines the supported queryTypes. The query of an ES|QL query is run by the backend, and its metrics and
+				// bucket aggregations are ignored. The queries built from the metrics and bucket aggregations have no queryType.
+				#ElasticsearchQueryType: "esql" @cuetsy(kind="type")
+
+				// auto returns a time series if the result of the ES|QL query is a time series, logs if the result contains the
+				// log message field, and a table otherwise.
+				#EsqlFormat: "auto" | "table" | "time_series" | "logs" @cuetsy(kind="type")
 			}
 		}]
 		lenses: []
@@ -236,6 +236,18 @@ export interface RawDocument extends BaseMetricAggregation {
 export interface RawData extends BaseMetricAggregation {
   settings?: {
     size?: string;
+    /**
+     * Page through the documents with search_after on a point in time
+     */
+    pointInTime?: boolean;
+    /**
+     * Point in time of the pages, returned in the custom metadata of the frame of the first page
+     */
+    pitId?: string;
+    /**
+     * Sort values of the last document of the previous page, returned in the custom metadata of its frame
+     */
+    searchAfter?: Array<(string | number)>;
   };
   type: 'raw_data';
 }
@@ -376,6 +388,18 @@ export type PipelineMetricAggregation = (MovingAverage | Derivative | Cumulative
 
 export type MetricAggregationWithSettings = (BucketScript | CumulativeSum | Derivative | SerialDiff | RawData | RawDocument | UniqueCount | Percentiles | ExtendedStats | Min | Max | Sum | Average | MovingAverage | MovingFunction | Logs | Rate | TopMetrics);
 
+/**
+ * Defines the supported queryTypes. The query of an ES|QL query is run by the backend, and its metrics and
+ * bucket aggregations are ignored. The queries built from the metrics and bucket aggregations have no queryType.
+ */
+export type ElasticsearchQueryType = 'esql';
+
+/**
+ * auto returns a time series if the result of the ES|QL query is a time series, logs if the result contains the
+ * log message field, and a table otherwise.
+ */
+export type EsqlFormat = ('auto' | 'table' | 'time_series' | 'logs');
+
 export interface Elasticsearch extends common.DataQuery {
   /**
    * Alias pattern
@@ -385,6 +409,10 @@ export interface Elasticsearch extends common.DataQuery {
    * List of bucket aggregations
    */
   bucketAggs?: Array<BucketAggregation>;
+  /**
+   * Format of the frame of an ES|QL query, whose queryType is #ElasticsearchQueryType
+   */
+  esqlFormat?: EsqlFormat;
   /**
    * List of metric aggregations
    */
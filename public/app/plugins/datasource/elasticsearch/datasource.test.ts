@@ -1,6 +1,7 @@
 import { map } from 'lodash';
 import { Observable, of, throwError } from 'rxjs';
 import { getQueryOptions } from 'test/helpers/getQueryOptions';
+import { initTemplateSrv } from 'test/helpers/initTemplateSrv';
 
 import {
   CoreApp,
@@ -1550,6 +1551,30 @@ describe('targetContainsTemplate', () => {
   });
 });
 
+describe('applyTemplateVariables', () => {
+  it('should interpolate the variables of ES|QL queries but not the interval and ad hoc filters', () => {
+    const templateSrv = initTemplateSrv('key', [
+      { type: 'custom', name: 'host', multi: true, current: { value: ['web-1', 'db "2"'] } },
+      { type: 'custom', name: 'level', current: { value: 'error' } },
+    ]);
+    const ds = createElasticDatasource({}, templateSrv);
+    const query: ElasticsearchQuery = {
+      refId: 'A',
+      queryType: 'esql',
+      query: 'FROM logs | WHERE host IN ($host) AND level == "$level" | STATS BY BUCKET(ts, $__interval)',
+    };
+    const scopedVars = { __interval: { text: '1m', value: '1m' }, __interval_ms: { text: '60000', value: 60000 } };
+
+    const interpolated = ds.applyTemplateVariables(query, scopedVars, [
+      { key: 'service', operator: '=', value: 'api', condition: '' },
+    ]);
+
+    expect(interpolated.query).toBe(
+      'FROM logs | WHERE host IN ("web-1", "db \\"2\\"") AND level == "error" | STATS BY BUCKET(ts, $__interval)'
+    );
+  });
+});
+
 describe('ElasticDatasource using backend', () => {
   beforeEach(() => {
     console.error = jest.fn();
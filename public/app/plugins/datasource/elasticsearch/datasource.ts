@@ -1,4 +1,4 @@
-import { cloneDeep, find, first as _first, isNumber, isObject, isString, map as _map } from 'lodash';
+import { cloneDeep, find, first as _first, isNumber, isObject, isString, map as _map, omit } from 'lodash';
 import { from, generate, lastValueFrom, Observable, of } from 'rxjs';
 import { catchError, first, map, mergeMap, skipWhile, throwIfEmpty, tap } from 'rxjs/operators';
 import { SemVer } from 'semver';
@@ -38,6 +38,7 @@ import {
   DataSourceGetTagValuesOptions,
   AdHocVariableFilter,
   DataSourceWithQueryModificationSupport,
+  VariableWithMultiSupport,
 } from '@grafana/data';
 import {
   DataSourceWithBackend,
@@ -412,6 +413,23 @@ export class ElasticDatasource
     return this.templateSrv.replace(queryString, scopedVars, 'lucene');
   }
 
+  // Values of multi-value variables are quoted as ES|QL strings, so that they can be used in lists, e.g. `IN ($host)`
+  interpolateEsqlQuery(queryString: string, scopedVars?: ScopedVars) {
+    return this.templateSrv.replace(
+      queryString,
+      scopedVars,
+      (value: string | string[] | number, variable: VariableWithMultiSupport) => {
+        if (Array.isArray(value)) {
+          return value.map(quoteEsqlString).join(', ');
+        }
+        if (typeof value === 'string' && (variable.multi || variable.includeAll)) {
+          return quoteEsqlString(value);
+        }
+        return value;
+      }
+    );
+  }
+
   interpolateVariablesInQueries(
     queries: ElasticsearchQuery[],
     scopedVars: ScopedVars,
@@ -986,6 +1004,16 @@ export class ElasticDatasource
     scopedVars: ScopedVars,
     filters?: AdHocVariableFilter[]
   ): ElasticsearchQuery {
+    // ES|QL queries are not Lucene queries, so neither the lucene format nor the ad hoc filters apply to them. The
+    // interval variables are left to the $__interval macros of the backend, which it formats as ES|QL time spans.
+    if (query.queryType === 'esql') {
+      return {
+        ...query,
+        datasource: this.getRef(),
+        query: this.interpolateEsqlQuery(query.query || '', omit(scopedVars, '__interval', '__interval_ms')),
+      };
+    }
+
     // We need a separate interpolation format for lucene queries, therefore we first interpolate any
     // lucene query string and then everything else
     const interpolateBucketAgg = (bucketAgg: BucketAggregation): BucketAggregation => {
@@ -1100,6 +1128,10 @@ export class ElasticDatasource
   };
 }
 
+function quoteEsqlString(value: string) {
+  return `"${value.replace(/\\/g, '\\\\').replace(/"/g, '\\"')}"`;
+}
+
 export function enhanceDataFrameWithDataLinks(dataFrame: DataFrame, dataLinks: DataLinkConfig[]) {
   if (!dataLinks.length) {
     return;
@@ -4,7 +4,10 @@ import { TemplateSrv } from '@grafana/runtime';
 import { ElasticDatasource } from './datasource';
 import { ElasticsearchOptions } from './types';
 
-export function createElasticDatasource(settings: Partial<DataSourceInstanceSettings<ElasticsearchOptions>> = {}) {
+export function createElasticDatasource(
+  settings: Partial<DataSourceInstanceSettings<ElasticsearchOptions>> = {},
+  templateSrv: TemplateSrv = createTemplateSrv()
+) {
   const { jsonData, ...rest } = settings;
 
   const instanceSettings: DataSourceInstanceSettings<ElasticsearchOptions> = {
@@ -45,7 +48,11 @@ export function createElasticDatasource(settings: Partial<DataSourceInstanceSett
     ...rest,
   };
 
-  const templateSrv: TemplateSrv = {
+  return new ElasticDatasource(instanceSettings, templateSrv);
+}
+
+function createTemplateSrv(): TemplateSrv {
+  return {
     getVariables: () => [],
     replace: (text?: string) => {
       if (text?.startsWith('$')) {
@@ -57,6 +64,4 @@ export function createElasticDatasource(settings: Partial<DataSourceInstanceSett
     containsTemplate: (text?: string) => text?.includes('$') ?? false,
     updateTimeRange: () => {},
   };
-
-  return new ElasticDatasource(instanceSettings, templateSrv);
 }
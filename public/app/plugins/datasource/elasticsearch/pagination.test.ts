@@ -0,0 +1,53 @@
+import { getDefaultTimeRange, LoadingState, PanelData, toDataFrame } from '@grafana/data';
+
+import { firstPageQuery, getPaginationMeta, isFirstPage, isPaginatedQuery, nextPageQuery } from './pagination';
+import { ElasticsearchQuery } from './types';
+
+const query: ElasticsearchQuery = {
+  refId: 'A',
+  query: '',
+  metrics: [{ id: '1', type: 'raw_data', settings: { size: '2', pointInTime: true } }],
+  bucketAggs: [],
+};
+
+const panelData = (custom?: Record<string, unknown>): PanelData => ({
+  state: LoadingState.Done,
+  timeRange: getDefaultTimeRange(),
+  series: [{ ...toDataFrame({ fields: [] }), refId: 'A', meta: { custom } }],
+});
+
+describe('pagination', () => {
+  it('should only paginate raw data queries with the point in time setting', () => {
+    expect(isPaginatedQuery(query)).toBe(true);
+    expect(isPaginatedQuery({ ...query, metrics: [{ id: '1', type: 'raw_data', settings: { size: '2' } }] })).toBe(
+      false
+    );
+    expect(isPaginatedQuery({ ...query, metrics: [{ id: '1', type: 'logs', settings: { limit: '2' } }] })).toBe(false);
+  });
+
+  it('should read the pagination metadata of the frame of the query', () => {
+    const data = panelData({ pitId: 'pit-1', searchAfter: [1700000000000, 42], hasMore: true });
+
+    expect(getPaginationMeta(data, 'A')).toEqual({ pitId: 'pit-1', searchAfter: [1700000000000, 42], hasMore: true });
+    expect(getPaginationMeta(data, 'B')).toBeUndefined();
+    expect(getPaginationMeta(panelData(), 'A')).toBeUndefined();
+    expect(getPaginationMeta(undefined, 'A')).toBeUndefined();
+  });
+
+  it('should build the query of the next page and go back to the first page', () => {
+    const next = nextPageQuery(query, { pitId: 'pit-1', searchAfter: [1700000000000, 42], hasMore: true });
+
+    expect(next.metrics?.[0]).toEqual({
+      id: '1',
+      type: 'raw_data',
+      settings: { size: '2', pointInTime: true, pitId: 'pit-1', searchAfter: [1700000000000, 42] },
+    });
+    expect(isFirstPage(query)).toBe(true);
+    expect(isFirstPage(next)).toBe(false);
+    expect(firstPageQuery(next)).toEqual(query);
+  });
+
+  it('should not change the query if the page is empty', () => {
+    expect(nextPageQuery(query, { pitId: 'pit-1', searchAfter: null, hasMore: false })).toEqual(query);
+  });
+});
@@ -0,0 +1,64 @@
+import { omit } from 'lodash';
+
+import { PanelData } from '@grafana/data';
+
+import { ElasticsearchQuery, MetricAggregation, RawData } from './types';
+
+/**
+ * The custom metadata of the frame of a raw data query that pages through the documents with a point in time,
+ * see setPaginationCustomMeta in pkg/tsdb/elasticsearch/response_parser.go.
+ */
+export interface PaginationMeta {
+  pitId: string;
+  // sort values of the last document of the page, null if the page is empty
+  searchAfter: Array<string | number> | null;
+  hasMore: boolean;
+}
+
+const isRawData = (metric?: MetricAggregation): metric is RawData => metric?.type === 'raw_data';
+
+export const isPaginatedQuery = (query: ElasticsearchQuery): boolean => {
+  const metric = query.metrics?.[0];
+  return isRawData(metric) && !!metric.settings?.pointInTime;
+};
+
+export const isFirstPage = (query: ElasticsearchQuery): boolean => {
+  const metric = query.metrics?.[0];
+  return !isRawData(metric) || !metric.settings?.searchAfter;
+};
+
+export const getPaginationMeta = (data: PanelData | undefined, refId: string): PaginationMeta | undefined => {
+  const custom = data?.series.find((frame) => frame.refId === refId)?.meta?.custom;
+  if (!custom || typeof custom.pitId !== 'string') {
+    return undefined;
+  }
+
+  return {
+    pitId: custom.pitId,
+    searchAfter: custom.searchAfter ?? null,
+    hasMore: !!custom.hasMore,
+  };
+};
+
+/**
+ * Returns the query of the page after the one described by meta. The page is searched on the same point in time, so
+ * documents indexed in the meantime do not shift the pages.
+ */
+export const nextPageQuery = (query: ElasticsearchQuery, meta: PaginationMeta): ElasticsearchQuery => ({
+  ...query,
+  metrics: query.metrics?.map((metric, index) =>
+    index === 0 && isRawData(metric) && meta.searchAfter
+      ? { ...metric, settings: { ...metric.settings, pitId: meta.pitId, searchAfter: meta.searchAfter } }
+      : metric
+  ),
+});
+
+/**
+ * Returns the query of the first page, which opens a new point in time.
+ */
+export const firstPageQuery = (query: ElasticsearchQuery): ElasticsearchQuery => ({
+  ...query,
+  metrics: query.metrics?.map((metric, index) =>
+    index === 0 && isRawData(metric) ? { ...metric, settings: omit(metric.settings, 'pitId', 'searchAfter') } : metric
+  ),
+});
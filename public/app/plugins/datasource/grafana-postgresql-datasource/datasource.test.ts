@@ -24,6 +24,8 @@ import {
 import { QueryFormat, SQLQuery } from 'app/features/plugins/sql/types';
 import { makeVariable } from 'app/features/plugins/sql/utils/testHelpers';
 
+import { initTemplateSrv } from '../../../../test/helpers/initTemplateSrv';
+
 import { PostgresDatasource } from './datasource';
 import { PostgresOptions } from './types';
 
@@ -760,6 +762,25 @@ describe('PostgreSQLDatasource', () => {
     });
   });
 
+  describe('When applying template variables', () => {
+    it('should leave the interval arithmetic macro to the backend', () => {
+      const templateSrv = initTemplateSrv('key', []);
+      const { ds } = setupTestContext({}, undefined, templateSrv);
+      const query: SQLQuery = {
+        rawSql: 'SELECT $__interval_ms, $__intervalMs(* 2), $__interval',
+        refId: 'A',
+        rawQuery: true,
+      };
+
+      const result = ds.applyTemplateVariables(query, {
+        __interval: { text: '1m', value: '1m' },
+        __interval_ms: { text: '60000', value: 60000 },
+      });
+
+      expect(result.rawSql).toBe('SELECT 60000, $__intervalMs(* 2), 1m');
+    });
+  });
+
   describe('targetContainsTemplate', () => {
     it('given query that contains template variable it should return true', () => {
       const rawSql = `SELECT
@@ -3,7 +3,7 @@ import { of } from 'rxjs';
 import { createFetchResponse } from 'test/helpers/createFetchResponse';
 
 import { AbstractLabelMatcher, AbstractLabelOperator, getFrameDisplayName, dateTime } from '@grafana/data';
-import { BackendSrvRequest } from '@grafana/runtime';
+import { BackendSrvRequest, HealthStatus } from '@grafana/runtime';
 import { backendSrv } from 'app/core/services/backend_srv'; // will use the version in __mocks__
 import { TemplateSrv } from 'app/features/templating/template_srv';
 
@@ -46,6 +46,29 @@ describe('graphiteDatasource', () => {
     expect(ctx.ds.graphiteVersion).toBe(DEFAULT_GRAPHITE_VERSION);
   });
 
+  describe('testDatasource', () => {
+    it('should call the health check of the backend', async () => {
+      const healthCheckMock = jest
+        .spyOn(ctx.ds, 'callHealthCheck')
+        .mockResolvedValue({ status: HealthStatus.OK, message: 'Data source is working' });
+
+      await expect(ctx.ds.testDatasource()).resolves.toEqual({ status: 'success', message: 'Data source is working' });
+      expect(healthCheckMock).toHaveBeenCalledTimes(1);
+      expect(fetchMock).not.toHaveBeenCalled();
+    });
+
+    it('should reject with the message of a failed health check', async () => {
+      jest
+        .spyOn(ctx.ds, 'callHealthCheck')
+        .mockResolvedValue({ status: HealthStatus.Error, message: 'failed to render a series: request failed' });
+
+      await expect(ctx.ds.testDatasource()).rejects.toMatchObject({
+        status: 'error',
+        message: 'failed to render a series: request failed',
+      });
+    });
+  });
+
   describe('convertResponseToDataFrames', () => {
     it('should transform regular result', () => {
       const result = ctx.ds.convertResponseToDataFrames({
@@ -290,10 +313,9 @@ describe('graphiteDatasource', () => {
       '{"testFunction":{"name":"function","description":"description","module":"graphite.render.functions","group":"Transform","params":[{"name":"param","type":"intOrInf","required":true,"default":Infinity}]}}';
 
     it('should parse the response with an invalid JSON', async () => {
-      fetchMock.mockImplementation(() => {
-        return of(createFetchResponse(INVALID_JSON));
-      });
+      const getResourceMock = jest.spyOn(ctx.ds, 'getResource').mockResolvedValue(INVALID_JSON);
       const funcDefs = await ctx.ds.getFuncDefs();
+      expect(getResourceMock).toHaveBeenCalledWith('functions', undefined, { responseType: 'text' });
       expect(funcDefs).toEqual({
         testFunction: {
           category: 'Transform',
@@ -415,9 +437,14 @@ describe('graphiteDatasource', () => {
     let requestOptions: BackendSrvRequest;
 
     beforeEach(() => {
-      fetchMock.mockImplementation((options) => {
-        requestOptions = options;
-        return of(createFetchResponse(['backend_01', 'backend_02']));
+      // metric find and tag autocompletion are forwarded to Graphite by the resources of the backend
+      jest.spyOn(ctx.ds, 'getResource').mockImplementation((path, params, options) => {
+        requestOptions = { ...options, method: 'GET', url: path, params: params ?? options?.params };
+        return Promise.resolve(['backend_01', 'backend_02']);
+      });
+      jest.spyOn(ctx.ds, 'postResource').mockImplementation((path, data, options) => {
+        requestOptions = { ...options, method: 'POST', url: path, data };
+        return Promise.resolve(['backend_01', 'backend_02']);
       });
     });
 
@@ -426,7 +453,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('tags/autoComplete/tags');
       expect(requestOptions.params?.expr).toEqual([]);
       expect(results).not.toBe(null);
     });
@@ -436,7 +463,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('tags/autoComplete/tags');
       expect(requestOptions.params?.expr).toEqual(['server=backend_01']);
       expect(results).not.toBe(null);
     });
@@ -446,7 +473,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('tags/autoComplete/tags');
       expect(requestOptions.params?.expr).toEqual(['server=backend_01']);
       expect(results).not.toBe(null);
     });
@@ -456,7 +483,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('tags/autoComplete/values');
       expect(requestOptions.params?.tag).toBe('server');
       expect(requestOptions.params?.expr).toEqual([]);
       expect(results).not.toBe(null);
@@ -467,7 +494,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('tags/autoComplete/values');
       expect(requestOptions.params?.tag).toBe('server');
       expect(requestOptions.params?.expr).toEqual(['server=~backend*']);
       expect(results).not.toBe(null);
@@ -478,7 +505,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('tags/autoComplete/values');
       expect(requestOptions.params?.tag).toBe('server');
       expect(requestOptions.params?.expr).toEqual([]);
       expect(results).not.toBe(null);
@@ -489,13 +516,13 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('tags/autoComplete/values');
       expect(requestOptions.params?.tag).toBe('server');
       expect(requestOptions.params?.expr).toEqual(['server=~backend*']);
       expect(results).not.toBe(null);
     });
 
-    it('/metrics/find should be POST', () => {
+    it('metrics/find should be POST', () => {
       ctx.templateSrv.init([
         {
           type: 'query',
@@ -506,7 +533,7 @@ describe('graphiteDatasource', () => {
       ctx.ds.metricFindQuery('[[foo]]').then((data: any) => {
         results = data;
       });
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
+      expect(requestOptions.url).toBe('metrics/find');
       expect(requestOptions.method).toEqual('POST');
       expect(requestOptions.headers).toHaveProperty('Content-Type', 'application/x-www-form-urlencoded');
       expect(requestOptions.data).toMatch(`query=bar`);
@@ -518,7 +545,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
+      expect(requestOptions.url).toBe('metrics/find');
       expect(requestOptions.params).toEqual({});
       expect(requestOptions.data).toEqual('query=app.backend*');
       expect(results).not.toBe(null);
@@ -529,7 +556,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
+      expect(requestOptions.url).toBe('metrics/find');
       expect(requestOptions.params).toEqual({});
       expect(requestOptions.data).toEqual('query=app.*');
       expect(results).not.toBe(null);
@@ -540,17 +567,17 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/expand');
+      expect(requestOptions.url).toBe('metrics/expand');
       expect(requestOptions.params?.query).toBe('*.servers.*');
       expect(results).not.toBe(null);
     });
 
-    it('should fetch from /metrics/find endpoint when queryType is default or query is string', async () => {
+    it('should fetch from metrics/find resource when queryType is default or query is string', async () => {
       const stringQuery = 'query';
       ctx.ds.metricFindQuery(stringQuery).then((data) => {
         results = data;
       });
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
+      expect(requestOptions.url).toBe('metrics/find');
       expect(results).not.toBe(null);
 
       const objectQuery = {
@@ -560,7 +587,7 @@ describe('graphiteDatasource', () => {
         datasource: ctx.ds,
       };
       const data = await ctx.ds.metricFindQuery(objectQuery);
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
+      expect(requestOptions.url).toBe('metrics/find');
       expect(data).toBeTruthy();
     });
 